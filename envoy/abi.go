@@ -1,491 +1,1077 @@
-//go:build cgo
-
 package envoy
 
-/*
-#include "abi.h"
-*/
-import "C"
 import (
+	"errors"
+	"fmt"
 	"io"
-	"runtime"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
-//export __envoy_dynamic_module_v1_event_program_init
-func __envoy_dynamic_module_v1_event_program_init() C.size_t {
+//go:generate go run ../internal/abigen
+
+// This file implements the event handlers called by the exported functions in abi_gen.go, and the
+// objects passed to the module code on top of the Envoy API wrappers in abi_gen.go. Without cgo,
+// the wrappers are backed by FakeHost in abi_gen_nocgo.go so that the module code can be tested without Envoy.
+
+func eventProgramInit() int {
+	if err := runProgramInitHooks(); err != nil {
+		fmt.Fprintf(os.Stderr, "envoy dynamic module: program init failed: %v\n", err)
+		return 1
+	}
 	return 0
 }
 
-//export __envoy_dynamic_module_v1_event_http_filter_init
-func __envoy_dynamic_module_v1_event_http_filter_init(
-	configPtr C.__envoy_dynamic_module_v1_type_HttpFilterConfigPtr,
-	configSize C.__envoy_dynamic_module_v1_type_HttpFilterConfigSize) C.__envoy_dynamic_module_v1_type_HttpFilterPtr {
-	rawStr := unsafe.String((*byte)(unsafe.Pointer(uintptr(configPtr))), configSize)
-	// Copy the config string to Go memory so that the caller can take ownership of the memory.
-	var configStrCopy = make([]byte, len(rawStr))
-	copy(configStrCopy, rawStr)
-	// Call the exported function from the Go module.
-	httpFilter := NewHttpFilter(rawStr)
-	pined := memManager.pinHttpFilter(httpFilter)
-	return C.__envoy_dynamic_module_v1_type_HttpFilterPtr((uintptr)(unsafe.Pointer(pined)))
-}
-
-//export __envoy_dynamic_module_v1_event_http_filter_destroy
-func __envoy_dynamic_module_v1_event_http_filter_destroy(
-	httpFilterPtr C.__envoy_dynamic_module_v1_type_HttpFilterPtr) {
-	httpFilter := memManager.unwrapPinnedHttpFilter(uintptr(httpFilterPtr))
+func eventHttpFilterInit(configPtr uintptr, configSize int) uintptr {
+	config := copyConfig(configPtr, configSize)
+	httpFilter := NewHttpFilter(config)
+	pined := memManager.pinHttpFilter(httpFilter, config, false)
+	return uintptr(unsafe.Pointer(pined))
+}
+
+func eventHttpFilterDestroy(httpFilterPtr uintptr) {
+	httpFilter := memManager.unwrapPinnedHttpFilter(httpFilterPtr)
+	if httpFilter.executor != nil {
+		httpFilter.executor.close()
+	}
 	httpFilter.filter.Destroy()
 	memManager.unpinHttpFilter(httpFilter)
 }
 
-//export __envoy_dynamic_module_v1_event_http_filter_instance_init
-func __envoy_dynamic_module_v1_event_http_filter_instance_init(
-	envoyFilterPtr C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr,
-	httpFilterPtr C.__envoy_dynamic_module_v1_type_HttpFilterPtr,
-) C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr {
-	envoyPtr := &envoyFilterInstance{raw: envoyFilterPtr}
-	httpFilter := memManager.unwrapPinnedHttpFilter(uintptr(httpFilterPtr))
-	httpInstance := httpFilter.filter.NewInstance(envoyPtr)
-	pined := memManager.pinHttpFilterInstance(httpInstance)
-	return C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr(uintptr((unsafe.Pointer(pined))))
-}
-
-//export __envoy_dynamic_module_v1_event_http_filter_instance_request_headers
-func __envoy_dynamic_module_v1_event_http_filter_instance_request_headers(
-	httpFilterInstancePtr C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr,
-	requestHeadersPtr C.__envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr,
-	endOfStream C.__envoy_dynamic_module_v1_type_EndOfStream,
-) C.__envoy_dynamic_module_v1_type_EventHttpRequestHeadersStatus {
-	httpInstance := unwrapRawPinHttpFilterInstance(uintptr(httpFilterInstancePtr))
-	mapPtr := RequestHeaders{raw: requestHeadersPtr}
-	end := endOfStream != 0
-	result := httpInstance.filterInstance.RequestHeaders(mapPtr, end)
-	return C.__envoy_dynamic_module_v1_type_EventHttpRequestHeadersStatus(result)
-}
-
-//export __envoy_dynamic_module_v1_event_http_filter_instance_request_body
-func __envoy_dynamic_module_v1_event_http_filter_instance_request_body(
-	httpFilterInstancePtr C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr,
-	buffer C.__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr,
-	endOfStream C.__envoy_dynamic_module_v1_type_EndOfStream) C.__envoy_dynamic_module_v1_type_EventHttpRequestBodyStatus {
-	httpInstance := unwrapRawPinHttpFilterInstance(uintptr(httpFilterInstancePtr))
-	buf := RequestBodyBuffer{raw: buffer}
-	end := endOfStream != 0
-	result := httpInstance.filterInstance.RequestBody(buf, end)
-	return C.__envoy_dynamic_module_v1_type_EventHttpRequestBodyStatus(result)
-}
-
-//export __envoy_dynamic_module_v1_event_http_filter_instance_response_headers
-func __envoy_dynamic_module_v1_event_http_filter_instance_response_headers(
-	httpFilterInstancePtr C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr,
-	responseHeadersMapPtr C.__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr,
-	endOfStream C.__envoy_dynamic_module_v1_type_EndOfStream) C.__envoy_dynamic_module_v1_type_EventHttpResponseHeadersStatus {
-	httpInstance := unwrapRawPinHttpFilterInstance(uintptr(httpFilterInstancePtr))
-	mapPtr := ResponseHeaders{raw: responseHeadersMapPtr}
-	end := endOfStream != 0
-	result := httpInstance.filterInstance.ResponseHeaders(mapPtr, end)
-	return C.__envoy_dynamic_module_v1_type_EventHttpResponseHeadersStatus(result)
-}
-
-//export __envoy_dynamic_module_v1_event_http_filter_instance_response_body
-func __envoy_dynamic_module_v1_event_http_filter_instance_response_body(
-	httpFilterInstancePtr C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr,
-	buffer C.__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr,
-	endOfStream C.__envoy_dynamic_module_v1_type_EndOfStream) C.__envoy_dynamic_module_v1_type_EventHttpResponseBodyStatus {
-	httpInstance := unwrapRawPinHttpFilterInstance(uintptr(httpFilterInstancePtr))
-	buf := ResponseBodyBuffer{raw: buffer}
-	end := endOfStream != 0
-	result := httpInstance.filterInstance.ResponseBody(buf, end)
-	return C.__envoy_dynamic_module_v1_type_EventHttpResponseBodyStatus(result)
-}
-
-//export __envoy_dynamic_module_v1_event_http_filter_instance_destroy
-func __envoy_dynamic_module_v1_event_http_filter_instance_destroy(
-	httpFilterInstancePtr C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr) {
-	httpInstance := unwrapRawPinHttpFilterInstance(uintptr(httpFilterInstancePtr))
+func eventUpstreamHttpFilterInit(configPtr uintptr, configSize int) uintptr {
+	newFilter := NewUpstreamHttpFilter
+	if newFilter == nil {
+		newFilter = NewHttpFilter
+	}
+	config := copyConfig(configPtr, configSize)
+	httpFilter := newFilter(config)
+	pined := memManager.pinHttpFilter(httpFilter, config, true)
+	return uintptr(unsafe.Pointer(pined))
+}
+
+func eventHttpFilterInstanceInit(envoyFilterPtr uintptr, httpFilterPtr uintptr) uintptr {
+	httpFilter := memManager.unwrapPinnedHttpFilter(httpFilterPtr)
+	pined := memManager.newHttpFilterInstance(httpFilter, envoyFilterPtr)
+	memManager.pinHttpFilterInstance(pined)
+	if pined.bypass != 0 && HostSupports(FeatureBypassEvents) {
+		hostHttpBypassEvents(envoyFilterPtr, int(pined.bypass))
+	}
+	return uintptr(unsafe.Pointer(pined))
+}
+
+func eventHttpFilterInstanceRequestHeaders(httpFilterInstancePtr uintptr, requestHeadersPtr uintptr, endOfStream bool) int {
+	defer recoverViewFault(panicOnViewFault())
+	httpInstance := unwrapRawPinHttpFilterInstance(httpFilterInstancePtr)
+	stamp := httpInstance.envoy.stamp.renew()
+	if httpInstance.filter.bodyLimit.CheckContentLength && httpInstance.requestBody != nil {
+		contentLength, _ := RequestHeaders{stamp: stamp, raw: requestHeadersPtr}.Get("content-length")
+		if !httpInstance.envoy.limitHeaders(pauseRequest, contentLength.String()) {
+			stamp.expire()
+			return int(RequestHeadersStatusStopIteration)
+		}
+	}
+	if httpInstance.requestHeaders == nil {
+		stamp.expire()
+		return int(HeadersStatusContinue)
+	}
+	resumes := httpInstance.envoy.resumes[pauseRequest].Load()
+	status := httpInstance.requestHeaders.RequestHeaders(RequestHeaders{stamp: stamp, raw: requestHeadersPtr}, endOfStream)
+	stamp.expire()
+	httpInstance.envoy.watchPause(pauseRequest, status != HeadersStatusContinue, resumes)
+	return int(status)
+}
+
+func eventHttpFilterInstanceRequestBody(httpFilterInstancePtr uintptr, buffer uintptr, endOfStream bool) int {
+	defer recoverViewFault(panicOnViewFault())
+	httpInstance := unwrapRawPinHttpFilterInstance(httpFilterInstancePtr)
+	if httpInstance.requestBody == nil {
+		httpInstance.envoy.watchPause(pauseRequest, false, 0)
+		return int(RequestBodyStatusContinue)
+	}
+	stamp := httpInstance.envoy.stamp.renew()
+	body := RequestBodyBuffer{stamp: stamp, raw: buffer}
+	if deliver, pass := httpInstance.envoy.limitBody(pauseRequest, body.Length); !deliver {
+		stamp.expire()
+		httpInstance.envoy.watchPause(pauseRequest, false, 0)
+		if pass {
+			return int(RequestBodyStatusContinue)
+		}
+		return int(RequestBodyStatusStopIterationAndBuffer)
+	}
+	resumes := httpInstance.envoy.resumes[pauseRequest].Load()
+	status := httpInstance.requestBody.RequestBody(body, endOfStream)
+	stamp.expire()
+	httpInstance.envoy.watchPause(pauseRequest, status != RequestBodyStatusContinue, resumes)
+	return int(status)
+}
+
+func eventHttpFilterInstanceResponseHeaders(httpFilterInstancePtr uintptr, responseHeadersMapPtr uintptr, endOfStream bool) int {
+	defer recoverViewFault(panicOnViewFault())
+	httpInstance := unwrapRawPinHttpFilterInstance(httpFilterInstancePtr)
+	stamp := httpInstance.envoy.stamp.renew()
+	if httpInstance.filter.bodyLimit.CheckContentLength && httpInstance.responseBody != nil {
+		contentLength, _ := ResponseHeaders{stamp: stamp, raw: responseHeadersMapPtr}.Get("content-length")
+		if !httpInstance.envoy.limitHeaders(pauseResponse, contentLength.String()) {
+			stamp.expire()
+			return int(ResponseHeadersStatusStopIteration)
+		}
+	}
+	if httpInstance.responseHeaders == nil {
+		stamp.expire()
+		return int(ResponseHeadersStatusContinue)
+	}
+	resumes := httpInstance.envoy.resumes[pauseResponse].Load()
+	status := httpInstance.responseHeaders.ResponseHeaders(ResponseHeaders{stamp: stamp, raw: responseHeadersMapPtr}, endOfStream)
+	stamp.expire()
+	httpInstance.envoy.watchPause(pauseResponse, status != ResponseHeadersStatusContinue, resumes)
+	return int(status)
+}
+
+func eventHttpFilterInstanceResponseBody(httpFilterInstancePtr uintptr, buffer uintptr, endOfStream bool) int {
+	defer recoverViewFault(panicOnViewFault())
+	httpInstance := unwrapRawPinHttpFilterInstance(httpFilterInstancePtr)
+	if httpInstance.responseBody == nil {
+		httpInstance.envoy.watchPause(pauseResponse, false, 0)
+		return int(ResponseBodyStatusContinue)
+	}
+	stamp := httpInstance.envoy.stamp.renew()
+	body := ResponseBodyBuffer{stamp: stamp, raw: buffer}
+	if deliver, pass := httpInstance.envoy.limitBody(pauseResponse, body.Length); !deliver {
+		stamp.expire()
+		httpInstance.envoy.watchPause(pauseResponse, false, 0)
+		if pass {
+			return int(ResponseBodyStatusContinue)
+		}
+		return int(ResponseBodyStatusStopIterationAndBuffer)
+	}
+	resumes := httpInstance.envoy.resumes[pauseResponse].Load()
+	status := httpInstance.responseBody.ResponseBody(body, endOfStream)
+	stamp.expire()
+	httpInstance.envoy.watchPause(pauseResponse, status != ResponseBodyStatusContinue, resumes)
+	return int(status)
+}
+
+func eventHttpFilterInstanceDestroy(httpFilterInstancePtr uintptr) {
+	defer recoverViewFault(panicOnViewFault())
+	httpInstance := unwrapRawPinHttpFilterInstance(httpFilterInstancePtr)
+	if httpInstance.envoy.watching.Load() {
+		httpInstance.envoy.disarmPause(pauseRequest)
+		httpInstance.envoy.disarmPause(pauseResponse)
+	}
+	httpInstance.envoy.detach()
+	httpInstance.envoy.stamp.expire()
 	httpInstance.filterInstance.Destroy()
-	memManager.unpinHttpFilterInstance((*pinedHttpFilterInstance)(unsafe.Pointer(uintptr(httpFilterInstancePtr))))
+	memManager.unpinHttpFilterInstance(httpInstance)
+	memManager.releaseHttpFilterInstance(httpInstance)
+}
+
+func eventHttpFilterInstanceRequestAboveHighWatermark(httpFilterInstancePtr uintptr) {
+	defer recoverViewFault(panicOnViewFault())
+	httpInstance := unwrapRawPinHttpFilterInstance(httpFilterInstancePtr)
+	if httpInstance.watermark != nil {
+		httpInstance.watermark.RequestAboveHighWatermark()
+	}
+}
+
+func eventHttpFilterInstanceRequestBelowLowWatermark(httpFilterInstancePtr uintptr) {
+	defer recoverViewFault(panicOnViewFault())
+	httpInstance := unwrapRawPinHttpFilterInstance(httpFilterInstancePtr)
+	if httpInstance.watermark != nil {
+		httpInstance.watermark.RequestBelowLowWatermark()
+	}
+}
+
+func eventHttpFilterInstanceResponseAboveHighWatermark(httpFilterInstancePtr uintptr) {
+	defer recoverViewFault(panicOnViewFault())
+	httpInstance := unwrapRawPinHttpFilterInstance(httpFilterInstancePtr)
+	if httpInstance.watermark != nil {
+		httpInstance.watermark.ResponseAboveHighWatermark()
+	}
 }
 
-// envoyFilterInstance implements the EnvoyFilterInstance interface in abi_nocgo.go which is not included in the shared library.
+func eventHttpFilterInstanceResponseBelowLowWatermark(httpFilterInstancePtr uintptr) {
+	defer recoverViewFault(panicOnViewFault())
+	httpInstance := unwrapRawPinHttpFilterInstance(httpFilterInstancePtr)
+	if httpInstance.watermark != nil {
+		httpInstance.watermark.ResponseBelowLowWatermark()
+	}
+}
+
+// copyConfig copies the config string owned by Envoy to Go memory so that the module can retain it.
+func copyConfig(configPtr uintptr, configSize int) string {
+	if configSize == 0 {
+		return ""
+	}
+	return strings.Clone(unsafe.String((*byte)(unsafe.Pointer(configPtr)), configSize))
+}
+
+// envoyFilterInstance is the underlying type of EnvoyFilterInstance.
 type envoyFilterInstance struct {
-	raw C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr
+	// mu guards raw so that the stream is not destroyed while a method is calling Envoy from another Goroutine.
+	mu sync.RWMutex
+	// raw is the pointer to the Envoy filter instance, which is zero after the stream is destroyed.
+	raw uintptr
+	// requestReadDisabled and responseReadDisabled hold the current read-disable state so that
+	// the calls to Envoy are always balanced.
+	requestReadDisabled, responseReadDisabled atomic.Bool
+	// filter is the HttpFilter of the stream, which holds the configuration of the watchdog.
+	filter *pinedHttpFilter
+	// pauseDeadline is the deadline set by SetPauseDeadline in the current event callback.
+	pauseDeadline time.Duration
+	// watching is true if the watchdog has ever watched the pauses of the stream, so that the streams that never
+	// paused skip watchMu.
+	watching atomic.Bool
+	// watchMu guards pauses.
+	watchMu sync.Mutex
+	pauses  [2]pause
+	// resumes is the number of the resumes of the directions by the module code, which tells the watchdog that
+	// the direction has been resumed during the event callback returning the Stop status.
+	resumes [2]atomic.Uint64
+	// bodyLimits is the state of the body limit, which is only accessed on the Envoy worker thread.
+	bodyLimits [2]bodyLimitStream
+	// stamp is carried by the body buffers returned by GetRequestBodyBuffer and GetResponseBodyBuffer.
+	stamp streamStamp
 }
 
-// EnvoyFilterInstance implements the EnvoyFilterInstance interface in abi_nocgo.go which is not included in the shared library.
+// ErrStreamDestroyed is returned by EnvoyFilterInstance.Err, and ReadAt of the body buffers it returns, after the
+// stream is destroyed.
+var ErrStreamDestroyed = errors.New("envoy: stream is destroyed")
+
+// detach clears the pointer to the Envoy filter instance when the stream is destroyed, which waits for the calls to
+// Envoy in flight on the other Goroutines. The envoyFilterInstance is never attached to another stream.
+func (c *envoyFilterInstance) detach() {
+	c.mu.Lock()
+	c.raw = 0
+	c.mu.Unlock()
+}
+
+// acquire returns the pointer to the Envoy filter instance, and keeps the stream alive until release is called.
+// Returns false if the stream is already destroyed, in which case release must not be called.
+func (c *envoyFilterInstance) acquire() (uintptr, bool) {
+	c.mu.RLock()
+	if c.raw == 0 {
+		c.mu.RUnlock()
+		return 0, false
+	}
+	return c.raw, true
+}
+
+// release releases the stream acquired by acquire.
+func (c *envoyFilterInstance) release() {
+	c.mu.RUnlock()
+}
+
+// EnvoyFilterInstance is an opaque object that represents the underlying Envoy Http filter instance.
+// This is used to interact with it from the module code.
+//
+// This can be used from any Goroutine, e.g. to continue the stream after an asynchronous operation. Once the
+// stream is destroyed, i.e. HttpFilterInstance.Destroy is about to be called, the methods are no-op and return
+// the zero values without calling Envoy, and Err returns ErrStreamDestroyed. The destroy waits for the calls in
+// flight on the other Goroutines. See Resetter for the instances that are pooled.
 type EnvoyFilterInstance = *envoyFilterInstance
 
-// ContinueRequest implements EnvoyFilterInstance interface in abi_nocgo.go which is not included in the shared library.
+// Err returns ErrStreamDestroyed if the stream is destroyed, and nil otherwise. This can be used by the Goroutines
+// outliving the stream to tell whether the calls, e.g. ContinueRequest, have been no-op.
+func (c *envoyFilterInstance) Err() error {
+	if _, ok := c.acquire(); !ok {
+		return ErrStreamDestroyed
+	}
+	c.release()
+	return nil
+}
+
+// ContinueRequest is a function that continues the request processing.
 func (c *envoyFilterInstance) ContinueRequest() {
-	C.__envoy_dynamic_module_v1_http_continue_request(c.raw)
+	c.resume(pauseRequest)
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	hostHttpContinueRequest(raw)
 }
 
-// ContinueResponse implements EnvoyFilterInstance interface in abi_nocgo.go which is not included in the shared library.
+// ContinueResponse is a function that continues the response processing.
 func (c *envoyFilterInstance) ContinueResponse() {
-	C.__envoy_dynamic_module_v1_http_continue_response(c.raw)
+	c.resume(pauseResponse)
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	hostHttpContinueResponse(raw)
 }
 
-// GetRequestBodyBuffer implements EnvoyFilterInstance interface in abi_nocgo.go which is not included in the shared library.
+// GetRequestBodyBuffer returns the entire request body buffer that is currently buffered.
+// The methods of the buffer are no-op without calling Envoy once the stream is destroyed.
 func (c *envoyFilterInstance) GetRequestBodyBuffer() RequestBodyBuffer {
-	return RequestBodyBuffer{raw: C.__envoy_dynamic_module_v1_http_get_request_body_buffer(c.raw)}
+	raw, ok := c.acquire()
+	if !ok {
+		return RequestBodyBuffer{}
+	}
+	defer c.release()
+	return RequestBodyBuffer{stamp: c.stamp.load(), raw: hostHttpGetRequestBodyBuffer(raw), owner: c}
 }
 
-// GetResponseBodyBuffer implements EnvoyFilterInstance interface in abi_nocgo.go which is not included in the shared library.
+// GetResponseBodyBuffer returns the entire response body buffer that is currently buffered.
+// The methods of the buffer are no-op without calling Envoy once the stream is destroyed.
 func (c *envoyFilterInstance) GetResponseBodyBuffer() ResponseBodyBuffer {
-	return ResponseBodyBuffer{raw: C.__envoy_dynamic_module_v1_http_get_response_body_buffer(c.raw)}
+	raw, ok := c.acquire()
+	if !ok {
+		return ResponseBodyBuffer{}
+	}
+	defer c.release()
+	return ResponseBodyBuffer{stamp: c.stamp.load(), raw: hostHttpGetResponseBodyBuffer(raw), owner: c}
 }
 
-// SendResponse implements EnvoyFilterInstance interface in abi_nocgo.go which is not included in the shared library.
+// SendResponse is a function that sends the response to the downstream.
 func (c *envoyFilterInstance) SendResponse(statusCode int, headers [][2]string, body []byte) {
-	headersPtr := unsafe.Pointer(&headers[0])
-	headersLen := len(headers)
-	bodyPtr := unsafe.Pointer(&body[0])
-	bodyLen := len(body)
-	C.__envoy_dynamic_module_v1_http_send_response(c.raw,
-		C.uint32_t(statusCode),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(headersPtr)),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferLength(headersLen),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(bodyPtr)),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferLength(bodyLen),
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	// [2]string has the same memory layout as __envoy_dynamic_module_v1_type_InModuleHeader.
+	hostHttpSendResponse(raw, uint32(statusCode),
+		unsafe.Pointer(unsafe.SliceData(headers)), len(headers),
+		bytesPtr(body), len(body),
 	)
 }
 
-// RequestHeaders implements RequestHeaders interface in abi_nocgo.go which is not included in the shared library.
+// ReadDisableRequest stops reading the request data from the downstream if disable is true,
+// and resumes it if false. This can be used to throttle the request data, e.g. when the module
+// cannot keep up with the data arriving in HttpFilterInstance.RequestBody.
+//
+// Calling this with the same value as the previous call is a no-op. This is no-op if the host
+// doesn't support FeatureFlowControl.
+func (c *envoyFilterInstance) ReadDisableRequest(disable bool) {
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	if !HostSupports(FeatureFlowControl) {
+		return
+	}
+	if c.requestReadDisabled.Swap(disable) == disable {
+		return
+	}
+	hostHttpReadDisableRequest(raw, boolToInt(disable))
+}
+
+// ReadDisableResponse stops reading the response data from the upstream if disable is true,
+// and resumes it if false. This can be used to throttle the response data, e.g. when the module
+// cannot keep up with the data arriving in HttpFilterInstance.ResponseBody.
+//
+// Calling this with the same value as the previous call is a no-op. This is no-op if the host
+// doesn't support FeatureFlowControl.
+func (c *envoyFilterInstance) ReadDisableResponse(disable bool) {
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	if !HostSupports(FeatureFlowControl) {
+		return
+	}
+	if c.responseReadDisabled.Swap(disable) == disable {
+		return
+	}
+	hostHttpReadDisableResponse(raw, boolToInt(disable))
+}
+
+// InjectRequestData injects the data into the request filter chain right after this filter
+// without buffering it in the request body buffer. `endOfStream` indicates that this is the last data
+// of the request. The data is copied, so it can be reused after this returns.
+//
+// This is supposed to be used while the request processing is stopped, e.g. by returning
+// RequestBodyStatusStopIterationAndBuffer after draining the body, and can be called from any Goroutine.
+// This enables streaming transformations such as the chunked generation of the request body.
+// This is no-op if the data is empty without endOfStream, or the host doesn't support FeatureDataInjection.
+func (c *envoyFilterInstance) InjectRequestData(data []byte, endOfStream bool) {
+	if endOfStream {
+		// Injecting the end of stream resumes the stream for the watchdog as ContinueRequest does.
+		c.resume(pauseRequest)
+	}
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	if !HostSupports(FeatureDataInjection) || len(data) == 0 && !endOfStream {
+		return
+	}
+	hostHttpInjectRequestData(raw, bytesPtr(data), len(data), endOfStream)
+}
+
+// InjectResponseData injects the data into the response filter chain right after this filter
+// without buffering it in the response body buffer. `endOfStream` indicates that this is the last data
+// of the response. The data is copied, so it can be reused after this returns.
+//
+// This is supposed to be used while the response processing is stopped, e.g. by returning
+// ResponseBodyStatusStopIterationAndBuffer after draining the body, and can be called from any Goroutine.
+// This enables streaming transformations such as Server-Sent Events and the chunked generation of the response body.
+// This is no-op if the data is empty without endOfStream, or the host doesn't support FeatureDataInjection.
+func (c *envoyFilterInstance) InjectResponseData(data []byte, endOfStream bool) {
+	if endOfStream {
+		// Injecting the end of stream resumes the stream for the watchdog as ContinueResponse does.
+		c.resume(pauseResponse)
+	}
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	if !HostSupports(FeatureDataInjection) || len(data) == 0 && !endOfStream {
+		return
+	}
+	hostHttpInjectResponseData(raw, bytesPtr(data), len(data), endOfStream)
+}
+
+// AddRequestBody attaches the body to the header-only request. This must be called in
+// HttpFilterInstance.RequestHeaders where `endOfStream` is true, and the given `headers` must be the one
+// passed to it. The content-length header is set to the length of the data and transfer-encoding is removed.
+//
+// This is no-op if the data is empty or the host doesn't support FeatureAddBody.
+func (c *envoyFilterInstance) AddRequestBody(headers RequestHeaders, data []byte) {
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	if len(data) == 0 || !HostSupports(FeatureAddBody) {
+		return
+	}
+	hostHttpAddRequestBody(raw, bytesPtr(data), len(data))
+	headers.Remove("transfer-encoding")
+	headers.Set("content-length", strconv.Itoa(len(data)))
+}
+
+// AddResponseBody attaches the body to the header-only response. This must be called in
+// HttpFilterInstance.ResponseHeaders where `endOfStream` is true, and the given `headers` must be the one
+// passed to it. The content-length header is set to the length of the data and transfer-encoding is removed.
+//
+// This is no-op if the data is empty or the host doesn't support FeatureAddBody.
+func (c *envoyFilterInstance) AddResponseBody(headers ResponseHeaders, data []byte) {
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	if len(data) == 0 || !HostSupports(FeatureAddBody) {
+		return
+	}
+	hostHttpAddResponseBody(raw, bytesPtr(data), len(data))
+	headers.Remove("transfer-encoding")
+	headers.Set("content-length", strconv.Itoa(len(data)))
+}
+
+// UpstreamInfo returns the information of the upstream request attempt if this is running as an upstream
+// http filter created via NewUpstreamHttpFilter. Returns false at the second return value otherwise,
+// including when the host doesn't support FeatureUpstreamInfo and after the stream is destroyed.
+func (c *envoyFilterInstance) UpstreamInfo() (UpstreamInfo, bool) {
+	if !HostSupports(FeatureUpstreamInfo) {
+		return UpstreamInfo{}, false
+	}
+	raw, ok := c.acquire()
+	if !ok {
+		return UpstreamInfo{}, false
+	}
+	defer c.release()
+	attempt := hostHttpGetUpstreamAttempt(raw)
+	if attempt == 0 {
+		return UpstreamInfo{}, false
+	}
+	info := UpstreamInfo{Attempt: attempt}
+	var resultPtr *byte
+	var resultSize int
+	if hostHttpGetUpstreamClusterName(raw, unsafe.Pointer(&resultPtr), unsafe.Pointer(&resultSize)) != 0 {
+		info.Cluster = string(unsafe.Slice(resultPtr, resultSize))
+	}
+	if hostHttpGetUpstreamHostAddress(raw, unsafe.Pointer(&resultPtr), unsafe.Pointer(&resultSize)) != 0 {
+		info.Host = string(unsafe.Slice(resultPtr, resultSize))
+	}
+	return info, true
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// bytesPtr returns the pointer to the data of the slice, which is nil or a dangling pointer if the slice is empty.
+func bytesPtr(b []byte) unsafe.Pointer {
+	return unsafe.Pointer(unsafe.SliceData(b))
+}
+
+// stringPtr returns the pointer to the data of the string, which is nil or a dangling pointer if the string is empty.
+func stringPtr(s string) unsafe.Pointer {
+	return unsafe.Pointer(unsafe.StringData(s))
+}
+
+// RequestHeaders is an opaque object that represents the underlying Envoy Http request headers map.
+// This is used to interact with it from the module code.
 type RequestHeaders struct {
-	raw C.__envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr
+	stamp viewStamp
+	raw   uintptr
 }
 
-// ResponseHeaders implements ResponseHeaders interface in abi_nocgo.go which is not included in the shared library.
+// ResponseHeaders is an opaque object that represents the underlying Envoy Http response headers map.
+// This is used to interact with it from the module code.
 type ResponseHeaders struct {
-	raw C.__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr
+	stamp viewStamp
+	raw   uintptr
 }
 
-// RequestBodyBuffer implements RequestBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
+// dataSlice has the same memory layout as __envoy_dynamic_module_v1_type_DataSlice.
+type dataSlice struct {
+	data *byte
+	size int
+}
+
+// getManyChunkSize is the maximum number of the keys passed to Envoy at once in GetMany.
+const getManyChunkSize = 32
+
+// RequestBodyBuffer is an opaque object that represents the underlying Envoy Http request body buffer.
+// This is used to interact with it from the module code. A buffer consists of a multiple slices of data,
+// not a single contiguous buffer.
+//
+// This provides a zero-copy view of the HTTP request body buffer.
+//
+// This implements io.ReaderAt interface.
 type RequestBodyBuffer struct {
-	raw C.__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr
+	stamp viewStamp
+	raw   uintptr
+	// owner is the stream of the buffer returned by EnvoyFilterInstance.GetRequestBodyBuffer, which is nil for
+	// the buffers passed to the event callbacks.
+	owner *envoyFilterInstance
 }
 
-// ResponseBodyBuffer implements ResponseBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
+// ResponseBodyBuffer is an opaque object that represents the underlying Envoy Http response body buffer.
+// This is used to interact with it from the module code. A buffer consists of a multiple slices of data,
+// not a single contiguous buffer.
+//
+// This provides a zero-copy view of the HTTP response body buffer.
+//
+// This implements io.ReaderAt interface.
 type ResponseBodyBuffer struct {
-	raw C.__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr
+	stamp viewStamp
+	raw   uintptr
+	// owner is the stream of the buffer returned by EnvoyFilterInstance.GetResponseBodyBuffer, which is nil for
+	// the buffers passed to the event callbacks.
+	owner *envoyFilterInstance
 }
 
-// Get implements RequestHeaders interface in abi_nocgo.go which is not included in the shared library.
+// Get returns the first header value for the given key. To handle multiple values, use the Values method.
+// Returns true at the second return value if the key exists.
 func (r RequestHeaders) Get(key string) (HeaderValue, bool) {
-	// Take the raw pointer to the key by using unsafe.
-	keyPtr := uintptr(unsafe.Pointer(unsafe.StringData(key)))
-	keySize := len(key)
-
+	r.stamp.check("RequestHeaders")
 	var resultPtr *byte
 	var resultSize int
-	total := C.__envoy_dynamic_module_v1_http_get_request_header_value(r.raw,
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(keyPtr),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keySize),
-		C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&resultPtr))),
-		C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&resultSize))),
-	)
+	total := hostHttpGetRequestHeaderValue(r.raw, stringPtr(key), len(key),
+		unsafe.Pointer(&resultPtr), unsafe.Pointer(&resultSize))
 	if total == 0 {
 		return HeaderValue{}, false
 	}
-	runtime.KeepAlive(key)
-	return HeaderValue{data: resultPtr, size: int(resultSize)}, true
+	return HeaderValue{stamp: r.stamp, data: resultPtr, size: resultSize}, true
 }
 
-// Values implements RequestHeaders interface in abi_nocgo.go which is not included in the shared library.
+// Values iterates over the header values for the given key.
 func (r RequestHeaders) Values(key string, iter func(value HeaderValue)) {
-	// Take the raw pointer to the key by using unsafe.
-	keyPtr := uintptr(unsafe.Pointer(unsafe.StringData(key)))
-	keySize := len(key)
-
+	r.stamp.check("RequestHeaders")
 	var resultPtr *byte
 	var resultSize int
-	total := C.__envoy_dynamic_module_v1_http_get_request_header_value(r.raw,
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(keyPtr),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keySize),
-		C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&resultPtr))),
-		C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&resultSize))),
-	)
+	total := hostHttpGetRequestHeaderValue(r.raw, stringPtr(key), len(key),
+		unsafe.Pointer(&resultPtr), unsafe.Pointer(&resultSize))
 	if total == 0 {
 		return
 	}
 
-	iter(HeaderValue{data: resultPtr, size: int(resultSize)})
+	iter(HeaderValue{stamp: r.stamp, data: resultPtr, size: resultSize})
 
-	for i := 1; i < int(total); i++ {
-		C.__envoy_dynamic_module_v1_http_get_request_header_value_nth(r.raw,
-			C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(keyPtr),
-			C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keySize),
-			C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&resultPtr))),
-			C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&resultSize))),
-			C.size_t(i),
-		)
-		iter(HeaderValue{data: resultPtr, size: int(resultSize)})
+	for i := 1; i < total; i++ {
+		hostHttpGetRequestHeaderValueNth(r.raw, stringPtr(key), len(key),
+			unsafe.Pointer(&resultPtr), unsafe.Pointer(&resultSize), i)
+		iter(HeaderValue{stamp: r.stamp, data: resultPtr, size: resultSize})
 	}
-	runtime.KeepAlive(key)
 }
 
-// Set implements RequestHeaders interface in abi_nocgo.go which is not included in the shared library.
+// Set sets the value for the given key. If multiple values are set for the same key,
+// this removes all the previous values and sets the new single value.
 func (r RequestHeaders) Set(key, value string) {
-	r.set(
-		uintptr(unsafe.Pointer(unsafe.StringData(key))), len(key),
-		uintptr(unsafe.Pointer(unsafe.StringData(value))), len(value),
-	)
-	runtime.KeepAlive(key)
-	runtime.KeepAlive(value)
+	r.stamp.check("RequestHeaders")
+	hostHttpSetRequestHeader(r.raw, stringPtr(key), len(key), stringPtr(value), len(value))
 }
 
-// Remove implements RequestHeaders interface in abi_nocgo.go which is not included in the shared library.
+// Remove removes the value for the given key. If multiple values are set for the same key,
+// this removes all the values.
 func (r RequestHeaders) Remove(key string) {
-	r.set(uintptr(unsafe.Pointer(unsafe.StringData(key))), len(key), 0, 0)
-	runtime.KeepAlive(key)
+	r.stamp.check("RequestHeaders")
+	hostHttpSetRequestHeader(r.raw, stringPtr(key), len(key), nil, 0)
 }
 
-func (r RequestHeaders) set(keyPtr uintptr, keySize int, valuePtr uintptr, valueSize int) {
-	C.__envoy_dynamic_module_v1_http_set_request_header(r.raw,
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(keyPtr),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keySize),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(valuePtr),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferLength(valueSize),
+// Apply applies the batch of the mutations to the headers in a single call to Envoy, which is cheaper than
+// calling Set and Remove for each header.
+//
+// If the host doesn't support FeatureHeaderBatch, this falls back to calling Remove and Set for each header.
+// As there's no other Envoy API to add a value, this returns ErrHeaderAddUnsupported without applying any
+// mutation if Add is not empty in that case.
+func (r RequestHeaders) Apply(m HeaderMutation) error {
+	r.stamp.check("RequestHeaders")
+	if !HostSupports(FeatureHeaderBatch) {
+		if len(m.Add) > 0 {
+			return ErrHeaderAddUnsupported
+		}
+		for _, key := range m.Remove {
+			r.Remove(key)
+		}
+		for _, kv := range m.Set {
+			r.Set(kv[0], kv[1])
+		}
+		return nil
+	}
+	// string and [2]string have the same memory layout as __envoy_dynamic_module_v1_type_InModuleBuffer
+	// and __envoy_dynamic_module_v1_type_InModuleHeader respectively.
+	hostHttpMutateRequestHeaders(r.raw,
+		unsafe.Pointer(unsafe.SliceData(m.Remove)), len(m.Remove),
+		unsafe.Pointer(unsafe.SliceData(m.Set)), len(m.Set),
+		unsafe.Pointer(unsafe.SliceData(m.Add)), len(m.Add),
 	)
+	return nil
 }
 
-// Values implements ResponseHeaders interface in abi_nocgo.go which is not included in the shared library.
-func (r ResponseHeaders) Get(key string) (HeaderValue, bool) {
-	// Take the raw pointer to the key by using unsafe.
-	keyPtr := uintptr(unsafe.Pointer(unsafe.StringData(key)))
-	keySize := len(key)
+// GetMany returns the first values for the given keys in a single call to Envoy, which is cheaper than calling
+// Get for each key. The i-th value is for the i-th key, and is the zero HeaderValue if the key doesn't exist.
+func (r RequestHeaders) GetMany(keys ...string) []HeaderValue {
+	r.stamp.check("RequestHeaders")
+	values := make([]HeaderValue, len(keys))
+	if len(keys) == 0 {
+		return values
+	}
+	if !HostSupports(FeatureHeaderBatch) {
+		for i, key := range keys {
+			values[i], _ = r.Get(key)
+		}
+		return values
+	}
+	// The keys are processed in chunks so that the results are allocated once regardless of the number of keys.
+	var buf [getManyChunkSize]dataSlice
+	for offset := 0; offset < len(keys); offset += getManyChunkSize {
+		chunk := keys[offset:min(offset+getManyChunkSize, len(keys))]
+		results := buf[:len(chunk)]
+		hostHttpGetRequestHeaderValues(r.raw, unsafe.Pointer(unsafe.SliceData(chunk)), len(chunk),
+			unsafe.Pointer(unsafe.SliceData(results)))
+		for i, result := range results {
+			values[offset+i] = HeaderValue{stamp: r.stamp, data: result.data, size: result.size}
+		}
+	}
+	return values
+}
 
+// Get returns the first header value for the given key. To handle multiple values, use the Values method.
+// Returns true at the second return value if the key exists.
+func (r ResponseHeaders) Get(key string) (HeaderValue, bool) {
+	r.stamp.check("ResponseHeaders")
 	var resultPtr *byte
 	var resultSize int
-	total := C.__envoy_dynamic_module_v1_http_get_response_header_value(r.raw,
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(keyPtr),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keySize),
-		C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&resultPtr))),
-		C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&resultSize))),
-	)
+	total := hostHttpGetResponseHeaderValue(r.raw, stringPtr(key), len(key),
+		unsafe.Pointer(&resultPtr), unsafe.Pointer(&resultSize))
 	if total == 0 {
 		return HeaderValue{}, false
 	}
-	runtime.KeepAlive(key)
-	return HeaderValue{data: resultPtr, size: resultSize}, true
+	return HeaderValue{stamp: r.stamp, data: resultPtr, size: resultSize}, true
 }
 
-// Values implements ResponseHeaders interface in abi_nocgo.go which is not included in the shared library.
+// Values iterates over the header values for the given key.
 func (r ResponseHeaders) Values(key string, iter func(value HeaderValue)) {
-	// Take the raw pointer to the key by using unsafe.
-	keyPtr := uintptr(unsafe.Pointer(unsafe.StringData(key)))
-	keySize := len(key)
-
+	r.stamp.check("ResponseHeaders")
 	var resultPtr *byte
 	var resultSize int
-	total := C.__envoy_dynamic_module_v1_http_get_response_header_value(r.raw,
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(keyPtr),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keySize),
-		C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&resultPtr))),
-		C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&resultSize))),
-	)
+	total := hostHttpGetResponseHeaderValue(r.raw, stringPtr(key), len(key),
+		unsafe.Pointer(&resultPtr), unsafe.Pointer(&resultSize))
 	if total == 0 {
 		return
 	}
 
-	iter(HeaderValue{data: resultPtr, size: resultSize})
+	iter(HeaderValue{stamp: r.stamp, data: resultPtr, size: resultSize})
 
-	for i := 1; i < int(total); i++ {
-		C.__envoy_dynamic_module_v1_http_get_response_header_value_nth(r.raw,
-			C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(keyPtr),
-			C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keySize),
-			C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&resultPtr))),
-			C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&resultSize))),
-			C.size_t(i),
-		)
-		iter(HeaderValue{data: resultPtr, size: resultSize})
+	for i := 1; i < total; i++ {
+		hostHttpGetResponseHeaderValueNth(r.raw, stringPtr(key), len(key),
+			unsafe.Pointer(&resultPtr), unsafe.Pointer(&resultSize), i)
+		iter(HeaderValue{stamp: r.stamp, data: resultPtr, size: resultSize})
 	}
-
-	runtime.KeepAlive(key)
 }
 
-// Set implements ResponseHeaders interface in abi_nocgo.go which is not included in the shared library.
+// Set sets the value for the given key. If multiple values are set for the same key,
+// this removes all the previous values and sets the new single value.
 func (r ResponseHeaders) Set(key, value string) {
-	r.set(
-		uintptr(unsafe.Pointer(unsafe.StringData(key))), len(key),
-		uintptr(unsafe.Pointer(unsafe.StringData(value))), len(value),
-	)
-	runtime.KeepAlive(key)
-	runtime.KeepAlive(value)
+	r.stamp.check("ResponseHeaders")
+	hostHttpSetResponseHeader(r.raw, stringPtr(key), len(key), stringPtr(value), len(value))
 }
 
-// Remove implements ResponseHeaders interface in abi_nocgo.go which is not included in the shared library.
+// Remove removes the value for the given key. If multiple values are set for the same key,
+// this removes all the values.
 func (r ResponseHeaders) Remove(key string) {
-	r.set(uintptr(unsafe.Pointer(unsafe.StringData(key))), len(key), 0, 0)
-	runtime.KeepAlive(key)
+	r.stamp.check("ResponseHeaders")
+	hostHttpSetResponseHeader(r.raw, stringPtr(key), len(key), nil, 0)
 }
 
-func (r ResponseHeaders) set(keyPtr uintptr, keySize int, valuePtr uintptr, valueSize int) {
-	C.__envoy_dynamic_module_v1_http_set_response_header(r.raw,
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(keyPtr),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keySize),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(valuePtr),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferLength(valueSize),
+// Apply applies the batch of the mutations to the headers in a single call to Envoy, which is cheaper than
+// calling Set and Remove for each header.
+//
+// If the host doesn't support FeatureHeaderBatch, this falls back to calling Remove and Set for each header.
+// As there's no other Envoy API to add a value, this returns ErrHeaderAddUnsupported without applying any
+// mutation if Add is not empty in that case.
+func (r ResponseHeaders) Apply(m HeaderMutation) error {
+	r.stamp.check("ResponseHeaders")
+	if !HostSupports(FeatureHeaderBatch) {
+		if len(m.Add) > 0 {
+			return ErrHeaderAddUnsupported
+		}
+		for _, key := range m.Remove {
+			r.Remove(key)
+		}
+		for _, kv := range m.Set {
+			r.Set(kv[0], kv[1])
+		}
+		return nil
+	}
+	// string and [2]string have the same memory layout as __envoy_dynamic_module_v1_type_InModuleBuffer
+	// and __envoy_dynamic_module_v1_type_InModuleHeader respectively.
+	hostHttpMutateResponseHeaders(r.raw,
+		unsafe.Pointer(unsafe.SliceData(m.Remove)), len(m.Remove),
+		unsafe.Pointer(unsafe.SliceData(m.Set)), len(m.Set),
+		unsafe.Pointer(unsafe.SliceData(m.Add)), len(m.Add),
 	)
+	return nil
 }
 
-// Length implements RequestBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
+// GetMany returns the first values for the given keys in a single call to Envoy, which is cheaper than calling
+// Get for each key. The i-th value is for the i-th key, and is the zero HeaderValue if the key doesn't exist.
+func (r ResponseHeaders) GetMany(keys ...string) []HeaderValue {
+	r.stamp.check("ResponseHeaders")
+	values := make([]HeaderValue, len(keys))
+	if len(keys) == 0 {
+		return values
+	}
+	if !HostSupports(FeatureHeaderBatch) {
+		for i, key := range keys {
+			values[i], _ = r.Get(key)
+		}
+		return values
+	}
+	// The keys are processed in chunks so that the results are allocated once regardless of the number of keys.
+	var buf [getManyChunkSize]dataSlice
+	for offset := 0; offset < len(keys); offset += getManyChunkSize {
+		chunk := keys[offset:min(offset+getManyChunkSize, len(keys))]
+		results := buf[:len(chunk)]
+		hostHttpGetResponseHeaderValues(r.raw, unsafe.Pointer(unsafe.SliceData(chunk)), len(chunk),
+			unsafe.Pointer(unsafe.SliceData(results)))
+		for i, result := range results {
+			values[offset+i] = HeaderValue{stamp: r.stamp, data: result.data, size: result.size}
+		}
+	}
+	return values
+}
+
+// Length returns the total number of bytes in the buffer.
 func (r RequestBodyBuffer) Length() int {
-	return int(C.__envoy_dynamic_module_v1_http_get_request_body_buffer_length(r.raw))
+	if !acquireBuffer(r.raw, r.owner) {
+		return 0
+	}
+	defer releaseBuffer(r.owner)
+	r.stamp.check("RequestBodyBuffer")
+	return hostHttpGetRequestBodyBufferLength(r.raw)
 }
 
-// Slice implements RequestBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
+// Slices iterates over the slices of the buffer. The view byte slice must NOT be saved as the
+// memory is owned by the Envoy. To take a copy of the buffer, use the Copy method.
+//
+// For the buffer returned by EnvoyFilterInstance.GetRequestBodyBuffer, the destroy of the stream waits for
+// this to return, so iter must not call the methods of the buffer.
 func (r RequestBodyBuffer) Slices(iter func(view []byte)) {
-	sliceCount := C.__envoy_dynamic_module_v1_http_get_request_body_buffer_slices_count(r.raw)
-	for i := C.size_t(0); i < sliceCount; i++ {
+	if !acquireBuffer(r.raw, r.owner) {
+		return
+	}
+	defer releaseBuffer(r.owner)
+	r.stamp.check("RequestBodyBuffer")
+	r.slices(iter)
+}
+
+func (r RequestBodyBuffer) slices(iter func(view []byte)) {
+	sliceCount := hostHttpGetRequestBodyBufferSlicesCount(r.raw)
+	for i := 0; i < sliceCount; i++ {
 		var ptr *byte
 		var size int
-		C.__envoy_dynamic_module_v1_http_get_request_body_buffer_slice(r.raw,
-			i,
-			C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&ptr))),
-			C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&size))),
-		)
-		iter(unsafe.Slice(ptr, size))
+		hostHttpGetRequestBodyBufferSlice(r.raw, i, unsafe.Pointer(&ptr), unsafe.Pointer(&size))
+		iterView(ptr, size, false, iter)
 	}
 }
 
-// Copy implements RequestBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
+// Copy returns a copy of the bytes in the buffer as a single contiguous buffer.
 func (r RequestBodyBuffer) Copy() []byte {
-	bytes := make([]byte, r.Length())
-	offset := 0
-	r.Slices(func(view []byte) {
-		copy(bytes[offset:], view)
-		offset += len(view)
-	})
-	return bytes
+	if !acquireBuffer(r.raw, r.owner) {
+		return []byte{}
+	}
+	defer releaseBuffer(r.owner)
+	r.stamp.check("RequestBodyBuffer")
+	return copyBuffer(hostHttpGetRequestBodyBufferLength(r.raw), r.slices)
 }
 
-// ReadAt implements io.ReaderAt, and RequestBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
+// ReadAt implements io.ReaderAt. This returns ErrStreamDestroyed after the stream of the buffer returned by
+// EnvoyFilterInstance.GetRequestBodyBuffer is destroyed.
 func (r RequestBodyBuffer) ReadAt(p []byte, off int64) (n int, err error) {
-	length := r.Length()
-	if off >= int64(length) {
-		return 0, io.EOF
+	if !acquireBuffer(r.raw, r.owner) {
+		return 0, unavailableBufferError(r.raw, off)
 	}
-	diff := int64(length) - off
-	if int64(len(p)) > diff {
-		p = p[:diff]
-		err = io.EOF
+	defer releaseBuffer(r.owner)
+	r.stamp.check("RequestBodyBuffer")
+	p, err = readAtRange(hostHttpGetRequestBodyBufferLength(r.raw), p, off)
+	if len(p) == 0 {
+		return 0, err
 	}
-	C.__envoy_dynamic_module_v1_http_copy_out_response_body_buffer(
-		r.raw, C.size_t(off), C.size_t(len(p)),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(unsafe.Pointer(&p[0]))),
-	)
+	hostHttpCopyOutRequestBodyBuffer(r.raw, int(off), len(p), bytesPtr(p))
 	return len(p), err
 }
 
-// Length implements ResponseBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
+// Append appends the data to the buffer.
+func (r RequestBodyBuffer) Append(data []byte) {
+	if !acquireBuffer(r.raw, r.owner) {
+		return
+	}
+	defer releaseBuffer(r.owner)
+	r.stamp.check("RequestBodyBuffer")
+	if len(data) == 0 {
+		return
+	}
+	hostHttpAppendRequestBodyBuffer(r.raw, bytesPtr(data), len(data))
+}
+
+// Prepend prepends the data to the buffer.
+func (r RequestBodyBuffer) Prepend(data []byte) {
+	if !acquireBuffer(r.raw, r.owner) {
+		return
+	}
+	defer releaseBuffer(r.owner)
+	r.stamp.check("RequestBodyBuffer")
+	if len(data) == 0 {
+		return
+	}
+	hostHttpPrependRequestBodyBuffer(r.raw, bytesPtr(data), len(data))
+}
+
+// Drain removes the given number of bytes from the front of the buffer. The length is clamped to
+// the range of [0, Length()], so draining more than the buffer empties it.
+func (r RequestBodyBuffer) Drain(length int) {
+	if !acquireBuffer(r.raw, r.owner) {
+		return
+	}
+	defer releaseBuffer(r.owner)
+	r.stamp.check("RequestBodyBuffer")
+	if length <= 0 {
+		return
+	}
+	if length = min(length, hostHttpGetRequestBodyBufferLength(r.raw)); length > 0 {
+		hostHttpDrainRequestBodyBuffer(r.raw, length)
+	}
+}
+
+// Replace replaces the buffer with the given data. This doesn't take the ownership of the data.
+// Therefore, data will be copied to the buffer internally.
+func (r RequestBodyBuffer) Replace(data []byte) {
+	if !acquireBuffer(r.raw, r.owner) {
+		return
+	}
+	defer releaseBuffer(r.owner)
+	r.stamp.check("RequestBodyBuffer")
+	if length := hostHttpGetRequestBodyBufferLength(r.raw); length > 0 {
+		hostHttpDrainRequestBodyBuffer(r.raw, length)
+	}
+	if len(data) > 0 {
+		hostHttpAppendRequestBodyBuffer(r.raw, bytesPtr(data), len(data))
+	}
+}
+
+// Length returns the total number of bytes in the buffer.
 func (r ResponseBodyBuffer) Length() int {
-	return int(C.__envoy_dynamic_module_v1_http_get_response_body_buffer_length(r.raw))
+	if !acquireBuffer(r.raw, r.owner) {
+		return 0
+	}
+	defer releaseBuffer(r.owner)
+	r.stamp.check("ResponseBodyBuffer")
+	return hostHttpGetResponseBodyBufferLength(r.raw)
 }
 
-// Slice implements ResponseBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
+// Slices iterates over the slices of the buffer. The view byte slice must NOT be saved as the
+// memory is owned by the Envoy. To take a copy of the buffer, use the Copy method.
+//
+// For the buffer returned by EnvoyFilterInstance.GetResponseBodyBuffer, the destroy of the stream waits for
+// this to return, so iter must not call the methods of the buffer.
 func (r ResponseBodyBuffer) Slices(iter func(view []byte)) {
-	sliceCount := C.__envoy_dynamic_module_v1_http_get_response_body_buffer_slices_count(r.raw)
-	for i := C.size_t(0); i < sliceCount; i++ {
+	if !acquireBuffer(r.raw, r.owner) {
+		return
+	}
+	defer releaseBuffer(r.owner)
+	r.stamp.check("ResponseBodyBuffer")
+	r.slices(iter)
+}
+
+func (r ResponseBodyBuffer) slices(iter func(view []byte)) {
+	sliceCount := hostHttpGetResponseBodyBufferSlicesCount(r.raw)
+	for i := 0; i < sliceCount; i++ {
 		var ptr *byte
 		var size int
-		C.__envoy_dynamic_module_v1_http_get_response_body_buffer_slice(r.raw,
-			i,
-			C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&ptr))),
-			C.__envoy_dynamic_module_v1_type_DataSliceLengthResult(uintptr(unsafe.Pointer(&size))),
-		)
-		iter(unsafe.Slice(ptr, size))
+		hostHttpGetResponseBodyBufferSlice(r.raw, i, unsafe.Pointer(&ptr), unsafe.Pointer(&size))
+		iterView(ptr, size, false, iter)
 	}
 }
 
-// Copy implements ResponseBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
+// Copy returns a copy of the bytes in the buffer as a single contiguous buffer.
 func (r ResponseBodyBuffer) Copy() []byte {
-	bytes := make([]byte, r.Length())
-	offset := 0
-	r.Slices(func(view []byte) {
-		copy(bytes[offset:], view)
-		offset += len(view)
-	})
-	return bytes
+	if !acquireBuffer(r.raw, r.owner) {
+		return []byte{}
+	}
+	defer releaseBuffer(r.owner)
+	r.stamp.check("ResponseBodyBuffer")
+	return copyBuffer(hostHttpGetResponseBodyBufferLength(r.raw), r.slices)
 }
 
-// ReadAt implements io.ReaderAt, and RequestBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
+// ReadAt implements io.ReaderAt. This returns ErrStreamDestroyed after the stream of the buffer returned by
+// EnvoyFilterInstance.GetResponseBodyBuffer is destroyed.
 func (r ResponseBodyBuffer) ReadAt(p []byte, off int64) (n int, err error) {
-	length := r.Length()
-	if off >= int64(length) {
-		return 0, io.EOF
+	if !acquireBuffer(r.raw, r.owner) {
+		return 0, unavailableBufferError(r.raw, off)
 	}
-	diff := int64(length) - off
-	if int64(len(p)) > diff {
-		p = p[:diff]
-		err = io.EOF
+	defer releaseBuffer(r.owner)
+	r.stamp.check("ResponseBodyBuffer")
+	p, err = readAtRange(hostHttpGetResponseBodyBufferLength(r.raw), p, off)
+	if len(p) == 0 {
+		return 0, err
 	}
-	C.__envoy_dynamic_module_v1_http_copy_out_response_body_buffer(
-		r.raw, C.size_t(off), C.size_t(len(p)),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(unsafe.Pointer(&p[0]))),
-	)
+	hostHttpCopyOutResponseBodyBuffer(r.raw, int(off), len(p), bytesPtr(p))
 	return len(p), err
 }
 
-// Append implements RequestBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
-func (r RequestBodyBuffer) Append(data []byte) {
-	C.__envoy_dynamic_module_v1_http_append_request_body_buffer(
-		r.raw,
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(unsafe.Pointer(&data[0]))),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferLength(len(data)),
-	)
-	runtime.KeepAlive(data)
+// Append appends the data to the buffer.
+func (r ResponseBodyBuffer) Append(data []byte) {
+	if !acquireBuffer(r.raw, r.owner) {
+		return
+	}
+	defer releaseBuffer(r.owner)
+	r.stamp.check("ResponseBodyBuffer")
+	if len(data) == 0 {
+		return
+	}
+	hostHttpAppendResponseBodyBuffer(r.raw, bytesPtr(data), len(data))
 }
 
-// Prepend implements RequestBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
-func (r RequestBodyBuffer) Prepend(data []byte) {
-	C.__envoy_dynamic_module_v1_http_prepend_request_body_buffer(
-		r.raw,
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(unsafe.Pointer(&data[0]))),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferLength(len(data)),
-	)
-	runtime.KeepAlive(data)
+// Prepend prepends the data to the buffer.
+func (r ResponseBodyBuffer) Prepend(data []byte) {
+	if !acquireBuffer(r.raw, r.owner) {
+		return
+	}
+	defer releaseBuffer(r.owner)
+	r.stamp.check("ResponseBodyBuffer")
+	if len(data) == 0 {
+		return
+	}
+	hostHttpPrependResponseBodyBuffer(r.raw, bytesPtr(data), len(data))
+}
 
+// Drain removes the given number of bytes from the front of the buffer. The length is clamped to
+// the range of [0, Length()], so draining more than the buffer empties it.
+func (r ResponseBodyBuffer) Drain(length int) {
+	if !acquireBuffer(r.raw, r.owner) {
+		return
+	}
+	defer releaseBuffer(r.owner)
+	r.stamp.check("ResponseBodyBuffer")
+	if length <= 0 {
+		return
+	}
+	if length = min(length, hostHttpGetResponseBodyBufferLength(r.raw)); length > 0 {
+		hostHttpDrainResponseBodyBuffer(r.raw, length)
+	}
 }
 
-// Drain implements RequestBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
-func (r RequestBodyBuffer) Drain(length int) {
-	C.__envoy_dynamic_module_v1_http_drain_request_body_buffer(r.raw, C.size_t(length))
+// Replace replaces the buffer with the given data. This doesn't take the ownership of the data.
+// Therefore, data will be copied to the buffer internally.
+func (r ResponseBodyBuffer) Replace(data []byte) {
+	if !acquireBuffer(r.raw, r.owner) {
+		return
+	}
+	defer releaseBuffer(r.owner)
+	r.stamp.check("ResponseBodyBuffer")
+	if length := hostHttpGetResponseBodyBufferLength(r.raw); length > 0 {
+		hostHttpDrainResponseBodyBuffer(r.raw, length)
+	}
+	if len(data) > 0 {
+		hostHttpAppendResponseBodyBuffer(r.raw, bytesPtr(data), len(data))
+	}
 }
 
-func (r RequestBodyBuffer) Replace(data []byte) {
-	r.Drain(r.Length())
-	r.Append(data)
+// acquireBuffer keeps the stream of the body buffer alive until releaseBuffer is called if the buffer is owned by
+// the stream. Returns false if the buffer is the zero value or the stream is destroyed, in which case the methods
+// of the buffer are no-op and releaseBuffer must not be called.
+func acquireBuffer(raw uintptr, owner *envoyFilterInstance) bool {
+	if raw == 0 {
+		return false
+	}
+	if owner == nil {
+		return true
+	}
+	_, ok := owner.acquire()
+	return ok
 }
 
-// Append implements ResponseBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
-func (r ResponseBodyBuffer) Append(data []byte) {
-	C.__envoy_dynamic_module_v1_http_append_response_body_buffer(
-		r.raw,
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(unsafe.Pointer(&data[0]))),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferLength(len(data)),
-	)
-	runtime.KeepAlive(data)
+// releaseBuffer releases the stream acquired by acquireBuffer.
+func releaseBuffer(owner *envoyFilterInstance) {
+	if owner != nil {
+		owner.release()
+	}
 }
 
-// Prepend implements ResponseBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
-func (r ResponseBodyBuffer) Prepend(data []byte) {
-	C.__envoy_dynamic_module_v1_http_prepend_response_body_buffer(
-		r.raw,
-		C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(unsafe.Pointer(&data[0]))),
-		C.__envoy_dynamic_module_v1_type_InModuleBufferLength(len(data)),
-	)
-	runtime.KeepAlive(data)
+// unavailableBufferError returns the error of ReadAt of the body buffer whose methods are no-op, which is read as
+// an empty buffer if the buffer is the zero value.
+func unavailableBufferError(raw uintptr, off int64) error {
+	if raw != 0 {
+		return ErrStreamDestroyed
+	}
+	_, err := readAtRange(0, nil, off)
+	return err
 }
 
-// Drain implements ResponseBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
-func (r ResponseBodyBuffer) Drain(length int) {
-	C.__envoy_dynamic_module_v1_http_drain_response_body_buffer(r.raw, C.size_t(length))
+// copyBuffer copies the slices of the buffer of the given length into a single contiguous buffer.
+func copyBuffer(length int, slices func(iter func(view []byte))) []byte {
+	bytes := make([]byte, length)
+	offset := 0
+	slices(func(view []byte) {
+		offset += copy(bytes[offset:], view)
+	})
+	return bytes
 }
 
-// Replace implements ResponseBodyBuffer interface in abi_nocgo.go which is not included in the shared library.
-func (r ResponseBodyBuffer) Replace(data []byte) {
-	r.Drain(r.Length())
-	r.Append(data)
+// errNegativeOffset is returned by ReadAt of the buffers for the negative offset.
+var errNegativeOffset = errors.New("envoy: ReadAt with negative offset")
+
+// readAtRange truncates p to the range of the buffer of the given length starting at off, and returns
+// io.EOF if p reaches the end of the buffer as io.ReaderAt requires.
+func readAtRange(length int, p []byte, off int64) ([]byte, error) {
+	if off < 0 {
+		return nil, errNegativeOffset
+	}
+	if off >= int64(length) {
+		return nil, io.EOF
+	}
+	if diff := int64(length) - off; int64(len(p)) > diff {
+		return p[:diff], io.EOF
+	}
+	return p, nil
 }
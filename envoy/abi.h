@@ -145,12 +145,167 @@ typedef struct {
 // __envoy_dynamic_module_v1_type_InModuleBufferVectorPtr is a pointer to a vector of
 // __envoy_dynamic_module_v1_type_InModuleHeader. This is currently only used for sending local
 // responses.
-typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_InModuleHeadersPtr;
-OWNED_BY_MODULE;
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_InModuleHeadersPtr
+    OWNED_BY_MODULE;
 
 // __envoy_dynamic_module_v1_type_InModuleHeadersSize is the size of the vector of buffers.
 typedef size_t __envoy_dynamic_module_v1_type_InModuleHeadersSize;
 
+// __envoy_dynamic_module_v1_type_InModuleBuffer is a struct that contains representation of a
+// buffer managed by the module. This is used to pass multiple buffers, e.g. header keys, to Envoy.
+typedef struct {
+  __envoy_dynamic_module_v1_type_InModuleBufferPtr buffer;
+  __envoy_dynamic_module_v1_type_InModuleBufferLength buffer_length;
+} __envoy_dynamic_module_v1_type_InModuleBuffer;
+
+// __envoy_dynamic_module_v1_type_InModuleBuffersPtr is a pointer to a vector of
+// __envoy_dynamic_module_v1_type_InModuleBuffer.
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_InModuleBuffersPtr
+    OWNED_BY_MODULE;
+
+// __envoy_dynamic_module_v1_type_InModuleBuffersSize is the size of the vector of buffers.
+typedef size_t __envoy_dynamic_module_v1_type_InModuleBuffersSize;
+
+// __envoy_dynamic_module_v1_type_DataSlice is a struct that contains representation of a buffer
+// managed by Envoy. This is used to pass multiple buffers, e.g. header values, to the module.
+typedef struct {
+  __envoy_dynamic_module_v1_type_DataSlicePtr data;
+  __envoy_dynamic_module_v1_type_DataSliceLength data_length;
+} __envoy_dynamic_module_v1_type_DataSlice;
+
+// __envoy_dynamic_module_v1_type_DataSlicesResult is a pointer to a vector of
+// __envoy_dynamic_module_v1_type_DataSlice that is managed by the module.
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_DataSlicesResult
+    OWNED_BY_MODULE;
+
+// __envoy_dynamic_module_v1_type_HttpFilterEventMask is a bit set of the
+// __ENVOY_DYNAMIC_MODULE_V1_HTTP_FILTER_EVENT_* values. This is used to tell Envoy which event hooks
+// are not implemented by the module for a filter instance.
+typedef size_t __envoy_dynamic_module_v1_type_HttpFilterEventMask;
+
+// __envoy_dynamic_module_v1_type_NetworkFilterConfigPtr is a pointer to the configuration passed
+// to the __envoy_dynamic_module_v1_event_network_filter_init function. Envoy owns the memory of the
+// configuration and the module is not supposed to take ownership of it.
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_NetworkFilterConfigPtr
+    OWNED_BY_ENVOY;
+
+// __envoy_dynamic_module_v1_type_NetworkFilterConfigSize is the size of the configuration passed
+// to the __envoy_dynamic_module_v1_event_network_filter_init function.
+typedef size_t __envoy_dynamic_module_v1_type_NetworkFilterConfigSize;
+
+// __envoy_dynamic_module_v1_type_NetworkFilterPtr is a pointer to in-module singleton context
+// corresponding to the network filter configuration. This is passed to
+// __envoy_dynamic_module_v1_event_network_filter_instance_init.
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_NetworkFilterPtr
+    OWNED_BY_MODULE;
+
+// __envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr is a pointer to the
+// DynamicModule::NetworkFilter instance. Modules are not supposed to manipulate this pointer.
+//
+// This is passed to __envoy_dynamic_module_v1_event_network_filter_instance_init, and becomes
+// invalid after the __envoy_dynamic_module_v1_event_network_filter_instance_destroy is called.
+typedef __envoy_dynamic_module_v1_raw_pointer
+    __envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr OWNED_BY_ENVOY;
+
+// __envoy_dynamic_module_v1_type_NetworkFilterInstancePtr is a pointer to in-module context
+// corresponding to a single downstream connection. It is always passed to the module's network
+// event hooks.
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_NetworkFilterInstancePtr
+    OWNED_BY_MODULE;
+
+// __envoy_dynamic_module_v1_type_NetworkReadBufferPtr is a pointer to the buffer of the data read
+// from the downstream connection passed via
+// __envoy_dynamic_module_v1_event_network_filter_instance_read. Modules are not supposed to
+// manipulate this pointer directly.
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_NetworkReadBufferPtr
+    OWNED_BY_ENVOY;
+
+// __envoy_dynamic_module_v1_type_NetworkWriteBufferPtr is a pointer to the buffer of the data
+// written to the downstream connection passed via
+// __envoy_dynamic_module_v1_event_network_filter_instance_write. Modules are not supposed to
+// manipulate this pointer directly.
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_NetworkWriteBufferPtr
+    OWNED_BY_ENVOY;
+
+// __envoy_dynamic_module_v1_type_EventNetworkFilterStatus is the return value of the
+// network filter event hooks. It should be one of the values defined in the FilterStatus enum.
+typedef size_t __envoy_dynamic_module_v1_type_EventNetworkFilterStatus;
+
+// __envoy_dynamic_module_v1_type_NetworkConnectionEvent is the reason of the connection close
+// passed to __envoy_dynamic_module_v1_event_network_filter_instance_close.
+typedef size_t __envoy_dynamic_module_v1_type_NetworkConnectionEvent;
+
+// __envoy_dynamic_module_v1_type_ListenerFilterConfigPtr is a pointer to the configuration passed
+// to the __envoy_dynamic_module_v1_event_listener_filter_init function. Envoy owns the memory of the
+// configuration and the module is not supposed to take ownership of it.
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_ListenerFilterConfigPtr
+    OWNED_BY_ENVOY;
+
+// __envoy_dynamic_module_v1_type_ListenerFilterConfigSize is the size of the configuration passed
+// to the __envoy_dynamic_module_v1_event_listener_filter_init function.
+typedef size_t __envoy_dynamic_module_v1_type_ListenerFilterConfigSize;
+
+// __envoy_dynamic_module_v1_type_ListenerFilterPtr is a pointer to in-module singleton context
+// corresponding to the listener filter configuration. This is passed to
+// __envoy_dynamic_module_v1_event_listener_filter_instance_init.
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_ListenerFilterPtr
+    OWNED_BY_MODULE;
+
+// __envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr is a pointer to the
+// DynamicModule::ListenerFilter instance. Modules are not supposed to manipulate this pointer.
+//
+// This is passed to __envoy_dynamic_module_v1_event_listener_filter_instance_init, and becomes
+// invalid after the __envoy_dynamic_module_v1_event_listener_filter_instance_destroy is called.
+typedef __envoy_dynamic_module_v1_raw_pointer
+    __envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr OWNED_BY_ENVOY;
+
+// __envoy_dynamic_module_v1_type_ListenerFilterInstancePtr is a pointer to in-module context
+// corresponding to a single accepted socket. It is always passed to the module's listener event
+// hooks.
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_ListenerFilterInstancePtr
+    OWNED_BY_MODULE;
+
+// __envoy_dynamic_module_v1_type_ListenerPeekBufferPtr is a pointer to the buffer of the data
+// peeked from the accepted socket passed via
+// __envoy_dynamic_module_v1_event_listener_filter_instance_data. The data is not consumed from the
+// socket. Modules are not supposed to manipulate this pointer directly.
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_ListenerPeekBufferPtr
+    OWNED_BY_ENVOY;
+
+// __envoy_dynamic_module_v1_type_EventListenerFilterStatus is the return value of the listener
+// filter event hooks. It should be one of the values defined in the FilterStatus enum.
+typedef size_t __envoy_dynamic_module_v1_type_EventListenerFilterStatus;
+
+// __envoy_dynamic_module_v1_type_AccessLoggerConfigPtr is a pointer to the configuration passed
+// to the __envoy_dynamic_module_v1_event_access_logger_init function. Envoy owns the memory of the
+// configuration and the module is not supposed to take ownership of it.
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_AccessLoggerConfigPtr
+    OWNED_BY_ENVOY;
+
+// __envoy_dynamic_module_v1_type_AccessLoggerConfigSize is the size of the configuration passed
+// to the __envoy_dynamic_module_v1_event_access_logger_init function.
+typedef size_t __envoy_dynamic_module_v1_type_AccessLoggerConfigSize;
+
+// __envoy_dynamic_module_v1_type_AccessLoggerPtr is a pointer to in-module singleton context
+// corresponding to the access logger configuration. This is passed to
+// __envoy_dynamic_module_v1_event_access_logger_log.
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_AccessLoggerPtr
+    OWNED_BY_MODULE;
+
+// __envoy_dynamic_module_v1_type_AccessLogEntryPtr is a pointer to the log entry of a single
+// stream passed via __envoy_dynamic_module_v1_event_access_logger_log. This is only valid during
+// the event hook. Modules are not supposed to manipulate this pointer directly.
+typedef __envoy_dynamic_module_v1_raw_pointer __envoy_dynamic_module_v1_type_AccessLogEntryPtr
+    OWNED_BY_ENVOY;
+
+// __envoy_dynamic_module_v1_type_AccessLogHeadersType specifies the header map of the log entry
+// to access. It should be one of the __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_HEADERS_* values.
+typedef size_t __envoy_dynamic_module_v1_type_AccessLogHeadersType;
+
+// __envoy_dynamic_module_v1_type_AccessLogTiming specifies the timing of the log entry to access.
+// It should be one of the __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_TIMING_* values.
+typedef size_t __envoy_dynamic_module_v1_type_AccessLogTiming;
+
 // -----------------------------------------------------------------------------
 // ----------------------------------- Enums -----------------------------------
 // -----------------------------------------------------------------------------
@@ -225,6 +380,75 @@ static const __envoy_dynamic_module_v1_type_EventHttpResponseBodyStatus
     __envoy_dynamic_module_v1_type_EventHttpResponseBodyStatusStopIterationAndBuffer =
         __ENVOY_DYNAMIC_MODULE_V1_BODY_STATUS_STOP_ITERATION_AND_BUFFER;
 
+// __ENVOY_DYNAMIC_MODULE_V1_HTTP_FILTER_EVENT_* are the bits of
+// __envoy_dynamic_module_v1_type_HttpFilterEventMask. Each bit corresponds to the event hook(s) of
+// the http filter instance.
+#define __ENVOY_DYNAMIC_MODULE_V1_HTTP_FILTER_EVENT_REQUEST_HEADERS (1 << 0)
+#define __ENVOY_DYNAMIC_MODULE_V1_HTTP_FILTER_EVENT_REQUEST_BODY (1 << 1)
+#define __ENVOY_DYNAMIC_MODULE_V1_HTTP_FILTER_EVENT_RESPONSE_HEADERS (1 << 2)
+#define __ENVOY_DYNAMIC_MODULE_V1_HTTP_FILTER_EVENT_RESPONSE_BODY (1 << 3)
+// __ENVOY_DYNAMIC_MODULE_V1_HTTP_FILTER_EVENT_WATERMARK corresponds to all the
+// __envoy_dynamic_module_v1_event_http_filter_instance_*_watermark event hooks.
+#define __ENVOY_DYNAMIC_MODULE_V1_HTTP_FILTER_EVENT_WATERMARK (1 << 4)
+
+// __ENVOY_DYNAMIC_MODULE_V1_NETWORK_FILTER_STATUS_CONTINUE indicates that Envoy should continue
+// the iteration of the network filter chain.
+#define __ENVOY_DYNAMIC_MODULE_V1_NETWORK_FILTER_STATUS_CONTINUE 0
+// __ENVOY_DYNAMIC_MODULE_V1_NETWORK_FILTER_STATUS_STOP_ITERATION indicates that Envoy should stop
+// the iteration of the network filter chain. For the read path, the iteration can be resumed by
+// calling __envoy_dynamic_module_v1_network_continue_reading.
+#define __ENVOY_DYNAMIC_MODULE_V1_NETWORK_FILTER_STATUS_STOP_ITERATION 1
+
+// __ENVOY_DYNAMIC_MODULE_V1_NETWORK_CONNECTION_EVENT_REMOTE_CLOSE indicates that the connection
+// was closed by the remote peer.
+#define __ENVOY_DYNAMIC_MODULE_V1_NETWORK_CONNECTION_EVENT_REMOTE_CLOSE 0
+// __ENVOY_DYNAMIC_MODULE_V1_NETWORK_CONNECTION_EVENT_LOCAL_CLOSE indicates that the connection was
+// closed locally, e.g. by Envoy or by the module.
+#define __ENVOY_DYNAMIC_MODULE_V1_NETWORK_CONNECTION_EVENT_LOCAL_CLOSE 1
+
+// __ENVOY_DYNAMIC_MODULE_V1_LISTENER_FILTER_STATUS_CONTINUE indicates that the listener filter has
+// finished inspecting the socket and Envoy should continue to the next listener filter.
+#define __ENVOY_DYNAMIC_MODULE_V1_LISTENER_FILTER_STATUS_CONTINUE 0
+// __ENVOY_DYNAMIC_MODULE_V1_LISTENER_FILTER_STATUS_STOP_ITERATION indicates that Envoy should stop
+// the iteration of the listener filter chain. Envoy calls
+// __envoy_dynamic_module_v1_event_listener_filter_instance_data when more data is available, or
+// the module can resume the iteration by calling
+// __envoy_dynamic_module_v1_listener_continue_filter_chain.
+#define __ENVOY_DYNAMIC_MODULE_V1_LISTENER_FILTER_STATUS_STOP_ITERATION 1
+
+// __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_HEADERS_* are the values of
+// __envoy_dynamic_module_v1_type_AccessLogHeadersType.
+#define __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_HEADERS_REQUEST 0
+#define __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_HEADERS_REQUEST_TRAILERS 1
+#define __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_HEADERS_RESPONSE 2
+#define __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_HEADERS_RESPONSE_TRAILERS 3
+
+// __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_TIMING_* are the values of
+// __envoy_dynamic_module_v1_type_AccessLogTiming. Each corresponds to the timestamp of the same
+// name in Envoy's StreamInfo, measured from the start of the stream.
+#define __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_TIMING_LAST_DOWNSTREAM_RX_BYTE_RECEIVED 0
+#define __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_TIMING_FIRST_UPSTREAM_TX_BYTE_SENT 1
+#define __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_TIMING_LAST_UPSTREAM_TX_BYTE_SENT 2
+#define __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_TIMING_FIRST_UPSTREAM_RX_BYTE_RECEIVED 3
+#define __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_TIMING_LAST_UPSTREAM_RX_BYTE_RECEIVED 4
+#define __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_TIMING_FIRST_DOWNSTREAM_TX_BYTE_SENT 5
+#define __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_TIMING_LAST_DOWNSTREAM_TX_BYTE_SENT 6
+#define __ENVOY_DYNAMIC_MODULE_V1_ACCESS_LOG_TIMING_REQUEST_COMPLETE 7
+
+// __ENVOY_DYNAMIC_MODULE_V1_ABI_VERSION is the version of the ABI defined in this header. This is
+// incremented when new event hooks or Envoy APIs are added in a backward compatible way. The
+// version implemented by Envoy can be retrieved via __envoy_dynamic_module_v1_get_abi_version.
+//
+//  * 1: The initial version, i.e. the Header API, the Buffer API and the Miscellaneous API except
+//  __envoy_dynamic_module_v1_http_bypass_events.
+//  * 2: Adds the Flow Control API, Data Injection API, Header-only Stream Body API, Upstream Info
+//  API, __envoy_dynamic_module_v1_http_bypass_events, network filters, listener filters, access
+//  loggers and the upstream http filters.
+//  * 3: Adds the Stats API.
+//  * 4: Adds the Batch Header API.
+//  * 5: Adds the Stream Reset API.
+#define __ENVOY_DYNAMIC_MODULE_V1_ABI_VERSION 5
+
 // -----------------------------------------------------------------------------
 // ------------------------------- Event Hooks ---------------------------------
 // -----------------------------------------------------------------------------
@@ -241,6 +465,10 @@ typedef __envoy_dynamic_module_v1_type_HttpFilterPtr (
     __envoy_dynamic_module_v1_type_HttpFilterConfigSize);
 typedef void (*__envoy_dynamic_module_v1_event_http_filter_destroy)(
     __envoy_dynamic_module_v1_type_HttpFilterPtr);
+typedef __envoy_dynamic_module_v1_type_HttpFilterPtr (
+    *__envoy_dynamic_module_v1_event_upstream_http_filter_init)(
+    __envoy_dynamic_module_v1_type_HttpFilterConfigPtr,
+    __envoy_dynamic_module_v1_type_HttpFilterConfigSize);
 typedef __envoy_dynamic_module_v1_type_HttpFilterInstancePtr (
     *__envoy_dynamic_module_v1_event_http_filter_instance_init)(
     __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr,
@@ -267,6 +495,71 @@ typedef __envoy_dynamic_module_v1_type_EventHttpResponseBodyStatus (
     __envoy_dynamic_module_v1_type_EndOfStream);
 typedef void (*__envoy_dynamic_module_v1_event_http_filter_instance_destroy)(
     __envoy_dynamic_module_v1_type_HttpFilterInstancePtr);
+typedef void (*__envoy_dynamic_module_v1_event_http_filter_instance_request_above_high_watermark)(
+    __envoy_dynamic_module_v1_type_HttpFilterInstancePtr);
+typedef void (*__envoy_dynamic_module_v1_event_http_filter_instance_request_below_low_watermark)(
+    __envoy_dynamic_module_v1_type_HttpFilterInstancePtr);
+typedef void (*__envoy_dynamic_module_v1_event_http_filter_instance_response_above_high_watermark)(
+    __envoy_dynamic_module_v1_type_HttpFilterInstancePtr);
+typedef void (*__envoy_dynamic_module_v1_event_http_filter_instance_response_below_low_watermark)(
+    __envoy_dynamic_module_v1_type_HttpFilterInstancePtr);
+typedef __envoy_dynamic_module_v1_type_NetworkFilterPtr (
+    *__envoy_dynamic_module_v1_event_network_filter_init)(
+    __envoy_dynamic_module_v1_type_NetworkFilterConfigPtr,
+    __envoy_dynamic_module_v1_type_NetworkFilterConfigSize);
+typedef void (*__envoy_dynamic_module_v1_event_network_filter_destroy)(
+    __envoy_dynamic_module_v1_type_NetworkFilterPtr);
+typedef __envoy_dynamic_module_v1_type_NetworkFilterInstancePtr (
+    *__envoy_dynamic_module_v1_event_network_filter_instance_init)(
+    __envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr,
+    __envoy_dynamic_module_v1_type_NetworkFilterPtr);
+typedef __envoy_dynamic_module_v1_type_EventNetworkFilterStatus (
+    *__envoy_dynamic_module_v1_event_network_filter_instance_new_connection)(
+    __envoy_dynamic_module_v1_type_NetworkFilterInstancePtr);
+typedef __envoy_dynamic_module_v1_type_EventNetworkFilterStatus (
+    *__envoy_dynamic_module_v1_event_network_filter_instance_read)(
+    __envoy_dynamic_module_v1_type_NetworkFilterInstancePtr,
+    __envoy_dynamic_module_v1_type_NetworkReadBufferPtr, __envoy_dynamic_module_v1_type_EndOfStream);
+typedef __envoy_dynamic_module_v1_type_EventNetworkFilterStatus (
+    *__envoy_dynamic_module_v1_event_network_filter_instance_write)(
+    __envoy_dynamic_module_v1_type_NetworkFilterInstancePtr,
+    __envoy_dynamic_module_v1_type_NetworkWriteBufferPtr,
+    __envoy_dynamic_module_v1_type_EndOfStream);
+typedef void (*__envoy_dynamic_module_v1_event_network_filter_instance_close)(
+    __envoy_dynamic_module_v1_type_NetworkFilterInstancePtr,
+    __envoy_dynamic_module_v1_type_NetworkConnectionEvent);
+typedef void (*__envoy_dynamic_module_v1_event_network_filter_instance_destroy)(
+    __envoy_dynamic_module_v1_type_NetworkFilterInstancePtr);
+typedef __envoy_dynamic_module_v1_type_ListenerFilterPtr (
+    *__envoy_dynamic_module_v1_event_listener_filter_init)(
+    __envoy_dynamic_module_v1_type_ListenerFilterConfigPtr,
+    __envoy_dynamic_module_v1_type_ListenerFilterConfigSize);
+typedef void (*__envoy_dynamic_module_v1_event_listener_filter_destroy)(
+    __envoy_dynamic_module_v1_type_ListenerFilterPtr);
+typedef __envoy_dynamic_module_v1_type_ListenerFilterInstancePtr (
+    *__envoy_dynamic_module_v1_event_listener_filter_instance_init)(
+    __envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr,
+    __envoy_dynamic_module_v1_type_ListenerFilterPtr);
+typedef size_t (*__envoy_dynamic_module_v1_event_listener_filter_instance_max_read_bytes)(
+    __envoy_dynamic_module_v1_type_ListenerFilterInstancePtr);
+typedef __envoy_dynamic_module_v1_type_EventListenerFilterStatus (
+    *__envoy_dynamic_module_v1_event_listener_filter_instance_accept)(
+    __envoy_dynamic_module_v1_type_ListenerFilterInstancePtr);
+typedef __envoy_dynamic_module_v1_type_EventListenerFilterStatus (
+    *__envoy_dynamic_module_v1_event_listener_filter_instance_data)(
+    __envoy_dynamic_module_v1_type_ListenerFilterInstancePtr,
+    __envoy_dynamic_module_v1_type_ListenerPeekBufferPtr);
+typedef void (*__envoy_dynamic_module_v1_event_listener_filter_instance_destroy)(
+    __envoy_dynamic_module_v1_type_ListenerFilterInstancePtr);
+
+typedef __envoy_dynamic_module_v1_type_AccessLoggerPtr (
+    *__envoy_dynamic_module_v1_event_access_logger_init)(
+    __envoy_dynamic_module_v1_type_AccessLoggerConfigPtr,
+    __envoy_dynamic_module_v1_type_AccessLoggerConfigSize);
+typedef void (*__envoy_dynamic_module_v1_event_access_logger_destroy)(
+    __envoy_dynamic_module_v1_type_AccessLoggerPtr);
+typedef void (*__envoy_dynamic_module_v1_event_access_logger_log)(
+    __envoy_dynamic_module_v1_type_AccessLoggerPtr, __envoy_dynamic_module_v1_type_AccessLogEntryPtr);
 
 #else // If this is the module code, all definitions are declared function prototypes.
 
@@ -289,6 +582,16 @@ __envoy_dynamic_module_v1_type_HttpFilterPtr __envoy_dynamic_module_v1_event_htt
 void __envoy_dynamic_module_v1_event_http_filter_destroy(
     __envoy_dynamic_module_v1_type_HttpFilterPtr http_filter_ptr);
 
+// __envoy_dynamic_module_v1_event_upstream_http_filter_init is called by the main thread when the
+// http filter is loaded as an upstream http filter of a cluster. This is the same as
+// __envoy_dynamic_module_v1_event_http_filter_init except that the filter instances run for each
+// upstream request attempt, i.e. after the route and the cluster are selected. The returned pointer
+// is destroyed by __envoy_dynamic_module_v1_event_http_filter_destroy, and the instances share all
+// the http filter instance event hooks.
+__envoy_dynamic_module_v1_type_HttpFilterPtr __envoy_dynamic_module_v1_event_upstream_http_filter_init(
+    __envoy_dynamic_module_v1_type_HttpFilterConfigPtr config_ptr,
+    __envoy_dynamic_module_v1_type_HttpFilterConfigSize config_size);
+
 // __envoy_dynamic_module_v1_event_http_filter_instance_init is called by any worker thread when a
 // new stream is created. That means that the function should be thread-safe.
 //
@@ -335,6 +638,160 @@ __envoy_dynamic_module_v1_event_http_filter_instance_response_body(
 // destroyed.
 void __envoy_dynamic_module_v1_event_http_filter_instance_destroy(
     __envoy_dynamic_module_v1_type_HttpFilterInstancePtr http_filter_instance_ptr);
+
+// __envoy_dynamic_module_v1_event_http_filter_instance_request_above_high_watermark is called when
+// the buffer that holds the request data on the way to the upstream goes above the high watermark.
+// The module should stop producing request data (e.g. injecting data) until
+// __envoy_dynamic_module_v1_event_http_filter_instance_request_below_low_watermark is called.
+void __envoy_dynamic_module_v1_event_http_filter_instance_request_above_high_watermark(
+    __envoy_dynamic_module_v1_type_HttpFilterInstancePtr http_filter_instance_ptr);
+
+// __envoy_dynamic_module_v1_event_http_filter_instance_request_below_low_watermark is called when
+// the buffer that holds the request data on the way to the upstream is drained below the low
+// watermark after __envoy_dynamic_module_v1_event_http_filter_instance_request_above_high_watermark
+// is called.
+void __envoy_dynamic_module_v1_event_http_filter_instance_request_below_low_watermark(
+    __envoy_dynamic_module_v1_type_HttpFilterInstancePtr http_filter_instance_ptr);
+
+// __envoy_dynamic_module_v1_event_http_filter_instance_response_above_high_watermark is called
+// when the buffer that holds the response data on the way to the downstream goes above the high
+// watermark. The module should stop producing response data (e.g. injecting data) until
+// __envoy_dynamic_module_v1_event_http_filter_instance_response_below_low_watermark is called.
+void __envoy_dynamic_module_v1_event_http_filter_instance_response_above_high_watermark(
+    __envoy_dynamic_module_v1_type_HttpFilterInstancePtr http_filter_instance_ptr);
+
+// __envoy_dynamic_module_v1_event_http_filter_instance_response_below_low_watermark is called when
+// the buffer that holds the response data on the way to the downstream is drained below the low
+// watermark after
+// __envoy_dynamic_module_v1_event_http_filter_instance_response_above_high_watermark is called.
+void __envoy_dynamic_module_v1_event_http_filter_instance_response_below_low_watermark(
+    __envoy_dynamic_module_v1_type_HttpFilterInstancePtr http_filter_instance_ptr);
+
+// __envoy_dynamic_module_v1_event_network_filter_init is called by the main thread when the
+// network filter is loaded. The function returns __envoy_dynamic_module_v1_type_NetworkFilterPtr
+// which is a pointer to the in-module singleton context per network filter configuration. Returning
+// nullptr indicates a failure to initialize the module.
+__envoy_dynamic_module_v1_type_NetworkFilterPtr __envoy_dynamic_module_v1_event_network_filter_init(
+    __envoy_dynamic_module_v1_type_NetworkFilterConfigPtr config_ptr,
+    __envoy_dynamic_module_v1_type_NetworkFilterConfigSize config_size);
+
+// __envoy_dynamic_module_v1_event_network_filter_destroy is called exactly once when the network
+// filter is unloaded.
+void __envoy_dynamic_module_v1_event_network_filter_destroy(
+    __envoy_dynamic_module_v1_type_NetworkFilterPtr network_filter_ptr);
+
+// __envoy_dynamic_module_v1_event_network_filter_instance_init is called by any worker thread when
+// a new downstream connection is accepted. That means that the function should be thread-safe.
+//
+// The function returns a pointer to a new instance of the context or nullptr on failure.
+__envoy_dynamic_module_v1_type_NetworkFilterInstancePtr
+__envoy_dynamic_module_v1_event_network_filter_instance_init(
+    __envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_NetworkFilterPtr network_filter_ptr);
+
+// __envoy_dynamic_module_v1_event_network_filter_instance_new_connection is called when the
+// connection is established and before any data is read.
+__envoy_dynamic_module_v1_type_EventNetworkFilterStatus
+__envoy_dynamic_module_v1_event_network_filter_instance_new_connection(
+    __envoy_dynamic_module_v1_type_NetworkFilterInstancePtr network_filter_instance_ptr);
+
+// __envoy_dynamic_module_v1_event_network_filter_instance_read is called when data is read from the
+// downstream connection. buffer contains all the data that has been read and not yet consumed.
+__envoy_dynamic_module_v1_type_EventNetworkFilterStatus
+__envoy_dynamic_module_v1_event_network_filter_instance_read(
+    __envoy_dynamic_module_v1_type_NetworkFilterInstancePtr network_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer,
+    __envoy_dynamic_module_v1_type_EndOfStream end_of_stream);
+
+// __envoy_dynamic_module_v1_event_network_filter_instance_write is called when data is to be
+// written to the downstream connection.
+__envoy_dynamic_module_v1_type_EventNetworkFilterStatus
+__envoy_dynamic_module_v1_event_network_filter_instance_write(
+    __envoy_dynamic_module_v1_type_NetworkFilterInstancePtr network_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer,
+    __envoy_dynamic_module_v1_type_EndOfStream end_of_stream);
+
+// __envoy_dynamic_module_v1_event_network_filter_instance_close is called when the downstream
+// connection is closed.
+void __envoy_dynamic_module_v1_event_network_filter_instance_close(
+    __envoy_dynamic_module_v1_type_NetworkFilterInstancePtr network_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_NetworkConnectionEvent event);
+
+// __envoy_dynamic_module_v1_event_network_filter_instance_destroy is called when the filter
+// instance is destroyed.
+void __envoy_dynamic_module_v1_event_network_filter_instance_destroy(
+    __envoy_dynamic_module_v1_type_NetworkFilterInstancePtr network_filter_instance_ptr);
+
+// __envoy_dynamic_module_v1_event_listener_filter_init is called by the main thread when the
+// listener filter is loaded. The function returns __envoy_dynamic_module_v1_type_ListenerFilterPtr
+// which is a pointer to the in-module singleton context per listener filter configuration.
+// Returning nullptr indicates a failure to initialize the module.
+__envoy_dynamic_module_v1_type_ListenerFilterPtr
+__envoy_dynamic_module_v1_event_listener_filter_init(
+    __envoy_dynamic_module_v1_type_ListenerFilterConfigPtr config_ptr,
+    __envoy_dynamic_module_v1_type_ListenerFilterConfigSize config_size);
+
+// __envoy_dynamic_module_v1_event_listener_filter_destroy is called exactly once when the listener
+// filter is unloaded.
+void __envoy_dynamic_module_v1_event_listener_filter_destroy(
+    __envoy_dynamic_module_v1_type_ListenerFilterPtr listener_filter_ptr);
+
+// __envoy_dynamic_module_v1_event_listener_filter_instance_init is called by any worker thread when
+// a new socket is accepted. That means that the function should be thread-safe.
+//
+// The function returns a pointer to a new instance of the context or nullptr on failure.
+__envoy_dynamic_module_v1_type_ListenerFilterInstancePtr
+__envoy_dynamic_module_v1_event_listener_filter_instance_init(
+    __envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_ListenerFilterPtr listener_filter_ptr);
+
+// __envoy_dynamic_module_v1_event_listener_filter_instance_max_read_bytes is called right after
+// __envoy_dynamic_module_v1_event_listener_filter_instance_init to get the maximum number of bytes
+// the module wants to peek from the socket. Returning 0 means that the module doesn't need the data
+// and __envoy_dynamic_module_v1_event_listener_filter_instance_data is never called.
+size_t __envoy_dynamic_module_v1_event_listener_filter_instance_max_read_bytes(
+    __envoy_dynamic_module_v1_type_ListenerFilterInstancePtr listener_filter_instance_ptr);
+
+// __envoy_dynamic_module_v1_event_listener_filter_instance_accept is called when the socket is
+// accepted.
+__envoy_dynamic_module_v1_type_EventListenerFilterStatus
+__envoy_dynamic_module_v1_event_listener_filter_instance_accept(
+    __envoy_dynamic_module_v1_type_ListenerFilterInstancePtr listener_filter_instance_ptr);
+
+// __envoy_dynamic_module_v1_event_listener_filter_instance_data is called when the data is
+// available on the socket after __envoy_dynamic_module_v1_event_listener_filter_instance_accept
+// returned the stop iteration status. buffer contains all the data peeked so far, up to the
+// number of bytes returned by __envoy_dynamic_module_v1_event_listener_filter_instance_max_read_bytes.
+__envoy_dynamic_module_v1_type_EventListenerFilterStatus
+__envoy_dynamic_module_v1_event_listener_filter_instance_data(
+    __envoy_dynamic_module_v1_type_ListenerFilterInstancePtr listener_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_ListenerPeekBufferPtr buffer);
+
+// __envoy_dynamic_module_v1_event_listener_filter_instance_destroy is called when the listener
+// filter instance is destroyed, i.e. after the listener filter chain is completed or the socket is
+// closed.
+void __envoy_dynamic_module_v1_event_listener_filter_instance_destroy(
+    __envoy_dynamic_module_v1_type_ListenerFilterInstancePtr listener_filter_instance_ptr);
+
+// __envoy_dynamic_module_v1_event_access_logger_init is called by the main thread when the access
+// logger is loaded. The function returns __envoy_dynamic_module_v1_type_AccessLoggerPtr which is a
+// pointer to the in-module singleton context per access logger configuration. Returning nullptr
+// indicates a failure to initialize the module.
+__envoy_dynamic_module_v1_type_AccessLoggerPtr __envoy_dynamic_module_v1_event_access_logger_init(
+    __envoy_dynamic_module_v1_type_AccessLoggerConfigPtr config_ptr,
+    __envoy_dynamic_module_v1_type_AccessLoggerConfigSize config_size);
+
+// __envoy_dynamic_module_v1_event_access_logger_destroy is called exactly once when the access
+// logger is destroyed.
+void __envoy_dynamic_module_v1_event_access_logger_destroy(
+    __envoy_dynamic_module_v1_type_AccessLoggerPtr access_logger_ptr);
+
+// __envoy_dynamic_module_v1_event_access_logger_log is called by any worker thread when a stream
+// is completed and logged. log_entry_ptr is only valid during this call, so the module must copy
+// out all the data it needs before returning. This can be called concurrently for multiple streams.
+void __envoy_dynamic_module_v1_event_access_logger_log(
+    __envoy_dynamic_module_v1_type_AccessLoggerPtr access_logger_ptr,
+    __envoy_dynamic_module_v1_type_AccessLogEntryPtr log_entry_ptr);
 #endif
 
 #undef OWNED_BY_ENVOY
@@ -348,6 +805,18 @@ void __envoy_dynamic_module_v1_event_http_filter_instance_destroy(
 //
 // Note that pointers owned by Envoy should be made sure valid by following the caveat in the
 // comments.
+//
+// The functions that are not part of the ABI version 1 are declared as weak symbols in the module
+// code at the end of this file, so that the module can be loaded by Envoy that doesn't implement
+// them. The address of such a function is null if Envoy doesn't implement it, and the module must
+// check it before calling.
+
+// ---------------- Version API ----------------
+
+// __envoy_dynamic_module_v1_get_abi_version is called by the module to get the ABI version
+// implemented by Envoy, i.e. the __ENVOY_DYNAMIC_MODULE_V1_ABI_VERSION that Envoy is built with.
+// Envoy that doesn't implement this function implements the version 1.
+size_t __envoy_dynamic_module_v1_get_abi_version();
 
 // ---------------- Header API ----------------
 
@@ -564,8 +1033,120 @@ void __envoy_dynamic_module_v1_http_continue_request(
 void __envoy_dynamic_module_v1_http_continue_response(
     __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr);
 
+// ---------------- Flow Control API ----------------
+
+// __envoy_dynamic_module_v1_http_read_disable_request is called by the module to stop or resume
+// reading the request data from the downstream. If disable is non-zero, Envoy stops reading from
+// the downstream connection as if the buffer of this filter went above the high watermark. If
+// disable is zero, Envoy resumes reading. Calls must be balanced: each non-zero call must be
+// followed by exactly one zero call.
+void __envoy_dynamic_module_v1_http_read_disable_request(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr,
+    size_t disable);
+
+// __envoy_dynamic_module_v1_http_read_disable_response is called by the module to stop or resume
+// reading the response data from the upstream. If disable is non-zero, Envoy stops reading from
+// the upstream connection as if the buffer of this filter went above the high watermark. If
+// disable is zero, Envoy resumes reading. Calls must be balanced: each non-zero call must be
+// followed by exactly one zero call.
+void __envoy_dynamic_module_v1_http_read_disable_response(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr,
+    size_t disable);
+
+// ---------------- Data Injection API ----------------
+
+// __envoy_dynamic_module_v1_http_inject_request_data is called by the module to inject the data
+// into the request filter chain right after this filter, i.e. the data bypasses the buffered
+// request body and is passed to the next filter directly. end_of_stream indicates that this is the
+// last data of the request. The data is copied by Envoy, so the module can reuse the buffer after
+// this returns.
+//
+// This is supposed to be used while the request processing is stopped by this filter, and can be
+// called from any thread. Envoy schedules the injection on the worker thread of the stream.
+void __envoy_dynamic_module_v1_http_inject_request_data(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length,
+    __envoy_dynamic_module_v1_type_EndOfStream end_of_stream);
+
+// __envoy_dynamic_module_v1_http_inject_response_data is called by the module to inject the data
+// into the response filter chain right after this filter, i.e. the data bypasses the buffered
+// response body and is passed to the next filter directly. end_of_stream indicates that this is the
+// last data of the response. The data is copied by Envoy, so the module can reuse the buffer after
+// this returns.
+//
+// This is supposed to be used while the response processing is stopped by this filter, and can be
+// called from any thread. Envoy schedules the injection on the worker thread of the stream.
+void __envoy_dynamic_module_v1_http_inject_response_data(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length,
+    __envoy_dynamic_module_v1_type_EndOfStream end_of_stream);
+
+// ---------------- Header-only Stream Body API ----------------
+
+// __envoy_dynamic_module_v1_http_add_request_body is called by the module to attach a body to
+// the header-only request. This is only valid during
+// __envoy_dynamic_module_v1_event_http_filter_instance_request_headers with end_of_stream set to 1.
+// Envoy creates the request body buffer with the data and the request is no longer header-only.
+//
+// Envoy doesn't modify the content-length header, so the module is responsible for it.
+void __envoy_dynamic_module_v1_http_add_request_body(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length);
+
+// __envoy_dynamic_module_v1_http_add_response_body is called by the module to attach a body to
+// the header-only response. This is only valid during
+// __envoy_dynamic_module_v1_event_http_filter_instance_response_headers with end_of_stream set to
+// 1. Envoy creates the response body buffer with the data and the response is no longer
+// header-only.
+//
+// Envoy doesn't modify the content-length header, so the module is responsible for it.
+void __envoy_dynamic_module_v1_http_add_response_body(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length);
+
+// ---------------- Upstream Info API ----------------
+
+// __envoy_dynamic_module_v1_http_get_upstream_attempt is called by the module to get the 1-based
+// number of the upstream request attempt the filter instance is running for, which is incremented
+// on each retry. This returns 0 if the filter instance is not running as an upstream http filter.
+size_t __envoy_dynamic_module_v1_http_get_upstream_attempt(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr);
+
+// __envoy_dynamic_module_v1_http_get_upstream_cluster_name is called by the module to get the name
+// of the cluster the upstream http filter instance is running for. result_buffer_ptr and
+// result_buffer_length_ptr are direct references to the name owned by Envoy which is valid until the
+// filter instance is destroyed. The function returns 0 if the filter instance is not running as an
+// upstream http filter.
+size_t __envoy_dynamic_module_v1_http_get_upstream_cluster_name(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr);
+
+// __envoy_dynamic_module_v1_http_get_upstream_host_address is called by the module to get the
+// address of the upstream host selected for the attempt in the form of "ip:port".
+// result_buffer_ptr and result_buffer_length_ptr are direct references to the address owned by
+// Envoy which is valid until the filter instance is destroyed. The function returns 0 if the host
+// is not selected yet or the filter instance is not running as an upstream http filter.
+size_t __envoy_dynamic_module_v1_http_get_upstream_host_address(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr);
+
 // ---------------- Miscellaneous API ----------------
 
+// __envoy_dynamic_module_v1_http_bypass_events is called by the module during
+// __envoy_dynamic_module_v1_event_http_filter_instance_init to tell Envoy the events that the module
+// is not interested in for the filter instance. Envoy will not invoke the event hooks in the given
+// mask for the instance, and behaves as if the Continue status was returned from them. This is
+// purely an optimization to avoid calling into the module, so Envoy may ignore it.
+void __envoy_dynamic_module_v1_http_bypass_events(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_HttpFilterEventMask events);
+
 // __envoy_dynamic_module_v1_http_send_response is called by the module to send a response to the
 // client. headers_vector is a vector of headers to send. status_code is the status code to send.
 // body is the body to send. body_length is the length of the body.
@@ -576,6 +1157,423 @@ void __envoy_dynamic_module_v1_http_send_response(
     __envoy_dynamic_module_v1_type_InModuleBufferPtr body,
     __envoy_dynamic_module_v1_type_InModuleBufferLength body_length);
 
+// ---------------- Network Filter API ----------------
+
+// __envoy_dynamic_module_v1_network_get_read_buffer_length is called by the module to get the
+// length (number of bytes) of the read buffer.
+size_t __envoy_dynamic_module_v1_network_get_read_buffer_length(
+    __envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer);
+
+// __envoy_dynamic_module_v1_network_get_read_buffer_slices_count is called by the module to get
+// the number of slices in the read buffer.
+size_t __envoy_dynamic_module_v1_network_get_read_buffer_slices_count(
+    __envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer);
+
+// __envoy_dynamic_module_v1_network_get_read_buffer_slice is called by the module to get the
+// n-th slice of the read buffer. If nth is out of bounds, this function returns nullptr and 0.
+void __envoy_dynamic_module_v1_network_get_read_buffer_slice(
+    __envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer, size_t nth,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr);
+
+// __envoy_dynamic_module_v1_network_copy_out_read_buffer is called by the module to copy
+// `length` bytes from the read buffer starting from `offset` to the `result_buffer_ptr`.
+void __envoy_dynamic_module_v1_network_copy_out_read_buffer(
+    __envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer, size_t offset, size_t length,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr result_buffer_ptr);
+
+// __envoy_dynamic_module_v1_network_append_read_buffer is called by the module to append data
+// to the end of the read buffer.
+//
+// After calling this function, the previously returned slices may be invalidated.
+void __envoy_dynamic_module_v1_network_append_read_buffer(
+    __envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length);
+
+// __envoy_dynamic_module_v1_network_prepend_read_buffer is called by the module to prepend data
+// to the beginning of the read buffer.
+//
+// After calling this function, the previously returned slices may be invalidated.
+void __envoy_dynamic_module_v1_network_prepend_read_buffer(
+    __envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length);
+
+// __envoy_dynamic_module_v1_network_drain_read_buffer is called by the module to drain length
+// bytes from the beginning of the read buffer.
+//
+// After calling this function, the previously returned slices may be invalidated.
+void __envoy_dynamic_module_v1_network_drain_read_buffer(
+    __envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer, size_t length);
+
+// __envoy_dynamic_module_v1_network_get_write_buffer_length is called by the module to get the
+// length (number of bytes) of the write buffer.
+size_t __envoy_dynamic_module_v1_network_get_write_buffer_length(
+    __envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer);
+
+// __envoy_dynamic_module_v1_network_get_write_buffer_slices_count is called by the module to get
+// the number of slices in the write buffer.
+size_t __envoy_dynamic_module_v1_network_get_write_buffer_slices_count(
+    __envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer);
+
+// __envoy_dynamic_module_v1_network_get_write_buffer_slice is called by the module to get the
+// n-th slice of the write buffer. If nth is out of bounds, this function returns nullptr and 0.
+void __envoy_dynamic_module_v1_network_get_write_buffer_slice(
+    __envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer, size_t nth,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr);
+
+// __envoy_dynamic_module_v1_network_copy_out_write_buffer is called by the module to copy
+// `length` bytes from the write buffer starting from `offset` to the `result_buffer_ptr`.
+void __envoy_dynamic_module_v1_network_copy_out_write_buffer(
+    __envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer, size_t offset, size_t length,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr result_buffer_ptr);
+
+// __envoy_dynamic_module_v1_network_append_write_buffer is called by the module to append data
+// to the end of the write buffer.
+//
+// After calling this function, the previously returned slices may be invalidated.
+void __envoy_dynamic_module_v1_network_append_write_buffer(
+    __envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length);
+
+// __envoy_dynamic_module_v1_network_prepend_write_buffer is called by the module to prepend data
+// to the beginning of the write buffer.
+//
+// After calling this function, the previously returned slices may be invalidated.
+void __envoy_dynamic_module_v1_network_prepend_write_buffer(
+    __envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length);
+
+// __envoy_dynamic_module_v1_network_drain_write_buffer is called by the module to drain length
+// bytes from the beginning of the write buffer.
+//
+// After calling this function, the previously returned slices may be invalidated.
+void __envoy_dynamic_module_v1_network_drain_write_buffer(
+    __envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer, size_t length);
+
+// __envoy_dynamic_module_v1_network_continue_reading is called by the module to resume the
+// iteration of the read filter chain after
+// __envoy_dynamic_module_v1_event_network_filter_instance_new_connection or
+// __envoy_dynamic_module_v1_event_network_filter_instance_read returned the stop iteration status.
+void __envoy_dynamic_module_v1_network_continue_reading(
+    __envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr envoy_filter_instance_ptr);
+
+// __envoy_dynamic_module_v1_network_write is called by the module to write data directly to the
+// downstream connection. end_of_stream indicates that this is the last data to write, i.e. the
+// write side of the connection is half-closed after the data is written.
+void __envoy_dynamic_module_v1_network_write(
+    __envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length,
+    __envoy_dynamic_module_v1_type_EndOfStream end_of_stream);
+
+// __envoy_dynamic_module_v1_network_close is called by the module to close the downstream
+// connection. If flush_write is non-zero, Envoy flushes the pending write data before closing.
+void __envoy_dynamic_module_v1_network_close(
+    __envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr envoy_filter_instance_ptr,
+    size_t flush_write);
+
+// __envoy_dynamic_module_v1_network_get_remote_address is called by the module to get the remote
+// address of the downstream connection in the form of "ip:port". result_buffer_ptr and
+// result_buffer_length_ptr are direct references to the address owned by Envoy which is valid
+// until the connection is closed. The function returns 0 if the address is not available.
+size_t __envoy_dynamic_module_v1_network_get_remote_address(
+    __envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr);
+
+// ---------------- Listener Filter API ----------------
+
+// __envoy_dynamic_module_v1_listener_get_peek_buffer_length is called by the module to get the
+// length (number of bytes) of the peek buffer.
+size_t __envoy_dynamic_module_v1_listener_get_peek_buffer_length(
+    __envoy_dynamic_module_v1_type_ListenerPeekBufferPtr buffer);
+
+// __envoy_dynamic_module_v1_listener_get_peek_buffer_slices_count is called by the module to get
+// the number of slices in the peek buffer.
+size_t __envoy_dynamic_module_v1_listener_get_peek_buffer_slices_count(
+    __envoy_dynamic_module_v1_type_ListenerPeekBufferPtr buffer);
+
+// __envoy_dynamic_module_v1_listener_get_peek_buffer_slice is called by the module to get the
+// n-th slice of the peek buffer. If nth is out of bounds, this function returns nullptr and 0.
+void __envoy_dynamic_module_v1_listener_get_peek_buffer_slice(
+    __envoy_dynamic_module_v1_type_ListenerPeekBufferPtr buffer, size_t nth,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr);
+
+// __envoy_dynamic_module_v1_listener_copy_out_peek_buffer is called by the module to copy `length`
+// bytes from the peek buffer starting from `offset` to the `result_buffer_ptr`.
+void __envoy_dynamic_module_v1_listener_copy_out_peek_buffer(
+    __envoy_dynamic_module_v1_type_ListenerPeekBufferPtr buffer, size_t offset, size_t length,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr result_buffer_ptr);
+
+// __envoy_dynamic_module_v1_listener_set_detected_transport_protocol is called by the module to
+// set the detected transport protocol of the socket, e.g. "tls" or "raw_buffer", which is used for
+// the filter chain matching.
+void __envoy_dynamic_module_v1_listener_set_detected_transport_protocol(
+    __envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr protocol,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength protocol_length);
+
+// __envoy_dynamic_module_v1_listener_set_requested_server_name is called by the module to set the
+// requested server name of the socket, e.g. SNI, which is used for the filter chain matching.
+void __envoy_dynamic_module_v1_listener_set_requested_server_name(
+    __envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr server_name,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength server_name_length);
+
+// __envoy_dynamic_module_v1_listener_continue_filter_chain is called by the module to resume the
+// listener filter chain after the module returned the stop iteration status. If success is zero,
+// Envoy closes the socket, i.e. the socket is rejected.
+void __envoy_dynamic_module_v1_listener_continue_filter_chain(
+    __envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr envoy_filter_instance_ptr,
+    size_t success);
+
+// __envoy_dynamic_module_v1_listener_close_socket is called by the module to close the socket
+// during the listener event hooks, i.e. the socket is rejected. The module should return the stop
+// iteration status after calling this function.
+void __envoy_dynamic_module_v1_listener_close_socket(
+    __envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr envoy_filter_instance_ptr);
+
+// __envoy_dynamic_module_v1_listener_get_remote_address is called by the module to get the remote
+// address of the socket in the form of "ip:port". result_buffer_ptr and result_buffer_length_ptr
+// are direct references to the address owned by Envoy which is valid until the listener filter
+// instance is destroyed. The function returns 0 if the address is not available.
+size_t __envoy_dynamic_module_v1_listener_get_remote_address(
+    __envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr);
+
+// ---------------- Access Logger API ----------------
+//
+// All the functions in this section take the log_entry_ptr passed to
+// __envoy_dynamic_module_v1_event_access_logger_log, and the returned data slices are only valid
+// during the event hook.
+
+// __envoy_dynamic_module_v1_access_log_get_header_value is called by the module to get the value
+// for a header key in the header map of the log entry specified by headers_type. The function
+// returns the number of values found. If the key or the header map is not found, this function
+// returns nullptr and 0. The n-th value can be accessed by calling
+// __envoy_dynamic_module_v1_access_log_get_header_value_nth.
+size_t __envoy_dynamic_module_v1_access_log_get_header_value(
+    __envoy_dynamic_module_v1_type_AccessLogEntryPtr log_entry_ptr,
+    __envoy_dynamic_module_v1_type_AccessLogHeadersType headers_type,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr key,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength key_length,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr);
+
+// __envoy_dynamic_module_v1_access_log_get_header_value_nth is almost the same as
+// __envoy_dynamic_module_v1_access_log_get_header_value, but it allows the module to access n-th
+// value of the header. If nth is out of bounds, this function returns nullptr and 0.
+void __envoy_dynamic_module_v1_access_log_get_header_value_nth(
+    __envoy_dynamic_module_v1_type_AccessLogEntryPtr log_entry_ptr,
+    __envoy_dynamic_module_v1_type_AccessLogHeadersType headers_type,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr key,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength key_length,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr, size_t nth);
+
+// __envoy_dynamic_module_v1_access_log_get_headers_count is called by the module to get the number
+// of the header entries in the header map of the log entry specified by headers_type. This returns
+// 0 if the header map is not found.
+size_t __envoy_dynamic_module_v1_access_log_get_headers_count(
+    __envoy_dynamic_module_v1_type_AccessLogEntryPtr log_entry_ptr,
+    __envoy_dynamic_module_v1_type_AccessLogHeadersType headers_type);
+
+// __envoy_dynamic_module_v1_access_log_get_header_nth is called by the module to get the n-th
+// header entry in the header map of the log entry specified by headers_type. If nth is out of
+// bounds, this function returns nullptr and 0 for both the key and the value.
+void __envoy_dynamic_module_v1_access_log_get_header_nth(
+    __envoy_dynamic_module_v1_type_AccessLogEntryPtr log_entry_ptr,
+    __envoy_dynamic_module_v1_type_AccessLogHeadersType headers_type, size_t nth,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_key_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_key_length_ptr,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_value_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_value_length_ptr);
+
+// __envoy_dynamic_module_v1_access_log_get_start_time is called by the module to get the start time
+// of the stream in nanoseconds since the Unix epoch.
+int64_t __envoy_dynamic_module_v1_access_log_get_start_time(
+    __envoy_dynamic_module_v1_type_AccessLogEntryPtr log_entry_ptr);
+
+// __envoy_dynamic_module_v1_access_log_get_timing is called by the module to get the duration
+// in nanoseconds from the start of the stream to the given timing. This returns -1 if the timing
+// is not available, e.g. the stream has never reached the upstream.
+int64_t __envoy_dynamic_module_v1_access_log_get_timing(
+    __envoy_dynamic_module_v1_type_AccessLogEntryPtr log_entry_ptr,
+    __envoy_dynamic_module_v1_type_AccessLogTiming timing);
+
+// __envoy_dynamic_module_v1_access_log_get_bytes_received is called by the module to get the
+// number of body bytes received from the downstream.
+uint64_t __envoy_dynamic_module_v1_access_log_get_bytes_received(
+    __envoy_dynamic_module_v1_type_AccessLogEntryPtr log_entry_ptr);
+
+// __envoy_dynamic_module_v1_access_log_get_bytes_sent is called by the module to get the number
+// of body bytes sent to the downstream.
+uint64_t __envoy_dynamic_module_v1_access_log_get_bytes_sent(
+    __envoy_dynamic_module_v1_type_AccessLogEntryPtr log_entry_ptr);
+
+// __envoy_dynamic_module_v1_access_log_get_response_code is called by the module to get the
+// response code of the stream. This returns 0 if the response code is not available.
+uint32_t __envoy_dynamic_module_v1_access_log_get_response_code(
+    __envoy_dynamic_module_v1_type_AccessLogEntryPtr log_entry_ptr);
+
+// __envoy_dynamic_module_v1_access_log_get_response_flags is called by the module to get the
+// response flags of the stream as a bitmask where the n-th bit corresponds to the n-th flag
+// of Envoy's StreamInfo::CoreResponseFlag.
+uint64_t __envoy_dynamic_module_v1_access_log_get_response_flags(
+    __envoy_dynamic_module_v1_type_AccessLogEntryPtr log_entry_ptr);
+
+// __envoy_dynamic_module_v1_access_log_get_dynamic_metadata is called by the module to get the
+// value of the dynamic metadata for the given namespace and key. Non-string values are serialized
+// as JSON. The function returns 0 if the metadata is not found.
+size_t __envoy_dynamic_module_v1_access_log_get_dynamic_metadata(
+    __envoy_dynamic_module_v1_type_AccessLogEntryPtr log_entry_ptr,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr metadata_namespace,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength metadata_namespace_length,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr key,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength key_length,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr);
+
+// ---------------- Stats API ----------------
+
+// __envoy_dynamic_module_v1_stats_set_gauge is called by the module to set the value of the gauge
+// of the given name in the stats scope of the module, e.g. "dynamic_module.<name>" in the Envoy
+// stats. The gauge is created on the first call. This can be called from any thread.
+void __envoy_dynamic_module_v1_stats_set_gauge(
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr name,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength name_length, uint64_t value);
+
+// __envoy_dynamic_module_v1_stats_increment_counter is called by the module to add the value to the
+// counter of the given name in the stats scope of the module in the same way as
+// __envoy_dynamic_module_v1_stats_set_gauge. This can be called from any thread.
+void __envoy_dynamic_module_v1_stats_increment_counter(
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr name,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength name_length, uint64_t value);
+
+// ---------------- Batch Header API ----------------
+
+// __envoy_dynamic_module_v1_http_mutate_request_headers is called by the module to apply multiple
+// mutations to the request headers in a single call. headers is the one passed to the
+// __envoy_dynamic_module_v1_event_http_filter_instance_request_headers. The mutations are applied
+// in the following order:
+//  1. All the values of each key in remove are removed.
+//  2. Each header in set is set in the same way as __envoy_dynamic_module_v1_http_set_request_header.
+//  3. Each header in add is added as a new value, keeping the existing values of the same key.
+void __envoy_dynamic_module_v1_http_mutate_request_headers(
+    __envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr headers,
+    __envoy_dynamic_module_v1_type_InModuleBuffersPtr remove,
+    __envoy_dynamic_module_v1_type_InModuleBuffersSize remove_size,
+    __envoy_dynamic_module_v1_type_InModuleHeadersPtr set,
+    __envoy_dynamic_module_v1_type_InModuleHeadersSize set_size,
+    __envoy_dynamic_module_v1_type_InModuleHeadersPtr add,
+    __envoy_dynamic_module_v1_type_InModuleHeadersSize add_size);
+
+// __envoy_dynamic_module_v1_http_mutate_response_headers is the same as
+// __envoy_dynamic_module_v1_http_mutate_request_headers but for the response headers passed to the
+// __envoy_dynamic_module_v1_event_http_filter_instance_response_headers.
+void __envoy_dynamic_module_v1_http_mutate_response_headers(
+    __envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers,
+    __envoy_dynamic_module_v1_type_InModuleBuffersPtr remove,
+    __envoy_dynamic_module_v1_type_InModuleBuffersSize remove_size,
+    __envoy_dynamic_module_v1_type_InModuleHeadersPtr set,
+    __envoy_dynamic_module_v1_type_InModuleHeadersSize set_size,
+    __envoy_dynamic_module_v1_type_InModuleHeadersPtr add,
+    __envoy_dynamic_module_v1_type_InModuleHeadersSize add_size);
+
+// __envoy_dynamic_module_v1_http_get_request_header_values is called by the module to get the first
+// values of multiple request header keys in a single call. results points to the vector of
+// keys_size slices, and Envoy sets the i-th slice to the first value of the i-th key. The data of
+// the slice is nullptr if the key is not found. The function returns the number of keys found.
+size_t __envoy_dynamic_module_v1_http_get_request_header_values(
+    __envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr headers,
+    __envoy_dynamic_module_v1_type_InModuleBuffersPtr keys,
+    __envoy_dynamic_module_v1_type_InModuleBuffersSize keys_size,
+    __envoy_dynamic_module_v1_type_DataSlicesResult results);
+
+// __envoy_dynamic_module_v1_http_get_response_header_values is the same as
+// __envoy_dynamic_module_v1_http_get_request_header_values but for the response headers.
+size_t __envoy_dynamic_module_v1_http_get_response_header_values(
+    __envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers,
+    __envoy_dynamic_module_v1_type_InModuleBuffersPtr keys,
+    __envoy_dynamic_module_v1_type_InModuleBuffersSize keys_size,
+    __envoy_dynamic_module_v1_type_DataSlicesResult results);
+
+// ---------------- Stream Reset API ----------------
+
+// __envoy_dynamic_module_v1_http_reset_stream is called by the module to reset the stream of the
+// filter instance, e.g. when the processing of the stream is stuck. Envoy resets the downstream
+// stream, and __envoy_dynamic_module_v1_event_http_filter_instance_destroy is called later as
+// usual. This can be called from any thread, and is no-op if the stream is already completed.
+void __envoy_dynamic_module_v1_http_reset_stream(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr);
+
+#ifndef ENVOY_DYNAMIC_MODULE
+// The Envoy APIs that are not part of the ABI version 1 are weak symbols in the module code.
+#pragma weak __envoy_dynamic_module_v1_get_abi_version
+#pragma weak __envoy_dynamic_module_v1_http_read_disable_request
+#pragma weak __envoy_dynamic_module_v1_http_read_disable_response
+#pragma weak __envoy_dynamic_module_v1_http_inject_request_data
+#pragma weak __envoy_dynamic_module_v1_http_inject_response_data
+#pragma weak __envoy_dynamic_module_v1_http_add_request_body
+#pragma weak __envoy_dynamic_module_v1_http_add_response_body
+#pragma weak __envoy_dynamic_module_v1_http_get_upstream_attempt
+#pragma weak __envoy_dynamic_module_v1_http_get_upstream_cluster_name
+#pragma weak __envoy_dynamic_module_v1_http_get_upstream_host_address
+#pragma weak __envoy_dynamic_module_v1_http_bypass_events
+#pragma weak __envoy_dynamic_module_v1_network_get_read_buffer_length
+#pragma weak __envoy_dynamic_module_v1_network_get_read_buffer_slices_count
+#pragma weak __envoy_dynamic_module_v1_network_get_read_buffer_slice
+#pragma weak __envoy_dynamic_module_v1_network_copy_out_read_buffer
+#pragma weak __envoy_dynamic_module_v1_network_append_read_buffer
+#pragma weak __envoy_dynamic_module_v1_network_prepend_read_buffer
+#pragma weak __envoy_dynamic_module_v1_network_drain_read_buffer
+#pragma weak __envoy_dynamic_module_v1_network_get_write_buffer_length
+#pragma weak __envoy_dynamic_module_v1_network_get_write_buffer_slices_count
+#pragma weak __envoy_dynamic_module_v1_network_get_write_buffer_slice
+#pragma weak __envoy_dynamic_module_v1_network_copy_out_write_buffer
+#pragma weak __envoy_dynamic_module_v1_network_append_write_buffer
+#pragma weak __envoy_dynamic_module_v1_network_prepend_write_buffer
+#pragma weak __envoy_dynamic_module_v1_network_drain_write_buffer
+#pragma weak __envoy_dynamic_module_v1_network_continue_reading
+#pragma weak __envoy_dynamic_module_v1_network_write
+#pragma weak __envoy_dynamic_module_v1_network_close
+#pragma weak __envoy_dynamic_module_v1_network_get_remote_address
+#pragma weak __envoy_dynamic_module_v1_listener_get_peek_buffer_length
+#pragma weak __envoy_dynamic_module_v1_listener_get_peek_buffer_slices_count
+#pragma weak __envoy_dynamic_module_v1_listener_get_peek_buffer_slice
+#pragma weak __envoy_dynamic_module_v1_listener_copy_out_peek_buffer
+#pragma weak __envoy_dynamic_module_v1_listener_set_detected_transport_protocol
+#pragma weak __envoy_dynamic_module_v1_listener_set_requested_server_name
+#pragma weak __envoy_dynamic_module_v1_listener_continue_filter_chain
+#pragma weak __envoy_dynamic_module_v1_listener_close_socket
+#pragma weak __envoy_dynamic_module_v1_listener_get_remote_address
+#pragma weak __envoy_dynamic_module_v1_access_log_get_header_value
+#pragma weak __envoy_dynamic_module_v1_access_log_get_header_value_nth
+#pragma weak __envoy_dynamic_module_v1_access_log_get_headers_count
+#pragma weak __envoy_dynamic_module_v1_access_log_get_header_nth
+#pragma weak __envoy_dynamic_module_v1_access_log_get_start_time
+#pragma weak __envoy_dynamic_module_v1_access_log_get_timing
+#pragma weak __envoy_dynamic_module_v1_access_log_get_bytes_received
+#pragma weak __envoy_dynamic_module_v1_access_log_get_bytes_sent
+#pragma weak __envoy_dynamic_module_v1_access_log_get_response_code
+#pragma weak __envoy_dynamic_module_v1_access_log_get_response_flags
+#pragma weak __envoy_dynamic_module_v1_access_log_get_dynamic_metadata
+#pragma weak __envoy_dynamic_module_v1_stats_set_gauge
+#pragma weak __envoy_dynamic_module_v1_stats_increment_counter
+#pragma weak __envoy_dynamic_module_v1_http_mutate_request_headers
+#pragma weak __envoy_dynamic_module_v1_http_mutate_response_headers
+#pragma weak __envoy_dynamic_module_v1_http_get_request_header_values
+#pragma weak __envoy_dynamic_module_v1_http_get_response_header_values
+#pragma weak __envoy_dynamic_module_v1_http_reset_stream
+#endif
+
 #ifdef __cplusplus
 }
 #endif
@@ -0,0 +1,170 @@
+package envoy
+
+import (
+	"time"
+	"unsafe"
+)
+
+func eventAccessLoggerInit(configPtr uintptr, configSize int) uintptr {
+	accessLogger := NewAccessLogger(copyConfig(configPtr, configSize))
+	pined := memManager.pinAccessLogger(accessLogger)
+	return uintptr(unsafe.Pointer(pined))
+}
+
+func eventAccessLoggerDestroy(accessLoggerPtr uintptr) {
+	accessLogger := memManager.unwrapPinnedAccessLogger(accessLoggerPtr)
+	accessLogger.logger.Destroy()
+	memManager.unpinAccessLogger(accessLogger)
+}
+
+func eventAccessLoggerLog(accessLoggerPtr uintptr, logEntryPtr uintptr) {
+	accessLogger := memManager.unwrapPinnedAccessLogger(accessLoggerPtr)
+	stamp := newViewStamp()
+	accessLogger.logger.Log(AccessLogEntry{stamp: stamp, raw: logEntryPtr})
+	stamp.expire()
+}
+
+// AccessLogEntry is an opaque object that represents the log entry of a single completed stream.
+// This is only valid during AccessLogger.Log.
+type AccessLogEntry struct {
+	stamp viewStamp
+	raw   uintptr
+}
+
+// RequestHeaders returns the request headers of the stream.
+func (e AccessLogEntry) RequestHeaders() AccessLogHeaders {
+	e.stamp.check("AccessLogEntry")
+	return AccessLogHeaders{stamp: e.stamp, raw: e.raw, typ: abiAccessLogHeadersRequest}
+}
+
+// RequestTrailers returns the request trailers of the stream.
+func (e AccessLogEntry) RequestTrailers() AccessLogHeaders {
+	e.stamp.check("AccessLogEntry")
+	return AccessLogHeaders{stamp: e.stamp, raw: e.raw, typ: abiAccessLogHeadersRequestTrailers}
+}
+
+// ResponseHeaders returns the response headers of the stream.
+func (e AccessLogEntry) ResponseHeaders() AccessLogHeaders {
+	e.stamp.check("AccessLogEntry")
+	return AccessLogHeaders{stamp: e.stamp, raw: e.raw, typ: abiAccessLogHeadersResponse}
+}
+
+// ResponseTrailers returns the response trailers of the stream.
+func (e AccessLogEntry) ResponseTrailers() AccessLogHeaders {
+	e.stamp.check("AccessLogEntry")
+	return AccessLogHeaders{stamp: e.stamp, raw: e.raw, typ: abiAccessLogHeadersResponseTrailers}
+}
+
+// StartTime returns the time when the stream was started.
+func (e AccessLogEntry) StartTime() time.Time {
+	e.stamp.check("AccessLogEntry")
+	return time.Unix(0, hostAccessLogGetStartTime(e.raw))
+}
+
+// Timing returns the duration from the start of the stream to the given timing.
+// Returns false at the second return value if the timing is not available, e.g. the stream has never
+// reached the upstream.
+func (e AccessLogEntry) Timing(timing AccessLogTiming) (time.Duration, bool) {
+	e.stamp.check("AccessLogEntry")
+	d := hostAccessLogGetTiming(e.raw, int(timing))
+	if d < 0 {
+		return 0, false
+	}
+	return time.Duration(d), true
+}
+
+// BytesReceived returns the number of body bytes received from the downstream.
+func (e AccessLogEntry) BytesReceived() uint64 {
+	e.stamp.check("AccessLogEntry")
+	return hostAccessLogGetBytesReceived(e.raw)
+}
+
+// BytesSent returns the number of body bytes sent to the downstream.
+func (e AccessLogEntry) BytesSent() uint64 {
+	e.stamp.check("AccessLogEntry")
+	return hostAccessLogGetBytesSent(e.raw)
+}
+
+// ResponseCode returns the response code of the stream.
+// Returns false at the second return value if the response code is not available, e.g. the stream was reset
+// before the response.
+func (e AccessLogEntry) ResponseCode() (int, bool) {
+	e.stamp.check("AccessLogEntry")
+	code := hostAccessLogGetResponseCode(e.raw)
+	return int(code), code != 0
+}
+
+// ResponseFlags returns the response flags of the stream.
+func (e AccessLogEntry) ResponseFlags() ResponseFlags {
+	e.stamp.check("AccessLogEntry")
+	return ResponseFlags(hostAccessLogGetResponseFlags(e.raw))
+}
+
+// DynamicMetadata returns the value of the dynamic metadata for the given namespace and key.
+// Non-string values are serialized as JSON. Returns false at the second return value if not found.
+func (e AccessLogEntry) DynamicMetadata(namespace, key string) (string, bool) {
+	e.stamp.check("AccessLogEntry")
+	var resultPtr *byte
+	var resultSize int
+	if hostAccessLogGetDynamicMetadata(e.raw, stringPtr(namespace), len(namespace), stringPtr(key), len(key),
+		unsafe.Pointer(&resultPtr), unsafe.Pointer(&resultSize)) == 0 {
+		return "", false
+	}
+	return string(unsafe.Slice(resultPtr, resultSize)), true
+}
+
+// AccessLogHeaders is an opaque object that represents one of the header maps of AccessLogEntry.
+// If the header map doesn't exist in the stream, e.g. the response trailers, it behaves as an empty map.
+type AccessLogHeaders struct {
+	stamp viewStamp
+	raw   uintptr
+	typ   int
+}
+
+// Get returns the first header value for the given key. To handle multiple values, use the Values method.
+// Returns true at the second return value if the key exists.
+func (h AccessLogHeaders) Get(key string) (HeaderValue, bool) {
+	h.stamp.check("AccessLogHeaders")
+	var resultPtr *byte
+	var resultSize int
+	total := hostAccessLogGetHeaderValue(h.raw, h.typ, stringPtr(key), len(key),
+		unsafe.Pointer(&resultPtr), unsafe.Pointer(&resultSize))
+	if total == 0 {
+		return HeaderValue{}, false
+	}
+	return HeaderValue{stamp: h.stamp, data: resultPtr, size: resultSize}, true
+}
+
+// Values iterates over the header values for the given key.
+func (h AccessLogHeaders) Values(key string, iter func(value HeaderValue)) {
+	h.stamp.check("AccessLogHeaders")
+	var resultPtr *byte
+	var resultSize int
+	total := hostAccessLogGetHeaderValue(h.raw, h.typ, stringPtr(key), len(key),
+		unsafe.Pointer(&resultPtr), unsafe.Pointer(&resultSize))
+	if total == 0 {
+		return
+	}
+
+	iter(HeaderValue{stamp: h.stamp, data: resultPtr, size: resultSize})
+
+	for i := 1; i < total; i++ {
+		hostAccessLogGetHeaderValueNth(h.raw, h.typ, stringPtr(key), len(key),
+			unsafe.Pointer(&resultPtr), unsafe.Pointer(&resultSize), i)
+		iter(HeaderValue{stamp: h.stamp, data: resultPtr, size: resultSize})
+	}
+}
+
+// All iterates over all the header entries in the map.
+func (h AccessLogHeaders) All(iter func(key, value HeaderValue)) {
+	h.stamp.check("AccessLogHeaders")
+	count := hostAccessLogGetHeadersCount(h.raw, h.typ)
+	for i := 0; i < count; i++ {
+		var keyPtr, valuePtr *byte
+		var keySize, valueSize int
+		hostAccessLogGetHeaderNth(h.raw, h.typ, i,
+			unsafe.Pointer(&keyPtr), unsafe.Pointer(&keySize),
+			unsafe.Pointer(&valuePtr), unsafe.Pointer(&valueSize))
+		iter(HeaderValue{stamp: h.stamp, data: keyPtr, size: keySize}, HeaderValue{stamp: h.stamp, data: valuePtr, size: valueSize})
+	}
+}
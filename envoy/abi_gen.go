@@ -0,0 +1,988 @@
+// Code generated by internal/abigen from abi.h. DO NOT EDIT.
+
+//go:build cgo
+
+package envoy
+
+/*
+#include "abi.h"
+
+typedef struct {
+	size_t ret;
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_http_get_request_header_value_results;
+
+static inline envoy_go_http_get_request_header_value_results envoy_go_http_get_request_header_value(__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength) {
+	envoy_go_http_get_request_header_value_results r = {0};
+	r.ret = __envoy_dynamic_module_v1_http_get_request_header_value(headers, key, keyLength, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr);
+	return r;
+}
+
+typedef struct {
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_http_get_request_header_value_nth_results;
+
+static inline envoy_go_http_get_request_header_value_nth_results envoy_go_http_get_request_header_value_nth(__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength, size_t nth) {
+	envoy_go_http_get_request_header_value_nth_results r = {0};
+	__envoy_dynamic_module_v1_http_get_request_header_value_nth(headers, key, keyLength, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr, nth);
+	return r;
+}
+
+typedef struct {
+	size_t ret;
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_http_get_response_header_value_results;
+
+static inline envoy_go_http_get_response_header_value_results envoy_go_http_get_response_header_value(__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength) {
+	envoy_go_http_get_response_header_value_results r = {0};
+	r.ret = __envoy_dynamic_module_v1_http_get_response_header_value(headers, key, keyLength, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr);
+	return r;
+}
+
+typedef struct {
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_http_get_response_header_value_nth_results;
+
+static inline envoy_go_http_get_response_header_value_nth_results envoy_go_http_get_response_header_value_nth(__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength, size_t nth) {
+	envoy_go_http_get_response_header_value_nth_results r = {0};
+	__envoy_dynamic_module_v1_http_get_response_header_value_nth(headers, key, keyLength, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr, nth);
+	return r;
+}
+
+typedef struct {
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_http_get_request_body_buffer_slice_results;
+
+static inline envoy_go_http_get_request_body_buffer_slice_results envoy_go_http_get_request_body_buffer_slice(__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer, size_t nth) {
+	envoy_go_http_get_request_body_buffer_slice_results r = {0};
+	__envoy_dynamic_module_v1_http_get_request_body_buffer_slice(buffer, nth, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr);
+	return r;
+}
+
+typedef struct {
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_http_get_response_body_buffer_slice_results;
+
+static inline envoy_go_http_get_response_body_buffer_slice_results envoy_go_http_get_response_body_buffer_slice(__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer, size_t nth) {
+	envoy_go_http_get_response_body_buffer_slice_results r = {0};
+	__envoy_dynamic_module_v1_http_get_response_body_buffer_slice(buffer, nth, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr);
+	return r;
+}
+
+typedef struct {
+	size_t ret;
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_http_get_upstream_cluster_name_results;
+
+static inline envoy_go_http_get_upstream_cluster_name_results envoy_go_http_get_upstream_cluster_name(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr) {
+	envoy_go_http_get_upstream_cluster_name_results r = {0};
+	r.ret = __envoy_dynamic_module_v1_http_get_upstream_cluster_name(envoyFilterInstancePtr, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr);
+	return r;
+}
+
+typedef struct {
+	size_t ret;
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_http_get_upstream_host_address_results;
+
+static inline envoy_go_http_get_upstream_host_address_results envoy_go_http_get_upstream_host_address(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr) {
+	envoy_go_http_get_upstream_host_address_results r = {0};
+	r.ret = __envoy_dynamic_module_v1_http_get_upstream_host_address(envoyFilterInstancePtr, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr);
+	return r;
+}
+
+typedef struct {
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_network_get_read_buffer_slice_results;
+
+static inline envoy_go_network_get_read_buffer_slice_results envoy_go_network_get_read_buffer_slice(__envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer, size_t nth) {
+	envoy_go_network_get_read_buffer_slice_results r = {0};
+	__envoy_dynamic_module_v1_network_get_read_buffer_slice(buffer, nth, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr);
+	return r;
+}
+
+typedef struct {
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_network_get_write_buffer_slice_results;
+
+static inline envoy_go_network_get_write_buffer_slice_results envoy_go_network_get_write_buffer_slice(__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer, size_t nth) {
+	envoy_go_network_get_write_buffer_slice_results r = {0};
+	__envoy_dynamic_module_v1_network_get_write_buffer_slice(buffer, nth, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr);
+	return r;
+}
+
+typedef struct {
+	size_t ret;
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_network_get_remote_address_results;
+
+static inline envoy_go_network_get_remote_address_results envoy_go_network_get_remote_address(__envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr envoyFilterInstancePtr) {
+	envoy_go_network_get_remote_address_results r = {0};
+	r.ret = __envoy_dynamic_module_v1_network_get_remote_address(envoyFilterInstancePtr, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr);
+	return r;
+}
+
+typedef struct {
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_listener_get_peek_buffer_slice_results;
+
+static inline envoy_go_listener_get_peek_buffer_slice_results envoy_go_listener_get_peek_buffer_slice(__envoy_dynamic_module_v1_type_ListenerPeekBufferPtr buffer, size_t nth) {
+	envoy_go_listener_get_peek_buffer_slice_results r = {0};
+	__envoy_dynamic_module_v1_listener_get_peek_buffer_slice(buffer, nth, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr);
+	return r;
+}
+
+typedef struct {
+	size_t ret;
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_listener_get_remote_address_results;
+
+static inline envoy_go_listener_get_remote_address_results envoy_go_listener_get_remote_address(__envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr envoyFilterInstancePtr) {
+	envoy_go_listener_get_remote_address_results r = {0};
+	r.ret = __envoy_dynamic_module_v1_listener_get_remote_address(envoyFilterInstancePtr, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr);
+	return r;
+}
+
+typedef struct {
+	size_t ret;
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_access_log_get_header_value_results;
+
+static inline envoy_go_access_log_get_header_value_results envoy_go_access_log_get_header_value(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr, __envoy_dynamic_module_v1_type_AccessLogHeadersType headersType, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength) {
+	envoy_go_access_log_get_header_value_results r = {0};
+	r.ret = __envoy_dynamic_module_v1_access_log_get_header_value(logEntryPtr, headersType, key, keyLength, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr);
+	return r;
+}
+
+typedef struct {
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_access_log_get_header_value_nth_results;
+
+static inline envoy_go_access_log_get_header_value_nth_results envoy_go_access_log_get_header_value_nth(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr, __envoy_dynamic_module_v1_type_AccessLogHeadersType headersType, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength, size_t nth) {
+	envoy_go_access_log_get_header_value_nth_results r = {0};
+	__envoy_dynamic_module_v1_access_log_get_header_value_nth(logEntryPtr, headersType, key, keyLength, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr, nth);
+	return r;
+}
+
+typedef struct {
+	__envoy_dynamic_module_v1_raw_pointer resultKeyPtr;
+	size_t resultKeyLengthPtr;
+	__envoy_dynamic_module_v1_raw_pointer resultValuePtr;
+	size_t resultValueLengthPtr;
+} envoy_go_access_log_get_header_nth_results;
+
+static inline envoy_go_access_log_get_header_nth_results envoy_go_access_log_get_header_nth(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr, __envoy_dynamic_module_v1_type_AccessLogHeadersType headersType, size_t nth) {
+	envoy_go_access_log_get_header_nth_results r = {0};
+	__envoy_dynamic_module_v1_access_log_get_header_nth(logEntryPtr, headersType, nth, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultKeyPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultKeyLengthPtr, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultValuePtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultValueLengthPtr);
+	return r;
+}
+
+typedef struct {
+	size_t ret;
+	__envoy_dynamic_module_v1_raw_pointer resultBufferPtr;
+	size_t resultBufferLengthPtr;
+} envoy_go_access_log_get_dynamic_metadata_results;
+
+static inline envoy_go_access_log_get_dynamic_metadata_results envoy_go_access_log_get_dynamic_metadata(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr, __envoy_dynamic_module_v1_type_InModuleBufferPtr metadataNamespace, __envoy_dynamic_module_v1_type_InModuleBufferLength metadataNamespaceLength, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength) {
+	envoy_go_access_log_get_dynamic_metadata_results r = {0};
+	r.ret = __envoy_dynamic_module_v1_access_log_get_dynamic_metadata(logEntryPtr, metadataNamespace, metadataNamespaceLength, key, keyLength, (__envoy_dynamic_module_v1_type_DataSlicePtrResult)&r.resultBufferPtr, (__envoy_dynamic_module_v1_type_DataSliceLengthResult)&r.resultBufferLengthPtr);
+	return r;
+}
+*/
+import "C"
+import (
+	"runtime"
+	"unsafe"
+)
+
+var _ = runtime.KeepAlive
+var _ unsafe.Pointer
+
+// The pointers are passed to Envoy as integers, so the compiler may keep the memory they point to on the goroutine
+// stack, which can be moved by the stack growth in the cgo call before Envoy accesses it. To prevent this, the results
+// are received on the C stack by the shims in the preamble, and the other pointers are made to escape to the heap
+// by escape, in the same way as cgo does for the pointer arguments.
+var (
+	escapeAlwaysFalse bool
+	escapeSink        unsafe.Pointer
+)
+
+func escape(p unsafe.Pointer) {
+	if escapeAlwaysFalse {
+		escapeSink = p
+	}
+}
+
+//export __envoy_dynamic_module_v1_event_program_init
+func __envoy_dynamic_module_v1_event_program_init() C.size_t {
+	return C.size_t(eventProgramInit())
+}
+
+//export __envoy_dynamic_module_v1_event_http_filter_init
+func __envoy_dynamic_module_v1_event_http_filter_init(configPtr C.__envoy_dynamic_module_v1_type_HttpFilterConfigPtr, configSize C.__envoy_dynamic_module_v1_type_HttpFilterConfigSize) C.__envoy_dynamic_module_v1_type_HttpFilterPtr {
+	return C.__envoy_dynamic_module_v1_type_HttpFilterPtr(eventHttpFilterInit(uintptr(configPtr), int(configSize)))
+}
+
+//export __envoy_dynamic_module_v1_event_http_filter_destroy
+func __envoy_dynamic_module_v1_event_http_filter_destroy(httpFilterPtr C.__envoy_dynamic_module_v1_type_HttpFilterPtr) {
+	eventHttpFilterDestroy(uintptr(httpFilterPtr))
+}
+
+//export __envoy_dynamic_module_v1_event_upstream_http_filter_init
+func __envoy_dynamic_module_v1_event_upstream_http_filter_init(configPtr C.__envoy_dynamic_module_v1_type_HttpFilterConfigPtr, configSize C.__envoy_dynamic_module_v1_type_HttpFilterConfigSize) C.__envoy_dynamic_module_v1_type_HttpFilterPtr {
+	return C.__envoy_dynamic_module_v1_type_HttpFilterPtr(eventUpstreamHttpFilterInit(uintptr(configPtr), int(configSize)))
+}
+
+//export __envoy_dynamic_module_v1_event_http_filter_instance_init
+func __envoy_dynamic_module_v1_event_http_filter_instance_init(envoyFilterInstancePtr C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr, httpFilterPtr C.__envoy_dynamic_module_v1_type_HttpFilterPtr) C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr {
+	return C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr(eventHttpFilterInstanceInit(uintptr(envoyFilterInstancePtr), uintptr(httpFilterPtr)))
+}
+
+//export __envoy_dynamic_module_v1_event_http_filter_instance_request_headers
+func __envoy_dynamic_module_v1_event_http_filter_instance_request_headers(httpFilterInstancePtr C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr, requestHeadersPtr C.__envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr, endOfStream C.__envoy_dynamic_module_v1_type_EndOfStream) C.__envoy_dynamic_module_v1_type_EventHttpRequestHeadersStatus {
+	return C.__envoy_dynamic_module_v1_type_EventHttpRequestHeadersStatus(eventHttpFilterInstanceRequestHeaders(uintptr(httpFilterInstancePtr), uintptr(requestHeadersPtr), endOfStream != 0))
+}
+
+//export __envoy_dynamic_module_v1_event_http_filter_instance_request_body
+func __envoy_dynamic_module_v1_event_http_filter_instance_request_body(httpFilterInstancePtr C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr, buffer C.__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr, endOfStream C.__envoy_dynamic_module_v1_type_EndOfStream) C.__envoy_dynamic_module_v1_type_EventHttpRequestBodyStatus {
+	return C.__envoy_dynamic_module_v1_type_EventHttpRequestBodyStatus(eventHttpFilterInstanceRequestBody(uintptr(httpFilterInstancePtr), uintptr(buffer), endOfStream != 0))
+}
+
+//export __envoy_dynamic_module_v1_event_http_filter_instance_response_headers
+func __envoy_dynamic_module_v1_event_http_filter_instance_response_headers(httpFilterInstancePtr C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr, responseHeadersMapPtr C.__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr, endOfStream C.__envoy_dynamic_module_v1_type_EndOfStream) C.__envoy_dynamic_module_v1_type_EventHttpResponseHeadersStatus {
+	return C.__envoy_dynamic_module_v1_type_EventHttpResponseHeadersStatus(eventHttpFilterInstanceResponseHeaders(uintptr(httpFilterInstancePtr), uintptr(responseHeadersMapPtr), endOfStream != 0))
+}
+
+//export __envoy_dynamic_module_v1_event_http_filter_instance_response_body
+func __envoy_dynamic_module_v1_event_http_filter_instance_response_body(httpFilterInstancePtr C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr, buffer C.__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr, endOfStream C.__envoy_dynamic_module_v1_type_EndOfStream) C.__envoy_dynamic_module_v1_type_EventHttpResponseBodyStatus {
+	return C.__envoy_dynamic_module_v1_type_EventHttpResponseBodyStatus(eventHttpFilterInstanceResponseBody(uintptr(httpFilterInstancePtr), uintptr(buffer), endOfStream != 0))
+}
+
+//export __envoy_dynamic_module_v1_event_http_filter_instance_destroy
+func __envoy_dynamic_module_v1_event_http_filter_instance_destroy(httpFilterInstancePtr C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr) {
+	eventHttpFilterInstanceDestroy(uintptr(httpFilterInstancePtr))
+}
+
+//export __envoy_dynamic_module_v1_event_http_filter_instance_request_above_high_watermark
+func __envoy_dynamic_module_v1_event_http_filter_instance_request_above_high_watermark(httpFilterInstancePtr C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr) {
+	eventHttpFilterInstanceRequestAboveHighWatermark(uintptr(httpFilterInstancePtr))
+}
+
+//export __envoy_dynamic_module_v1_event_http_filter_instance_request_below_low_watermark
+func __envoy_dynamic_module_v1_event_http_filter_instance_request_below_low_watermark(httpFilterInstancePtr C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr) {
+	eventHttpFilterInstanceRequestBelowLowWatermark(uintptr(httpFilterInstancePtr))
+}
+
+//export __envoy_dynamic_module_v1_event_http_filter_instance_response_above_high_watermark
+func __envoy_dynamic_module_v1_event_http_filter_instance_response_above_high_watermark(httpFilterInstancePtr C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr) {
+	eventHttpFilterInstanceResponseAboveHighWatermark(uintptr(httpFilterInstancePtr))
+}
+
+//export __envoy_dynamic_module_v1_event_http_filter_instance_response_below_low_watermark
+func __envoy_dynamic_module_v1_event_http_filter_instance_response_below_low_watermark(httpFilterInstancePtr C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr) {
+	eventHttpFilterInstanceResponseBelowLowWatermark(uintptr(httpFilterInstancePtr))
+}
+
+//export __envoy_dynamic_module_v1_event_network_filter_init
+func __envoy_dynamic_module_v1_event_network_filter_init(configPtr C.__envoy_dynamic_module_v1_type_NetworkFilterConfigPtr, configSize C.__envoy_dynamic_module_v1_type_NetworkFilterConfigSize) C.__envoy_dynamic_module_v1_type_NetworkFilterPtr {
+	return C.__envoy_dynamic_module_v1_type_NetworkFilterPtr(eventNetworkFilterInit(uintptr(configPtr), int(configSize)))
+}
+
+//export __envoy_dynamic_module_v1_event_network_filter_destroy
+func __envoy_dynamic_module_v1_event_network_filter_destroy(networkFilterPtr C.__envoy_dynamic_module_v1_type_NetworkFilterPtr) {
+	eventNetworkFilterDestroy(uintptr(networkFilterPtr))
+}
+
+//export __envoy_dynamic_module_v1_event_network_filter_instance_init
+func __envoy_dynamic_module_v1_event_network_filter_instance_init(envoyFilterInstancePtr C.__envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr, networkFilterPtr C.__envoy_dynamic_module_v1_type_NetworkFilterPtr) C.__envoy_dynamic_module_v1_type_NetworkFilterInstancePtr {
+	return C.__envoy_dynamic_module_v1_type_NetworkFilterInstancePtr(eventNetworkFilterInstanceInit(uintptr(envoyFilterInstancePtr), uintptr(networkFilterPtr)))
+}
+
+//export __envoy_dynamic_module_v1_event_network_filter_instance_new_connection
+func __envoy_dynamic_module_v1_event_network_filter_instance_new_connection(networkFilterInstancePtr C.__envoy_dynamic_module_v1_type_NetworkFilterInstancePtr) C.__envoy_dynamic_module_v1_type_EventNetworkFilterStatus {
+	return C.__envoy_dynamic_module_v1_type_EventNetworkFilterStatus(eventNetworkFilterInstanceNewConnection(uintptr(networkFilterInstancePtr)))
+}
+
+//export __envoy_dynamic_module_v1_event_network_filter_instance_read
+func __envoy_dynamic_module_v1_event_network_filter_instance_read(networkFilterInstancePtr C.__envoy_dynamic_module_v1_type_NetworkFilterInstancePtr, buffer C.__envoy_dynamic_module_v1_type_NetworkReadBufferPtr, endOfStream C.__envoy_dynamic_module_v1_type_EndOfStream) C.__envoy_dynamic_module_v1_type_EventNetworkFilterStatus {
+	return C.__envoy_dynamic_module_v1_type_EventNetworkFilterStatus(eventNetworkFilterInstanceRead(uintptr(networkFilterInstancePtr), uintptr(buffer), endOfStream != 0))
+}
+
+//export __envoy_dynamic_module_v1_event_network_filter_instance_write
+func __envoy_dynamic_module_v1_event_network_filter_instance_write(networkFilterInstancePtr C.__envoy_dynamic_module_v1_type_NetworkFilterInstancePtr, buffer C.__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr, endOfStream C.__envoy_dynamic_module_v1_type_EndOfStream) C.__envoy_dynamic_module_v1_type_EventNetworkFilterStatus {
+	return C.__envoy_dynamic_module_v1_type_EventNetworkFilterStatus(eventNetworkFilterInstanceWrite(uintptr(networkFilterInstancePtr), uintptr(buffer), endOfStream != 0))
+}
+
+//export __envoy_dynamic_module_v1_event_network_filter_instance_close
+func __envoy_dynamic_module_v1_event_network_filter_instance_close(networkFilterInstancePtr C.__envoy_dynamic_module_v1_type_NetworkFilterInstancePtr, event C.__envoy_dynamic_module_v1_type_NetworkConnectionEvent) {
+	eventNetworkFilterInstanceClose(uintptr(networkFilterInstancePtr), int(event))
+}
+
+//export __envoy_dynamic_module_v1_event_network_filter_instance_destroy
+func __envoy_dynamic_module_v1_event_network_filter_instance_destroy(networkFilterInstancePtr C.__envoy_dynamic_module_v1_type_NetworkFilterInstancePtr) {
+	eventNetworkFilterInstanceDestroy(uintptr(networkFilterInstancePtr))
+}
+
+//export __envoy_dynamic_module_v1_event_listener_filter_init
+func __envoy_dynamic_module_v1_event_listener_filter_init(configPtr C.__envoy_dynamic_module_v1_type_ListenerFilterConfigPtr, configSize C.__envoy_dynamic_module_v1_type_ListenerFilterConfigSize) C.__envoy_dynamic_module_v1_type_ListenerFilterPtr {
+	return C.__envoy_dynamic_module_v1_type_ListenerFilterPtr(eventListenerFilterInit(uintptr(configPtr), int(configSize)))
+}
+
+//export __envoy_dynamic_module_v1_event_listener_filter_destroy
+func __envoy_dynamic_module_v1_event_listener_filter_destroy(listenerFilterPtr C.__envoy_dynamic_module_v1_type_ListenerFilterPtr) {
+	eventListenerFilterDestroy(uintptr(listenerFilterPtr))
+}
+
+//export __envoy_dynamic_module_v1_event_listener_filter_instance_init
+func __envoy_dynamic_module_v1_event_listener_filter_instance_init(envoyFilterInstancePtr C.__envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr, listenerFilterPtr C.__envoy_dynamic_module_v1_type_ListenerFilterPtr) C.__envoy_dynamic_module_v1_type_ListenerFilterInstancePtr {
+	return C.__envoy_dynamic_module_v1_type_ListenerFilterInstancePtr(eventListenerFilterInstanceInit(uintptr(envoyFilterInstancePtr), uintptr(listenerFilterPtr)))
+}
+
+//export __envoy_dynamic_module_v1_event_listener_filter_instance_max_read_bytes
+func __envoy_dynamic_module_v1_event_listener_filter_instance_max_read_bytes(listenerFilterInstancePtr C.__envoy_dynamic_module_v1_type_ListenerFilterInstancePtr) C.size_t {
+	return C.size_t(eventListenerFilterInstanceMaxReadBytes(uintptr(listenerFilterInstancePtr)))
+}
+
+//export __envoy_dynamic_module_v1_event_listener_filter_instance_accept
+func __envoy_dynamic_module_v1_event_listener_filter_instance_accept(listenerFilterInstancePtr C.__envoy_dynamic_module_v1_type_ListenerFilterInstancePtr) C.__envoy_dynamic_module_v1_type_EventListenerFilterStatus {
+	return C.__envoy_dynamic_module_v1_type_EventListenerFilterStatus(eventListenerFilterInstanceAccept(uintptr(listenerFilterInstancePtr)))
+}
+
+//export __envoy_dynamic_module_v1_event_listener_filter_instance_data
+func __envoy_dynamic_module_v1_event_listener_filter_instance_data(listenerFilterInstancePtr C.__envoy_dynamic_module_v1_type_ListenerFilterInstancePtr, buffer C.__envoy_dynamic_module_v1_type_ListenerPeekBufferPtr) C.__envoy_dynamic_module_v1_type_EventListenerFilterStatus {
+	return C.__envoy_dynamic_module_v1_type_EventListenerFilterStatus(eventListenerFilterInstanceData(uintptr(listenerFilterInstancePtr), uintptr(buffer)))
+}
+
+//export __envoy_dynamic_module_v1_event_listener_filter_instance_destroy
+func __envoy_dynamic_module_v1_event_listener_filter_instance_destroy(listenerFilterInstancePtr C.__envoy_dynamic_module_v1_type_ListenerFilterInstancePtr) {
+	eventListenerFilterInstanceDestroy(uintptr(listenerFilterInstancePtr))
+}
+
+//export __envoy_dynamic_module_v1_event_access_logger_init
+func __envoy_dynamic_module_v1_event_access_logger_init(configPtr C.__envoy_dynamic_module_v1_type_AccessLoggerConfigPtr, configSize C.__envoy_dynamic_module_v1_type_AccessLoggerConfigSize) C.__envoy_dynamic_module_v1_type_AccessLoggerPtr {
+	return C.__envoy_dynamic_module_v1_type_AccessLoggerPtr(eventAccessLoggerInit(uintptr(configPtr), int(configSize)))
+}
+
+//export __envoy_dynamic_module_v1_event_access_logger_destroy
+func __envoy_dynamic_module_v1_event_access_logger_destroy(accessLoggerPtr C.__envoy_dynamic_module_v1_type_AccessLoggerPtr) {
+	eventAccessLoggerDestroy(uintptr(accessLoggerPtr))
+}
+
+//export __envoy_dynamic_module_v1_event_access_logger_log
+func __envoy_dynamic_module_v1_event_access_logger_log(accessLoggerPtr C.__envoy_dynamic_module_v1_type_AccessLoggerPtr, logEntryPtr C.__envoy_dynamic_module_v1_type_AccessLogEntryPtr) {
+	eventAccessLoggerLog(uintptr(accessLoggerPtr), uintptr(logEntryPtr))
+}
+
+// hostGetAbiVersion calls __envoy_dynamic_module_v1_get_abi_version in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostGetAbiVersion() int {
+	return int(C.__envoy_dynamic_module_v1_get_abi_version())
+}
+
+// hostHttpGetRequestHeaderValue calls __envoy_dynamic_module_v1_http_get_request_header_value in abi.h.
+func hostHttpGetRequestHeaderValue(headers uintptr, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	escape(key)
+	r := C.envoy_go_http_get_request_header_value(C.__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr(headers), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(key)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keyLength))
+	runtime.KeepAlive(key)
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+	return int(r.ret)
+}
+
+// hostHttpGetRequestHeaderValueNth calls __envoy_dynamic_module_v1_http_get_request_header_value_nth in abi.h.
+func hostHttpGetRequestHeaderValueNth(headers uintptr, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer, nth int) {
+	escape(key)
+	r := C.envoy_go_http_get_request_header_value_nth(C.__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr(headers), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(key)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keyLength), C.size_t(nth))
+	runtime.KeepAlive(key)
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+}
+
+// hostHttpGetResponseHeaderValue calls __envoy_dynamic_module_v1_http_get_response_header_value in abi.h.
+func hostHttpGetResponseHeaderValue(headers uintptr, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	escape(key)
+	r := C.envoy_go_http_get_response_header_value(C.__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr(headers), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(key)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keyLength))
+	runtime.KeepAlive(key)
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+	return int(r.ret)
+}
+
+// hostHttpGetResponseHeaderValueNth calls __envoy_dynamic_module_v1_http_get_response_header_value_nth in abi.h.
+func hostHttpGetResponseHeaderValueNth(headers uintptr, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer, nth int) {
+	escape(key)
+	r := C.envoy_go_http_get_response_header_value_nth(C.__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr(headers), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(key)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keyLength), C.size_t(nth))
+	runtime.KeepAlive(key)
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+}
+
+// hostHttpSetRequestHeader calls __envoy_dynamic_module_v1_http_set_request_header in abi.h.
+func hostHttpSetRequestHeader(headers uintptr, key unsafe.Pointer, keyLength int, value unsafe.Pointer, valueLength int) {
+	escape(key)
+	escape(value)
+	C.__envoy_dynamic_module_v1_http_set_request_header(C.__envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr(headers), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(key)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keyLength), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(value)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(valueLength))
+	runtime.KeepAlive(key)
+	runtime.KeepAlive(value)
+}
+
+// hostHttpSetResponseHeader calls __envoy_dynamic_module_v1_http_set_response_header in abi.h.
+func hostHttpSetResponseHeader(headers uintptr, key unsafe.Pointer, keyLength int, value unsafe.Pointer, valueLength int) {
+	escape(key)
+	escape(value)
+	C.__envoy_dynamic_module_v1_http_set_response_header(C.__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr(headers), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(key)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keyLength), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(value)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(valueLength))
+	runtime.KeepAlive(key)
+	runtime.KeepAlive(value)
+}
+
+// hostHttpGetRequestBodyBuffer calls __envoy_dynamic_module_v1_http_get_request_body_buffer in abi.h.
+func hostHttpGetRequestBodyBuffer(envoyFilterInstancePtr uintptr) uintptr {
+	return uintptr(C.__envoy_dynamic_module_v1_http_get_request_body_buffer(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr)))
+}
+
+// hostHttpGetResponseBodyBuffer calls __envoy_dynamic_module_v1_http_get_response_body_buffer in abi.h.
+func hostHttpGetResponseBodyBuffer(envoyFilterInstancePtr uintptr) uintptr {
+	return uintptr(C.__envoy_dynamic_module_v1_http_get_response_body_buffer(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr)))
+}
+
+// hostHttpGetRequestBodyBufferLength calls __envoy_dynamic_module_v1_http_get_request_body_buffer_length in abi.h.
+func hostHttpGetRequestBodyBufferLength(buffer uintptr) int {
+	return int(C.__envoy_dynamic_module_v1_http_get_request_body_buffer_length(C.__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr(buffer)))
+}
+
+// hostHttpGetRequestBodyBufferSlicesCount calls __envoy_dynamic_module_v1_http_get_request_body_buffer_slices_count in abi.h.
+func hostHttpGetRequestBodyBufferSlicesCount(buffer uintptr) int {
+	return int(C.__envoy_dynamic_module_v1_http_get_request_body_buffer_slices_count(C.__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr(buffer)))
+}
+
+// hostHttpGetRequestBodyBufferSlice calls __envoy_dynamic_module_v1_http_get_request_body_buffer_slice in abi.h.
+func hostHttpGetRequestBodyBufferSlice(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) {
+	r := C.envoy_go_http_get_request_body_buffer_slice(C.__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr(buffer), C.size_t(nth))
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+}
+
+// hostHttpCopyOutRequestBodyBuffer calls __envoy_dynamic_module_v1_http_copy_out_request_body_buffer in abi.h.
+func hostHttpCopyOutRequestBodyBuffer(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer) {
+	escape(resultBufferPtr)
+	C.__envoy_dynamic_module_v1_http_copy_out_request_body_buffer(C.__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr(buffer), C.size_t(offset), C.size_t(length), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(resultBufferPtr)))
+	runtime.KeepAlive(resultBufferPtr)
+}
+
+// hostHttpAppendRequestBodyBuffer calls __envoy_dynamic_module_v1_http_append_request_body_buffer in abi.h.
+func hostHttpAppendRequestBodyBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	escape(data)
+	C.__envoy_dynamic_module_v1_http_append_request_body_buffer(C.__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr(buffer), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(data)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(dataLength))
+	runtime.KeepAlive(data)
+}
+
+// hostHttpPrependRequestBodyBuffer calls __envoy_dynamic_module_v1_http_prepend_request_body_buffer in abi.h.
+func hostHttpPrependRequestBodyBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	escape(data)
+	C.__envoy_dynamic_module_v1_http_prepend_request_body_buffer(C.__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr(buffer), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(data)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(dataLength))
+	runtime.KeepAlive(data)
+}
+
+// hostHttpDrainRequestBodyBuffer calls __envoy_dynamic_module_v1_http_drain_request_body_buffer in abi.h.
+func hostHttpDrainRequestBodyBuffer(buffer uintptr, length int) {
+	C.__envoy_dynamic_module_v1_http_drain_request_body_buffer(C.__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr(buffer), C.size_t(length))
+}
+
+// hostHttpGetResponseBodyBufferLength calls __envoy_dynamic_module_v1_http_get_response_body_buffer_length in abi.h.
+func hostHttpGetResponseBodyBufferLength(buffer uintptr) int {
+	return int(C.__envoy_dynamic_module_v1_http_get_response_body_buffer_length(C.__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr(buffer)))
+}
+
+// hostHttpGetResponseBodyBufferSlicesCount calls __envoy_dynamic_module_v1_http_get_response_body_buffer_slices_count in abi.h.
+func hostHttpGetResponseBodyBufferSlicesCount(buffer uintptr) int {
+	return int(C.__envoy_dynamic_module_v1_http_get_response_body_buffer_slices_count(C.__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr(buffer)))
+}
+
+// hostHttpGetResponseBodyBufferSlice calls __envoy_dynamic_module_v1_http_get_response_body_buffer_slice in abi.h.
+func hostHttpGetResponseBodyBufferSlice(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) {
+	r := C.envoy_go_http_get_response_body_buffer_slice(C.__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr(buffer), C.size_t(nth))
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+}
+
+// hostHttpCopyOutResponseBodyBuffer calls __envoy_dynamic_module_v1_http_copy_out_response_body_buffer in abi.h.
+func hostHttpCopyOutResponseBodyBuffer(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer) {
+	escape(resultBufferPtr)
+	C.__envoy_dynamic_module_v1_http_copy_out_response_body_buffer(C.__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr(buffer), C.size_t(offset), C.size_t(length), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(resultBufferPtr)))
+	runtime.KeepAlive(resultBufferPtr)
+}
+
+// hostHttpAppendResponseBodyBuffer calls __envoy_dynamic_module_v1_http_append_response_body_buffer in abi.h.
+func hostHttpAppendResponseBodyBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	escape(data)
+	C.__envoy_dynamic_module_v1_http_append_response_body_buffer(C.__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr(buffer), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(data)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(dataLength))
+	runtime.KeepAlive(data)
+}
+
+// hostHttpPrependResponseBodyBuffer calls __envoy_dynamic_module_v1_http_prepend_response_body_buffer in abi.h.
+func hostHttpPrependResponseBodyBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	escape(data)
+	C.__envoy_dynamic_module_v1_http_prepend_response_body_buffer(C.__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr(buffer), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(data)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(dataLength))
+	runtime.KeepAlive(data)
+}
+
+// hostHttpDrainResponseBodyBuffer calls __envoy_dynamic_module_v1_http_drain_response_body_buffer in abi.h.
+func hostHttpDrainResponseBodyBuffer(buffer uintptr, length int) {
+	C.__envoy_dynamic_module_v1_http_drain_response_body_buffer(C.__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr(buffer), C.size_t(length))
+}
+
+// hostHttpContinueRequest calls __envoy_dynamic_module_v1_http_continue_request in abi.h.
+func hostHttpContinueRequest(envoyFilterInstancePtr uintptr) {
+	C.__envoy_dynamic_module_v1_http_continue_request(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr))
+}
+
+// hostHttpContinueResponse calls __envoy_dynamic_module_v1_http_continue_response in abi.h.
+func hostHttpContinueResponse(envoyFilterInstancePtr uintptr) {
+	C.__envoy_dynamic_module_v1_http_continue_response(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr))
+}
+
+// hostHttpReadDisableRequest calls __envoy_dynamic_module_v1_http_read_disable_request in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpReadDisableRequest(envoyFilterInstancePtr uintptr, disable int) {
+	C.__envoy_dynamic_module_v1_http_read_disable_request(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr), C.size_t(disable))
+}
+
+// hostHttpReadDisableResponse calls __envoy_dynamic_module_v1_http_read_disable_response in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpReadDisableResponse(envoyFilterInstancePtr uintptr, disable int) {
+	C.__envoy_dynamic_module_v1_http_read_disable_response(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr), C.size_t(disable))
+}
+
+// hostHttpInjectRequestData calls __envoy_dynamic_module_v1_http_inject_request_data in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpInjectRequestData(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int, endOfStream bool) {
+	escape(data)
+	C.__envoy_dynamic_module_v1_http_inject_request_data(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(data)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(dataLength), C.__envoy_dynamic_module_v1_type_EndOfStream(boolToInt(endOfStream)))
+	runtime.KeepAlive(data)
+}
+
+// hostHttpInjectResponseData calls __envoy_dynamic_module_v1_http_inject_response_data in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpInjectResponseData(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int, endOfStream bool) {
+	escape(data)
+	C.__envoy_dynamic_module_v1_http_inject_response_data(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(data)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(dataLength), C.__envoy_dynamic_module_v1_type_EndOfStream(boolToInt(endOfStream)))
+	runtime.KeepAlive(data)
+}
+
+// hostHttpAddRequestBody calls __envoy_dynamic_module_v1_http_add_request_body in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpAddRequestBody(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int) {
+	escape(data)
+	C.__envoy_dynamic_module_v1_http_add_request_body(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(data)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(dataLength))
+	runtime.KeepAlive(data)
+}
+
+// hostHttpAddResponseBody calls __envoy_dynamic_module_v1_http_add_response_body in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpAddResponseBody(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int) {
+	escape(data)
+	C.__envoy_dynamic_module_v1_http_add_response_body(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(data)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(dataLength))
+	runtime.KeepAlive(data)
+}
+
+// hostHttpGetUpstreamAttempt calls __envoy_dynamic_module_v1_http_get_upstream_attempt in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpGetUpstreamAttempt(envoyFilterInstancePtr uintptr) int {
+	return int(C.__envoy_dynamic_module_v1_http_get_upstream_attempt(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr)))
+}
+
+// hostHttpGetUpstreamClusterName calls __envoy_dynamic_module_v1_http_get_upstream_cluster_name in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpGetUpstreamClusterName(envoyFilterInstancePtr uintptr, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	r := C.envoy_go_http_get_upstream_cluster_name(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr))
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+	return int(r.ret)
+}
+
+// hostHttpGetUpstreamHostAddress calls __envoy_dynamic_module_v1_http_get_upstream_host_address in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpGetUpstreamHostAddress(envoyFilterInstancePtr uintptr, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	r := C.envoy_go_http_get_upstream_host_address(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr))
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+	return int(r.ret)
+}
+
+// hostHttpBypassEvents calls __envoy_dynamic_module_v1_http_bypass_events in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpBypassEvents(envoyFilterInstancePtr uintptr, events int) {
+	C.__envoy_dynamic_module_v1_http_bypass_events(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr), C.__envoy_dynamic_module_v1_type_HttpFilterEventMask(events))
+}
+
+// hostHttpSendResponse calls __envoy_dynamic_module_v1_http_send_response in abi.h.
+func hostHttpSendResponse(envoyFilterInstancePtr uintptr, statusCode uint32, headersVector unsafe.Pointer, headersVectorSize int, body unsafe.Pointer, bodyLength int) {
+	escape(headersVector)
+	escape(body)
+	C.__envoy_dynamic_module_v1_http_send_response(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr), C.uint32_t(statusCode), C.__envoy_dynamic_module_v1_type_InModuleHeadersPtr(uintptr(headersVector)), C.__envoy_dynamic_module_v1_type_InModuleHeadersSize(headersVectorSize), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(body)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(bodyLength))
+	runtime.KeepAlive(headersVector)
+	runtime.KeepAlive(body)
+}
+
+// hostNetworkGetReadBufferLength calls __envoy_dynamic_module_v1_network_get_read_buffer_length in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkGetReadBufferLength(buffer uintptr) int {
+	return int(C.__envoy_dynamic_module_v1_network_get_read_buffer_length(C.__envoy_dynamic_module_v1_type_NetworkReadBufferPtr(buffer)))
+}
+
+// hostNetworkGetReadBufferSlicesCount calls __envoy_dynamic_module_v1_network_get_read_buffer_slices_count in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkGetReadBufferSlicesCount(buffer uintptr) int {
+	return int(C.__envoy_dynamic_module_v1_network_get_read_buffer_slices_count(C.__envoy_dynamic_module_v1_type_NetworkReadBufferPtr(buffer)))
+}
+
+// hostNetworkGetReadBufferSlice calls __envoy_dynamic_module_v1_network_get_read_buffer_slice in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkGetReadBufferSlice(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) {
+	r := C.envoy_go_network_get_read_buffer_slice(C.__envoy_dynamic_module_v1_type_NetworkReadBufferPtr(buffer), C.size_t(nth))
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+}
+
+// hostNetworkCopyOutReadBuffer calls __envoy_dynamic_module_v1_network_copy_out_read_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkCopyOutReadBuffer(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer) {
+	escape(resultBufferPtr)
+	C.__envoy_dynamic_module_v1_network_copy_out_read_buffer(C.__envoy_dynamic_module_v1_type_NetworkReadBufferPtr(buffer), C.size_t(offset), C.size_t(length), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(resultBufferPtr)))
+	runtime.KeepAlive(resultBufferPtr)
+}
+
+// hostNetworkAppendReadBuffer calls __envoy_dynamic_module_v1_network_append_read_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkAppendReadBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	escape(data)
+	C.__envoy_dynamic_module_v1_network_append_read_buffer(C.__envoy_dynamic_module_v1_type_NetworkReadBufferPtr(buffer), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(data)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(dataLength))
+	runtime.KeepAlive(data)
+}
+
+// hostNetworkPrependReadBuffer calls __envoy_dynamic_module_v1_network_prepend_read_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkPrependReadBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	escape(data)
+	C.__envoy_dynamic_module_v1_network_prepend_read_buffer(C.__envoy_dynamic_module_v1_type_NetworkReadBufferPtr(buffer), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(data)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(dataLength))
+	runtime.KeepAlive(data)
+}
+
+// hostNetworkDrainReadBuffer calls __envoy_dynamic_module_v1_network_drain_read_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkDrainReadBuffer(buffer uintptr, length int) {
+	C.__envoy_dynamic_module_v1_network_drain_read_buffer(C.__envoy_dynamic_module_v1_type_NetworkReadBufferPtr(buffer), C.size_t(length))
+}
+
+// hostNetworkGetWriteBufferLength calls __envoy_dynamic_module_v1_network_get_write_buffer_length in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkGetWriteBufferLength(buffer uintptr) int {
+	return int(C.__envoy_dynamic_module_v1_network_get_write_buffer_length(C.__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr(buffer)))
+}
+
+// hostNetworkGetWriteBufferSlicesCount calls __envoy_dynamic_module_v1_network_get_write_buffer_slices_count in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkGetWriteBufferSlicesCount(buffer uintptr) int {
+	return int(C.__envoy_dynamic_module_v1_network_get_write_buffer_slices_count(C.__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr(buffer)))
+}
+
+// hostNetworkGetWriteBufferSlice calls __envoy_dynamic_module_v1_network_get_write_buffer_slice in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkGetWriteBufferSlice(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) {
+	r := C.envoy_go_network_get_write_buffer_slice(C.__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr(buffer), C.size_t(nth))
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+}
+
+// hostNetworkCopyOutWriteBuffer calls __envoy_dynamic_module_v1_network_copy_out_write_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkCopyOutWriteBuffer(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer) {
+	escape(resultBufferPtr)
+	C.__envoy_dynamic_module_v1_network_copy_out_write_buffer(C.__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr(buffer), C.size_t(offset), C.size_t(length), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(resultBufferPtr)))
+	runtime.KeepAlive(resultBufferPtr)
+}
+
+// hostNetworkAppendWriteBuffer calls __envoy_dynamic_module_v1_network_append_write_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkAppendWriteBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	escape(data)
+	C.__envoy_dynamic_module_v1_network_append_write_buffer(C.__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr(buffer), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(data)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(dataLength))
+	runtime.KeepAlive(data)
+}
+
+// hostNetworkPrependWriteBuffer calls __envoy_dynamic_module_v1_network_prepend_write_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkPrependWriteBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	escape(data)
+	C.__envoy_dynamic_module_v1_network_prepend_write_buffer(C.__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr(buffer), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(data)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(dataLength))
+	runtime.KeepAlive(data)
+}
+
+// hostNetworkDrainWriteBuffer calls __envoy_dynamic_module_v1_network_drain_write_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkDrainWriteBuffer(buffer uintptr, length int) {
+	C.__envoy_dynamic_module_v1_network_drain_write_buffer(C.__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr(buffer), C.size_t(length))
+}
+
+// hostNetworkContinueReading calls __envoy_dynamic_module_v1_network_continue_reading in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkContinueReading(envoyFilterInstancePtr uintptr) {
+	C.__envoy_dynamic_module_v1_network_continue_reading(C.__envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr(envoyFilterInstancePtr))
+}
+
+// hostNetworkWrite calls __envoy_dynamic_module_v1_network_write in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkWrite(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int, endOfStream bool) {
+	escape(data)
+	C.__envoy_dynamic_module_v1_network_write(C.__envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr(envoyFilterInstancePtr), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(data)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(dataLength), C.__envoy_dynamic_module_v1_type_EndOfStream(boolToInt(endOfStream)))
+	runtime.KeepAlive(data)
+}
+
+// hostNetworkClose calls __envoy_dynamic_module_v1_network_close in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkClose(envoyFilterInstancePtr uintptr, flushWrite int) {
+	C.__envoy_dynamic_module_v1_network_close(C.__envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr(envoyFilterInstancePtr), C.size_t(flushWrite))
+}
+
+// hostNetworkGetRemoteAddress calls __envoy_dynamic_module_v1_network_get_remote_address in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkGetRemoteAddress(envoyFilterInstancePtr uintptr, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	r := C.envoy_go_network_get_remote_address(C.__envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr(envoyFilterInstancePtr))
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+	return int(r.ret)
+}
+
+// hostListenerGetPeekBufferLength calls __envoy_dynamic_module_v1_listener_get_peek_buffer_length in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerGetPeekBufferLength(buffer uintptr) int {
+	return int(C.__envoy_dynamic_module_v1_listener_get_peek_buffer_length(C.__envoy_dynamic_module_v1_type_ListenerPeekBufferPtr(buffer)))
+}
+
+// hostListenerGetPeekBufferSlicesCount calls __envoy_dynamic_module_v1_listener_get_peek_buffer_slices_count in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerGetPeekBufferSlicesCount(buffer uintptr) int {
+	return int(C.__envoy_dynamic_module_v1_listener_get_peek_buffer_slices_count(C.__envoy_dynamic_module_v1_type_ListenerPeekBufferPtr(buffer)))
+}
+
+// hostListenerGetPeekBufferSlice calls __envoy_dynamic_module_v1_listener_get_peek_buffer_slice in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerGetPeekBufferSlice(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) {
+	r := C.envoy_go_listener_get_peek_buffer_slice(C.__envoy_dynamic_module_v1_type_ListenerPeekBufferPtr(buffer), C.size_t(nth))
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+}
+
+// hostListenerCopyOutPeekBuffer calls __envoy_dynamic_module_v1_listener_copy_out_peek_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerCopyOutPeekBuffer(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer) {
+	escape(resultBufferPtr)
+	C.__envoy_dynamic_module_v1_listener_copy_out_peek_buffer(C.__envoy_dynamic_module_v1_type_ListenerPeekBufferPtr(buffer), C.size_t(offset), C.size_t(length), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(resultBufferPtr)))
+	runtime.KeepAlive(resultBufferPtr)
+}
+
+// hostListenerSetDetectedTransportProtocol calls __envoy_dynamic_module_v1_listener_set_detected_transport_protocol in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerSetDetectedTransportProtocol(envoyFilterInstancePtr uintptr, protocol unsafe.Pointer, protocolLength int) {
+	escape(protocol)
+	C.__envoy_dynamic_module_v1_listener_set_detected_transport_protocol(C.__envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr(envoyFilterInstancePtr), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(protocol)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(protocolLength))
+	runtime.KeepAlive(protocol)
+}
+
+// hostListenerSetRequestedServerName calls __envoy_dynamic_module_v1_listener_set_requested_server_name in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerSetRequestedServerName(envoyFilterInstancePtr uintptr, serverName unsafe.Pointer, serverNameLength int) {
+	escape(serverName)
+	C.__envoy_dynamic_module_v1_listener_set_requested_server_name(C.__envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr(envoyFilterInstancePtr), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(serverName)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(serverNameLength))
+	runtime.KeepAlive(serverName)
+}
+
+// hostListenerContinueFilterChain calls __envoy_dynamic_module_v1_listener_continue_filter_chain in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerContinueFilterChain(envoyFilterInstancePtr uintptr, success int) {
+	C.__envoy_dynamic_module_v1_listener_continue_filter_chain(C.__envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr(envoyFilterInstancePtr), C.size_t(success))
+}
+
+// hostListenerCloseSocket calls __envoy_dynamic_module_v1_listener_close_socket in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerCloseSocket(envoyFilterInstancePtr uintptr) {
+	C.__envoy_dynamic_module_v1_listener_close_socket(C.__envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr(envoyFilterInstancePtr))
+}
+
+// hostListenerGetRemoteAddress calls __envoy_dynamic_module_v1_listener_get_remote_address in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerGetRemoteAddress(envoyFilterInstancePtr uintptr, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	r := C.envoy_go_listener_get_remote_address(C.__envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr(envoyFilterInstancePtr))
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+	return int(r.ret)
+}
+
+// hostAccessLogGetHeaderValue calls __envoy_dynamic_module_v1_access_log_get_header_value in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetHeaderValue(logEntryPtr uintptr, headersType int, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	escape(key)
+	r := C.envoy_go_access_log_get_header_value(C.__envoy_dynamic_module_v1_type_AccessLogEntryPtr(logEntryPtr), C.__envoy_dynamic_module_v1_type_AccessLogHeadersType(headersType), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(key)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keyLength))
+	runtime.KeepAlive(key)
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+	return int(r.ret)
+}
+
+// hostAccessLogGetHeaderValueNth calls __envoy_dynamic_module_v1_access_log_get_header_value_nth in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetHeaderValueNth(logEntryPtr uintptr, headersType int, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer, nth int) {
+	escape(key)
+	r := C.envoy_go_access_log_get_header_value_nth(C.__envoy_dynamic_module_v1_type_AccessLogEntryPtr(logEntryPtr), C.__envoy_dynamic_module_v1_type_AccessLogHeadersType(headersType), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(key)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keyLength), C.size_t(nth))
+	runtime.KeepAlive(key)
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+}
+
+// hostAccessLogGetHeadersCount calls __envoy_dynamic_module_v1_access_log_get_headers_count in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetHeadersCount(logEntryPtr uintptr, headersType int) int {
+	return int(C.__envoy_dynamic_module_v1_access_log_get_headers_count(C.__envoy_dynamic_module_v1_type_AccessLogEntryPtr(logEntryPtr), C.__envoy_dynamic_module_v1_type_AccessLogHeadersType(headersType)))
+}
+
+// hostAccessLogGetHeaderNth calls __envoy_dynamic_module_v1_access_log_get_header_nth in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetHeaderNth(logEntryPtr uintptr, headersType int, nth int, resultKeyPtr unsafe.Pointer, resultKeyLengthPtr unsafe.Pointer, resultValuePtr unsafe.Pointer, resultValueLengthPtr unsafe.Pointer) {
+	r := C.envoy_go_access_log_get_header_nth(C.__envoy_dynamic_module_v1_type_AccessLogEntryPtr(logEntryPtr), C.__envoy_dynamic_module_v1_type_AccessLogHeadersType(headersType), C.size_t(nth))
+	*(*uintptr)(resultKeyPtr) = uintptr(r.resultKeyPtr)
+	*(*int)(resultKeyLengthPtr) = int(r.resultKeyLengthPtr)
+	*(*uintptr)(resultValuePtr) = uintptr(r.resultValuePtr)
+	*(*int)(resultValueLengthPtr) = int(r.resultValueLengthPtr)
+}
+
+// hostAccessLogGetStartTime calls __envoy_dynamic_module_v1_access_log_get_start_time in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetStartTime(logEntryPtr uintptr) int64 {
+	return int64(C.__envoy_dynamic_module_v1_access_log_get_start_time(C.__envoy_dynamic_module_v1_type_AccessLogEntryPtr(logEntryPtr)))
+}
+
+// hostAccessLogGetTiming calls __envoy_dynamic_module_v1_access_log_get_timing in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetTiming(logEntryPtr uintptr, timing int) int64 {
+	return int64(C.__envoy_dynamic_module_v1_access_log_get_timing(C.__envoy_dynamic_module_v1_type_AccessLogEntryPtr(logEntryPtr), C.__envoy_dynamic_module_v1_type_AccessLogTiming(timing)))
+}
+
+// hostAccessLogGetBytesReceived calls __envoy_dynamic_module_v1_access_log_get_bytes_received in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetBytesReceived(logEntryPtr uintptr) uint64 {
+	return uint64(C.__envoy_dynamic_module_v1_access_log_get_bytes_received(C.__envoy_dynamic_module_v1_type_AccessLogEntryPtr(logEntryPtr)))
+}
+
+// hostAccessLogGetBytesSent calls __envoy_dynamic_module_v1_access_log_get_bytes_sent in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetBytesSent(logEntryPtr uintptr) uint64 {
+	return uint64(C.__envoy_dynamic_module_v1_access_log_get_bytes_sent(C.__envoy_dynamic_module_v1_type_AccessLogEntryPtr(logEntryPtr)))
+}
+
+// hostAccessLogGetResponseCode calls __envoy_dynamic_module_v1_access_log_get_response_code in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetResponseCode(logEntryPtr uintptr) uint32 {
+	return uint32(C.__envoy_dynamic_module_v1_access_log_get_response_code(C.__envoy_dynamic_module_v1_type_AccessLogEntryPtr(logEntryPtr)))
+}
+
+// hostAccessLogGetResponseFlags calls __envoy_dynamic_module_v1_access_log_get_response_flags in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetResponseFlags(logEntryPtr uintptr) uint64 {
+	return uint64(C.__envoy_dynamic_module_v1_access_log_get_response_flags(C.__envoy_dynamic_module_v1_type_AccessLogEntryPtr(logEntryPtr)))
+}
+
+// hostAccessLogGetDynamicMetadata calls __envoy_dynamic_module_v1_access_log_get_dynamic_metadata in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetDynamicMetadata(logEntryPtr uintptr, metadataNamespace unsafe.Pointer, metadataNamespaceLength int, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	escape(metadataNamespace)
+	escape(key)
+	r := C.envoy_go_access_log_get_dynamic_metadata(C.__envoy_dynamic_module_v1_type_AccessLogEntryPtr(logEntryPtr), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(metadataNamespace)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(metadataNamespaceLength), C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(key)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(keyLength))
+	runtime.KeepAlive(metadataNamespace)
+	runtime.KeepAlive(key)
+	*(*uintptr)(resultBufferPtr) = uintptr(r.resultBufferPtr)
+	*(*int)(resultBufferLengthPtr) = int(r.resultBufferLengthPtr)
+	return int(r.ret)
+}
+
+// hostStatsSetGauge calls __envoy_dynamic_module_v1_stats_set_gauge in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostStatsSetGauge(name unsafe.Pointer, nameLength int, value uint64) {
+	escape(name)
+	C.__envoy_dynamic_module_v1_stats_set_gauge(C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(name)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(nameLength), C.uint64_t(value))
+	runtime.KeepAlive(name)
+}
+
+// hostStatsIncrementCounter calls __envoy_dynamic_module_v1_stats_increment_counter in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostStatsIncrementCounter(name unsafe.Pointer, nameLength int, value uint64) {
+	escape(name)
+	C.__envoy_dynamic_module_v1_stats_increment_counter(C.__envoy_dynamic_module_v1_type_InModuleBufferPtr(uintptr(name)), C.__envoy_dynamic_module_v1_type_InModuleBufferLength(nameLength), C.uint64_t(value))
+	runtime.KeepAlive(name)
+}
+
+// hostHttpMutateRequestHeaders calls __envoy_dynamic_module_v1_http_mutate_request_headers in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpMutateRequestHeaders(headers uintptr, remove unsafe.Pointer, removeSize int, set unsafe.Pointer, setSize int, add unsafe.Pointer, addSize int) {
+	escape(remove)
+	escape(set)
+	escape(add)
+	C.__envoy_dynamic_module_v1_http_mutate_request_headers(C.__envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr(headers), C.__envoy_dynamic_module_v1_type_InModuleBuffersPtr(uintptr(remove)), C.__envoy_dynamic_module_v1_type_InModuleBuffersSize(removeSize), C.__envoy_dynamic_module_v1_type_InModuleHeadersPtr(uintptr(set)), C.__envoy_dynamic_module_v1_type_InModuleHeadersSize(setSize), C.__envoy_dynamic_module_v1_type_InModuleHeadersPtr(uintptr(add)), C.__envoy_dynamic_module_v1_type_InModuleHeadersSize(addSize))
+	runtime.KeepAlive(remove)
+	runtime.KeepAlive(set)
+	runtime.KeepAlive(add)
+}
+
+// hostHttpMutateResponseHeaders calls __envoy_dynamic_module_v1_http_mutate_response_headers in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpMutateResponseHeaders(headers uintptr, remove unsafe.Pointer, removeSize int, set unsafe.Pointer, setSize int, add unsafe.Pointer, addSize int) {
+	escape(remove)
+	escape(set)
+	escape(add)
+	C.__envoy_dynamic_module_v1_http_mutate_response_headers(C.__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr(headers), C.__envoy_dynamic_module_v1_type_InModuleBuffersPtr(uintptr(remove)), C.__envoy_dynamic_module_v1_type_InModuleBuffersSize(removeSize), C.__envoy_dynamic_module_v1_type_InModuleHeadersPtr(uintptr(set)), C.__envoy_dynamic_module_v1_type_InModuleHeadersSize(setSize), C.__envoy_dynamic_module_v1_type_InModuleHeadersPtr(uintptr(add)), C.__envoy_dynamic_module_v1_type_InModuleHeadersSize(addSize))
+	runtime.KeepAlive(remove)
+	runtime.KeepAlive(set)
+	runtime.KeepAlive(add)
+}
+
+// hostHttpGetRequestHeaderValues calls __envoy_dynamic_module_v1_http_get_request_header_values in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpGetRequestHeaderValues(headers uintptr, keys unsafe.Pointer, keysSize int, results unsafe.Pointer) int {
+	escape(keys)
+	escape(results)
+	ret := C.__envoy_dynamic_module_v1_http_get_request_header_values(C.__envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr(headers), C.__envoy_dynamic_module_v1_type_InModuleBuffersPtr(uintptr(keys)), C.__envoy_dynamic_module_v1_type_InModuleBuffersSize(keysSize), C.__envoy_dynamic_module_v1_type_DataSlicesResult(uintptr(results)))
+	runtime.KeepAlive(keys)
+	runtime.KeepAlive(results)
+	return int(ret)
+}
+
+// hostHttpGetResponseHeaderValues calls __envoy_dynamic_module_v1_http_get_response_header_values in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpGetResponseHeaderValues(headers uintptr, keys unsafe.Pointer, keysSize int, results unsafe.Pointer) int {
+	escape(keys)
+	escape(results)
+	ret := C.__envoy_dynamic_module_v1_http_get_response_header_values(C.__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr(headers), C.__envoy_dynamic_module_v1_type_InModuleBuffersPtr(uintptr(keys)), C.__envoy_dynamic_module_v1_type_InModuleBuffersSize(keysSize), C.__envoy_dynamic_module_v1_type_DataSlicesResult(uintptr(results)))
+	runtime.KeepAlive(keys)
+	runtime.KeepAlive(results)
+	return int(ret)
+}
+
+// hostHttpResetStream calls __envoy_dynamic_module_v1_http_reset_stream in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpResetStream(envoyFilterInstancePtr uintptr) {
+	C.__envoy_dynamic_module_v1_http_reset_stream(C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(envoyFilterInstancePtr))
+}
@@ -0,0 +1,36 @@
+// Code generated by internal/abigen from abi.h. DO NOT EDIT.
+
+package envoy
+
+// The constants defined in abi.h.
+const (
+	abiHeaderStatusContinue                        = 0
+	abiHeaderStatusStopIteration                   = 1
+	abiHeaderStatusStopAllIterationAndBuffer       = 3
+	abiBodyStatusContinue                          = 0
+	abiBodyStatusStopIterationAndBuffer            = 1
+	abiHttpFilterEventRequestHeaders               = (1 << 0)
+	abiHttpFilterEventRequestBody                  = (1 << 1)
+	abiHttpFilterEventResponseHeaders              = (1 << 2)
+	abiHttpFilterEventResponseBody                 = (1 << 3)
+	abiHttpFilterEventWatermark                    = (1 << 4)
+	abiNetworkFilterStatusContinue                 = 0
+	abiNetworkFilterStatusStopIteration            = 1
+	abiNetworkConnectionEventRemoteClose           = 0
+	abiNetworkConnectionEventLocalClose            = 1
+	abiListenerFilterStatusContinue                = 0
+	abiListenerFilterStatusStopIteration           = 1
+	abiAccessLogHeadersRequest                     = 0
+	abiAccessLogHeadersRequestTrailers             = 1
+	abiAccessLogHeadersResponse                    = 2
+	abiAccessLogHeadersResponseTrailers            = 3
+	abiAccessLogTimingLastDownstreamRxByteReceived = 0
+	abiAccessLogTimingFirstUpstreamTxByteSent      = 1
+	abiAccessLogTimingLastUpstreamTxByteSent       = 2
+	abiAccessLogTimingFirstUpstreamRxByteReceived  = 3
+	abiAccessLogTimingLastUpstreamRxByteReceived   = 4
+	abiAccessLogTimingFirstDownstreamTxByteSent    = 5
+	abiAccessLogTimingLastDownstreamTxByteSent     = 6
+	abiAccessLogTimingRequestComplete              = 7
+	abiVersion                                     = 5
+)
@@ -0,0 +1,755 @@
+// Code generated by internal/abigen from abi.h. DO NOT EDIT.
+
+//go:build !cgo
+
+package envoy
+
+import "unsafe"
+
+var _ unsafe.Pointer
+
+// FakeHostFuncs is the set of the fake Envoy API functions. Each field corresponds to the function
+// in abi.h of the same name without the __envoy_dynamic_module_v1_ prefix.
+type FakeHostFuncs struct {
+	GetAbiVersion                        func() int
+	HttpGetRequestHeaderValue            func(headers uintptr, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int
+	HttpGetRequestHeaderValueNth         func(headers uintptr, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer, nth int)
+	HttpGetResponseHeaderValue           func(headers uintptr, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int
+	HttpGetResponseHeaderValueNth        func(headers uintptr, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer, nth int)
+	HttpSetRequestHeader                 func(headers uintptr, key unsafe.Pointer, keyLength int, value unsafe.Pointer, valueLength int)
+	HttpSetResponseHeader                func(headers uintptr, key unsafe.Pointer, keyLength int, value unsafe.Pointer, valueLength int)
+	HttpGetRequestBodyBuffer             func(envoyFilterInstancePtr uintptr) uintptr
+	HttpGetResponseBodyBuffer            func(envoyFilterInstancePtr uintptr) uintptr
+	HttpGetRequestBodyBufferLength       func(buffer uintptr) int
+	HttpGetRequestBodyBufferSlicesCount  func(buffer uintptr) int
+	HttpGetRequestBodyBufferSlice        func(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer)
+	HttpCopyOutRequestBodyBuffer         func(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer)
+	HttpAppendRequestBodyBuffer          func(buffer uintptr, data unsafe.Pointer, dataLength int)
+	HttpPrependRequestBodyBuffer         func(buffer uintptr, data unsafe.Pointer, dataLength int)
+	HttpDrainRequestBodyBuffer           func(buffer uintptr, length int)
+	HttpGetResponseBodyBufferLength      func(buffer uintptr) int
+	HttpGetResponseBodyBufferSlicesCount func(buffer uintptr) int
+	HttpGetResponseBodyBufferSlice       func(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer)
+	HttpCopyOutResponseBodyBuffer        func(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer)
+	HttpAppendResponseBodyBuffer         func(buffer uintptr, data unsafe.Pointer, dataLength int)
+	HttpPrependResponseBodyBuffer        func(buffer uintptr, data unsafe.Pointer, dataLength int)
+	HttpDrainResponseBodyBuffer          func(buffer uintptr, length int)
+	HttpContinueRequest                  func(envoyFilterInstancePtr uintptr)
+	HttpContinueResponse                 func(envoyFilterInstancePtr uintptr)
+	HttpReadDisableRequest               func(envoyFilterInstancePtr uintptr, disable int)
+	HttpReadDisableResponse              func(envoyFilterInstancePtr uintptr, disable int)
+	HttpInjectRequestData                func(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int, endOfStream bool)
+	HttpInjectResponseData               func(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int, endOfStream bool)
+	HttpAddRequestBody                   func(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int)
+	HttpAddResponseBody                  func(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int)
+	HttpGetUpstreamAttempt               func(envoyFilterInstancePtr uintptr) int
+	HttpGetUpstreamClusterName           func(envoyFilterInstancePtr uintptr, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int
+	HttpGetUpstreamHostAddress           func(envoyFilterInstancePtr uintptr, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int
+	HttpBypassEvents                     func(envoyFilterInstancePtr uintptr, events int)
+	HttpSendResponse                     func(envoyFilterInstancePtr uintptr, statusCode uint32, headersVector unsafe.Pointer, headersVectorSize int, body unsafe.Pointer, bodyLength int)
+	NetworkGetReadBufferLength           func(buffer uintptr) int
+	NetworkGetReadBufferSlicesCount      func(buffer uintptr) int
+	NetworkGetReadBufferSlice            func(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer)
+	NetworkCopyOutReadBuffer             func(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer)
+	NetworkAppendReadBuffer              func(buffer uintptr, data unsafe.Pointer, dataLength int)
+	NetworkPrependReadBuffer             func(buffer uintptr, data unsafe.Pointer, dataLength int)
+	NetworkDrainReadBuffer               func(buffer uintptr, length int)
+	NetworkGetWriteBufferLength          func(buffer uintptr) int
+	NetworkGetWriteBufferSlicesCount     func(buffer uintptr) int
+	NetworkGetWriteBufferSlice           func(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer)
+	NetworkCopyOutWriteBuffer            func(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer)
+	NetworkAppendWriteBuffer             func(buffer uintptr, data unsafe.Pointer, dataLength int)
+	NetworkPrependWriteBuffer            func(buffer uintptr, data unsafe.Pointer, dataLength int)
+	NetworkDrainWriteBuffer              func(buffer uintptr, length int)
+	NetworkContinueReading               func(envoyFilterInstancePtr uintptr)
+	NetworkWrite                         func(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int, endOfStream bool)
+	NetworkClose                         func(envoyFilterInstancePtr uintptr, flushWrite int)
+	NetworkGetRemoteAddress              func(envoyFilterInstancePtr uintptr, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int
+	ListenerGetPeekBufferLength          func(buffer uintptr) int
+	ListenerGetPeekBufferSlicesCount     func(buffer uintptr) int
+	ListenerGetPeekBufferSlice           func(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer)
+	ListenerCopyOutPeekBuffer            func(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer)
+	ListenerSetDetectedTransportProtocol func(envoyFilterInstancePtr uintptr, protocol unsafe.Pointer, protocolLength int)
+	ListenerSetRequestedServerName       func(envoyFilterInstancePtr uintptr, serverName unsafe.Pointer, serverNameLength int)
+	ListenerContinueFilterChain          func(envoyFilterInstancePtr uintptr, success int)
+	ListenerCloseSocket                  func(envoyFilterInstancePtr uintptr)
+	ListenerGetRemoteAddress             func(envoyFilterInstancePtr uintptr, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int
+	AccessLogGetHeaderValue              func(logEntryPtr uintptr, headersType int, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int
+	AccessLogGetHeaderValueNth           func(logEntryPtr uintptr, headersType int, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer, nth int)
+	AccessLogGetHeadersCount             func(logEntryPtr uintptr, headersType int) int
+	AccessLogGetHeaderNth                func(logEntryPtr uintptr, headersType int, nth int, resultKeyPtr unsafe.Pointer, resultKeyLengthPtr unsafe.Pointer, resultValuePtr unsafe.Pointer, resultValueLengthPtr unsafe.Pointer)
+	AccessLogGetStartTime                func(logEntryPtr uintptr) int64
+	AccessLogGetTiming                   func(logEntryPtr uintptr, timing int) int64
+	AccessLogGetBytesReceived            func(logEntryPtr uintptr) uint64
+	AccessLogGetBytesSent                func(logEntryPtr uintptr) uint64
+	AccessLogGetResponseCode             func(logEntryPtr uintptr) uint32
+	AccessLogGetResponseFlags            func(logEntryPtr uintptr) uint64
+	AccessLogGetDynamicMetadata          func(logEntryPtr uintptr, metadataNamespace unsafe.Pointer, metadataNamespaceLength int, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int
+	StatsSetGauge                        func(name unsafe.Pointer, nameLength int, value uint64)
+	StatsIncrementCounter                func(name unsafe.Pointer, nameLength int, value uint64)
+	HttpMutateRequestHeaders             func(headers uintptr, remove unsafe.Pointer, removeSize int, set unsafe.Pointer, setSize int, add unsafe.Pointer, addSize int)
+	HttpMutateResponseHeaders            func(headers uintptr, remove unsafe.Pointer, removeSize int, set unsafe.Pointer, setSize int, add unsafe.Pointer, addSize int)
+	HttpGetRequestHeaderValues           func(headers uintptr, keys unsafe.Pointer, keysSize int, results unsafe.Pointer) int
+	HttpGetResponseHeaderValues          func(headers uintptr, keys unsafe.Pointer, keysSize int, results unsafe.Pointer) int
+	HttpResetStream                      func(envoyFilterInstancePtr uintptr)
+}
+
+// FakeHost is the fake Envoy used in the builds without cgo, e.g. CGO_ENABLED=0 go test, where there's no Envoy
+// that loads the module. The nil functions behave as the Envoy API returning zero values.
+//
+// This allows testing the module code without Envoy by setting the fake implementations of the Envoy API.
+var FakeHost FakeHostFuncs
+
+// hostGetAbiVersion calls __envoy_dynamic_module_v1_get_abi_version in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostGetAbiVersion() int {
+	if f := FakeHost.GetAbiVersion; f != nil {
+		return f()
+	}
+	return 0
+}
+
+// hostHttpGetRequestHeaderValue calls __envoy_dynamic_module_v1_http_get_request_header_value in abi.h.
+func hostHttpGetRequestHeaderValue(headers uintptr, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	if f := FakeHost.HttpGetRequestHeaderValue; f != nil {
+		return f(headers, key, keyLength, resultBufferPtr, resultBufferLengthPtr)
+	}
+	return 0
+}
+
+// hostHttpGetRequestHeaderValueNth calls __envoy_dynamic_module_v1_http_get_request_header_value_nth in abi.h.
+func hostHttpGetRequestHeaderValueNth(headers uintptr, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer, nth int) {
+	if f := FakeHost.HttpGetRequestHeaderValueNth; f != nil {
+		f(headers, key, keyLength, resultBufferPtr, resultBufferLengthPtr, nth)
+	}
+}
+
+// hostHttpGetResponseHeaderValue calls __envoy_dynamic_module_v1_http_get_response_header_value in abi.h.
+func hostHttpGetResponseHeaderValue(headers uintptr, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	if f := FakeHost.HttpGetResponseHeaderValue; f != nil {
+		return f(headers, key, keyLength, resultBufferPtr, resultBufferLengthPtr)
+	}
+	return 0
+}
+
+// hostHttpGetResponseHeaderValueNth calls __envoy_dynamic_module_v1_http_get_response_header_value_nth in abi.h.
+func hostHttpGetResponseHeaderValueNth(headers uintptr, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer, nth int) {
+	if f := FakeHost.HttpGetResponseHeaderValueNth; f != nil {
+		f(headers, key, keyLength, resultBufferPtr, resultBufferLengthPtr, nth)
+	}
+}
+
+// hostHttpSetRequestHeader calls __envoy_dynamic_module_v1_http_set_request_header in abi.h.
+func hostHttpSetRequestHeader(headers uintptr, key unsafe.Pointer, keyLength int, value unsafe.Pointer, valueLength int) {
+	if f := FakeHost.HttpSetRequestHeader; f != nil {
+		f(headers, key, keyLength, value, valueLength)
+	}
+}
+
+// hostHttpSetResponseHeader calls __envoy_dynamic_module_v1_http_set_response_header in abi.h.
+func hostHttpSetResponseHeader(headers uintptr, key unsafe.Pointer, keyLength int, value unsafe.Pointer, valueLength int) {
+	if f := FakeHost.HttpSetResponseHeader; f != nil {
+		f(headers, key, keyLength, value, valueLength)
+	}
+}
+
+// hostHttpGetRequestBodyBuffer calls __envoy_dynamic_module_v1_http_get_request_body_buffer in abi.h.
+func hostHttpGetRequestBodyBuffer(envoyFilterInstancePtr uintptr) uintptr {
+	if f := FakeHost.HttpGetRequestBodyBuffer; f != nil {
+		return f(envoyFilterInstancePtr)
+	}
+	return 0
+}
+
+// hostHttpGetResponseBodyBuffer calls __envoy_dynamic_module_v1_http_get_response_body_buffer in abi.h.
+func hostHttpGetResponseBodyBuffer(envoyFilterInstancePtr uintptr) uintptr {
+	if f := FakeHost.HttpGetResponseBodyBuffer; f != nil {
+		return f(envoyFilterInstancePtr)
+	}
+	return 0
+}
+
+// hostHttpGetRequestBodyBufferLength calls __envoy_dynamic_module_v1_http_get_request_body_buffer_length in abi.h.
+func hostHttpGetRequestBodyBufferLength(buffer uintptr) int {
+	if f := FakeHost.HttpGetRequestBodyBufferLength; f != nil {
+		return f(buffer)
+	}
+	return 0
+}
+
+// hostHttpGetRequestBodyBufferSlicesCount calls __envoy_dynamic_module_v1_http_get_request_body_buffer_slices_count in abi.h.
+func hostHttpGetRequestBodyBufferSlicesCount(buffer uintptr) int {
+	if f := FakeHost.HttpGetRequestBodyBufferSlicesCount; f != nil {
+		return f(buffer)
+	}
+	return 0
+}
+
+// hostHttpGetRequestBodyBufferSlice calls __envoy_dynamic_module_v1_http_get_request_body_buffer_slice in abi.h.
+func hostHttpGetRequestBodyBufferSlice(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) {
+	if f := FakeHost.HttpGetRequestBodyBufferSlice; f != nil {
+		f(buffer, nth, resultBufferPtr, resultBufferLengthPtr)
+	}
+}
+
+// hostHttpCopyOutRequestBodyBuffer calls __envoy_dynamic_module_v1_http_copy_out_request_body_buffer in abi.h.
+func hostHttpCopyOutRequestBodyBuffer(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer) {
+	if f := FakeHost.HttpCopyOutRequestBodyBuffer; f != nil {
+		f(buffer, offset, length, resultBufferPtr)
+	}
+}
+
+// hostHttpAppendRequestBodyBuffer calls __envoy_dynamic_module_v1_http_append_request_body_buffer in abi.h.
+func hostHttpAppendRequestBodyBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	if f := FakeHost.HttpAppendRequestBodyBuffer; f != nil {
+		f(buffer, data, dataLength)
+	}
+}
+
+// hostHttpPrependRequestBodyBuffer calls __envoy_dynamic_module_v1_http_prepend_request_body_buffer in abi.h.
+func hostHttpPrependRequestBodyBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	if f := FakeHost.HttpPrependRequestBodyBuffer; f != nil {
+		f(buffer, data, dataLength)
+	}
+}
+
+// hostHttpDrainRequestBodyBuffer calls __envoy_dynamic_module_v1_http_drain_request_body_buffer in abi.h.
+func hostHttpDrainRequestBodyBuffer(buffer uintptr, length int) {
+	if f := FakeHost.HttpDrainRequestBodyBuffer; f != nil {
+		f(buffer, length)
+	}
+}
+
+// hostHttpGetResponseBodyBufferLength calls __envoy_dynamic_module_v1_http_get_response_body_buffer_length in abi.h.
+func hostHttpGetResponseBodyBufferLength(buffer uintptr) int {
+	if f := FakeHost.HttpGetResponseBodyBufferLength; f != nil {
+		return f(buffer)
+	}
+	return 0
+}
+
+// hostHttpGetResponseBodyBufferSlicesCount calls __envoy_dynamic_module_v1_http_get_response_body_buffer_slices_count in abi.h.
+func hostHttpGetResponseBodyBufferSlicesCount(buffer uintptr) int {
+	if f := FakeHost.HttpGetResponseBodyBufferSlicesCount; f != nil {
+		return f(buffer)
+	}
+	return 0
+}
+
+// hostHttpGetResponseBodyBufferSlice calls __envoy_dynamic_module_v1_http_get_response_body_buffer_slice in abi.h.
+func hostHttpGetResponseBodyBufferSlice(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) {
+	if f := FakeHost.HttpGetResponseBodyBufferSlice; f != nil {
+		f(buffer, nth, resultBufferPtr, resultBufferLengthPtr)
+	}
+}
+
+// hostHttpCopyOutResponseBodyBuffer calls __envoy_dynamic_module_v1_http_copy_out_response_body_buffer in abi.h.
+func hostHttpCopyOutResponseBodyBuffer(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer) {
+	if f := FakeHost.HttpCopyOutResponseBodyBuffer; f != nil {
+		f(buffer, offset, length, resultBufferPtr)
+	}
+}
+
+// hostHttpAppendResponseBodyBuffer calls __envoy_dynamic_module_v1_http_append_response_body_buffer in abi.h.
+func hostHttpAppendResponseBodyBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	if f := FakeHost.HttpAppendResponseBodyBuffer; f != nil {
+		f(buffer, data, dataLength)
+	}
+}
+
+// hostHttpPrependResponseBodyBuffer calls __envoy_dynamic_module_v1_http_prepend_response_body_buffer in abi.h.
+func hostHttpPrependResponseBodyBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	if f := FakeHost.HttpPrependResponseBodyBuffer; f != nil {
+		f(buffer, data, dataLength)
+	}
+}
+
+// hostHttpDrainResponseBodyBuffer calls __envoy_dynamic_module_v1_http_drain_response_body_buffer in abi.h.
+func hostHttpDrainResponseBodyBuffer(buffer uintptr, length int) {
+	if f := FakeHost.HttpDrainResponseBodyBuffer; f != nil {
+		f(buffer, length)
+	}
+}
+
+// hostHttpContinueRequest calls __envoy_dynamic_module_v1_http_continue_request in abi.h.
+func hostHttpContinueRequest(envoyFilterInstancePtr uintptr) {
+	if f := FakeHost.HttpContinueRequest; f != nil {
+		f(envoyFilterInstancePtr)
+	}
+}
+
+// hostHttpContinueResponse calls __envoy_dynamic_module_v1_http_continue_response in abi.h.
+func hostHttpContinueResponse(envoyFilterInstancePtr uintptr) {
+	if f := FakeHost.HttpContinueResponse; f != nil {
+		f(envoyFilterInstancePtr)
+	}
+}
+
+// hostHttpReadDisableRequest calls __envoy_dynamic_module_v1_http_read_disable_request in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpReadDisableRequest(envoyFilterInstancePtr uintptr, disable int) {
+	if f := FakeHost.HttpReadDisableRequest; f != nil {
+		f(envoyFilterInstancePtr, disable)
+	}
+}
+
+// hostHttpReadDisableResponse calls __envoy_dynamic_module_v1_http_read_disable_response in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpReadDisableResponse(envoyFilterInstancePtr uintptr, disable int) {
+	if f := FakeHost.HttpReadDisableResponse; f != nil {
+		f(envoyFilterInstancePtr, disable)
+	}
+}
+
+// hostHttpInjectRequestData calls __envoy_dynamic_module_v1_http_inject_request_data in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpInjectRequestData(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int, endOfStream bool) {
+	if f := FakeHost.HttpInjectRequestData; f != nil {
+		f(envoyFilterInstancePtr, data, dataLength, endOfStream)
+	}
+}
+
+// hostHttpInjectResponseData calls __envoy_dynamic_module_v1_http_inject_response_data in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpInjectResponseData(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int, endOfStream bool) {
+	if f := FakeHost.HttpInjectResponseData; f != nil {
+		f(envoyFilterInstancePtr, data, dataLength, endOfStream)
+	}
+}
+
+// hostHttpAddRequestBody calls __envoy_dynamic_module_v1_http_add_request_body in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpAddRequestBody(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int) {
+	if f := FakeHost.HttpAddRequestBody; f != nil {
+		f(envoyFilterInstancePtr, data, dataLength)
+	}
+}
+
+// hostHttpAddResponseBody calls __envoy_dynamic_module_v1_http_add_response_body in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpAddResponseBody(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int) {
+	if f := FakeHost.HttpAddResponseBody; f != nil {
+		f(envoyFilterInstancePtr, data, dataLength)
+	}
+}
+
+// hostHttpGetUpstreamAttempt calls __envoy_dynamic_module_v1_http_get_upstream_attempt in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpGetUpstreamAttempt(envoyFilterInstancePtr uintptr) int {
+	if f := FakeHost.HttpGetUpstreamAttempt; f != nil {
+		return f(envoyFilterInstancePtr)
+	}
+	return 0
+}
+
+// hostHttpGetUpstreamClusterName calls __envoy_dynamic_module_v1_http_get_upstream_cluster_name in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpGetUpstreamClusterName(envoyFilterInstancePtr uintptr, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	if f := FakeHost.HttpGetUpstreamClusterName; f != nil {
+		return f(envoyFilterInstancePtr, resultBufferPtr, resultBufferLengthPtr)
+	}
+	return 0
+}
+
+// hostHttpGetUpstreamHostAddress calls __envoy_dynamic_module_v1_http_get_upstream_host_address in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpGetUpstreamHostAddress(envoyFilterInstancePtr uintptr, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	if f := FakeHost.HttpGetUpstreamHostAddress; f != nil {
+		return f(envoyFilterInstancePtr, resultBufferPtr, resultBufferLengthPtr)
+	}
+	return 0
+}
+
+// hostHttpBypassEvents calls __envoy_dynamic_module_v1_http_bypass_events in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpBypassEvents(envoyFilterInstancePtr uintptr, events int) {
+	if f := FakeHost.HttpBypassEvents; f != nil {
+		f(envoyFilterInstancePtr, events)
+	}
+}
+
+// hostHttpSendResponse calls __envoy_dynamic_module_v1_http_send_response in abi.h.
+func hostHttpSendResponse(envoyFilterInstancePtr uintptr, statusCode uint32, headersVector unsafe.Pointer, headersVectorSize int, body unsafe.Pointer, bodyLength int) {
+	if f := FakeHost.HttpSendResponse; f != nil {
+		f(envoyFilterInstancePtr, statusCode, headersVector, headersVectorSize, body, bodyLength)
+	}
+}
+
+// hostNetworkGetReadBufferLength calls __envoy_dynamic_module_v1_network_get_read_buffer_length in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkGetReadBufferLength(buffer uintptr) int {
+	if f := FakeHost.NetworkGetReadBufferLength; f != nil {
+		return f(buffer)
+	}
+	return 0
+}
+
+// hostNetworkGetReadBufferSlicesCount calls __envoy_dynamic_module_v1_network_get_read_buffer_slices_count in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkGetReadBufferSlicesCount(buffer uintptr) int {
+	if f := FakeHost.NetworkGetReadBufferSlicesCount; f != nil {
+		return f(buffer)
+	}
+	return 0
+}
+
+// hostNetworkGetReadBufferSlice calls __envoy_dynamic_module_v1_network_get_read_buffer_slice in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkGetReadBufferSlice(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) {
+	if f := FakeHost.NetworkGetReadBufferSlice; f != nil {
+		f(buffer, nth, resultBufferPtr, resultBufferLengthPtr)
+	}
+}
+
+// hostNetworkCopyOutReadBuffer calls __envoy_dynamic_module_v1_network_copy_out_read_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkCopyOutReadBuffer(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer) {
+	if f := FakeHost.NetworkCopyOutReadBuffer; f != nil {
+		f(buffer, offset, length, resultBufferPtr)
+	}
+}
+
+// hostNetworkAppendReadBuffer calls __envoy_dynamic_module_v1_network_append_read_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkAppendReadBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	if f := FakeHost.NetworkAppendReadBuffer; f != nil {
+		f(buffer, data, dataLength)
+	}
+}
+
+// hostNetworkPrependReadBuffer calls __envoy_dynamic_module_v1_network_prepend_read_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkPrependReadBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	if f := FakeHost.NetworkPrependReadBuffer; f != nil {
+		f(buffer, data, dataLength)
+	}
+}
+
+// hostNetworkDrainReadBuffer calls __envoy_dynamic_module_v1_network_drain_read_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkDrainReadBuffer(buffer uintptr, length int) {
+	if f := FakeHost.NetworkDrainReadBuffer; f != nil {
+		f(buffer, length)
+	}
+}
+
+// hostNetworkGetWriteBufferLength calls __envoy_dynamic_module_v1_network_get_write_buffer_length in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkGetWriteBufferLength(buffer uintptr) int {
+	if f := FakeHost.NetworkGetWriteBufferLength; f != nil {
+		return f(buffer)
+	}
+	return 0
+}
+
+// hostNetworkGetWriteBufferSlicesCount calls __envoy_dynamic_module_v1_network_get_write_buffer_slices_count in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkGetWriteBufferSlicesCount(buffer uintptr) int {
+	if f := FakeHost.NetworkGetWriteBufferSlicesCount; f != nil {
+		return f(buffer)
+	}
+	return 0
+}
+
+// hostNetworkGetWriteBufferSlice calls __envoy_dynamic_module_v1_network_get_write_buffer_slice in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkGetWriteBufferSlice(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) {
+	if f := FakeHost.NetworkGetWriteBufferSlice; f != nil {
+		f(buffer, nth, resultBufferPtr, resultBufferLengthPtr)
+	}
+}
+
+// hostNetworkCopyOutWriteBuffer calls __envoy_dynamic_module_v1_network_copy_out_write_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkCopyOutWriteBuffer(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer) {
+	if f := FakeHost.NetworkCopyOutWriteBuffer; f != nil {
+		f(buffer, offset, length, resultBufferPtr)
+	}
+}
+
+// hostNetworkAppendWriteBuffer calls __envoy_dynamic_module_v1_network_append_write_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkAppendWriteBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	if f := FakeHost.NetworkAppendWriteBuffer; f != nil {
+		f(buffer, data, dataLength)
+	}
+}
+
+// hostNetworkPrependWriteBuffer calls __envoy_dynamic_module_v1_network_prepend_write_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkPrependWriteBuffer(buffer uintptr, data unsafe.Pointer, dataLength int) {
+	if f := FakeHost.NetworkPrependWriteBuffer; f != nil {
+		f(buffer, data, dataLength)
+	}
+}
+
+// hostNetworkDrainWriteBuffer calls __envoy_dynamic_module_v1_network_drain_write_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkDrainWriteBuffer(buffer uintptr, length int) {
+	if f := FakeHost.NetworkDrainWriteBuffer; f != nil {
+		f(buffer, length)
+	}
+}
+
+// hostNetworkContinueReading calls __envoy_dynamic_module_v1_network_continue_reading in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkContinueReading(envoyFilterInstancePtr uintptr) {
+	if f := FakeHost.NetworkContinueReading; f != nil {
+		f(envoyFilterInstancePtr)
+	}
+}
+
+// hostNetworkWrite calls __envoy_dynamic_module_v1_network_write in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkWrite(envoyFilterInstancePtr uintptr, data unsafe.Pointer, dataLength int, endOfStream bool) {
+	if f := FakeHost.NetworkWrite; f != nil {
+		f(envoyFilterInstancePtr, data, dataLength, endOfStream)
+	}
+}
+
+// hostNetworkClose calls __envoy_dynamic_module_v1_network_close in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkClose(envoyFilterInstancePtr uintptr, flushWrite int) {
+	if f := FakeHost.NetworkClose; f != nil {
+		f(envoyFilterInstancePtr, flushWrite)
+	}
+}
+
+// hostNetworkGetRemoteAddress calls __envoy_dynamic_module_v1_network_get_remote_address in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostNetworkGetRemoteAddress(envoyFilterInstancePtr uintptr, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	if f := FakeHost.NetworkGetRemoteAddress; f != nil {
+		return f(envoyFilterInstancePtr, resultBufferPtr, resultBufferLengthPtr)
+	}
+	return 0
+}
+
+// hostListenerGetPeekBufferLength calls __envoy_dynamic_module_v1_listener_get_peek_buffer_length in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerGetPeekBufferLength(buffer uintptr) int {
+	if f := FakeHost.ListenerGetPeekBufferLength; f != nil {
+		return f(buffer)
+	}
+	return 0
+}
+
+// hostListenerGetPeekBufferSlicesCount calls __envoy_dynamic_module_v1_listener_get_peek_buffer_slices_count in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerGetPeekBufferSlicesCount(buffer uintptr) int {
+	if f := FakeHost.ListenerGetPeekBufferSlicesCount; f != nil {
+		return f(buffer)
+	}
+	return 0
+}
+
+// hostListenerGetPeekBufferSlice calls __envoy_dynamic_module_v1_listener_get_peek_buffer_slice in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerGetPeekBufferSlice(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) {
+	if f := FakeHost.ListenerGetPeekBufferSlice; f != nil {
+		f(buffer, nth, resultBufferPtr, resultBufferLengthPtr)
+	}
+}
+
+// hostListenerCopyOutPeekBuffer calls __envoy_dynamic_module_v1_listener_copy_out_peek_buffer in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerCopyOutPeekBuffer(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer) {
+	if f := FakeHost.ListenerCopyOutPeekBuffer; f != nil {
+		f(buffer, offset, length, resultBufferPtr)
+	}
+}
+
+// hostListenerSetDetectedTransportProtocol calls __envoy_dynamic_module_v1_listener_set_detected_transport_protocol in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerSetDetectedTransportProtocol(envoyFilterInstancePtr uintptr, protocol unsafe.Pointer, protocolLength int) {
+	if f := FakeHost.ListenerSetDetectedTransportProtocol; f != nil {
+		f(envoyFilterInstancePtr, protocol, protocolLength)
+	}
+}
+
+// hostListenerSetRequestedServerName calls __envoy_dynamic_module_v1_listener_set_requested_server_name in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerSetRequestedServerName(envoyFilterInstancePtr uintptr, serverName unsafe.Pointer, serverNameLength int) {
+	if f := FakeHost.ListenerSetRequestedServerName; f != nil {
+		f(envoyFilterInstancePtr, serverName, serverNameLength)
+	}
+}
+
+// hostListenerContinueFilterChain calls __envoy_dynamic_module_v1_listener_continue_filter_chain in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerContinueFilterChain(envoyFilterInstancePtr uintptr, success int) {
+	if f := FakeHost.ListenerContinueFilterChain; f != nil {
+		f(envoyFilterInstancePtr, success)
+	}
+}
+
+// hostListenerCloseSocket calls __envoy_dynamic_module_v1_listener_close_socket in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerCloseSocket(envoyFilterInstancePtr uintptr) {
+	if f := FakeHost.ListenerCloseSocket; f != nil {
+		f(envoyFilterInstancePtr)
+	}
+}
+
+// hostListenerGetRemoteAddress calls __envoy_dynamic_module_v1_listener_get_remote_address in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostListenerGetRemoteAddress(envoyFilterInstancePtr uintptr, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	if f := FakeHost.ListenerGetRemoteAddress; f != nil {
+		return f(envoyFilterInstancePtr, resultBufferPtr, resultBufferLengthPtr)
+	}
+	return 0
+}
+
+// hostAccessLogGetHeaderValue calls __envoy_dynamic_module_v1_access_log_get_header_value in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetHeaderValue(logEntryPtr uintptr, headersType int, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	if f := FakeHost.AccessLogGetHeaderValue; f != nil {
+		return f(logEntryPtr, headersType, key, keyLength, resultBufferPtr, resultBufferLengthPtr)
+	}
+	return 0
+}
+
+// hostAccessLogGetHeaderValueNth calls __envoy_dynamic_module_v1_access_log_get_header_value_nth in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetHeaderValueNth(logEntryPtr uintptr, headersType int, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer, nth int) {
+	if f := FakeHost.AccessLogGetHeaderValueNth; f != nil {
+		f(logEntryPtr, headersType, key, keyLength, resultBufferPtr, resultBufferLengthPtr, nth)
+	}
+}
+
+// hostAccessLogGetHeadersCount calls __envoy_dynamic_module_v1_access_log_get_headers_count in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetHeadersCount(logEntryPtr uintptr, headersType int) int {
+	if f := FakeHost.AccessLogGetHeadersCount; f != nil {
+		return f(logEntryPtr, headersType)
+	}
+	return 0
+}
+
+// hostAccessLogGetHeaderNth calls __envoy_dynamic_module_v1_access_log_get_header_nth in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetHeaderNth(logEntryPtr uintptr, headersType int, nth int, resultKeyPtr unsafe.Pointer, resultKeyLengthPtr unsafe.Pointer, resultValuePtr unsafe.Pointer, resultValueLengthPtr unsafe.Pointer) {
+	if f := FakeHost.AccessLogGetHeaderNth; f != nil {
+		f(logEntryPtr, headersType, nth, resultKeyPtr, resultKeyLengthPtr, resultValuePtr, resultValueLengthPtr)
+	}
+}
+
+// hostAccessLogGetStartTime calls __envoy_dynamic_module_v1_access_log_get_start_time in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetStartTime(logEntryPtr uintptr) int64 {
+	if f := FakeHost.AccessLogGetStartTime; f != nil {
+		return f(logEntryPtr)
+	}
+	return 0
+}
+
+// hostAccessLogGetTiming calls __envoy_dynamic_module_v1_access_log_get_timing in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetTiming(logEntryPtr uintptr, timing int) int64 {
+	if f := FakeHost.AccessLogGetTiming; f != nil {
+		return f(logEntryPtr, timing)
+	}
+	return 0
+}
+
+// hostAccessLogGetBytesReceived calls __envoy_dynamic_module_v1_access_log_get_bytes_received in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetBytesReceived(logEntryPtr uintptr) uint64 {
+	if f := FakeHost.AccessLogGetBytesReceived; f != nil {
+		return f(logEntryPtr)
+	}
+	return 0
+}
+
+// hostAccessLogGetBytesSent calls __envoy_dynamic_module_v1_access_log_get_bytes_sent in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetBytesSent(logEntryPtr uintptr) uint64 {
+	if f := FakeHost.AccessLogGetBytesSent; f != nil {
+		return f(logEntryPtr)
+	}
+	return 0
+}
+
+// hostAccessLogGetResponseCode calls __envoy_dynamic_module_v1_access_log_get_response_code in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetResponseCode(logEntryPtr uintptr) uint32 {
+	if f := FakeHost.AccessLogGetResponseCode; f != nil {
+		return f(logEntryPtr)
+	}
+	return 0
+}
+
+// hostAccessLogGetResponseFlags calls __envoy_dynamic_module_v1_access_log_get_response_flags in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetResponseFlags(logEntryPtr uintptr) uint64 {
+	if f := FakeHost.AccessLogGetResponseFlags; f != nil {
+		return f(logEntryPtr)
+	}
+	return 0
+}
+
+// hostAccessLogGetDynamicMetadata calls __envoy_dynamic_module_v1_access_log_get_dynamic_metadata in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostAccessLogGetDynamicMetadata(logEntryPtr uintptr, metadataNamespace unsafe.Pointer, metadataNamespaceLength int, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+	if f := FakeHost.AccessLogGetDynamicMetadata; f != nil {
+		return f(logEntryPtr, metadataNamespace, metadataNamespaceLength, key, keyLength, resultBufferPtr, resultBufferLengthPtr)
+	}
+	return 0
+}
+
+// hostStatsSetGauge calls __envoy_dynamic_module_v1_stats_set_gauge in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostStatsSetGauge(name unsafe.Pointer, nameLength int, value uint64) {
+	if f := FakeHost.StatsSetGauge; f != nil {
+		f(name, nameLength, value)
+	}
+}
+
+// hostStatsIncrementCounter calls __envoy_dynamic_module_v1_stats_increment_counter in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostStatsIncrementCounter(name unsafe.Pointer, nameLength int, value uint64) {
+	if f := FakeHost.StatsIncrementCounter; f != nil {
+		f(name, nameLength, value)
+	}
+}
+
+// hostHttpMutateRequestHeaders calls __envoy_dynamic_module_v1_http_mutate_request_headers in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpMutateRequestHeaders(headers uintptr, remove unsafe.Pointer, removeSize int, set unsafe.Pointer, setSize int, add unsafe.Pointer, addSize int) {
+	if f := FakeHost.HttpMutateRequestHeaders; f != nil {
+		f(headers, remove, removeSize, set, setSize, add, addSize)
+	}
+}
+
+// hostHttpMutateResponseHeaders calls __envoy_dynamic_module_v1_http_mutate_response_headers in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpMutateResponseHeaders(headers uintptr, remove unsafe.Pointer, removeSize int, set unsafe.Pointer, setSize int, add unsafe.Pointer, addSize int) {
+	if f := FakeHost.HttpMutateResponseHeaders; f != nil {
+		f(headers, remove, removeSize, set, setSize, add, addSize)
+	}
+}
+
+// hostHttpGetRequestHeaderValues calls __envoy_dynamic_module_v1_http_get_request_header_values in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpGetRequestHeaderValues(headers uintptr, keys unsafe.Pointer, keysSize int, results unsafe.Pointer) int {
+	if f := FakeHost.HttpGetRequestHeaderValues; f != nil {
+		return f(headers, keys, keysSize, results)
+	}
+	return 0
+}
+
+// hostHttpGetResponseHeaderValues calls __envoy_dynamic_module_v1_http_get_response_header_values in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpGetResponseHeaderValues(headers uintptr, keys unsafe.Pointer, keysSize int, results unsafe.Pointer) int {
+	if f := FakeHost.HttpGetResponseHeaderValues; f != nil {
+		return f(headers, keys, keysSize, results)
+	}
+	return 0
+}
+
+// hostHttpResetStream calls __envoy_dynamic_module_v1_http_reset_stream in abi.h.
+// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.
+func hostHttpResetStream(envoyFilterInstancePtr uintptr) {
+	if f := FakeHost.HttpResetStream; f != nil {
+		f(envoyFilterInstancePtr)
+	}
+}
@@ -0,0 +1,98 @@
+//go:build cgo
+
+package envoy
+
+/*
+#include "abi.h"
+
+// The optional Envoy APIs are weak symbols, so their addresses are null if not implemented by Envoy.
+// These are defined in this file as the file with //export directives can only have declarations.
+
+static size_t envoy_go_host_abi_version() {
+  if (__envoy_dynamic_module_v1_get_abi_version == NULL) {
+    return 1;
+  }
+  return __envoy_dynamic_module_v1_get_abi_version();
+}
+
+static int envoy_go_host_has_flow_control() {
+  return __envoy_dynamic_module_v1_http_read_disable_request != NULL &&
+         __envoy_dynamic_module_v1_http_read_disable_response != NULL;
+}
+
+static int envoy_go_host_has_data_injection() {
+  return __envoy_dynamic_module_v1_http_inject_request_data != NULL &&
+         __envoy_dynamic_module_v1_http_inject_response_data != NULL;
+}
+
+static int envoy_go_host_has_add_body() {
+  return __envoy_dynamic_module_v1_http_add_request_body != NULL &&
+         __envoy_dynamic_module_v1_http_add_response_body != NULL;
+}
+
+static int envoy_go_host_has_bypass_events() {
+  return __envoy_dynamic_module_v1_http_bypass_events != NULL;
+}
+
+static int envoy_go_host_has_upstream_info() {
+  return __envoy_dynamic_module_v1_http_get_upstream_attempt != NULL &&
+         __envoy_dynamic_module_v1_http_get_upstream_cluster_name != NULL &&
+         __envoy_dynamic_module_v1_http_get_upstream_host_address != NULL;
+}
+
+static int envoy_go_host_has_network_filter() {
+  return __envoy_dynamic_module_v1_network_get_read_buffer_length != NULL &&
+         __envoy_dynamic_module_v1_network_write != NULL &&
+         __envoy_dynamic_module_v1_network_close != NULL;
+}
+
+static int envoy_go_host_has_listener_filter() {
+  return __envoy_dynamic_module_v1_listener_get_peek_buffer_length != NULL &&
+         __envoy_dynamic_module_v1_listener_continue_filter_chain != NULL;
+}
+
+static int envoy_go_host_has_access_logger() {
+  return __envoy_dynamic_module_v1_access_log_get_header_value != NULL &&
+         __envoy_dynamic_module_v1_access_log_get_timing != NULL;
+}
+
+static int envoy_go_host_has_stats() {
+  return __envoy_dynamic_module_v1_stats_set_gauge != NULL &&
+         __envoy_dynamic_module_v1_stats_increment_counter != NULL;
+}
+
+static int envoy_go_host_has_header_batch() {
+  return __envoy_dynamic_module_v1_http_mutate_request_headers != NULL &&
+         __envoy_dynamic_module_v1_http_mutate_response_headers != NULL &&
+         __envoy_dynamic_module_v1_http_get_request_header_values != NULL &&
+         __envoy_dynamic_module_v1_http_get_response_header_values != NULL;
+}
+
+static int envoy_go_host_has_reset_stream() {
+  return __envoy_dynamic_module_v1_http_reset_stream != NULL;
+}
+*/
+import "C"
+
+// detectHostInfo detects the ABI version and the features implemented by Envoy via the weak symbols.
+func detectHostInfo() hostInfo {
+	info := hostInfo{abiVersion: int(C.envoy_go_host_abi_version())}
+	for feature, has := range map[Feature]C.int{
+		FeatureFlowControl:    C.envoy_go_host_has_flow_control(),
+		FeatureDataInjection:  C.envoy_go_host_has_data_injection(),
+		FeatureAddBody:        C.envoy_go_host_has_add_body(),
+		FeatureBypassEvents:   C.envoy_go_host_has_bypass_events(),
+		FeatureUpstreamInfo:   C.envoy_go_host_has_upstream_info(),
+		FeatureNetworkFilter:  C.envoy_go_host_has_network_filter(),
+		FeatureListenerFilter: C.envoy_go_host_has_listener_filter(),
+		FeatureAccessLogger:   C.envoy_go_host_has_access_logger(),
+		FeatureStats:          C.envoy_go_host_has_stats(),
+		FeatureHeaderBatch:    C.envoy_go_host_has_header_batch(),
+		FeatureResetStream:    C.envoy_go_host_has_reset_stream(),
+	} {
+		if has != 0 {
+			info.features |= feature
+		}
+	}
+	return info
+}
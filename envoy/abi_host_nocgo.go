@@ -0,0 +1,15 @@
+//go:build !cgo
+
+package envoy
+
+// This file is only included when cgo is disabled which is used for testing purposes.
+
+// detectHostInfo returns the host that implements all the features of ABIVersion as there's no Envoy without cgo.
+func detectHostInfo() hostInfo {
+	return hostInfo{
+		abiVersion: ABIVersion,
+		features: FeatureFlowControl | FeatureDataInjection | FeatureAddBody | FeatureBypassEvents |
+			FeatureUpstreamInfo | FeatureNetworkFilter | FeatureListenerFilter | FeatureAccessLogger | FeatureStats |
+			FeatureHeaderBatch | FeatureResetStream,
+	}
+}
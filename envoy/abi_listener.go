@@ -0,0 +1,212 @@
+package envoy
+
+import (
+	"errors"
+	"sync"
+	"unsafe"
+)
+
+func eventListenerFilterInit(configPtr uintptr, configSize int) uintptr {
+	listenerFilter := NewListenerFilter(copyConfig(configPtr, configSize))
+	pined := memManager.pinListenerFilter(listenerFilter)
+	return uintptr(unsafe.Pointer(pined))
+}
+
+func eventListenerFilterDestroy(listenerFilterPtr uintptr) {
+	listenerFilter := memManager.unwrapPinnedListenerFilter(listenerFilterPtr)
+	listenerFilter.filter.Destroy()
+	memManager.unpinListenerFilter(listenerFilter)
+}
+
+func eventListenerFilterInstanceInit(envoyFilterPtr uintptr, listenerFilterPtr uintptr) uintptr {
+	envoyPtr := &envoyListenerFilterInstance{raw: envoyFilterPtr}
+	listenerFilter := memManager.unwrapPinnedListenerFilter(listenerFilterPtr)
+	instance := listenerFilter.filter.NewInstance(envoyPtr)
+	pined := memManager.pinListenerFilterInstance(instance, envoyPtr)
+	return uintptr(unsafe.Pointer(pined))
+}
+
+func eventListenerFilterInstanceMaxReadBytes(listenerFilterInstancePtr uintptr) int {
+	instance := unwrapRawPinListenerFilterInstance(listenerFilterInstancePtr)
+	return max(instance.filterInstance.MaxReadBytes(), 0)
+}
+
+func eventListenerFilterInstanceAccept(listenerFilterInstancePtr uintptr) int {
+	instance := unwrapRawPinListenerFilterInstance(listenerFilterInstancePtr)
+	return int(instance.filterInstance.OnAccept())
+}
+
+func eventListenerFilterInstanceData(listenerFilterInstancePtr uintptr, buffer uintptr) int {
+	defer recoverViewFault(panicOnViewFault())
+	instance := unwrapRawPinListenerFilterInstance(listenerFilterInstancePtr)
+	stamp := newViewStamp()
+	status := instance.filterInstance.OnData(ListenerPeekBuffer{stamp: stamp, raw: buffer})
+	stamp.expire()
+	return int(status)
+}
+
+func eventListenerFilterInstanceDestroy(listenerFilterInstancePtr uintptr) {
+	instance := unwrapRawPinListenerFilterInstance(listenerFilterInstancePtr)
+	instance.envoy.detach()
+	instance.filterInstance.Destroy()
+	memManager.unpinListenerFilterInstance(instance)
+}
+
+// envoyListenerFilterInstance is the underlying type of EnvoyListenerFilterInstance.
+type envoyListenerFilterInstance struct {
+	// mu guards raw so that the socket is not destroyed while a method is calling Envoy from another Goroutine.
+	mu sync.RWMutex
+	// raw is the pointer to the Envoy listener filter instance, which is zero after the instance is destroyed.
+	raw uintptr
+}
+
+// ErrSocketDestroyed is returned by EnvoyListenerFilterInstance.Err after the listener filter instance is destroyed.
+var ErrSocketDestroyed = errors.New("envoy: listener filter instance is destroyed")
+
+// detach clears the pointer to the Envoy listener filter instance when it is destroyed, which waits for the calls
+// to Envoy in flight on the other Goroutines.
+func (c *envoyListenerFilterInstance) detach() {
+	c.mu.Lock()
+	c.raw = 0
+	c.mu.Unlock()
+}
+
+// acquire returns the pointer to the Envoy listener filter instance, and keeps it alive until release is called.
+// Returns false if it is already destroyed, in which case release must not be called.
+func (c *envoyListenerFilterInstance) acquire() (uintptr, bool) {
+	c.mu.RLock()
+	if c.raw == 0 {
+		c.mu.RUnlock()
+		return 0, false
+	}
+	return c.raw, true
+}
+
+// release releases the listener filter instance acquired by acquire.
+func (c *envoyListenerFilterInstance) release() {
+	c.mu.RUnlock()
+}
+
+// EnvoyListenerFilterInstance is an opaque object that represents the underlying Envoy listener filter instance
+// for an accepted socket. This is used to interact with it from the module code.
+//
+// Once the instance is destroyed, i.e. ListenerFilterInstance.Destroy is about to be called, the methods are no-op
+// and return the zero values without calling Envoy, and Err returns ErrSocketDestroyed. The destroy waits for
+// the calls in flight on the other Goroutines, e.g. ContinueFilterChain after an asynchronous classification.
+type EnvoyListenerFilterInstance = *envoyListenerFilterInstance
+
+// Err returns ErrSocketDestroyed if the listener filter instance is destroyed, and nil otherwise. This can be used
+// by the Goroutines outliving the instance to tell whether the calls, e.g. ContinueFilterChain, have been no-op.
+func (c *envoyListenerFilterInstance) Err() error {
+	if _, ok := c.acquire(); !ok {
+		return ErrSocketDestroyed
+	}
+	c.release()
+	return nil
+}
+
+// SetDetectedTransportProtocol sets the detected transport protocol of the socket, e.g. "tls" or "raw_buffer",
+// which is used for the filter chain matching.
+func (c *envoyListenerFilterInstance) SetDetectedTransportProtocol(protocol string) {
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	hostListenerSetDetectedTransportProtocol(raw, stringPtr(protocol), len(protocol))
+}
+
+// SetRequestedServerName sets the requested server name of the socket, e.g. the SNI,
+// which is used for the filter chain matching.
+func (c *envoyListenerFilterInstance) SetRequestedServerName(serverName string) {
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	hostListenerSetRequestedServerName(raw, stringPtr(serverName), len(serverName))
+}
+
+// ContinueFilterChain resumes the listener filter chain after ListenerFilterStatusStopIteration is returned.
+// If `accept` is false, the socket is rejected and closed. This can be called from any Goroutine, e.g. after
+// an asynchronous classification of the socket.
+func (c *envoyListenerFilterInstance) ContinueFilterChain(accept bool) {
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	hostListenerContinueFilterChain(raw, boolToInt(accept))
+}
+
+// CloseSocket closes the socket during ListenerFilterInstance.OnAccept or ListenerFilterInstance.OnData, i.e.
+// the socket is rejected. ListenerFilterStatusStopIteration should be returned after calling this.
+func (c *envoyListenerFilterInstance) CloseSocket() {
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	hostListenerCloseSocket(raw)
+}
+
+// RemoteAddress returns the remote address of the socket in the form of "ip:port".
+// Returns false at the second return value if the address is not available.
+func (c *envoyListenerFilterInstance) RemoteAddress() (string, bool) {
+	raw, ok := c.acquire()
+	if !ok {
+		return "", false
+	}
+	defer c.release()
+	var resultPtr *byte
+	var resultSize int
+	if hostListenerGetRemoteAddress(raw, unsafe.Pointer(&resultPtr), unsafe.Pointer(&resultSize)) == 0 {
+		return "", false
+	}
+	return string(unsafe.Slice(resultPtr, resultSize)), true
+}
+
+// ListenerPeekBuffer is an opaque object that represents the data peeked from the accepted socket.
+// A buffer consists of a multiple slices of data, not a single contiguous buffer.
+//
+// This provides a read-only zero-copy view of the data. The data is not consumed from the socket.
+//
+// This implements io.ReaderAt interface. This is only valid during the event callback where it is passed.
+type ListenerPeekBuffer struct {
+	stamp viewStamp
+	raw   uintptr
+}
+
+// Length returns the total number of bytes in the buffer.
+func (b ListenerPeekBuffer) Length() int {
+	b.stamp.check("ListenerPeekBuffer")
+	return hostListenerGetPeekBufferLength(b.raw)
+}
+
+// Slices iterates over the slices of the buffer. The view byte slice must NOT be saved nor modified as the
+// memory is owned by the Envoy. To take a copy of the buffer, use the Copy method.
+func (b ListenerPeekBuffer) Slices(iter func(view []byte)) {
+	b.stamp.check("ListenerPeekBuffer")
+	sliceCount := hostListenerGetPeekBufferSlicesCount(b.raw)
+	for i := 0; i < sliceCount; i++ {
+		var ptr *byte
+		var size int
+		hostListenerGetPeekBufferSlice(b.raw, i, unsafe.Pointer(&ptr), unsafe.Pointer(&size))
+		iterView(ptr, size, true, iter)
+	}
+}
+
+// Copy returns a copy of the bytes in the buffer as a single contiguous buffer.
+func (b ListenerPeekBuffer) Copy() []byte {
+	return copyBuffer(b.Length(), b.Slices)
+}
+
+// ReadAt implements io.ReaderAt.
+func (b ListenerPeekBuffer) ReadAt(p []byte, off int64) (n int, err error) {
+	p, err = readAtRange(b.Length(), p, off)
+	if len(p) == 0 {
+		return 0, err
+	}
+	hostListenerCopyOutPeekBuffer(b.raw, int(off), len(p), bytesPtr(p))
+	return len(p), err
+}
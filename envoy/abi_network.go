@@ -0,0 +1,338 @@
+package envoy
+
+import (
+	"errors"
+	"sync"
+	"unsafe"
+)
+
+func eventNetworkFilterInit(configPtr uintptr, configSize int) uintptr {
+	networkFilter := NewNetworkFilter(copyConfig(configPtr, configSize))
+	pined := memManager.pinNetworkFilter(networkFilter)
+	return uintptr(unsafe.Pointer(pined))
+}
+
+func eventNetworkFilterDestroy(networkFilterPtr uintptr) {
+	networkFilter := memManager.unwrapPinnedNetworkFilter(networkFilterPtr)
+	networkFilter.filter.Destroy()
+	memManager.unpinNetworkFilter(networkFilter)
+}
+
+func eventNetworkFilterInstanceInit(envoyFilterPtr uintptr, networkFilterPtr uintptr) uintptr {
+	envoyPtr := &envoyNetworkFilterInstance{raw: envoyFilterPtr}
+	networkFilter := memManager.unwrapPinnedNetworkFilter(networkFilterPtr)
+	instance := networkFilter.filter.NewInstance(envoyPtr)
+	pined := memManager.pinNetworkFilterInstance(instance, envoyPtr)
+	return uintptr(unsafe.Pointer(pined))
+}
+
+func eventNetworkFilterInstanceNewConnection(networkFilterInstancePtr uintptr) int {
+	instance := unwrapRawPinNetworkFilterInstance(networkFilterInstancePtr)
+	return int(instance.filterInstance.OnNewConnection())
+}
+
+func eventNetworkFilterInstanceRead(networkFilterInstancePtr uintptr, buffer uintptr, endOfStream bool) int {
+	defer recoverViewFault(panicOnViewFault())
+	instance := unwrapRawPinNetworkFilterInstance(networkFilterInstancePtr)
+	stamp := newViewStamp()
+	status := instance.filterInstance.OnRead(NetworkReadBuffer{stamp: stamp, raw: buffer}, endOfStream)
+	stamp.expire()
+	return int(status)
+}
+
+func eventNetworkFilterInstanceWrite(networkFilterInstancePtr uintptr, buffer uintptr, endOfStream bool) int {
+	defer recoverViewFault(panicOnViewFault())
+	instance := unwrapRawPinNetworkFilterInstance(networkFilterInstancePtr)
+	stamp := newViewStamp()
+	status := instance.filterInstance.OnWrite(NetworkWriteBuffer{stamp: stamp, raw: buffer}, endOfStream)
+	stamp.expire()
+	return int(status)
+}
+
+func eventNetworkFilterInstanceClose(networkFilterInstancePtr uintptr, event int) {
+	instance := unwrapRawPinNetworkFilterInstance(networkFilterInstancePtr)
+	instance.filterInstance.OnClose(ConnectionEvent(event))
+}
+
+func eventNetworkFilterInstanceDestroy(networkFilterInstancePtr uintptr) {
+	instance := unwrapRawPinNetworkFilterInstance(networkFilterInstancePtr)
+	instance.envoy.detach()
+	instance.filterInstance.Destroy()
+	memManager.unpinNetworkFilterInstance(instance)
+}
+
+// envoyNetworkFilterInstance is the underlying type of EnvoyNetworkFilterInstance.
+type envoyNetworkFilterInstance struct {
+	// mu guards raw so that the connection is not destroyed while a method is calling Envoy from another Goroutine.
+	mu sync.RWMutex
+	// raw is the pointer to the Envoy network filter instance, which is zero after the connection is destroyed.
+	raw uintptr
+}
+
+// ErrConnectionDestroyed is returned by EnvoyNetworkFilterInstance.Err after the connection is destroyed.
+var ErrConnectionDestroyed = errors.New("envoy: connection is destroyed")
+
+// detach clears the pointer to the Envoy network filter instance when the connection is destroyed, which waits for
+// the calls to Envoy in flight on the other Goroutines.
+func (c *envoyNetworkFilterInstance) detach() {
+	c.mu.Lock()
+	c.raw = 0
+	c.mu.Unlock()
+}
+
+// acquire returns the pointer to the Envoy network filter instance, and keeps the connection alive until release is
+// called. Returns false if the connection is already destroyed, in which case release must not be called.
+func (c *envoyNetworkFilterInstance) acquire() (uintptr, bool) {
+	c.mu.RLock()
+	if c.raw == 0 {
+		c.mu.RUnlock()
+		return 0, false
+	}
+	return c.raw, true
+}
+
+// release releases the connection acquired by acquire.
+func (c *envoyNetworkFilterInstance) release() {
+	c.mu.RUnlock()
+}
+
+// EnvoyNetworkFilterInstance is an opaque object that represents the underlying Envoy network filter instance
+// for a downstream connection. This is used to interact with it from the module code.
+//
+// This can be used from any Goroutine, e.g. to write the response of an asynchronous operation. Once the
+// connection is destroyed, i.e. NetworkFilterInstance.Destroy is about to be called, the methods are no-op and return
+// the zero values without calling Envoy, and Err returns ErrConnectionDestroyed. The destroy waits for the calls in
+// flight on the other Goroutines.
+type EnvoyNetworkFilterInstance = *envoyNetworkFilterInstance
+
+// Err returns ErrConnectionDestroyed if the connection is destroyed, and nil otherwise. This can be used by
+// the Goroutines outliving the connection to tell whether the calls, e.g. Write, have been no-op.
+func (c *envoyNetworkFilterInstance) Err() error {
+	if _, ok := c.acquire(); !ok {
+		return ErrConnectionDestroyed
+	}
+	c.release()
+	return nil
+}
+
+// ContinueReading resumes the iteration of the read filter chain after NetworkFilterInstance.OnNewConnection or
+// NetworkFilterInstance.OnRead returned NetworkFilterStatusStopIteration.
+func (c *envoyNetworkFilterInstance) ContinueReading() {
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	hostNetworkContinueReading(raw)
+}
+
+// Write writes the data directly to the downstream connection. If `endOfStream` is true,
+// the write side of the connection is half-closed after the data is written.
+func (c *envoyNetworkFilterInstance) Write(data []byte, endOfStream bool) {
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	hostNetworkWrite(raw, bytesPtr(data), len(data), endOfStream)
+}
+
+// Close closes the downstream connection. If `flushWrite` is true, the pending write data is flushed before closing.
+func (c *envoyNetworkFilterInstance) Close(flushWrite bool) {
+	raw, ok := c.acquire()
+	if !ok {
+		return
+	}
+	defer c.release()
+	hostNetworkClose(raw, boolToInt(flushWrite))
+}
+
+// RemoteAddress returns the remote address of the downstream connection in the form of "ip:port".
+// Returns false at the second return value if the address is not available.
+func (c *envoyNetworkFilterInstance) RemoteAddress() (string, bool) {
+	raw, ok := c.acquire()
+	if !ok {
+		return "", false
+	}
+	defer c.release()
+	var resultPtr *byte
+	var resultSize int
+	if hostNetworkGetRemoteAddress(raw, unsafe.Pointer(&resultPtr), unsafe.Pointer(&resultSize)) == 0 {
+		return "", false
+	}
+	return string(unsafe.Slice(resultPtr, resultSize)), true
+}
+
+// NetworkReadBuffer is an opaque object that represents the underlying buffer of the data read from the
+// downstream connection. A buffer consists of a multiple slices of data, not a single contiguous buffer.
+//
+// This provides a zero-copy view of the buffer.
+//
+// This implements io.ReaderAt interface. This is only valid during the event callback where it is passed.
+type NetworkReadBuffer struct {
+	stamp viewStamp
+	raw   uintptr
+}
+
+// NetworkWriteBuffer is an opaque object that represents the underlying buffer of the data written to the
+// downstream connection. A buffer consists of a multiple slices of data, not a single contiguous buffer.
+//
+// This provides a zero-copy view of the buffer.
+//
+// This implements io.ReaderAt interface. This is only valid during the event callback where it is passed.
+type NetworkWriteBuffer struct {
+	stamp viewStamp
+	raw   uintptr
+}
+
+// Length returns the total number of bytes in the buffer.
+func (b NetworkReadBuffer) Length() int {
+	b.stamp.check("NetworkReadBuffer")
+	return hostNetworkGetReadBufferLength(b.raw)
+}
+
+// Slices iterates over the slices of the buffer. The view byte slice must NOT be saved as the
+// memory is owned by the Envoy. To take a copy of the buffer, use the Copy method.
+func (b NetworkReadBuffer) Slices(iter func(view []byte)) {
+	b.stamp.check("NetworkReadBuffer")
+	sliceCount := hostNetworkGetReadBufferSlicesCount(b.raw)
+	for i := 0; i < sliceCount; i++ {
+		var ptr *byte
+		var size int
+		hostNetworkGetReadBufferSlice(b.raw, i, unsafe.Pointer(&ptr), unsafe.Pointer(&size))
+		iterView(ptr, size, false, iter)
+	}
+}
+
+// Copy returns a copy of the bytes in the buffer as a single contiguous buffer.
+func (b NetworkReadBuffer) Copy() []byte {
+	b.stamp.check("NetworkReadBuffer")
+	return copyBuffer(b.Length(), b.Slices)
+}
+
+// ReadAt implements io.ReaderAt.
+func (b NetworkReadBuffer) ReadAt(p []byte, off int64) (n int, err error) {
+	b.stamp.check("NetworkReadBuffer")
+	p, err = readAtRange(b.Length(), p, off)
+	if len(p) == 0 {
+		return 0, err
+	}
+	hostNetworkCopyOutReadBuffer(b.raw, int(off), len(p), bytesPtr(p))
+	return len(p), err
+}
+
+// Append appends the data to the buffer.
+func (b NetworkReadBuffer) Append(data []byte) {
+	b.stamp.check("NetworkReadBuffer")
+	if len(data) == 0 {
+		return
+	}
+	hostNetworkAppendReadBuffer(b.raw, bytesPtr(data), len(data))
+}
+
+// Prepend prepends the data to the buffer.
+func (b NetworkReadBuffer) Prepend(data []byte) {
+	b.stamp.check("NetworkReadBuffer")
+	if len(data) == 0 {
+		return
+	}
+	hostNetworkPrependReadBuffer(b.raw, bytesPtr(data), len(data))
+}
+
+// Drain removes the given number of bytes from the front of the buffer. The length is clamped to
+// the range of [0, Length()], so draining more than the buffer empties it.
+func (b NetworkReadBuffer) Drain(length int) {
+	b.stamp.check("NetworkReadBuffer")
+	if length <= 0 {
+		return
+	}
+	if length = min(length, b.Length()); length > 0 {
+		hostNetworkDrainReadBuffer(b.raw, length)
+	}
+}
+
+// Replace replaces the buffer with the given data. This doesn't take the ownership of the data.
+// Therefore, data will be copied to the buffer internally.
+func (b NetworkReadBuffer) Replace(data []byte) {
+	b.stamp.check("NetworkReadBuffer")
+	if length := b.Length(); length > 0 {
+		hostNetworkDrainReadBuffer(b.raw, length)
+	}
+	b.Append(data)
+}
+
+// Length returns the total number of bytes in the buffer.
+func (b NetworkWriteBuffer) Length() int {
+	b.stamp.check("NetworkWriteBuffer")
+	return hostNetworkGetWriteBufferLength(b.raw)
+}
+
+// Slices iterates over the slices of the buffer. The view byte slice must NOT be saved as the
+// memory is owned by the Envoy. To take a copy of the buffer, use the Copy method.
+func (b NetworkWriteBuffer) Slices(iter func(view []byte)) {
+	b.stamp.check("NetworkWriteBuffer")
+	sliceCount := hostNetworkGetWriteBufferSlicesCount(b.raw)
+	for i := 0; i < sliceCount; i++ {
+		var ptr *byte
+		var size int
+		hostNetworkGetWriteBufferSlice(b.raw, i, unsafe.Pointer(&ptr), unsafe.Pointer(&size))
+		iterView(ptr, size, false, iter)
+	}
+}
+
+// Copy returns a copy of the bytes in the buffer as a single contiguous buffer.
+func (b NetworkWriteBuffer) Copy() []byte {
+	b.stamp.check("NetworkWriteBuffer")
+	return copyBuffer(b.Length(), b.Slices)
+}
+
+// ReadAt implements io.ReaderAt.
+func (b NetworkWriteBuffer) ReadAt(p []byte, off int64) (n int, err error) {
+	b.stamp.check("NetworkWriteBuffer")
+	p, err = readAtRange(b.Length(), p, off)
+	if len(p) == 0 {
+		return 0, err
+	}
+	hostNetworkCopyOutWriteBuffer(b.raw, int(off), len(p), bytesPtr(p))
+	return len(p), err
+}
+
+// Append appends the data to the buffer.
+func (b NetworkWriteBuffer) Append(data []byte) {
+	b.stamp.check("NetworkWriteBuffer")
+	if len(data) == 0 {
+		return
+	}
+	hostNetworkAppendWriteBuffer(b.raw, bytesPtr(data), len(data))
+}
+
+// Prepend prepends the data to the buffer.
+func (b NetworkWriteBuffer) Prepend(data []byte) {
+	b.stamp.check("NetworkWriteBuffer")
+	if len(data) == 0 {
+		return
+	}
+	hostNetworkPrependWriteBuffer(b.raw, bytesPtr(data), len(data))
+}
+
+// Drain removes the given number of bytes from the front of the buffer. The length is clamped to
+// the range of [0, Length()], so draining more than the buffer empties it.
+func (b NetworkWriteBuffer) Drain(length int) {
+	b.stamp.check("NetworkWriteBuffer")
+	if length <= 0 {
+		return
+	}
+	if length = min(length, b.Length()); length > 0 {
+		hostNetworkDrainWriteBuffer(b.raw, length)
+	}
+}
+
+// Replace replaces the buffer with the given data. This doesn't take the ownership of the data.
+// Therefore, data will be copied to the buffer internally.
+func (b NetworkWriteBuffer) Replace(data []byte) {
+	b.stamp.check("NetworkWriteBuffer")
+	if length := b.Length(); length > 0 {
+		hostNetworkDrainWriteBuffer(b.raw, length)
+	}
+	b.Append(data)
+}
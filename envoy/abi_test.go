@@ -0,0 +1,341 @@
+//go:build !cgo
+
+package envoy
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// fakeBody is a body buffer of the fake Envoy, which consists of multiple slices as Envoy's buffers do.
+type fakeBody struct {
+	slices [][]byte
+}
+
+// bytes returns the data of the buffer.
+func (b *fakeBody) bytes() []byte {
+	var data []byte
+	for _, s := range b.slices {
+		data = append(data, s...)
+	}
+	return data
+}
+
+// fakeBodies sets the body buffer functions of FakeHost for the test, and returns the buffers by the pointers
+// passed to the event hooks. The buffers returned by GetRequestBodyBuffer and GetResponseBodyBuffer are the ones
+// at requestBufferOf and responseBufferOf the pointer of the EnvoyFilterInstance, which are created on demand.
+func fakeBodies(t *testing.T) map[uintptr]*fakeBody {
+	bodies := map[uintptr]*fakeBody{}
+	body := func(buffer uintptr) *fakeBody {
+		b, ok := bodies[buffer]
+		if !ok {
+			b = &fakeBody{}
+			bodies[buffer] = b
+		}
+		return b
+	}
+	length := func(buffer uintptr) int { return len(body(buffer).bytes()) }
+	slicesCount := func(buffer uintptr) int { return len(body(buffer).slices) }
+	slice := func(buffer uintptr, nth int, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) {
+		s := body(buffer).slices[nth]
+		*(**byte)(resultBufferPtr) = unsafe.SliceData(s)
+		*(*int)(resultBufferLengthPtr) = len(s)
+	}
+	copyOut := func(buffer uintptr, offset int, length int, resultBufferPtr unsafe.Pointer) {
+		copy(unsafe.Slice((*byte)(resultBufferPtr), length), body(buffer).bytes()[offset:])
+	}
+	appendData := func(buffer uintptr, data unsafe.Pointer, dataLength int) {
+		b := body(buffer)
+		b.slices = append(b.slices, append([]byte(nil), unsafe.Slice((*byte)(data), dataLength)...))
+	}
+	prependData := func(buffer uintptr, data unsafe.Pointer, dataLength int) {
+		b := body(buffer)
+		b.slices = append([][]byte{append([]byte(nil), unsafe.Slice((*byte)(data), dataLength)...)}, b.slices...)
+	}
+	drain := func(buffer uintptr, length int) {
+		b := body(buffer)
+		for length > 0 && len(b.slices) > 0 {
+			n := min(length, len(b.slices[0]))
+			if b.slices[0] = b.slices[0][n:]; len(b.slices[0]) == 0 {
+				b.slices = b.slices[1:]
+			}
+			length -= n
+		}
+	}
+
+	prev := FakeHost
+	FakeHost.HttpGetRequestBodyBuffer = requestBufferOf
+	FakeHost.HttpGetResponseBodyBuffer = responseBufferOf
+	FakeHost.HttpGetRequestBodyBufferLength, FakeHost.HttpGetResponseBodyBufferLength = length, length
+	FakeHost.HttpGetRequestBodyBufferSlicesCount = slicesCount
+	FakeHost.HttpGetResponseBodyBufferSlicesCount = slicesCount
+	FakeHost.HttpGetRequestBodyBufferSlice, FakeHost.HttpGetResponseBodyBufferSlice = slice, slice
+	FakeHost.HttpCopyOutRequestBodyBuffer, FakeHost.HttpCopyOutResponseBodyBuffer = copyOut, copyOut
+	FakeHost.HttpAppendRequestBodyBuffer, FakeHost.HttpAppendResponseBodyBuffer = appendData, appendData
+	FakeHost.HttpPrependRequestBodyBuffer, FakeHost.HttpPrependResponseBodyBuffer = prependData, prependData
+	FakeHost.HttpDrainRequestBodyBuffer, FakeHost.HttpDrainResponseBodyBuffer = drain, drain
+	t.Cleanup(func() { FakeHost = prev })
+	return bodies
+}
+
+// fakeHeaders sets the header functions of FakeHost for the test to operate on the headers, which are shared by the
+// request and the response headers.
+func fakeHeaders(t *testing.T, headers map[string]string) {
+	get := func(_ uintptr, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer,
+		resultBufferLengthPtr unsafe.Pointer) int {
+		value, ok := headers[unsafe.String((*byte)(key), keyLength)]
+		if !ok {
+			return 0
+		}
+		*(**byte)(resultBufferPtr) = unsafe.StringData(value)
+		*(*int)(resultBufferLengthPtr) = len(value)
+		return 1
+	}
+	set := func(_ uintptr, key unsafe.Pointer, keyLength int, value unsafe.Pointer, valueLength int) {
+		k := strings.Clone(unsafe.String((*byte)(key), keyLength))
+		if value == nil {
+			delete(headers, k)
+		} else {
+			headers[k] = strings.Clone(unsafe.String((*byte)(value), valueLength))
+		}
+	}
+	prev := FakeHost
+	FakeHost.HttpGetRequestHeaderValue, FakeHost.HttpGetResponseHeaderValue = get, get
+	FakeHost.HttpSetRequestHeader, FakeHost.HttpSetResponseHeader = set, set
+	t.Cleanup(func() { FakeHost = prev })
+}
+
+// requestBufferOf returns the pointer of the buffered request body of the fake EnvoyFilterInstance.
+func requestBufferOf(envoyFilterInstancePtr uintptr) uintptr { return envoyFilterInstancePtr<<8 | 1 }
+
+// responseBufferOf returns the pointer of the buffered response body of the fake EnvoyFilterInstance.
+func responseBufferOf(envoyFilterInstancePtr uintptr) uintptr { return envoyFilterInstancePtr<<8 | 2 }
+
+// bodyHttpFilter is the HttpFilter whose instances run the functions of the test in the body events.
+type bodyHttpFilter struct {
+	nopHttpFilter
+	requestBody  func(e EnvoyFilterInstance, body RequestBodyBuffer, endOfStream bool) RequestBodyStatus
+	responseBody func(e EnvoyFilterInstance, body ResponseBodyBuffer, endOfStream bool) ResponseBodyStatus
+}
+
+func (f bodyHttpFilter) NewInstance(e EnvoyFilterInstance) HttpFilterInstance {
+	return &bodyHttpFilterInstance{envoy: e, filter: f}
+}
+
+type bodyHttpFilterInstance struct {
+	envoy  EnvoyFilterInstance
+	filter bodyHttpFilter
+}
+
+func (*bodyHttpFilterInstance) Destroy() {}
+
+func (i *bodyHttpFilterInstance) RequestBody(body RequestBodyBuffer, endOfStream bool) RequestBodyStatus {
+	return i.filter.requestBody(i.envoy, body, endOfStream)
+}
+
+func (i *bodyHttpFilterInstance) ResponseBody(body ResponseBodyBuffer, endOfStream bool) ResponseBodyStatus {
+	return i.filter.responseBody(i.envoy, body, endOfStream)
+}
+
+func TestGetBodyBuffer(t *testing.T) {
+	bodies := fakeBodies(t)
+	filter := newTestHttpFilter(t, bodyHttpFilter{
+		requestBody: func(e EnvoyFilterInstance, body RequestBodyBuffer, _ bool) RequestBodyStatus {
+			buffered := e.GetRequestBodyBuffer()
+			buffered.Append(body.Copy())
+			body.Drain(body.Length())
+			return RequestBodyStatusContinue
+		},
+		responseBody: func(e EnvoyFilterInstance, body ResponseBodyBuffer, _ bool) ResponseBodyStatus {
+			buffered := e.GetResponseBodyBuffer()
+			buffered.Prepend(body.Copy())
+			body.Replace([]byte("replaced"))
+			return ResponseBodyStatusContinue
+		},
+	})
+	instance := eventHttpFilterInstanceInit(1, filter)
+	bodies[10] = &fakeBody{slices: [][]byte{[]byte("req"), []byte("uest")}}
+	bodies[20] = &fakeBody{slices: [][]byte{[]byte("response")}}
+	bodies[responseBufferOf(1)] = &fakeBody{slices: [][]byte{[]byte(" buffered")}}
+	eventHttpFilterInstanceRequestBody(instance, 10, true)
+	eventHttpFilterInstanceResponseBody(instance, 20, true)
+	eventHttpFilterInstanceDestroy(instance)
+
+	for buffer, want := range map[uintptr]string{
+		10:                  "",
+		20:                  "replaced",
+		requestBufferOf(1):  "request",
+		responseBufferOf(1): "response buffered",
+	} {
+		if got := string(bodies[buffer].bytes()); got != want {
+			t.Errorf("buffer %#x = %q, want %q", buffer, got, want)
+		}
+	}
+}
+
+// bodyBuffer is the methods shared by RequestBodyBuffer and ResponseBodyBuffer.
+type bodyBuffer interface {
+	io.ReaderAt
+	Length() int
+	Slices(iter func(view []byte))
+	Copy() []byte
+	Append(data []byte)
+	Prepend(data []byte)
+	Drain(length int)
+	Replace(data []byte)
+}
+
+func TestBodyBufferAfterDestroy(t *testing.T) {
+	bodies := fakeBodies(t)
+	filter := newTestHttpFilter(t, bodyHttpFilter{})
+	instance := eventHttpFilterInstanceInit(1, filter)
+	e := unwrapRawPinHttpFilterInstance(instance).envoy
+	bodies[requestBufferOf(1)] = &fakeBody{slices: [][]byte{[]byte("request")}}
+	bodies[responseBufferOf(1)] = &fakeBody{slices: [][]byte{[]byte("response")}}
+	request, response := e.GetRequestBodyBuffer(), e.GetResponseBodyBuffer()
+	if request.Length() != 7 || response.Length() != 8 {
+		t.Fatalf("lengths before the destroy = %d, %d", request.Length(), response.Length())
+	}
+	eventHttpFilterInstanceDestroy(instance)
+
+	for name, tc := range map[string]struct {
+		buffer bodyBuffer
+		err    error
+	}{
+		"request":       {buffer: request, err: ErrStreamDestroyed},
+		"response":      {buffer: response, err: ErrStreamDestroyed},
+		"zero request":  {buffer: e.GetRequestBodyBuffer(), err: io.EOF},
+		"zero response": {buffer: ResponseBodyBuffer{}, err: io.EOF},
+	} {
+		// The fake host would modify the bodies or create the body at zero if called.
+		b := tc.buffer
+		b.Append([]byte("append"))
+		b.Prepend([]byte("prepend"))
+		b.Drain(1)
+		b.Replace([]byte("replace"))
+		b.Slices(func([]byte) { t.Errorf("%s: Slices iterates", name) })
+		if length, data := b.Length(), b.Copy(); length != 0 || data == nil || len(data) != 0 {
+			t.Errorf("%s: Length = %d, Copy = %q", name, length, data)
+		}
+		if n, err := b.ReadAt(make([]byte, 1), 0); n != 0 || err != tc.err {
+			t.Errorf("%s: ReadAt = %d, %v, want %v", name, n, err, tc.err)
+		}
+	}
+	if len(bodies) != 2 || string(bodies[requestBufferOf(1)].bytes()) != "request" ||
+		string(bodies[responseBufferOf(1)].bytes()) != "response" {
+		t.Fatalf("Envoy is called after the destroy: %v", bodies)
+	}
+}
+
+func TestBodyBufferBoundaries(t *testing.T) {
+	bodies := fakeBodies(t)
+	var drained []int
+	drain := FakeHost.HttpDrainRequestBodyBuffer
+	FakeHost.HttpDrainRequestBodyBuffer = func(buffer uintptr, length int) {
+		drained = append(drained, length)
+		drain(buffer, length)
+	}
+	event := NewFakeEvent()
+	defer event.End()
+	body := event.RequestBody(10)
+	bodies[10] = &fakeBody{slices: [][]byte{[]byte("hel"), []byte("lo")}}
+
+	// Drain is clamped to the range of [0, Length()], and never calls Envoy with zero or negative.
+	for _, length := range []int{-1, 0, 2, 10, 1} {
+		body.Drain(length)
+	}
+	if !slices.Equal(drained, []int{2, 3}) || body.Length() != 0 {
+		t.Fatalf("drained %v, left %q", drained, bodies[10].bytes())
+	}
+	drained = nil
+	body.Replace(nil)
+	body.Replace([]byte("hello"))
+	if !slices.Equal(drained, nil) || string(bodies[10].bytes()) != "hello" {
+		t.Fatalf("drained %v by Replace, left %q", drained, bodies[10].bytes())
+	}
+
+	for _, tc := range []struct {
+		off  int64
+		size int
+		want string
+		err  error
+	}{
+		{off: 0, size: 3, want: "hel"},
+		{off: 1, size: 4, want: "ello"},
+		{off: 3, size: 4, want: "lo", err: io.EOF},
+		{off: 5, size: 1, err: io.EOF},
+		{off: 6, size: 1, err: io.EOF},
+		{off: -1, size: 1, err: errNegativeOffset},
+	} {
+		p := make([]byte, tc.size)
+		n, err := body.ReadAt(p, tc.off)
+		if string(p[:n]) != tc.want || err != tc.err {
+			t.Errorf("ReadAt(%d bytes, %d) = %q, %v, want %q, %v", tc.size, tc.off, p[:n], err, tc.want, tc.err)
+		}
+	}
+}
+
+func TestInjectEmptyData(t *testing.T) {
+	var injected []bool
+	FakeHost.HttpInjectRequestData = func(_ uintptr, _ unsafe.Pointer, _ int, endOfStream bool) {
+		injected = append(injected, endOfStream)
+	}
+	t.Cleanup(func() { FakeHost.HttpInjectRequestData = nil })
+	filter := newTestHttpFilter(t, bodyHttpFilter{})
+	instance := eventHttpFilterInstanceInit(1, filter)
+	defer eventHttpFilterInstanceDestroy(instance)
+	e := unwrapRawPinHttpFilterInstance(instance).envoy
+
+	// The empty data is only passed to Envoy along with the end of stream.
+	e.InjectRequestData(nil, false)
+	e.InjectRequestData([]byte{}, false)
+	e.InjectRequestData(nil, true)
+	if !slices.Equal(injected, []bool{true}) {
+		t.Fatalf("injected %v, want [true]", injected)
+	}
+}
+
+// withoutFeatures makes HostSupports report the features unsupported during the test.
+func withoutFeatures(t *testing.T, features Feature) {
+	prev := getHostInfo
+	host := prev()
+	host.features &^= features
+	getHostInfo = func() hostInfo { return host }
+	t.Cleanup(func() { getHostInfo = prev })
+}
+
+func TestRequestHeadersApplyFallback(t *testing.T) {
+	var calls []string
+	FakeHost.HttpSetRequestHeader = func(_ uintptr, key unsafe.Pointer, keyLength int,
+		value unsafe.Pointer, valueLength int) {
+		calls = append(calls, fmt.Sprintf("%s=%s",
+			unsafe.String((*byte)(key), keyLength), unsafe.String((*byte)(value), valueLength)))
+	}
+	FakeHost.HttpMutateRequestHeaders = func(uintptr, unsafe.Pointer, int, unsafe.Pointer, int, unsafe.Pointer, int) {
+		calls = append(calls, "batch")
+	}
+	t.Cleanup(func() { FakeHost.HttpSetRequestHeader, FakeHost.HttpMutateRequestHeaders = nil, nil })
+	event := NewFakeEvent()
+	defer event.End()
+	headers := event.RequestHeaders(1)
+
+	m := HeaderMutation{Remove: []string{"a"}, Set: [][2]string{{"b", "1"}}}
+	if err := headers.Apply(m); err != nil || !slices.Equal(calls, []string{"batch"}) {
+		t.Fatalf("Apply with FeatureHeaderBatch = %v with calls %v", err, calls)
+	}
+
+	withoutFeatures(t, FeatureHeaderBatch)
+	calls = nil
+	if err := headers.Apply(m); err != nil || !slices.Equal(calls, []string{"a=", "b=1"}) {
+		t.Fatalf("Apply without FeatureHeaderBatch = %v with calls %v", err, calls)
+	}
+	calls = nil
+	m.Add = [][2]string{{"c", "2"}}
+	if err := headers.Apply(m); err != ErrHeaderAddUnsupported || len(calls) != 0 {
+		t.Fatalf("Apply with Add without FeatureHeaderBatch = %v with calls %v", err, calls)
+	}
+}
@@ -0,0 +1,157 @@
+package envoy
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NewAccessLogger is a function that creates a new AccessLogger that corresponds to each access log configuration
+// in the Envoy configuration. This is a global variable that should be set in the init function in the program once
+// if the module is used as an access logger.
+//
+// The function is only called by the main thread, so it does not need to be thread-safe.
+//
+// `config` is the configuration string that is passed to the module that is set in the Envoy configuration.
+var NewAccessLogger func(config string) AccessLogger
+
+// AccessLogger is an interface that represents a single access logger in the Envoy configuration.
+//
+// This is only created once per access log configuration via the NewAccessLogger function.
+type AccessLogger interface {
+	// Log is called when a stream is completed and logged.
+	// Note that this must be concurrency-safe as it can be called concurrently for multiple streams.
+	//
+	// * `entry` is only valid during this call, and all the HeaderValue(s) retrieved from it as well.
+	// The data must be copied out before returning, e.g. into a struct that is handed to AccessLogBatcher.
+	//
+	// This is called on the Envoy worker thread, so it should not block. Use AccessLogBatcher to ship the logs
+	// to a sink on a background Goroutine.
+	Log(entry AccessLogEntry)
+
+	// Destroy is called when this logger is destroyed. E.g. the configuration is updated and removed from the Envoy.
+	Destroy()
+}
+
+// AccessLogBatcherConfig is the configuration of AccessLogBatcher.
+type AccessLogBatcherConfig struct {
+	// MaxBatchSize is the maximum number of items passed to the flush function at once.
+	// Defaults to 100 if not positive.
+	MaxBatchSize int
+	// FlushInterval is the maximum duration an item waits in the batcher before being flushed.
+	// Defaults to 1 second if not positive.
+	FlushInterval time.Duration
+	// QueueSize is the maximum number of items that can be queued waiting for the flush.
+	// When the queue is full, AccessLogBatcher.Add drops the item instead of blocking the caller.
+	// Defaults to 10 * MaxBatchSize if not positive.
+	QueueSize int
+}
+
+// AccessLogBatcher batches the items added from AccessLogger.Log and flushes them on a background Goroutine,
+// so that the Envoy worker threads are never blocked by the sink, e.g. writing JSON lines to a file or
+// sending them over the network.
+//
+// The batch is flushed when it reaches AccessLogBatcherConfig.MaxBatchSize or AccessLogBatcherConfig.FlushInterval
+// has elapsed since the first item of the batch was added, whichever comes first.
+type AccessLogBatcher[T any] struct {
+	flush         func(batch []T)
+	maxBatchSize  int
+	flushInterval time.Duration
+	queue         chan T
+	dropped       atomic.Uint64
+	closeOnce     sync.Once
+	done          chan struct{}
+}
+
+// NewAccessLogBatcher creates a new AccessLogBatcher and starts the background Goroutine.
+//
+// `flush` is called on the background Goroutine with a non-empty batch. The batch slice is reused after `flush`
+// returns, so it must not be retained. Calls to `flush` are never concurrent.
+//
+// AccessLogBatcher.Close should be called when the batcher is no longer used, typically in AccessLogger.Destroy.
+func NewAccessLogBatcher[T any](config AccessLogBatcherConfig, flush func(batch []T)) *AccessLogBatcher[T] {
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 10 * config.MaxBatchSize
+	}
+	b := &AccessLogBatcher[T]{
+		flush:         flush,
+		maxBatchSize:  config.MaxBatchSize,
+		flushInterval: config.FlushInterval,
+		queue:         make(chan T, config.QueueSize),
+		done:          make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Add adds the item to the batcher without blocking. Returns false if the item is dropped because the queue is full.
+//
+// This must not be called after Close.
+func (b *AccessLogBatcher[T]) Add(item T) bool {
+	select {
+	case b.queue <- item:
+		return true
+	default:
+		b.dropped.Add(1)
+		return false
+	}
+}
+
+// Dropped returns the total number of items dropped by Add because the queue was full.
+func (b *AccessLogBatcher[T]) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// Close flushes all the queued items and stops the background Goroutine. This blocks until the last flush is done.
+// Calling Close multiple times is safe.
+func (b *AccessLogBatcher[T]) Close() {
+	b.closeOnce.Do(func() { close(b.queue) })
+	<-b.done
+}
+
+// run is the background Goroutine of the batcher.
+func (b *AccessLogBatcher[T]) run() {
+	defer close(b.done)
+	batch := make([]T, 0, b.maxBatchSize)
+	timer := time.NewTimer(b.flushInterval)
+	timer.Stop()
+	doFlush := func() {
+		if !timer.Stop() {
+			// Drain the channel in case the timer fired concurrently.
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if len(batch) == 0 {
+			return
+		}
+		b.flush(batch)
+		clear(batch)
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case item, ok := <-b.queue:
+			if !ok {
+				doFlush()
+				return
+			}
+			if len(batch) == 0 {
+				timer.Reset(b.flushInterval)
+			}
+			batch = append(batch, item)
+			if len(batch) >= b.maxBatchSize {
+				doFlush()
+			}
+		case <-timer.C:
+			doFlush()
+		}
+	}
+}
@@ -0,0 +1,216 @@
+//go:build !cgo
+
+package envoy
+
+import (
+	"slices"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// fakeLogEntry is the log entry of a completed stream served by fakeLogEntries.
+type fakeLogEntry struct {
+	// headers are the entries of each header map indexed by the headers type, e.g. abiAccessLogHeadersRequest.
+	headers  [4][][2]string
+	start    time.Time
+	timings  map[AccessLogTiming]time.Duration
+	received uint64
+	sent     uint64
+	code     uint32
+	flags    ResponseFlags
+	metadata map[[2]string]string
+}
+
+// values returns the values of the key in the header map of the type.
+func (e *fakeLogEntry) values(headersType int, key unsafe.Pointer, keyLength int) []string {
+	var values []string
+	for _, kv := range e.headers[headersType] {
+		if kv[0] == unsafe.String((*byte)(key), keyLength) {
+			values = append(values, kv[1])
+		}
+	}
+	return values
+}
+
+// fakeLogEntries sets the access log functions of FakeHost for the test to operate on the returned entries by
+// the pointers passed to the log event.
+func fakeLogEntries(t *testing.T) map[uintptr]*fakeLogEntry {
+	entries := map[uintptr]*fakeLogEntry{}
+	setResult := func(value string, resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) {
+		*(**byte)(resultBufferPtr) = unsafe.StringData(value)
+		*(*int)(resultBufferLengthPtr) = len(value)
+	}
+	prev := FakeHost
+	FakeHost.AccessLogGetHeaderValue = func(logEntryPtr uintptr, headersType int, key unsafe.Pointer, keyLength int,
+		resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer) int {
+		values := entries[logEntryPtr].values(headersType, key, keyLength)
+		if len(values) > 0 {
+			setResult(values[0], resultBufferPtr, resultBufferLengthPtr)
+		}
+		return len(values)
+	}
+	FakeHost.AccessLogGetHeaderValueNth = func(logEntryPtr uintptr, headersType int, key unsafe.Pointer, keyLength int,
+		resultBufferPtr unsafe.Pointer, resultBufferLengthPtr unsafe.Pointer, nth int) {
+		setResult(entries[logEntryPtr].values(headersType, key, keyLength)[nth], resultBufferPtr, resultBufferLengthPtr)
+	}
+	FakeHost.AccessLogGetHeadersCount = func(logEntryPtr uintptr, headersType int) int {
+		return len(entries[logEntryPtr].headers[headersType])
+	}
+	FakeHost.AccessLogGetHeaderNth = func(logEntryPtr uintptr, headersType int, nth int, resultKeyPtr unsafe.Pointer,
+		resultKeyLengthPtr unsafe.Pointer, resultValuePtr unsafe.Pointer, resultValueLengthPtr unsafe.Pointer) {
+		kv := entries[logEntryPtr].headers[headersType][nth]
+		setResult(kv[0], resultKeyPtr, resultKeyLengthPtr)
+		setResult(kv[1], resultValuePtr, resultValueLengthPtr)
+	}
+	FakeHost.AccessLogGetStartTime = func(logEntryPtr uintptr) int64 { return entries[logEntryPtr].start.UnixNano() }
+	FakeHost.AccessLogGetTiming = func(logEntryPtr uintptr, timing int) int64 {
+		d, ok := entries[logEntryPtr].timings[AccessLogTiming(timing)]
+		if !ok {
+			return -1
+		}
+		return int64(d)
+	}
+	FakeHost.AccessLogGetBytesReceived = func(logEntryPtr uintptr) uint64 { return entries[logEntryPtr].received }
+	FakeHost.AccessLogGetBytesSent = func(logEntryPtr uintptr) uint64 { return entries[logEntryPtr].sent }
+	FakeHost.AccessLogGetResponseCode = func(logEntryPtr uintptr) uint32 { return entries[logEntryPtr].code }
+	FakeHost.AccessLogGetResponseFlags = func(logEntryPtr uintptr) uint64 { return uint64(entries[logEntryPtr].flags) }
+	FakeHost.AccessLogGetDynamicMetadata = func(logEntryPtr uintptr, metadataNamespace unsafe.Pointer,
+		metadataNamespaceLength int, key unsafe.Pointer, keyLength int, resultBufferPtr unsafe.Pointer,
+		resultBufferLengthPtr unsafe.Pointer) int {
+		value, ok := entries[logEntryPtr].metadata[[2]string{
+			unsafe.String((*byte)(metadataNamespace), metadataNamespaceLength),
+			unsafe.String((*byte)(key), keyLength),
+		}]
+		if !ok {
+			return 0
+		}
+		setResult(value, resultBufferPtr, resultBufferLengthPtr)
+		return 1
+	}
+	t.Cleanup(func() { FakeHost = prev })
+	return entries
+}
+
+// newTestAccessLogger creates the access logger of the test via the event hook, and returns the function that
+// destroys it.
+func newTestAccessLogger(t *testing.T, logger AccessLogger) (raw uintptr, destroy func()) {
+	prev := NewAccessLogger
+	NewAccessLogger = func(string) AccessLogger { return logger }
+	config := t.Name()
+	raw = eventAccessLoggerInit(uintptr(unsafe.Pointer(unsafe.StringData(config))), len(config))
+	NewAccessLogger = prev
+	return raw, func() { eventAccessLoggerDestroy(raw) }
+}
+
+// accessLogRecord is the copy of AccessLogEntry taken by recordAccessLogger.
+type accessLogRecord struct {
+	path, forwarded string
+	forwardedAll    []string
+	trailers        []string
+	start           time.Time
+	complete        time.Duration
+	upstream        bool
+	received, sent  uint64
+	code            int
+	responded       bool
+	flags           ResponseFlags
+	route           string
+	routeFound      bool
+}
+
+// recordAccessLogger is the AccessLogger that copies the entries into the records and ships them via
+// AccessLogBatcher, which is closed on the destroy.
+type recordAccessLogger struct {
+	batcher *AccessLogBatcher[accessLogRecord]
+}
+
+func (l recordAccessLogger) Log(entry AccessLogEntry) {
+	var r accessLogRecord
+	if path, ok := entry.RequestHeaders().Get(":path"); ok {
+		r.path = path.String()
+	}
+	if forwarded, ok := entry.RequestHeaders().Get("x-forwarded-for"); ok {
+		r.forwarded = forwarded.String()
+	}
+	entry.RequestHeaders().Values("x-forwarded-for", func(value HeaderValue) {
+		r.forwardedAll = append(r.forwardedAll, value.String())
+	})
+	entry.ResponseTrailers().All(func(key, value HeaderValue) {
+		r.trailers = append(r.trailers, key.String()+"="+value.String())
+	})
+	r.start = entry.StartTime()
+	r.complete, _ = entry.Timing(AccessLogTimingRequestComplete)
+	_, r.upstream = entry.Timing(AccessLogTimingFirstUpstreamTxByteSent)
+	r.received, r.sent = entry.BytesReceived(), entry.BytesSent()
+	r.code, r.responded = entry.ResponseCode()
+	r.flags = entry.ResponseFlags()
+	r.route, r.routeFound = entry.DynamicMetadata("envoy.router", "route")
+	l.batcher.Add(r)
+}
+
+func (l recordAccessLogger) Destroy() { l.batcher.Close() }
+
+func TestAccessLogger(t *testing.T) {
+	entries := fakeLogEntries(t)
+	start := time.Unix(1700000000, 123)
+	entries[1] = &fakeLogEntry{
+		headers: [4][][2]string{
+			abiAccessLogHeadersRequest: {
+				{":path", "/foo"}, {"x-forwarded-for", "10.0.0.1"}, {"x-forwarded-for", "10.0.0.2"},
+			},
+			abiAccessLogHeadersResponseTrailers: {{"grpc-status", "0"}, {"grpc-message", "ok"}},
+		},
+		start: start,
+		timings: map[AccessLogTiming]time.Duration{
+			AccessLogTimingFirstUpstreamTxByteSent: time.Millisecond,
+			AccessLogTimingRequestComplete:         5 * time.Millisecond,
+		},
+		received: 10,
+		sent:     20,
+		code:     200,
+		metadata: map[[2]string]string{{"envoy.router", "route"}: "default"},
+	}
+	// The stream reset before reaching the upstream, which has nothing but the start time.
+	entries[2] = &fakeLogEntry{start: start, flags: ResponseFlagLocalReset | ResponseFlagNoRouteFound}
+
+	var records []accessLogRecord
+	logger, destroy := newTestAccessLogger(t, recordAccessLogger{
+		batcher: NewAccessLogBatcher(AccessLogBatcherConfig{}, func(batch []accessLogRecord) {
+			records = append(records, batch...)
+		}),
+	})
+	eventAccessLoggerLog(logger, 1)
+	eventAccessLoggerLog(logger, 2)
+	destroy()
+
+	if len(records) != 2 {
+		t.Fatalf("%d records are flushed", len(records))
+	}
+	got := records[0]
+	if got.path != "/foo" || got.forwarded != "10.0.0.1" ||
+		!slices.Equal(got.forwardedAll, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Fatalf("request headers of the record = %q, %q, %q", got.path, got.forwarded, got.forwardedAll)
+	}
+	if !slices.Equal(got.trailers, []string{"grpc-status=0", "grpc-message=ok"}) {
+		t.Fatalf("response trailers of the record = %q", got.trailers)
+	}
+	if !got.start.Equal(start) || got.complete != 5*time.Millisecond || !got.upstream {
+		t.Fatalf("timings of the record = %s, %s, %t", got.start, got.complete, got.upstream)
+	}
+	if got.received != 10 || got.sent != 20 || got.code != 200 || !got.responded || got.flags != 0 {
+		t.Fatalf("stats of the record = %+v", got)
+	}
+	if got.route != "default" || !got.routeFound {
+		t.Fatalf("route of the record = %q, %t", got.route, got.routeFound)
+	}
+
+	got = records[1]
+	if got.path != "" || got.forwardedAll != nil || got.trailers != nil || got.upstream || got.complete != 0 ||
+		got.responded || got.routeFound {
+		t.Fatalf("record of the reset stream = %+v", got)
+	}
+	if got.flags != ResponseFlagLocalReset|ResponseFlagNoRouteFound {
+		t.Fatalf("flags of the reset stream = %b", got.flags)
+	}
+}
@@ -0,0 +1,203 @@
+package envoy
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+// BodyLimitAction is the action taken when the body of a stream exceeds the limit of BodyLimit.
+type BodyLimitAction int
+
+const (
+	// BodyLimitLocalReply sends the local reply via EnvoyFilterInstance.SendResponse, which is 413 Content Too Large
+	// for the request and 502 Bad Gateway for the response.
+	BodyLimitLocalReply BodyLimitAction = iota
+	// BodyLimitPassThrough stops delivering the body events of the direction to the HttpFilterInstance, and lets
+	// the body pass through including the part buffered so far. The headers are already modified by then, so this is
+	// not suitable for the HttpFilterInstance changing the headers for the body it modifies, e.g. content-encoding.
+	BodyLimitPassThrough
+)
+
+// String implements fmt.Stringer.
+func (a BodyLimitAction) String() string {
+	switch a {
+	case BodyLimitLocalReply:
+		return "local_reply"
+	case BodyLimitPassThrough:
+		return "pass_through"
+	default:
+		return "unknown"
+	}
+}
+
+// BodyLimit is the configuration of the limit of the body size of an HttpFilter.
+//
+// When the HttpFilterInstance buffers the body with RequestBodyStatusStopIterationAndBuffer or
+// ResponseBodyStatusStopIterationAndBuffer, Envoy replies 500 to the client if the buffer limit of Envoy is reached.
+// The limit is checked by the SDK before delivering each body event to the HttpFilterInstance instead, and Action is
+// taken when the total size of the body received in the direction exceeds the limit, so that the HttpFilterInstance
+// never sees a body larger than the limit. The limit should be lower than the buffer limit of Envoy, i.e.
+// per_connection_buffer_limit_bytes of the listener and the cluster.
+//
+// The limit of a direction only applies if the HttpFilterInstance implements the body handler of the direction.
+type BodyLimit struct {
+	// MaxRequestBodySize and MaxResponseBodySize are the limits of the request and the response body sizes.
+	// Zero disables the limit of the direction.
+	MaxRequestBodySize, MaxResponseBodySize int
+	// Action is the action taken when the limit is exceeded.
+	Action BodyLimitAction
+	// CheckContentLength takes Action in the headers event if the content-length header exceeds the limit, before
+	// the headers handler of the HttpFilterInstance is called. With BodyLimitLocalReply, the headers handler is not
+	// called at all, and with BodyLimitPassThrough, the headers handler is called but the body events are not.
+	CheckContentLength bool
+	// LocalReplyHeaders and LocalReplyBody are the headers and the body of the local reply of BodyLimitLocalReply.
+	// LocalReplyBody defaults to "request body too large" or "response body too large".
+	LocalReplyHeaders [][2]string
+	LocalReplyBody    string
+}
+
+// BodyLimitProvider is an optional interface that can be implemented by HttpFilter to limit the body size of
+// its HttpFilterInstances. This is detected when the HttpFilter is created.
+type BodyLimitProvider interface {
+	// BodyLimit returns the configuration of the limit. This is called once when the HttpFilter is created.
+	BodyLimit() BodyLimit
+}
+
+// BodyLimitStats is the counters of the streams of an HttpFilter exceeding the limit of BodyLimit. The counters are
+// also set to the go_sdk.body_limit.<name>.local_replies, .early_local_replies and .pass_throughs gauges if the
+// host supports FeatureStats, where <name> is the stat name of the HttpFilter. See StatNameProvider.
+type BodyLimitStats struct {
+	// LocalReplies is the number of the directions of the streams replied locally by BodyLimitLocalReply, of which
+	// EarlyLocalReplies are by the content-length header.
+	LocalReplies, EarlyLocalReplies uint64
+	// PassThroughs is the number of the directions of the streams passed through by BodyLimitPassThrough.
+	PassThroughs uint64
+}
+
+// BodyLimitState is the state of the limit of the body size of a stream returned by
+// EnvoyFilterInstance.BodyLimitState.
+type BodyLimitState struct {
+	// Limit is the configuration of the HttpFilter of the stream, which is zero if it doesn't implement
+	// BodyLimitProvider.
+	Limit BodyLimit
+	// RequestBodySize and ResponseBodySize are the sizes of the bodies received so far, or the content-length if the
+	// limit is exceeded by it.
+	RequestBodySize, ResponseBodySize int
+	// RequestExceeded and ResponseExceeded are true if the limit of the direction is exceeded, after which the body
+	// events of the direction are not delivered to the HttpFilterInstance.
+	RequestExceeded, ResponseExceeded bool
+	// Stats is the counters of the HttpFilter of the stream.
+	Stats BodyLimitStats
+}
+
+// bodyLimitCounters is BodyLimitStats of an HttpFilter, which is updated by the streams concurrently.
+type bodyLimitCounters struct {
+	localReplies, earlyLocalReplies, passThroughs atomic.Uint64
+}
+
+func (c *bodyLimitCounters) stats() BodyLimitStats {
+	return BodyLimitStats{
+		LocalReplies:      c.localReplies.Load(),
+		EarlyLocalReplies: c.earlyLocalReplies.Load(),
+		PassThroughs:      c.passThroughs.Load(),
+	}
+}
+
+// bodyLimitStream is the state of the limit of the body size of a direction of a stream.
+type bodyLimitStream struct {
+	size     int
+	exceeded bool
+}
+
+// BodyLimitState returns the state of the limit of the body size of the stream. See BodyLimitProvider.
+// This must be called in the event callbacks.
+func (c *envoyFilterInstance) BodyLimitState() BodyLimitState {
+	return BodyLimitState{
+		Limit:            c.filter.bodyLimit,
+		RequestBodySize:  c.bodyLimits[pauseRequest].size,
+		ResponseBodySize: c.bodyLimits[pauseResponse].size,
+		RequestExceeded:  c.bodyLimits[pauseRequest].exceeded,
+		ResponseExceeded: c.bodyLimits[pauseResponse].exceeded,
+		Stats:            c.filter.bodyLimitCounters.stats(),
+	}
+}
+
+// maxBodySize returns the limit of the direction, or zero if not limited.
+func (l *BodyLimit) maxBodySize(d pauseDirection) int {
+	if d == pauseResponse {
+		return l.MaxResponseBodySize
+	}
+	return l.MaxRequestBodySize
+}
+
+// limitHeaders is called in the headers event of the direction with the content-length header before calling the
+// headers handler, and returns false if the local reply is sent, in which case the handler must not be called.
+func (c *envoyFilterInstance) limitHeaders(d pauseDirection, contentLength string) bool {
+	limit := &c.filter.bodyLimit
+	maxSize := limit.maxBodySize(d)
+	if maxSize <= 0 || !limit.CheckContentLength || contentLength == "" {
+		return true
+	}
+	n, err := strconv.ParseInt(contentLength, 10, 64)
+	if err != nil || n <= int64(maxSize) {
+		return true
+	}
+	c.bodyLimits[d] = bodyLimitStream{size: int(min(n, math.MaxInt)), exceeded: true}
+	if limit.Action == BodyLimitPassThrough {
+		c.countBodyLimit(&c.filter.bodyLimitCounters.passThroughs, "pass_throughs")
+		return true
+	}
+	c.countBodyLimit(&c.filter.bodyLimitCounters.earlyLocalReplies, "early_local_replies")
+	c.bodyLimitLocalReply(d)
+	return false
+}
+
+// limitBody is called in the body event of the direction with the length of the chunk before calling the body
+// handler, and returns false if the handler must not be called. Then the event returns the Continue status if pass
+// is true, or the Stop status otherwise.
+func (c *envoyFilterInstance) limitBody(d pauseDirection, length func() int) (deliver, pass bool) {
+	limit := &c.filter.bodyLimit
+	maxSize := limit.maxBodySize(d)
+	s := &c.bodyLimits[d]
+	switch {
+	case maxSize <= 0:
+		return true, false
+	case s.exceeded:
+		return false, limit.Action == BodyLimitPassThrough
+	}
+	if s.size += length(); s.size <= maxSize {
+		return true, false
+	}
+	s.exceeded = true
+	if limit.Action == BodyLimitPassThrough {
+		c.countBodyLimit(&c.filter.bodyLimitCounters.passThroughs, "pass_throughs")
+		return false, true
+	}
+	c.bodyLimitLocalReply(d)
+	return false, false
+}
+
+// bodyLimitLocalReply sends the local reply of BodyLimitLocalReply for the direction.
+func (c *envoyFilterInstance) bodyLimitLocalReply(d pauseDirection) {
+	limit := &c.filter.bodyLimit
+	status, body := http.StatusRequestEntityTooLarge, "request body too large"
+	if d == pauseResponse {
+		status, body = http.StatusBadGateway, "response body too large"
+	}
+	if limit.LocalReplyBody != "" {
+		body = limit.LocalReplyBody
+	}
+	c.countBodyLimit(&c.filter.bodyLimitCounters.localReplies, "local_replies")
+	c.SendResponse(status, limit.LocalReplyHeaders, []byte(body))
+}
+
+// countBodyLimit increments the counter and sets the gauge of the name. See BodyLimitStats.
+func (c *envoyFilterInstance) countBodyLimit(counter *atomic.Uint64, name string) {
+	n := counter.Add(1)
+	if HostSupports(FeatureStats) {
+		name = "go_sdk.body_limit." + c.filter.statName + "." + name
+		hostStatsSetGauge(stringPtr(name), len(name), n)
+	}
+}
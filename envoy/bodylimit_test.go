@@ -0,0 +1,218 @@
+//go:build !cgo
+
+package envoy
+
+import (
+	"slices"
+	"testing"
+)
+
+// limitHttpFilter is the HttpFilter implementing BodyLimitProvider, whose instances record the calls to the handlers.
+type limitHttpFilter struct {
+	limit BodyLimit
+	// calls is the calls to the handlers, which are the headers or the body of the chunks.
+	calls *[]string
+}
+
+func (f limitHttpFilter) BodyLimit() BodyLimit { return f.limit }
+
+func (limitHttpFilter) StatName() string { return "limit" }
+
+func (f limitHttpFilter) NewInstance(EnvoyFilterInstance) HttpFilterInstance {
+	return &limitHttpFilterInstance{calls: f.calls}
+}
+
+func (limitHttpFilter) Destroy() {}
+
+type limitHttpFilterInstance struct{ calls *[]string }
+
+func (*limitHttpFilterInstance) Destroy() {}
+
+func (i *limitHttpFilterInstance) RequestHeaders(RequestHeaders, bool) RequestHeadersStatus {
+	*i.calls = append(*i.calls, "headers")
+	return HeadersStatusContinue
+}
+
+func (i *limitHttpFilterInstance) ResponseHeaders(ResponseHeaders, bool) ResponseHeadersStatus {
+	*i.calls = append(*i.calls, "headers")
+	return ResponseHeadersStatusContinue
+}
+
+func (i *limitHttpFilterInstance) RequestBody(body RequestBodyBuffer, _ bool) RequestBodyStatus {
+	*i.calls = append(*i.calls, string(body.Copy()))
+	return RequestBodyStatusContinue
+}
+
+func (i *limitHttpFilterInstance) ResponseBody(body ResponseBodyBuffer, _ bool) ResponseBodyStatus {
+	*i.calls = append(*i.calls, string(body.Copy()))
+	return ResponseBodyStatusContinue
+}
+
+// limitDirection is the events and the statuses of a direction of the stream.
+type limitDirection struct {
+	name       string
+	headers    func(instance uintptr) int
+	body       func(instance uintptr, buffer uintptr) int
+	stopHeader int
+	stopBody   int
+	reply      uint32
+	// state returns the size and whether the limit is exceeded in the direction.
+	state func(s BodyLimitState) (int, bool)
+}
+
+var limitDirections = []limitDirection{
+	{
+		name:    "request",
+		headers: func(instance uintptr) int { return eventHttpFilterInstanceRequestHeaders(instance, 1, false) },
+		body: func(instance, buffer uintptr) int {
+			return eventHttpFilterInstanceRequestBody(instance, buffer, false)
+		},
+		stopHeader: int(RequestHeadersStatusStopIteration),
+		stopBody:   int(RequestBodyStatusStopIterationAndBuffer),
+		reply:      413,
+		state:      func(s BodyLimitState) (int, bool) { return s.RequestBodySize, s.RequestExceeded },
+	},
+	{
+		name:    "response",
+		headers: func(instance uintptr) int { return eventHttpFilterInstanceResponseHeaders(instance, 1, false) },
+		body: func(instance, buffer uintptr) int {
+			return eventHttpFilterInstanceResponseBody(instance, buffer, false)
+		},
+		stopHeader: int(ResponseHeadersStatusStopIteration),
+		stopBody:   int(ResponseBodyStatusStopIterationAndBuffer),
+		reply:      502,
+		state:      func(s BodyLimitState) (int, bool) { return s.ResponseBodySize, s.ResponseExceeded },
+	},
+}
+
+func TestBodyLimit(t *testing.T) {
+	for _, d := range limitDirections {
+		for _, action := range []BodyLimitAction{BodyLimitLocalReply, BodyLimitPassThrough} {
+			t.Run(d.name+"/"+action.String(), func(t *testing.T) {
+				replies := fakeLocalReplies(t)
+				bodies := fakeBodies(t)
+				gauges := fakeGauges(t)
+				var calls []string
+				filter := newTestHttpFilter(t, limitHttpFilter{
+					limit: BodyLimit{MaxRequestBodySize: 10, MaxResponseBodySize: 10, Action: action},
+					calls: &calls,
+				})
+				instance := eventHttpFilterInstanceInit(1, filter)
+				defer eventHttpFilterInstanceDestroy(instance)
+				e := unwrapRawPinHttpFilterInstance(instance).envoy
+
+				// The body up to the limit is delivered.
+				for i, chunk := range []string{"12345", "67890"} {
+					bodies[uintptr(10+i)] = &fakeBody{slices: [][]byte{[]byte(chunk)}}
+					if status := d.body(instance, uintptr(10+i)); status != 0 {
+						t.Fatalf("status of %q = %d, want Continue", chunk, status)
+					}
+				}
+				// Then the chunk exceeding the limit and the rest are not.
+				wantStatus, wantReply, wantGauge := 0, uint32(0), "go_sdk.body_limit.limit.pass_throughs"
+				if action == BodyLimitLocalReply {
+					wantStatus, wantReply, wantGauge = d.stopBody, d.reply, "go_sdk.body_limit.limit.local_replies"
+				}
+				for i, chunk := range []string{"x", "rest"} {
+					bodies[uintptr(20+i)] = &fakeBody{slices: [][]byte{[]byte(chunk)}}
+					if status := d.body(instance, uintptr(20+i)); status != wantStatus {
+						t.Fatalf("status of %q = %d, want %d", chunk, status, wantStatus)
+					}
+				}
+				if !slices.Equal(calls, []string{"12345", "67890"}) {
+					t.Fatalf("delivered %q, want the body up to the limit", calls)
+				}
+				if got := replies()[1]; got != wantReply {
+					t.Fatalf("local reply = %d, want %d", got, wantReply)
+				}
+
+				state := e.BodyLimitState()
+				if size, exceeded := d.state(state); size != 11 || !exceeded {
+					t.Fatalf("state = %+v", state)
+				}
+				if state.Stats.LocalReplies+state.Stats.PassThroughs != 1 || state.Stats.EarlyLocalReplies != 0 ||
+					len(gauges) != 1 || gauges[wantGauge] != 1 {
+					t.Fatalf("stats = %+v, gauges = %v", state.Stats, gauges)
+				}
+			})
+		}
+	}
+}
+
+func TestBodyLimitContentLength(t *testing.T) {
+	for _, d := range limitDirections {
+		for _, tc := range []struct {
+			name          string
+			action        BodyLimitAction
+			check         bool
+			contentLength string
+			// exceeded is true if the limit is exceeded by the content-length.
+			exceeded bool
+		}{
+			{name: "local reply", check: true, contentLength: "11", exceeded: true},
+			{name: "pass through", action: BodyLimitPassThrough, check: true, contentLength: "11", exceeded: true},
+			{name: "within limit", check: true, contentLength: "10"},
+			{name: "invalid", check: true, contentLength: "ten"},
+			{name: "unchecked", contentLength: "11"},
+		} {
+			t.Run(d.name+"/"+tc.name, func(t *testing.T) {
+				replies := fakeLocalReplies(t)
+				bodies := fakeBodies(t)
+				fakeHeaders(t, map[string]string{"content-length": tc.contentLength})
+				var calls []string
+				filter := newTestHttpFilter(t, limitHttpFilter{
+					limit: BodyLimit{
+						MaxRequestBodySize:  10,
+						MaxResponseBodySize: 10,
+						Action:              tc.action,
+						CheckContentLength:  tc.check,
+					},
+					calls: &calls,
+				})
+				instance := eventHttpFilterInstanceInit(1, filter)
+				defer eventHttpFilterInstanceDestroy(instance)
+				e := unwrapRawPinHttpFilterInstance(instance).envoy
+
+				earlyReply := tc.exceeded && tc.action == BodyLimitLocalReply
+				wantStatus, wantReply, wantCalls := 0, uint32(0), []string{"headers", "body"}
+				switch {
+				case earlyReply:
+					wantStatus, wantReply, wantCalls = d.stopHeader, d.reply, nil
+				case tc.exceeded:
+					wantCalls = []string{"headers"}
+				}
+				if status := d.headers(instance); status != wantStatus {
+					t.Fatalf("status of headers = %d, want %d", status, wantStatus)
+				}
+				if !earlyReply {
+					bodies[10] = &fakeBody{slices: [][]byte{[]byte("body")}}
+					if status := d.body(instance, 10); status != 0 {
+						t.Fatalf("status of body = %d, want Continue", status)
+					}
+				}
+				if !slices.Equal(calls, wantCalls) {
+					t.Fatalf("calls = %q, want %q", calls, wantCalls)
+				}
+				if got := replies()[1]; got != wantReply {
+					t.Fatalf("local reply = %d, want %d", got, wantReply)
+				}
+
+				state := e.BodyLimitState()
+				size, exceeded := d.state(state)
+				if exceeded != tc.exceeded || exceeded && size != 11 {
+					t.Fatalf("state = %+v", state)
+				}
+				wantStats := BodyLimitStats{}
+				switch {
+				case earlyReply:
+					wantStats = BodyLimitStats{LocalReplies: 1, EarlyLocalReplies: 1}
+				case tc.exceeded:
+					wantStats = BodyLimitStats{PassThroughs: 1}
+				}
+				if state.Stats != wantStats {
+					t.Fatalf("stats = %+v, want %+v", state.Stats, wantStats)
+				}
+			})
+		}
+	}
+}
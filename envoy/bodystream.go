@@ -0,0 +1,265 @@
+package envoy
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+const (
+	// bodyReaderHighWatermark is the number of the bytes buffered in BodyReader above which reading the body from
+	// the peer is disabled until the buffered bytes are read down to bodyReaderLowWatermark.
+	bodyReaderHighWatermark = 1 << 20
+	bodyReaderLowWatermark  = bodyReaderHighWatermark / 4
+	// bodyReaderMaxBuffered is the number of the bytes buffered in BodyReader above which the reader is closed with
+	// ErrBodyReaderFull if the host doesn't support FeatureFlowControl, i.e. reading can't be disabled.
+	bodyReaderMaxBuffered = 4 * bodyReaderHighWatermark
+)
+
+var (
+	// ErrBodyReaderFull is returned by BodyReader.Read when the reader is closed because the reader falls behind the
+	// body by more than 4MiB without FeatureFlowControl.
+	ErrBodyReaderFull = errors.New("envoy: body reader buffer is full")
+
+	// errBodyWriterClosed is returned by BodyWriter.Write after Close.
+	errBodyWriterClosed = errors.New("envoy: write to closed BodyWriter")
+)
+
+// BodyReader is an io.Reader over the request or response body streamed through the event callbacks. This lets the
+// standard decoders such as encoding/json, encoding/xml and compress/gzip consume the body on a Goroutine without
+// buffering the entire body. Read blocks until more data or the end of stream arrives.
+//
+// The body is fed by calling FeedRequestBody or FeedResponseBody in the event callback, which moves the data out of
+// Envoy. The output is typically written back to Envoy via BodyWriter:
+//
+//	func (h *myHttpFilterInstance) RequestHeaders(headers envoy.RequestHeaders, endOfStream bool) envoy.RequestHeadersStatus {
+//		if endOfStream {
+//			return envoy.HeadersStatusContinue
+//		}
+//		headers.Remove("content-length")
+//		h.body = envoy.NewRequestBodyReader(h.envoyFilter)
+//		go func() {
+//			w := envoy.NewRequestBodyWriter(h.envoyFilter)
+//			transform(w, h.body)
+//			w.Close()
+//			h.body.Close() // Discards the rest of the body if transform returns early.
+//		}()
+//		return envoy.HeadersStatusContinue
+//	}
+//
+//	func (h *myHttpFilterInstance) RequestBody(body envoy.RequestBodyBuffer, endOfStream bool) envoy.RequestBodyStatus {
+//		return h.body.FeedRequestBody(body, endOfStream)
+//	}
+//
+//	func (h *myHttpFilterInstance) Destroy() {
+//		if h.body != nil {
+//			h.body.CloseWithError(envoy.ErrStreamDestroyed)
+//		}
+//	}
+//
+// While more than 1MiB is buffered in the reader, reading the body from the peer is disabled via
+// EnvoyFilterInstance.ReadDisableRequest or ReadDisableResponse so that a slow reader applies the backpressure.
+// If the host doesn't support FeatureFlowControl, the buffer is capped instead: when more than 4MiB is buffered, the
+// rest of the body is discarded and Read returns ErrBodyReaderFull, after which the Goroutine reading the body should
+// end the stream, e.g. by EnvoyFilterInstance.SendResponse with 413 Payload Too Large.
+type BodyReader struct {
+	mu sync.Mutex
+	// cond is signaled when a chunk or the end of stream arrives, or the reader is closed.
+	cond   sync.Cond
+	chunks [][]byte
+	// buffered is the total number of the bytes in chunks.
+	buffered int
+	eos      bool
+	// err is the error returned by Read after CloseWithError.
+	err error
+	// readDisable is EnvoyFilterInstance.ReadDisableRequest or ReadDisableResponse.
+	readDisable  func(disable bool)
+	readDisabled bool
+	// limit is bodyReaderMaxBuffered if the host doesn't support FeatureFlowControl, or zero otherwise.
+	limit int
+}
+
+// NewRequestBodyReader creates a new BodyReader fed by FeedRequestBody.
+func NewRequestBodyReader(e EnvoyFilterInstance) *BodyReader {
+	return newBodyReader(e.ReadDisableRequest)
+}
+
+// NewResponseBodyReader creates a new BodyReader fed by FeedResponseBody.
+func NewResponseBodyReader(e EnvoyFilterInstance) *BodyReader {
+	return newBodyReader(e.ReadDisableResponse)
+}
+
+func newBodyReader(readDisable func(disable bool)) *BodyReader {
+	r := &BodyReader{readDisable: readDisable}
+	if !HostSupports(FeatureFlowControl) {
+		r.limit = bodyReaderMaxBuffered
+	}
+	r.cond.L = &r.mu
+	return r
+}
+
+// FeedRequestBody moves the data in the buffer to the reader, and returns the status that
+// RequestBodyHandler.RequestBody must return. This must be called in RequestBodyHandler.RequestBody.
+func (r *BodyReader) FeedRequestBody(body RequestBodyBuffer, endOfStream bool) RequestBodyStatus {
+	data := body.Copy()
+	body.Drain(len(data))
+	r.feed(data, endOfStream)
+	return RequestBodyStatusStopIterationAndBuffer
+}
+
+// FeedResponseBody moves the data in the buffer to the reader, and returns the status that
+// ResponseBodyHandler.ResponseBody must return. This must be called in ResponseBodyHandler.ResponseBody.
+func (r *BodyReader) FeedResponseBody(body ResponseBodyBuffer, endOfStream bool) ResponseBodyStatus {
+	data := body.Copy()
+	body.Drain(len(data))
+	r.feed(data, endOfStream)
+	return ResponseBodyStatusStopIterationAndBuffer
+}
+
+// feed appends the data to the reader. The data is discarded if the reader is closed.
+func (r *BodyReader) feed(data []byte, endOfStream bool) {
+	r.mu.Lock()
+	if r.err != nil {
+		r.mu.Unlock()
+		return
+	}
+	if r.limit > 0 && r.buffered+len(data) > r.limit {
+		// Without the flow control, the buffer would grow without limit if the reader is slower than the peer.
+		r.err = ErrBodyReaderFull
+		r.chunks, r.buffered = nil, 0
+		r.cond.Broadcast()
+		r.mu.Unlock()
+		return
+	}
+	if len(data) > 0 {
+		r.chunks = append(r.chunks, data)
+		r.buffered += len(data)
+	}
+	r.eos = r.eos || endOfStream
+	disable := r.limit == 0 && !r.readDisabled && r.buffered > bodyReaderHighWatermark
+	r.readDisabled = r.readDisabled || disable
+	r.cond.Broadcast()
+	r.mu.Unlock()
+	if disable {
+		r.readDisable(true)
+	}
+}
+
+// Read implements io.Reader. This returns io.EOF after all the data is read and the end of stream is fed, or the
+// error passed to CloseWithError.
+func (r *BodyReader) Read(p []byte) (n int, err error) {
+	r.mu.Lock()
+	for len(r.chunks) == 0 && !r.eos && r.err == nil {
+		r.cond.Wait()
+	}
+	switch {
+	case r.err != nil:
+		err = r.err
+	case len(r.chunks) == 0:
+		err = io.EOF
+	default:
+		n = copy(p, r.chunks[0])
+		if r.chunks[0] = r.chunks[0][n:]; len(r.chunks[0]) == 0 {
+			r.chunks[0] = nil
+			r.chunks = r.chunks[1:]
+		}
+		r.buffered -= n
+	}
+	enable := r.readDisabled && r.buffered <= bodyReaderLowWatermark
+	r.readDisabled = r.readDisabled && !enable
+	r.mu.Unlock()
+	if enable {
+		r.readDisable(false)
+	}
+	return
+}
+
+// Close implements io.Closer, and is the same as CloseWithError(nil).
+func (r *BodyReader) Close() error {
+	r.CloseWithError(nil)
+	return nil
+}
+
+// CloseWithError closes the reader. The subsequent and blocked Reads return the error, or io.ErrClosedPipe if nil,
+// and the data fed afterwards is discarded. This is supposed to be called in HttpFilterInstance.Destroy with
+// ErrStreamDestroyed to unblock the Goroutine reading the body of the stream that is destroyed before the end.
+func (r *BodyReader) CloseWithError(err error) {
+	if err == nil {
+		err = io.ErrClosedPipe
+	}
+	r.mu.Lock()
+	if r.err != nil {
+		r.mu.Unlock()
+		return
+	}
+	r.err = err
+	r.chunks, r.buffered = nil, 0
+	enable := r.readDisabled
+	r.readDisabled = false
+	r.cond.Broadcast()
+	r.mu.Unlock()
+	if enable {
+		r.readDisable(false)
+	}
+}
+
+// BodyWriter is an io.WriteCloser that emits the output of a streaming transformation as the request or response body
+// via EnvoyFilterInstance.InjectRequestData or InjectResponseData, and is supposed to be used along with BodyReader.
+// Close sends the end of stream. This can be used from any Goroutine.
+//
+// Each Write is a call to Envoy, so wrapping this with bufio.Writer is recommended for the small writes. Write returns
+// errors.ErrUnsupported if the host doesn't support FeatureDataInjection.
+type BodyWriter struct {
+	mu     sync.Mutex
+	closed bool
+	// inject is EnvoyFilterInstance.InjectRequestData or InjectResponseData.
+	inject func(data []byte, endOfStream bool)
+	// err is EnvoyFilterInstance.Err.
+	err func() error
+}
+
+// NewRequestBodyWriter creates a new BodyWriter that emits the request body.
+func NewRequestBodyWriter(e EnvoyFilterInstance) *BodyWriter {
+	return &BodyWriter{inject: e.InjectRequestData, err: e.Err}
+}
+
+// NewResponseBodyWriter creates a new BodyWriter that emits the response body.
+func NewResponseBodyWriter(e EnvoyFilterInstance) *BodyWriter {
+	return &BodyWriter{inject: e.InjectResponseData, err: e.Err}
+}
+
+// Write implements io.Writer. This returns ErrStreamDestroyed if the stream is already destroyed.
+func (w *BodyWriter) Write(p []byte) (int, error) {
+	if !HostSupports(FeatureDataInjection) {
+		return 0, errors.ErrUnsupported
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, errBodyWriterClosed
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	w.inject(p, false)
+	if err := w.err(); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close implements io.Closer, and sends the end of stream. Calling this more than once is a no-op. This returns
+// ErrStreamDestroyed if the stream is already destroyed.
+func (w *BodyWriter) Close() error {
+	if !HostSupports(FeatureDataInjection) {
+		return errors.ErrUnsupported
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	w.inject(nil, true)
+	return w.err()
+}
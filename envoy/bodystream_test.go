@@ -0,0 +1,141 @@
+//go:build !cgo
+
+package envoy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"unsafe"
+)
+
+// fakeReadDisable returns the readDisable of BodyReader recording the calls.
+func fakeReadDisable() (func(disable bool), *[]bool) {
+	var calls []bool
+	return func(disable bool) { calls = append(calls, disable) }, &calls
+}
+
+func TestBodyReaderFeed(t *testing.T) {
+	bodies := fakeBodies(t)
+	var r *BodyReader
+	filter := newTestHttpFilter(t, bodyHttpFilter{
+		requestBody: func(e EnvoyFilterInstance, body RequestBodyBuffer, endOfStream bool) RequestBodyStatus {
+			if r == nil {
+				r = NewRequestBodyReader(e)
+			}
+			return r.FeedRequestBody(body, endOfStream)
+		},
+	})
+	instance := eventHttpFilterInstanceInit(1, filter)
+	defer eventHttpFilterInstanceDestroy(instance)
+	bodies[10] = &fakeBody{slices: [][]byte{[]byte("hello "), []byte("world")}}
+	bodies[20] = &fakeBody{slices: [][]byte{[]byte("!")}}
+	status := eventHttpFilterInstanceRequestBody(instance, 10, false)
+	if status != int(RequestBodyStatusStopIterationAndBuffer) {
+		t.Fatalf("status = %d, want StopIterationAndBuffer", status)
+	}
+	eventHttpFilterInstanceRequestBody(instance, 20, true)
+	// The data is moved out of Envoy.
+	if len(bodies[10].bytes()) != 0 || len(bodies[20].bytes()) != 0 {
+		t.Fatalf("the data is left in Envoy: %q, %q", bodies[10].bytes(), bodies[20].bytes())
+	}
+	got, err := io.ReadAll(r)
+	if err != nil || string(got) != "hello world!" {
+		t.Fatalf("ReadAll = %q, %v", got, err)
+	}
+}
+
+func TestBodyReaderFlowControl(t *testing.T) {
+	readDisable, calls := fakeReadDisable()
+	r := newBodyReader(readDisable)
+	chunk := make([]byte, bodyReaderHighWatermark/2)
+	r.feed(chunk, false)
+	r.feed(chunk, false)
+	if len(*calls) != 0 {
+		t.Fatalf("readDisable = %v at the high watermark", *calls)
+	}
+	r.feed([]byte("x"), false)
+	r.feed([]byte("x"), false)
+	if !slices.Equal(*calls, []bool{true}) {
+		t.Fatalf("readDisable = %v above the high watermark, want [true]", *calls)
+	}
+	// Reading is enabled again when the reader reads down to the low watermark.
+	buf := make([]byte, bodyReaderHighWatermark/2)
+	r.Read(buf)
+	if !slices.Equal(*calls, []bool{true}) {
+		t.Fatalf("readDisable = %v above the low watermark, want [true]", *calls)
+	}
+	r.Read(buf[:bodyReaderHighWatermark/4+2])
+	if !slices.Equal(*calls, []bool{true, false}) {
+		t.Fatalf("readDisable = %v at the low watermark, want [true false]", *calls)
+	}
+
+	// Closing the disabled reader enables reading so that the rest of the body flows.
+	r.feed(chunk, false)
+	r.feed(chunk, false)
+	r.CloseWithError(ErrStreamDestroyed)
+	if !slices.Equal(*calls, []bool{true, false, true, false}) {
+		t.Fatalf("readDisable = %v after closed, want [true false true false]", *calls)
+	}
+	if _, err := r.Read(buf); err != ErrStreamDestroyed {
+		t.Fatalf("Read after closed = %v, want ErrStreamDestroyed", err)
+	}
+}
+
+func TestBodyReaderFull(t *testing.T) {
+	withoutFeatures(t, FeatureFlowControl)
+	readDisable, calls := fakeReadDisable()
+	r := newBodyReader(readDisable)
+	chunk := make([]byte, bodyReaderMaxBuffered/2)
+	r.feed(chunk, false)
+	r.feed(chunk, false)
+	if n, err := r.Read(make([]byte, 1)); n != 1 || err != nil {
+		t.Fatalf("Read at the limit = %d, %v", n, err)
+	}
+	r.feed([]byte("xx"), false)
+	if _, err := r.Read(make([]byte, 1)); err != ErrBodyReaderFull {
+		t.Fatalf("Read above the limit = %v, want ErrBodyReaderFull", err)
+	}
+	// The rest of the body is discarded.
+	r.feed(chunk, true)
+	if r.buffered != 0 || len(*calls) != 0 {
+		t.Fatalf("buffered = %d and readDisable = %v after full", r.buffered, *calls)
+	}
+}
+
+func TestBodyWriter(t *testing.T) {
+	var injected bytes.Buffer
+	var ended bool
+	FakeHost.HttpInjectRequestData = func(_ uintptr, data unsafe.Pointer, length int, endOfStream bool) {
+		injected.Write(unsafe.Slice((*byte)(data), length))
+		ended = ended || endOfStream
+	}
+	t.Cleanup(func() { FakeHost.HttpInjectRequestData = nil })
+	filter := newTestHttpFilter(t, bodyHttpFilter{})
+	instance := eventHttpFilterInstanceInit(1, filter)
+	e := unwrapRawPinHttpFilterInstance(instance).envoy
+
+	w := NewRequestBodyWriter(e)
+	if _, err := io.WriteString(w, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil || injected.String() != "hello" || !ended {
+		t.Fatalf("Close = %v with %q injected, ended %t", err, injected.String(), ended)
+	}
+	if _, err := w.Write([]byte("x")); !errors.Is(err, errBodyWriterClosed) {
+		t.Fatalf("Write after Close = %v", err)
+	}
+
+	w = NewRequestBodyWriter(e)
+	eventHttpFilterInstanceDestroy(instance)
+	if _, err := w.Write([]byte("x")); err != ErrStreamDestroyed {
+		t.Fatalf("Write after the destroy = %v, want ErrStreamDestroyed", err)
+	}
+
+	withoutFeatures(t, FeatureDataInjection)
+	if _, err := w.Write([]byte("x")); err != errors.ErrUnsupported {
+		t.Fatalf("Write without FeatureDataInjection = %v, want errors.ErrUnsupported", err)
+	}
+}
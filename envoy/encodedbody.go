@@ -0,0 +1,561 @@
+package envoy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxDecodedBodySize is the default of EncodedBodyConfig.MaxDecodedSize.
+const DefaultMaxDecodedBodySize = 64 << 20
+
+var (
+	// ErrDecodedBodyTooLarge is returned by EncodedBody when the decoded body exceeds
+	// EncodedBodyConfig.MaxDecodedSize, which protects the module from the decompression bombs.
+	ErrDecodedBodyTooLarge = errors.New("envoy: decoded body is too large")
+
+	errEncodedBodyTrailingData = errors.New("envoy: trailing data after the encoded body")
+	errEncodedBodyMode         = errors.New("envoy: method is not allowed in the EncodedBodyMode")
+	errDecoderClosed           = errors.New("envoy: EncodedBody is closed")
+)
+
+// Codec is a content-coding of the content-encoding header, e.g. gzip. gzip (and x-gzip) and deflate are built in,
+// and the others are added by RegisterCodec.
+type Codec interface {
+	// NewReader returns the reader that decodes the data read from r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+	// NewWriter returns the writer that encodes the data written to it into w.
+	NewWriter(w io.Writer) (CodecWriter, error)
+}
+
+// CodecWriter is the writer returned by Codec.NewWriter. Flush must write all the data written so far to the
+// underlying writer so that the encoded body can be emitted chunk by chunk, and Close must write the rest.
+type CodecWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+var codecs = struct {
+	sync.RWMutex
+	m map[string]Codec
+}{m: map[string]Codec{"gzip": gzipCodec{}, "x-gzip": gzipCodec{}, "deflate": deflateCodec{}}}
+
+// RegisterCodec registers the codec of the content-coding, which is case-insensitive, and replaces the one already
+// registered if any. This is typically called in init. For example, brotli and zstd can be added with
+// github.com/andybalholm/brotli and github.com/klauspost/compress/zstd as follows:
+//
+//	type brotliCodec struct{}
+//
+//	func (brotliCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+//		return io.NopCloser(brotli.NewReader(r)), nil
+//	}
+//
+//	func (brotliCodec) NewWriter(w io.Writer) (envoy.CodecWriter, error) { return brotli.NewWriter(w), nil }
+//
+//	type zstdCodec struct{}
+//
+//	func (zstdCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+//		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+//		if err != nil {
+//			return nil, err
+//		}
+//		return d.IOReadCloser(), nil
+//	}
+//
+//	func (zstdCodec) NewWriter(w io.Writer) (envoy.CodecWriter, error) { return zstd.NewWriter(w) }
+//
+//	func init() {
+//		envoy.RegisterCodec("br", brotliCodec{})
+//		envoy.RegisterCodec("zstd", zstdCodec{})
+//	}
+func RegisterCodec(encoding string, codec Codec) {
+	codecs.Lock()
+	defer codecs.Unlock()
+	codecs.m[strings.ToLower(encoding)] = codec
+}
+
+// lookupCodec returns the content-coding in lower case and its codec of the content-encoding header. This returns
+// the empty string and nil for the identity, and false if the content-coding is not registered or more than one
+// content-coding is applied.
+func lookupCodec(contentEncoding string) (string, Codec, bool) {
+	encoding := strings.ToLower(strings.TrimSpace(contentEncoding))
+	if encoding == "" || encoding == "identity" {
+		return "", nil, true
+	}
+	codecs.RLock()
+	defer codecs.RUnlock()
+	codec, ok := codecs.m[encoding]
+	return encoding, codec, ok
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }
+
+func (gzipCodec) NewWriter(w io.Writer) (CodecWriter, error) { return gzip.NewWriter(w), nil }
+
+// deflateCodec is the deflate content-coding, which is the zlib format as defined in RFC 9110.
+type deflateCodec struct{}
+
+func (deflateCodec) NewReader(r io.Reader) (io.ReadCloser, error) { return zlib.NewReader(r) }
+
+func (deflateCodec) NewWriter(w io.Writer) (CodecWriter, error) { return zlib.NewWriter(w), nil }
+
+// EncodedBodyMode is how EncodedBody emits the body.
+type EncodedBodyMode int
+
+const (
+	// EncodedBodyModeInspect passes through the body as is, and the headers are not modified. The decoded body is
+	// passed to the callback of InspectRequestBody and InspectResponseBody.
+	EncodedBodyModeInspect EncodedBodyMode = iota
+	// EncodedBodyModeDecode emits the body returned by the callback of TransformRequestBody and
+	// TransformResponseBody without encoding, and removes the content-encoding and content-length headers.
+	EncodedBodyModeDecode
+	// EncodedBodyModeReencode emits the body returned by the callback of TransformRequestBody and
+	// TransformResponseBody encoded with the same content-coding, and removes the content-length header.
+	EncodedBodyModeReencode
+)
+
+// EncodedBodyConfig is the configuration of EncodedBody.
+type EncodedBodyConfig struct {
+	Mode EncodedBodyMode
+	// Streaming is true to process the body chunk by chunk. Otherwise, the entire body is buffered in Envoy, and is
+	// processed at the end of stream.
+	Streaming bool
+	// MaxDecodedSize is the maximum size of the decoded body. If zero, DefaultMaxDecodedBodySize is used.
+	MaxDecodedSize int
+}
+
+// EncodedBody gives the access to the decoded request or response body of a stream regardless of the content-coding
+// shown by the content-encoding header, and keeps the content-encoding and content-length headers consistent with
+// the emitted body. Create one for each direction of a stream:
+//
+//	func (h *myHttpFilterInstance) ResponseHeaders(headers envoy.ResponseHeaders, endOfStream bool) envoy.ResponseHeadersStatus {
+//		h.body = envoy.NewEncodedBody(h.envoyFilter, envoy.EncodedBodyConfig{Mode: envoy.EncodedBodyModeReencode})
+//		h.body.PrepareResponseHeaders(headers)
+//		return envoy.ResponseHeadersStatusContinue
+//	}
+//
+//	func (h *myHttpFilterInstance) ResponseBody(body envoy.ResponseBodyBuffer, endOfStream bool) envoy.ResponseBodyStatus {
+//		status, err := h.body.TransformResponseBody(body, endOfStream, func(decoded []byte, _ bool) []byte {
+//			return bytes.ReplaceAll(decoded, []byte("foo"), []byte("bar"))
+//		})
+//		if err != nil {
+//			h.envoyFilter.SendResponse(502, nil, nil)
+//		}
+//		return status
+//	}
+//
+//	func (h *myHttpFilterInstance) Destroy() {
+//		if h.body != nil {
+//			h.body.Close()
+//		}
+//	}
+//
+// In the streaming mode, the decoded bytes are passed to the callback as soon as the decoder produces them, which can
+// lag behind the encoded chunks, and the re-encoded output is flushed at each chunk.
+//
+// The methods return an error if the body is corrupted or ErrDecodedBodyTooLarge. Then the body is left untouched,
+// and the subsequent chunks pass through with the same error. As the part of the body before the error may have been
+// emitted in the streaming mode, the stream should be reset or replied locally on error.
+type EncodedBody struct {
+	envoyFilter EnvoyFilterInstance
+	config      EncodedBodyConfig
+	// enabled is true after Prepare*Headers if the content-coding is supported.
+	enabled bool
+	// encoding and codec are the content-coding, which are empty and nil for the identity.
+	encoding string
+	codec    Codec
+	decoder  streamDecoder
+	writer   CodecWriter
+	encoded  bytes.Buffer
+	err      error
+}
+
+// NewEncodedBody creates a new EncodedBody for a direction of the stream of the EnvoyFilterInstance.
+func NewEncodedBody(e EnvoyFilterInstance, config EncodedBodyConfig) *EncodedBody {
+	if config.MaxDecodedSize <= 0 {
+		config.MaxDecodedSize = DefaultMaxDecodedBodySize
+	}
+	return &EncodedBody{envoyFilter: e, config: config}
+}
+
+// PrepareRequestHeaders must be called in RequestHeadersHandler.RequestHeaders before processing the request body.
+// This is the same as PrepareResponseHeaders for the request.
+func (b *EncodedBody) PrepareRequestHeaders(headers RequestHeaders) bool {
+	contentEncoding, _ := headers.Get("content-encoding")
+	if !b.prepare(contentEncoding.String()) {
+		return false
+	}
+	b.modifyHeaders(headers.Remove)
+	return true
+}
+
+// PrepareResponseHeaders must be called in ResponseHeadersHandler.ResponseHeaders before processing the response
+// body. This modifies the headers as documented in EncodedBodyMode.
+//
+// This returns false if the content-coding is not registered or more than one content-coding is applied. Then the
+// headers are not modified, and the body passes through without calling the callback.
+func (b *EncodedBody) PrepareResponseHeaders(headers ResponseHeaders) bool {
+	contentEncoding, _ := headers.Get("content-encoding")
+	if !b.prepare(contentEncoding.String()) {
+		return false
+	}
+	b.modifyHeaders(headers.Remove)
+	return true
+}
+
+func (b *EncodedBody) prepare(contentEncoding string) bool {
+	b.encoding, b.codec, b.enabled = lookupCodec(contentEncoding)
+	b.decoder = streamDecoder{codec: b.codec, max: b.config.MaxDecodedSize}
+	return b.enabled
+}
+
+func (b *EncodedBody) modifyHeaders(remove func(key string)) {
+	switch b.config.Mode {
+	case EncodedBodyModeDecode:
+		remove("content-encoding")
+		remove("content-length")
+	case EncodedBodyModeReencode:
+		remove("content-length")
+	}
+}
+
+// Encoding returns the content-coding of the body in lower case, or the empty string for the identity.
+func (b *EncodedBody) Encoding() string {
+	return b.encoding
+}
+
+// InspectRequestBody passes the decoded request body to inspect, and returns the status that
+// RequestBodyHandler.RequestBody must return. This must be called in RequestBodyHandler.RequestBody with
+// EncodedBodyModeInspect.
+func (b *EncodedBody) InspectRequestBody(body RequestBodyBuffer, endOfStream bool, inspect func(decoded []byte, endOfStream bool)) (RequestBodyStatus, error) {
+	if b.config.Mode != EncodedBodyModeInspect {
+		return RequestBodyStatusContinue, errEncodedBodyMode
+	}
+	if !b.enabled {
+		return RequestBodyStatusContinue, nil
+	}
+	if !b.config.Streaming && !endOfStream {
+		return RequestBodyStatusStopIterationAndBuffer, nil
+	}
+	data, _ := b.requestBody(body)
+	_, err := b.process(data, endOfStream, func(decoded []byte, endOfStream bool) []byte {
+		inspect(decoded, endOfStream)
+		return nil
+	})
+	return RequestBodyStatusContinue, err
+}
+
+// InspectResponseBody passes the decoded response body to inspect, and returns the status that
+// ResponseBodyHandler.ResponseBody must return. This must be called in ResponseBodyHandler.ResponseBody with
+// EncodedBodyModeInspect.
+//
+// In the streaming mode, inspect is called with the decoded bytes of each chunk, which can be empty only at the end
+// of stream. Otherwise, inspect is called once with the entire decoded body at the end of stream.
+func (b *EncodedBody) InspectResponseBody(body ResponseBodyBuffer, endOfStream bool, inspect func(decoded []byte, endOfStream bool)) (ResponseBodyStatus, error) {
+	if b.config.Mode != EncodedBodyModeInspect {
+		return ResponseBodyStatusContinue, errEncodedBodyMode
+	}
+	if !b.enabled {
+		return ResponseBodyStatusContinue, nil
+	}
+	if !b.config.Streaming && !endOfStream {
+		return ResponseBodyStatusStopIterationAndBuffer, nil
+	}
+	data, _ := b.responseBody(body)
+	_, err := b.process(data, endOfStream, func(decoded []byte, endOfStream bool) []byte {
+		inspect(decoded, endOfStream)
+		return nil
+	})
+	return ResponseBodyStatusContinue, err
+}
+
+// TransformRequestBody replaces the request body with the output of transform, and returns the status that
+// RequestBodyHandler.RequestBody must return. This must be called in RequestBodyHandler.RequestBody with
+// EncodedBodyModeDecode or EncodedBodyModeReencode.
+func (b *EncodedBody) TransformRequestBody(body RequestBodyBuffer, endOfStream bool, transform func(decoded []byte, endOfStream bool) []byte) (RequestBodyStatus, error) {
+	if b.config.Mode == EncodedBodyModeInspect {
+		return RequestBodyStatusContinue, errEncodedBodyMode
+	}
+	if !b.enabled {
+		return RequestBodyStatusContinue, nil
+	}
+	if !b.config.Streaming && !endOfStream {
+		return RequestBodyStatusStopIterationAndBuffer, nil
+	}
+	data, buffered := b.requestBody(body)
+	out, err := b.process(data, endOfStream, transform)
+	if err != nil {
+		return RequestBodyStatusContinue, err
+	}
+	if buffered.raw != 0 {
+		buffered.Drain(buffered.Length())
+	}
+	body.Replace(out)
+	return RequestBodyStatusContinue, nil
+}
+
+// TransformResponseBody replaces the response body with the output of transform, and returns the status that
+// ResponseBodyHandler.ResponseBody must return. This must be called in ResponseBodyHandler.ResponseBody with
+// EncodedBodyModeDecode or EncodedBodyModeReencode.
+//
+// In the streaming mode, transform is called with the decoded bytes of each chunk, which can be empty only at the
+// end of stream, and the output replaces the chunk. Otherwise, transform is called once with the entire decoded body
+// at the end of stream, and the output replaces the entire body.
+func (b *EncodedBody) TransformResponseBody(body ResponseBodyBuffer, endOfStream bool, transform func(decoded []byte, endOfStream bool) []byte) (ResponseBodyStatus, error) {
+	if b.config.Mode == EncodedBodyModeInspect {
+		return ResponseBodyStatusContinue, errEncodedBodyMode
+	}
+	if !b.enabled {
+		return ResponseBodyStatusContinue, nil
+	}
+	if !b.config.Streaming && !endOfStream {
+		return ResponseBodyStatusStopIterationAndBuffer, nil
+	}
+	data, buffered := b.responseBody(body)
+	out, err := b.process(data, endOfStream, transform)
+	if err != nil {
+		return ResponseBodyStatusContinue, err
+	}
+	if buffered.raw != 0 {
+		buffered.Drain(buffered.Length())
+	}
+	body.Replace(out)
+	return ResponseBodyStatusContinue, nil
+}
+
+// requestBody returns the chunk of the event in the streaming mode, or entireRequestBody otherwise.
+func (b *EncodedBody) requestBody(body RequestBodyBuffer) ([]byte, RequestBodyBuffer) {
+	if b.config.Streaming {
+		return body.Copy(), RequestBodyBuffer{}
+	}
+	return entireRequestBody(b.envoyFilter, body)
+}
+
+// responseBody is requestBody for the response.
+func (b *EncodedBody) responseBody(body ResponseBodyBuffer) ([]byte, ResponseBodyBuffer) {
+	if b.config.Streaming {
+		return body.Copy(), ResponseBodyBuffer{}
+	}
+	return entireResponseBody(b.envoyFilter, body)
+}
+
+// entireRequestBody returns the entire request body at the end of stream after the body has been buffered with
+// RequestBodyStatusStopIterationAndBuffer, and the body buffered in Envoy if it's not the same as the body of
+// the event. To replace the entire body, drain the returned buffer if not zero and replace the body of the event.
+func entireRequestBody(e EnvoyFilterInstance, body RequestBodyBuffer) ([]byte, RequestBodyBuffer) {
+	buffered := e.GetRequestBodyBuffer()
+	if buffered.raw == 0 || buffered.raw == body.raw {
+		return body.Copy(), RequestBodyBuffer{}
+	}
+	return slices.Concat(buffered.Copy(), body.Copy()), buffered
+}
+
+// entireResponseBody is entireRequestBody for the response.
+func entireResponseBody(e EnvoyFilterInstance, body ResponseBodyBuffer) ([]byte, ResponseBodyBuffer) {
+	buffered := e.GetResponseBodyBuffer()
+	if buffered.raw == 0 || buffered.raw == body.raw {
+		return body.Copy(), ResponseBodyBuffer{}
+	}
+	return slices.Concat(buffered.Copy(), body.Copy()), buffered
+}
+
+// process decodes the data, calls the callback with the decoded bytes and encodes the output if
+// EncodedBodyModeReencode. The returned bytes are valid until the next call.
+func (b *EncodedBody) process(data []byte, endOfStream bool, callback func(decoded []byte, endOfStream bool) []byte) ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	decoded, err := b.decoder.feed(data, endOfStream)
+	if err != nil {
+		b.err = err
+		return nil, err
+	}
+	if len(decoded) == 0 && !endOfStream {
+		return nil, nil
+	}
+	out := callback(decoded, endOfStream)
+	if b.config.Mode != EncodedBodyModeReencode || b.codec == nil {
+		return out, nil
+	}
+	if out, err = b.encode(out, endOfStream); err != nil {
+		b.err = err
+	}
+	return out, err
+}
+
+// encode encodes the data with the codec, and flushes the encoder at each chunk.
+func (b *EncodedBody) encode(data []byte, endOfStream bool) ([]byte, error) {
+	b.encoded.Reset()
+	if b.writer == nil {
+		w, err := b.codec.NewWriter(&b.encoded)
+		if err != nil {
+			return nil, err
+		}
+		b.writer = w
+	}
+	if len(data) > 0 {
+		if _, err := b.writer.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	switch {
+	case endOfStream:
+		err = b.writer.Close()
+	case len(data) > 0:
+		err = b.writer.Flush()
+	}
+	return b.encoded.Bytes(), err
+}
+
+// Close releases the decoder. This must be called in HttpFilterInstance.Destroy if the stream can be destroyed
+// before the end of stream, as the decoder runs on its own Goroutine in the streaming mode.
+func (b *EncodedBody) Close() {
+	b.decoder.close()
+}
+
+// streamDecoder decodes the chunks of the body with the io.Reader of the Codec, which pulls the data. As the data is
+// pushed by the events, the reader runs on its own Goroutine which reads the chunks from the streamDecoder itself.
+// The Goroutine and the event take turns: feed hands over a chunk and waits until the reader has consumed it and
+// asks for the next one, so the bytes decoded so far are returned synchronously in the event.
+type streamDecoder struct {
+	// codec is nil for the identity.
+	codec Codec
+	max   int
+	// input and yield are created when the Goroutine is started. yield receives nil when the reader asks for
+	// the next chunk, or the final error which is io.EOF at the end of the decoded body.
+	input chan decoderInput
+	yield chan error
+	// done is true after the final error is received from yield.
+	done bool
+	// The following fields are accessed by the side having the turn.
+	chunk   []byte
+	eos     bool
+	out     []byte
+	decoded int
+}
+
+type decoderInput struct {
+	chunk []byte
+	eos   bool
+}
+
+// feed decodes the chunk, and returns the decoded bytes which are owned by the caller.
+func (d *streamDecoder) feed(chunk []byte, endOfStream bool) ([]byte, error) {
+	if d.codec == nil {
+		if d.decoded += len(chunk); d.decoded > d.max {
+			return nil, ErrDecodedBodyTooLarge
+		}
+		return chunk, nil
+	}
+	switch {
+	case d.done:
+		if len(chunk) > 0 {
+			return nil, errEncodedBodyTrailingData
+		}
+		return nil, nil
+	case d.input == nil:
+		d.chunk, d.eos = chunk, endOfStream
+		d.input, d.yield = make(chan decoderInput), make(chan error, 1)
+		go d.run()
+	default:
+		d.input <- decoderInput{chunk: chunk, eos: endOfStream}
+	}
+	err := <-d.yield
+	out := d.out
+	d.out = nil
+	if err == nil {
+		return out, nil
+	}
+	d.done = true
+	switch {
+	case err != io.EOF:
+		return nil, err
+	case len(d.chunk) > 0:
+		return nil, errEncodedBodyTrailingData
+	}
+	return out, nil
+}
+
+func (d *streamDecoder) run() {
+	d.yield <- d.decode()
+}
+
+// decode runs the reader of the codec until the end of the decoded body or an error.
+func (d *streamDecoder) decode() error {
+	r, err := d.codec.NewReader(d)
+	if err != nil {
+		return fmt.Errorf("envoy: decoding body: %w", err)
+	}
+	defer r.Close()
+	// The reader can hand over the turn in the middle of Read, so it doesn't read into out directly.
+	buf := make([]byte, 16<<10)
+	for {
+		n, err := r.Read(buf)
+		d.out = append(d.out, buf[:n]...)
+		if d.decoded += n; d.decoded > d.max {
+			return ErrDecodedBodyTooLarge
+		}
+		switch {
+		case err == io.EOF:
+			return io.EOF
+		case err == errDecoderClosed:
+			return err
+		case err != nil:
+			return fmt.Errorf("envoy: decoding body: %w", err)
+		}
+	}
+}
+
+// Read implements io.Reader for the reader of the codec.
+func (d *streamDecoder) Read(p []byte) (int, error) {
+	if err := d.next(); err != nil {
+		return 0, err
+	}
+	n := copy(p, d.chunk)
+	d.chunk = d.chunk[n:]
+	return n, nil
+}
+
+// ReadByte implements io.ByteReader so that the reader of the codec doesn't read ahead beyond the encoded body,
+// which lets feed detect the trailing data.
+func (d *streamDecoder) ReadByte() (byte, error) {
+	if err := d.next(); err != nil {
+		return 0, err
+	}
+	c := d.chunk[0]
+	d.chunk = d.chunk[1:]
+	return c, nil
+}
+
+// next waits for the next chunk if the current one is consumed, and returns io.EOF at the end of stream.
+func (d *streamDecoder) next() error {
+	for len(d.chunk) == 0 {
+		if d.eos {
+			return io.EOF
+		}
+		d.yield <- nil
+		in, ok := <-d.input
+		if !ok {
+			return errDecoderClosed
+		}
+		d.chunk, d.eos = in.chunk, in.eos
+	}
+	return nil
+}
+
+// close stops the Goroutine waiting for the next chunk.
+func (d *streamDecoder) close() {
+	if d.input != nil && !d.done {
+		d.done = true
+		close(d.input)
+	}
+}
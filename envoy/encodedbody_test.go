@@ -0,0 +1,239 @@
+//go:build !cgo
+
+package envoy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+	"testing"
+)
+
+func gzipData(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(w, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func gunzipData(t *testing.T, data []byte) string {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(decoded)
+}
+
+// encodedBodyStream creates a stream whose response body events call respond with the EncodedBody of the config,
+// which is prepared with the fake response headers on the first event. This returns the pointer of the stream and
+// the EncodedBody once created.
+func encodedBodyStream(t *testing.T,
+	respond func(b *EncodedBody, body ResponseBodyBuffer, endOfStream bool) ResponseBodyStatus,
+	config EncodedBodyConfig,
+) (uintptr, func() *EncodedBody) {
+	var b *EncodedBody
+	filter := newTestHttpFilter(t, bodyHttpFilter{
+		responseBody: func(e EnvoyFilterInstance, body ResponseBodyBuffer, endOfStream bool) ResponseBodyStatus {
+			if b == nil {
+				b = NewEncodedBody(e, config)
+				event := NewFakeEvent()
+				b.PrepareResponseHeaders(event.ResponseHeaders(1))
+				event.End()
+			}
+			return respond(b, body, endOfStream)
+		},
+	})
+	instance := eventHttpFilterInstanceInit(1, filter)
+	t.Cleanup(func() {
+		if b != nil {
+			b.Close()
+		}
+		eventHttpFilterInstanceDestroy(instance)
+	})
+	return instance, func() *EncodedBody { return b }
+}
+
+func TestEncodedBodyReencodeStreaming(t *testing.T) {
+	bodies := fakeBodies(t)
+	headers := map[string]string{"content-encoding": "gzip", "content-length": "100"}
+	fakeHeaders(t, headers)
+	var decoded []byte
+	instance, encodedBody := encodedBodyStream(t,
+		func(b *EncodedBody, body ResponseBodyBuffer, endOfStream bool) ResponseBodyStatus {
+			status, err := b.TransformResponseBody(body, endOfStream, func(chunk []byte, _ bool) []byte {
+				decoded = append(decoded, chunk...)
+				return bytes.ToUpper(chunk)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return status
+		},
+		EncodedBodyConfig{Mode: EncodedBodyModeReencode, Streaming: true})
+
+	const data = "hello, encoded world"
+	encoded := gzipData(t, data)
+	var out []byte
+	for i := 0; i < len(encoded); i += 7 {
+		bodies[10] = &fakeBody{slices: [][]byte{encoded[i:min(i+7, len(encoded))]}}
+		status := eventHttpFilterInstanceResponseBody(instance, 10, i+7 >= len(encoded))
+		if status != int(ResponseBodyStatusContinue) {
+			t.Fatalf("status = %d, want Continue", status)
+		}
+		out = append(out, bodies[10].bytes()...)
+	}
+	if string(decoded) != data {
+		t.Fatalf("decoded = %q, want %q", decoded, data)
+	}
+	if got := gunzipData(t, out); got != strings.ToUpper(data) {
+		t.Fatalf("re-encoded body = %q", got)
+	}
+	if _, ok := headers["content-length"]; ok || headers["content-encoding"] != "gzip" {
+		t.Fatalf("headers = %v, want content-length removed", headers)
+	}
+	if encoding := encodedBody().Encoding(); encoding != "gzip" {
+		t.Fatalf("Encoding() = %q, want gzip", encoding)
+	}
+}
+
+func TestEncodedBodyDecodeBuffered(t *testing.T) {
+	bodies := fakeBodies(t)
+	headers := map[string]string{"content-encoding": "Deflate", "content-length": "100"}
+	fakeHeaders(t, headers)
+	instance, _ := encodedBodyStream(t,
+		func(b *EncodedBody, body ResponseBodyBuffer, endOfStream bool) ResponseBodyStatus {
+			status, err := b.TransformResponseBody(body, endOfStream, func(decoded []byte, endOfStream bool) []byte {
+				if !endOfStream {
+					t.Fatal("transform is called before the end of stream")
+				}
+				return append(decoded, '!')
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return status
+		},
+		EncodedBodyConfig{Mode: EncodedBodyModeDecode})
+
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	io.WriteString(w, "buffered body")
+	w.Close()
+	encoded := buf.Bytes()
+	bodies[10] = &fakeBody{slices: [][]byte{encoded[:5]}}
+	status := eventHttpFilterInstanceResponseBody(instance, 10, false)
+	if status != int(ResponseBodyStatusStopIterationAndBuffer) {
+		t.Fatalf("status = %d, want StopIterationAndBuffer", status)
+	}
+	// Envoy moves the chunk to the buffered body.
+	bodies[responseBufferOf(1)] = bodies[10]
+	bodies[20] = &fakeBody{slices: [][]byte{encoded[5:]}}
+	eventHttpFilterInstanceResponseBody(instance, 20, true)
+	buffered, last := string(bodies[responseBufferOf(1)].bytes()), string(bodies[20].bytes())
+	if buffered != "" || last != "buffered body!" {
+		t.Fatalf("buffered = %q, last = %q", buffered, last)
+	}
+	if len(headers) != 0 {
+		t.Fatalf("headers = %v, want content-encoding and content-length removed", headers)
+	}
+}
+
+func TestEncodedBodyPassThrough(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		encoding string
+		enabled  bool
+	}{
+		{name: "identity", encoding: "identity", enabled: true},
+		{name: "no encoding", enabled: true},
+		{name: "unregistered", encoding: "br"},
+		{name: "multiple", encoding: "gzip, br"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			bodies := fakeBodies(t)
+			headers := map[string]string{"content-length": "5"}
+			if tc.encoding != "" {
+				headers["content-encoding"] = tc.encoding
+			}
+			fakeHeaders(t, headers)
+			var inspected []string
+			instance, encodedBody := encodedBodyStream(t,
+				func(b *EncodedBody, body ResponseBodyBuffer, endOfStream bool) ResponseBodyStatus {
+					status, err := b.InspectResponseBody(body, endOfStream, func(decoded []byte, _ bool) {
+						inspected = append(inspected, string(decoded))
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+					return status
+				},
+				EncodedBodyConfig{Mode: EncodedBodyModeInspect, Streaming: true})
+			bodies[10] = &fakeBody{slices: [][]byte{[]byte("hello")}}
+			eventHttpFilterInstanceResponseBody(instance, 10, true)
+
+			if encodedBody().enabled != tc.enabled || len(headers) != 1+min(len(tc.encoding), 1) {
+				t.Fatalf("enabled = %t, headers = %v", encodedBody().enabled, headers)
+			}
+			if string(bodies[10].bytes()) != "hello" {
+				t.Fatalf("body = %q, want it untouched", bodies[10].bytes())
+			}
+			want := 0
+			if tc.enabled {
+				want = 1
+			}
+			if len(inspected) != want || want == 1 && inspected[0] != "hello" {
+				t.Fatalf("inspected = %q", inspected)
+			}
+		})
+	}
+}
+
+func TestEncodedBodyErrors(t *testing.T) {
+	bodies := fakeBodies(t)
+	headers := map[string]string{"content-encoding": "gzip"}
+	fakeHeaders(t, headers)
+	var errs []error
+	instance, _ := encodedBodyStream(t,
+		func(b *EncodedBody, body ResponseBodyBuffer, endOfStream bool) ResponseBodyStatus {
+			_, err := b.TransformResponseBody(body, endOfStream, func(decoded []byte, _ bool) []byte { return decoded })
+			errs = append(errs, err)
+			return ResponseBodyStatusContinue
+		},
+		EncodedBodyConfig{Mode: EncodedBodyModeDecode, Streaming: true, MaxDecodedSize: 1024})
+
+	// The decompression bomb is stopped at MaxDecodedSize, and the rest of the body passes through with the error.
+	bomb := gzipData(t, strings.Repeat("\x00", 1<<20))
+	bodies[10] = &fakeBody{slices: [][]byte{bomb}}
+	eventHttpFilterInstanceResponseBody(instance, 10, false)
+	bodies[20] = &fakeBody{slices: [][]byte{[]byte("rest")}}
+	eventHttpFilterInstanceResponseBody(instance, 20, true)
+	if len(errs) != 2 || errs[0] != ErrDecodedBodyTooLarge || errs[1] != ErrDecodedBodyTooLarge {
+		t.Fatalf("errors = %v, want ErrDecodedBodyTooLarge", errs)
+	}
+	if !bytes.Equal(bodies[10].bytes(), bomb) || string(bodies[20].bytes()) != "rest" {
+		t.Fatal("the body is modified on error")
+	}
+
+	// The methods of the other modes are rejected.
+	event := NewFakeEvent()
+	defer event.End()
+	b := NewEncodedBody(nil, EncodedBodyConfig{Mode: EncodedBodyModeDecode})
+	if _, err := b.InspectResponseBody(event.ResponseBody(10), true, nil); err != errEncodedBodyMode {
+		t.Fatalf("InspectResponseBody with EncodedBodyModeDecode = %v", err)
+	}
+	b = NewEncodedBody(nil, EncodedBodyConfig{Mode: EncodedBodyModeInspect})
+	if _, err := b.TransformRequestBody(event.RequestBody(10), true, nil); err != errEncodedBodyMode {
+		t.Fatalf("TransformRequestBody with EncodedBodyModeInspect = %v", err)
+	}
+}
@@ -80,3 +80,135 @@ const (
 	// subsequent HttpFilterInstance.EventHttpResponseBody calls.
 	ResponseBodyStatusStopIterationAndBuffer ResponseBodyStatus = 1
 )
+
+// httpFilterEventMask is a bit set of the events of HttpFilterInstance. This corresponds to
+// __envoy_dynamic_module_v1_type_HttpFilterEventMask in abi.h.
+type httpFilterEventMask uint
+
+const (
+	httpFilterEventRequestHeaders httpFilterEventMask = 1 << iota
+	httpFilterEventRequestBody
+	httpFilterEventResponseHeaders
+	httpFilterEventResponseBody
+	httpFilterEventWatermark
+)
+
+// NetworkFilterStatus is the return value of the NetworkFilterInstance events.
+type NetworkFilterStatus int
+
+const (
+	// NetworkFilterStatusContinue indicates that Envoy should continue the iteration of the network filter chain.
+	NetworkFilterStatusContinue NetworkFilterStatus = 0
+	// NetworkFilterStatusStopIteration indicates that Envoy should stop the iteration of the network filter chain.
+	// For the read path, the iteration can be resumed by calling EnvoyNetworkFilterInstance.ContinueReading.
+	NetworkFilterStatusStopIteration NetworkFilterStatus = 1
+)
+
+// ConnectionEvent is the reason of the connection close passed to NetworkFilterInstance.OnClose.
+type ConnectionEvent int
+
+const (
+	// ConnectionEventRemoteClose indicates that the connection was closed by the remote peer.
+	ConnectionEventRemoteClose ConnectionEvent = 0
+	// ConnectionEventLocalClose indicates that the connection was closed locally, e.g. by Envoy or by the module.
+	ConnectionEventLocalClose ConnectionEvent = 1
+)
+
+// ListenerFilterStatus is the return value of the ListenerFilterInstance events.
+type ListenerFilterStatus int
+
+const (
+	// ListenerFilterStatusContinue indicates that the filter has finished inspecting the socket
+	// and Envoy should continue to the next listener filter.
+	ListenerFilterStatusContinue ListenerFilterStatus = 0
+	// ListenerFilterStatusStopIteration indicates that Envoy should stop the iteration of the listener filter chain.
+	// ListenerFilterInstance.OnData will be called when more data is available, or the iteration can be resumed
+	// by calling EnvoyListenerFilterInstance.ContinueFilterChain.
+	ListenerFilterStatusStopIteration ListenerFilterStatus = 1
+)
+
+// AccessLogTiming specifies the timing of the stream recorded in AccessLogEntry.
+// Each corresponds to the timestamp of the same name in Envoy's StreamInfo.
+type AccessLogTiming int
+
+const (
+	// AccessLogTimingLastDownstreamRxByteReceived is the time when the last byte of the request was received from the downstream.
+	AccessLogTimingLastDownstreamRxByteReceived AccessLogTiming = 0
+	// AccessLogTimingFirstUpstreamTxByteSent is the time when the first byte of the request was sent to the upstream.
+	AccessLogTimingFirstUpstreamTxByteSent AccessLogTiming = 1
+	// AccessLogTimingLastUpstreamTxByteSent is the time when the last byte of the request was sent to the upstream.
+	AccessLogTimingLastUpstreamTxByteSent AccessLogTiming = 2
+	// AccessLogTimingFirstUpstreamRxByteReceived is the time when the first byte of the response was received from the upstream.
+	AccessLogTimingFirstUpstreamRxByteReceived AccessLogTiming = 3
+	// AccessLogTimingLastUpstreamRxByteReceived is the time when the last byte of the response was received from the upstream.
+	AccessLogTimingLastUpstreamRxByteReceived AccessLogTiming = 4
+	// AccessLogTimingFirstDownstreamTxByteSent is the time when the first byte of the response was sent to the downstream.
+	AccessLogTimingFirstDownstreamTxByteSent AccessLogTiming = 5
+	// AccessLogTimingLastDownstreamTxByteSent is the time when the last byte of the response was sent to the downstream.
+	AccessLogTimingLastDownstreamTxByteSent AccessLogTiming = 6
+	// AccessLogTimingRequestComplete is the time when the stream was completed. This is the total duration of the stream.
+	AccessLogTimingRequestComplete AccessLogTiming = 7
+)
+
+// ResponseFlags is a bitmask of the response flags of the stream that describe
+// the additional details about the response or the connection, e.g. timeouts and resets.
+// The bits follow the order of Envoy's StreamInfo::CoreResponseFlag.
+type ResponseFlags uint64
+
+// The response flags. See %RESPONSE_FLAGS% in the Envoy access log documentation for the details of each flag.
+const (
+	ResponseFlagFailedLocalHealthCheck ResponseFlags = 1 << iota
+	ResponseFlagNoHealthyUpstream
+	ResponseFlagUpstreamRequestTimeout
+	ResponseFlagLocalReset
+	ResponseFlagUpstreamRemoteReset
+	ResponseFlagUpstreamConnectionFailure
+	ResponseFlagUpstreamConnectionTermination
+	ResponseFlagUpstreamOverflow
+	ResponseFlagNoRouteFound
+	ResponseFlagDelayInjected
+	ResponseFlagFaultInjected
+	ResponseFlagRateLimited
+	ResponseFlagUnauthorizedExternalService
+	ResponseFlagRateLimitServiceError
+	ResponseFlagDownstreamConnectionTermination
+	ResponseFlagUpstreamRetryLimitExceeded
+	ResponseFlagStreamIdleTimeout
+	ResponseFlagInvalidEnvoyRequestHeaders
+	ResponseFlagDownstreamProtocolError
+	ResponseFlagUpstreamMaxStreamDurationReached
+	ResponseFlagResponseFromCacheFilter
+	ResponseFlagNoFilterConfigFound
+	ResponseFlagDurationTimeout
+	ResponseFlagUpstreamProtocolError
+	ResponseFlagNoClusterFound
+	ResponseFlagOverloadManager
+	ResponseFlagDnsResolutionFailed
+	ResponseFlagDropOverLoad
+	ResponseFlagDownstreamRemoteReset
+)
+
+// responseFlagShortNames are the short names of the response flags used in the Envoy access log format, indexed by the bit position.
+var responseFlagShortNames = [...]string{
+	"LH", "UH", "UT", "LR", "UR", "UF", "UC", "UO", "NR", "DI", "FI", "RL", "UAEX", "RLSE", "DC", "URX",
+	"SI", "IH", "DPE", "UMSDR", "RFCF", "NFCF", "DT", "UPE", "NC", "OM", "DF", "DO", "DR",
+}
+
+// String returns the comma separated short names of the flags, e.g. "UH,UF", the same as %RESPONSE_FLAGS% in Envoy.
+// Returns "-" if no flag is set.
+func (f ResponseFlags) String() string {
+	if f == 0 {
+		return "-"
+	}
+	var b []byte
+	for i, name := range responseFlagShortNames {
+		if f&(1<<i) == 0 {
+			continue
+		}
+		if len(b) > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, name...)
+	}
+	return string(b)
+}
@@ -2,6 +2,7 @@
 package envoy
 
 import (
+	"errors"
 	"unsafe"
 )
 
@@ -14,6 +15,31 @@ import (
 // `config` is the configuration string that is passed to the module that is set in the Envoy configuration.
 var NewHttpFilter func(config string) HttpFilter
 
+// NewUpstreamHttpFilter is a function that creates a new HttpFilter that corresponds to each upstream http filter
+// configuration of a cluster. This is a global variable that can be set in the init function in the program once.
+// If this is not set, NewHttpFilter is used for the upstream http filters as well.
+//
+// The HttpFilterInstance(s) created by an upstream HttpFilter run for each upstream request attempt instead of
+// each downstream request, so they are called again on retries. EnvoyFilterInstance.UpstreamInfo can be used to
+// get the attempt number and the upstream host of the instance.
+//
+// The function is only called by the main thread, so it does not need to be thread-safe.
+//
+// `config` is the configuration string that is passed to the module that is set in the Envoy configuration.
+var NewUpstreamHttpFilter func(config string) HttpFilter
+
+// UpstreamInfo is the information of the upstream request attempt that an upstream HttpFilterInstance is running for.
+type UpstreamInfo struct {
+	// Attempt is the 1-based number of the upstream request attempt which is incremented on each retry.
+	Attempt int
+	// Cluster is the name of the cluster the request is routed to.
+	Cluster string
+	// Host is the address of the upstream host selected for the attempt in the form of "ip:port".
+	// This is empty if the host is not selected yet, e.g. in RequestHeadersHandler.RequestHeaders as the host
+	// selection happens after the upstream filters process the request headers.
+	Host string
+}
+
 // HttpFilter is an interface that represents a single http filter in the Envoy filter chain.
 // It is used to create HttpFilterInstance(s) that correspond to each Http request.
 //
@@ -25,6 +51,8 @@ type HttpFilter interface {
 	// * `EnvoyFilterInstance` is the Envoy filter object that is used to interact with the underlying Envoy filter.
 	//  This object is unique for each Http request. The object is destroyed when the stream is destroyed.
 	//  Therefore, after EventHttpDestroy is called, this object should not be used.
+	//
+	// If the returned instance implements Resetter, it is pooled and this is only called when the pool is empty.
 	NewInstance(EnvoyFilterInstance) HttpFilterInstance
 
 	// Destroy is called when this filter is destroyed. E.g. the filter chain configuration is updated and removed from the Envoy.
@@ -33,49 +61,130 @@ type HttpFilter interface {
 
 // HttpFilterInstance is an interface that represents each Http request.
 //
-// Thisis created for each new Http request and is destroyed when the request is completed.
+// This is created for each new Http request and is destroyed when the request is completed.
+//
+// To receive the events of the request, the instance implements any of RequestHeadersHandler, RequestBodyHandler,
+// ResponseHeadersHandler, ResponseBodyHandler and WatermarkHandler. They are detected when the instance is created,
+// and the events for the handlers that are not implemented are bypassed by Envoy without calling into the module,
+// as if the continue status was returned. Implementing only the necessary handlers reduces the per-request overhead.
 type HttpFilterInstance interface {
+	// Destroy is called when the stream is destroyed.
+	// This is called when the stream is completed or when the stream is reset.
+	Destroy()
+}
+
+// RequestHeadersHandler is an optional interface that can be implemented by HttpFilterInstance to receive
+// the request headers.
+type RequestHeadersHandler interface {
 	// RequestHeaders is called when request headers are received.
 	// The function should return the status of the operation.
 	//
 	//  * `requestHeaders` is the pointer to the request headers map.
 	//  * `endOfStream` is a boolean that indicates if this is the headers-only request.
 	RequestHeaders(RequestHeaders, bool) RequestHeadersStatus
+}
+
+// RequestBodyHandler is an optional interface that can be implemented by HttpFilterInstance to receive
+// the request body.
+type RequestBodyHandler interface {
 	// RequestBody is called when request body data is received.
 	// The function should return the status of the operation.
 	//
 	//  * `requestBody` is the pointer to the newly arrived request body buffer.
 	//  * `endOfStream` is a boolean that indicates if this is the last data frame.
 	RequestBody(RequestBodyBuffer, bool) RequestBodyStatus
+}
+
+// ResponseHeadersHandler is an optional interface that can be implemented by HttpFilterInstance to receive
+// the response headers.
+type ResponseHeadersHandler interface {
 	// ResponseHeaders is called when response headers are received.
 	// The function should return the status of the operation.
 	//
 	//  * `responseHeaders` is the pointer to the response headers map.
 	//  * `endOfStream` is a boolean that indicates if this is the headers-only response.
 	ResponseHeaders(ResponseHeaders, bool) ResponseHeadersStatus
+}
+
+// ResponseBodyHandler is an optional interface that can be implemented by HttpFilterInstance to receive
+// the response body.
+type ResponseBodyHandler interface {
 	// ResponseBody is called when response body data is received.
 	// The function should return the status of the operation.
 	//
 	//  * `responseBody` is the pointer to the newly arrived response body buffer.
 	//  * `endOfStream` is a boolean that indicates if this is the last data frame.
 	ResponseBody(ResponseBodyBuffer, bool) ResponseBodyStatus
+}
 
-	// Destroy is called when the stream is destroyed.
-	// This is called when the stream is completed or when the stream is reset.
-	Destroy()
+// WatermarkHandler is an optional interface that can be implemented by HttpFilterInstance to receive
+// the flow control events. This is useful for filters that stream large bodies, e.g. by injecting data
+// from Goroutines, to apply the backpressure.
+//
+// When the HttpFilterInstance doesn't implement this interface, the events are ignored.
+type WatermarkHandler interface {
+	// RequestAboveHighWatermark is called when the buffer of the request data on the way to the upstream
+	// goes above the high watermark. The filter should stop producing the request data until
+	// RequestBelowLowWatermark is called.
+	RequestAboveHighWatermark()
+	// RequestBelowLowWatermark is called when the buffer of the request data is drained below the low watermark
+	// after RequestAboveHighWatermark is called.
+	RequestBelowLowWatermark()
+	// ResponseAboveHighWatermark is called when the buffer of the response data on the way to the downstream
+	// goes above the high watermark. The filter should stop producing the response data until
+	// ResponseBelowLowWatermark is called.
+	ResponseAboveHighWatermark()
+	// ResponseBelowLowWatermark is called when the buffer of the response data is drained below the low watermark
+	// after ResponseAboveHighWatermark is called.
+	ResponseBelowLowWatermark()
+}
+
+// Resetter is an optional interface that can be implemented by HttpFilterInstance to opt in to the pooling
+// of the instances, which removes the per-request allocations of the instance and the SDK objects around it.
+//
+// When a pooled instance is destroyed, it is put back to the pool of the HttpFilter that created it right after
+// HttpFilterInstance.Destroy. The instance is then reused for a later stream of the same HttpFilter, for which
+// Reset is called with the EnvoyFilterInstance of the new stream instead of HttpFilter.NewInstance.
+//
+// The EnvoyFilterInstance is new for each stream, and the one of the previous stream stays destroyed, so the
+// Goroutines still holding it never operate on the new stream. The instance itself is shared with the new stream,
+// so it must not be used after Destroy returns, e.g. by the Goroutines spawned by the instance. Such filters must
+// not implement this interface.
+type Resetter interface {
+	// Reset clears the per-stream state of the instance so that it can be reused for the new stream, and replaces
+	// the EnvoyFilterInstance held by the instance with the given one of the new stream.
+	Reset(EnvoyFilterInstance)
+}
+
+// HeaderMutation is a batch of the mutations applied to the headers in a single call to Envoy via
+// RequestHeaders.Apply or ResponseHeaders.Apply. The mutations are applied in the order of Remove, Set and Add.
+type HeaderMutation struct {
+	// Set is the list of the key-value pairs set in the same way as RequestHeaders.Set.
+	Set [][2]string
+	// Add is the list of the key-value pairs added as new values, keeping the existing values of the same key.
+	Add [][2]string
+	// Remove is the list of the keys whose values are all removed.
+	Remove []string
 }
 
+// ErrHeaderAddUnsupported is returned by RequestHeaders.Apply and ResponseHeaders.Apply when the HeaderMutation
+// has Add but the host doesn't support FeatureHeaderBatch.
+var ErrHeaderAddUnsupported = errors.New("envoy: adding header values requires FeatureHeaderBatch")
+
 // HeaderValue represents a single header value whose data is owned by the Envoy.
 //
-// This is a view of the underlying data and doesn't copy the data.
+// This is a view of the underlying data and doesn't copy the data, so this is only valid during the event callback
+// where this is retrieved. With the envoydebug build tag, using this after that panics.
 type HeaderValue struct {
-	data *byte
-	size int
+	stamp viewStamp
+	data  *byte
+	size  int
 }
 
 // String returns the string representation of the header value.
 // This copies the underlying data to a new buffer and returns the string.
 func (h HeaderValue) String() string {
+	h.stamp.check("HeaderValue")
 	view := unsafe.Slice(h.data, h.size)
 	return string(view)
 }
@@ -84,6 +193,7 @@ func (h HeaderValue) String() string {
 //
 // This doesn't copy the data and compares the data directly.
 func (h HeaderValue) Equal(str string) bool {
+	h.stamp.check("HeaderValue")
 	if h.size != len(str) || h.data == nil {
 		return false
 	}
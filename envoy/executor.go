@@ -0,0 +1,263 @@
+package envoy
+
+import (
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverloadPolicy is the behavior of EnvoyFilterInstance.Submit when the executor is overloaded, i.e. all the
+// workers are busy and the queue is full.
+type OverloadPolicy int
+
+const (
+	// OverloadReject sends the 503 response to the downstream via EnvoyFilterInstance.SendResponse.
+	OverloadReject OverloadPolicy = iota
+	// OverloadFailOpen drops the task, and the stream continues as if the filter didn't exist.
+	OverloadFailOpen
+	// OverloadWait queues the task beyond MaxQueue, and the stream stays paused until a worker runs the task.
+	// This never blocks the Envoy worker thread, but the number of the paused streams is not bounded, so this
+	// is supposed to be used along with the deadline of the watchdog. See WatchdogProvider.
+	OverloadWait
+)
+
+// ExecutorConfig is the configuration of the executor of an HttpFilter. See ExecutorProvider.
+type ExecutorConfig struct {
+	// MaxConcurrency is the number of the tasks running concurrently. Defaults to runtime.GOMAXPROCS(0).
+	MaxConcurrency int
+	// MaxQueue is the number of the tasks waiting for the workers. Zero means that the tasks are only accepted
+	// when a worker is idle.
+	MaxQueue int
+	// OverloadPolicy is the behavior when the executor is overloaded.
+	OverloadPolicy OverloadPolicy
+	// StatsName enables the Envoy gauges of the executor if not empty and the host supports FeatureStats.
+	// The gauges are go_sdk.executor.<StatsName>.running, .queued, .rejected and .failed_open in the stats scope
+	// of the module, and are set every StatsInterval until the HttpFilter is destroyed. The last two are the
+	// cumulative counts.
+	StatsName string
+	// StatsInterval is the interval of setting the gauges. Defaults to 10 seconds.
+	StatsInterval time.Duration
+	// CloseTimeout is the maximum duration that the destroy of the HttpFilter waits for the running tasks to finish
+	// on the Envoy main thread. The tasks still running after that are left behind. Defaults to 1 second, and
+	// negative doesn't wait at all.
+	CloseTimeout time.Duration
+}
+
+// ExecutorProvider is an optional interface that can be implemented by HttpFilter to run the asynchronous work of
+// its HttpFilterInstances on the bounded number of Goroutines via EnvoyFilterInstance.Submit, instead of spawning
+// a Goroutine per stream which grows without limit under load. This is detected when the HttpFilter is created.
+//
+// The executor is created along with the HttpFilter and closed when the HttpFilter is destroyed, right before
+// HttpFilter.Destroy is called. Since all the streams of the HttpFilter have been destroyed by then, the close drops
+// the queued tasks without running them, and waits for the running tasks to finish up to CloseTimeout:
+//
+//	func (h *myHttpFilterInstance) RequestHeaders(headers envoy.RequestHeaders, _ bool) envoy.RequestHeadersStatus {
+//		if !h.envoyFilter.Submit(func() {
+//			// Do the work, and then continue the stream.
+//			h.envoyFilter.ContinueRequest()
+//		}) {
+//			return envoy.HeadersStatusContinue
+//		}
+//		return envoy.RequestHeadersStatusStopIteration
+//	}
+type ExecutorProvider interface {
+	// Executor returns the configuration of the executor. This is called once when the HttpFilter is created.
+	Executor() ExecutorConfig
+}
+
+// ExecutorStats is a snapshot of the state of the executor of an HttpFilter returned by
+// EnvoyFilterInstance.ExecutorStats. See ExecutorConfig.StatsName for the gauges of the same values.
+type ExecutorStats struct {
+	// Running is the number of the tasks running now.
+	Running int
+	// Queued is the number of the tasks waiting for the workers.
+	Queued int
+	// Rejected is the number of the tasks rejected by OverloadReject so far.
+	Rejected uint64
+	// FailedOpen is the number of the tasks dropped by OverloadFailOpen so far.
+	FailedOpen uint64
+}
+
+// executor runs the tasks submitted via EnvoyFilterInstance.Submit for an HttpFilter. See ExecutorProvider.
+type executor struct {
+	config ExecutorConfig
+	// mu guards the fields below, and is never held while running a task or calling Envoy.
+	mu sync.Mutex
+	// cond is signaled when a task is queued or the executor is closed.
+	cond    sync.Cond
+	queue   []func()
+	running int
+	closed  bool
+	// workers is done when all the workers exit after the executor is closed and the running tasks finish.
+	workers sync.WaitGroup
+	// stopStats stops the Goroutine setting the gauges if any.
+	stopStats chan struct{}
+
+	rejected, failOpen atomic.Uint64
+}
+
+// newExecutor creates a new executor and starts its workers.
+func newExecutor(config ExecutorConfig) *executor {
+	if config.MaxConcurrency <= 0 {
+		config.MaxConcurrency = runtime.GOMAXPROCS(0)
+	}
+	config.MaxQueue = max(config.MaxQueue, 0)
+	if config.StatsInterval <= 0 {
+		config.StatsInterval = 10 * time.Second
+	}
+	if config.CloseTimeout == 0 {
+		config.CloseTimeout = time.Second
+	}
+	x := &executor{config: config}
+	x.cond.L = &x.mu
+	x.workers.Add(config.MaxConcurrency)
+	for i := 0; i < config.MaxConcurrency; i++ {
+		go x.work()
+	}
+	if config.StatsName != "" && HostSupports(FeatureStats) {
+		x.stopStats = make(chan struct{})
+		go x.reportStats()
+	}
+	return x
+}
+
+// work runs the queued tasks until the executor is closed.
+func (x *executor) work() {
+	defer x.workers.Done()
+	x.mu.Lock()
+	for {
+		for len(x.queue) == 0 && !x.closed {
+			x.cond.Wait()
+		}
+		if x.closed {
+			x.mu.Unlock()
+			return
+		}
+		task := x.queue[0]
+		x.queue[0] = nil
+		x.queue = x.queue[1:]
+		x.running++
+		x.mu.Unlock()
+		task()
+		x.mu.Lock()
+		x.running--
+	}
+}
+
+// submit queues the task unless the executor is closed or overloaded. See EnvoyFilterInstance.Submit.
+func (x *executor) submit(e EnvoyFilterInstance, task func()) bool {
+	x.mu.Lock()
+	if x.closed {
+		x.mu.Unlock()
+		return false
+	}
+	if x.running+len(x.queue) < x.config.MaxConcurrency+x.config.MaxQueue || x.config.OverloadPolicy == OverloadWait {
+		x.queue = append(x.queue, task)
+		x.cond.Signal()
+		x.mu.Unlock()
+		return true
+	}
+	x.mu.Unlock()
+	if x.config.OverloadPolicy == OverloadFailOpen {
+		x.failOpen.Add(1)
+		return false
+	}
+	x.rejected.Add(1)
+	e.SendResponse(http.StatusServiceUnavailable, nil, []byte("overloaded"))
+	return true
+}
+
+// stats returns the snapshot of the state of the executor.
+func (x *executor) stats() ExecutorStats {
+	x.mu.Lock()
+	running, queued := x.running, len(x.queue)
+	x.mu.Unlock()
+	return ExecutorStats{
+		Running:    running,
+		Queued:     queued,
+		Rejected:   x.rejected.Load(),
+		FailedOpen: x.failOpen.Load(),
+	}
+}
+
+// close stops accepting new tasks and drops the queued ones, and then waits for the running tasks to finish up to
+// CloseTimeout. This is called on the Envoy main thread, so it must not wait for the tasks stuck or queued beyond
+// MaxQueue by OverloadWait.
+func (x *executor) close() {
+	x.mu.Lock()
+	if x.closed {
+		x.mu.Unlock()
+		return
+	}
+	x.closed = true
+	clear(x.queue)
+	x.queue = nil
+	x.cond.Broadcast()
+	x.mu.Unlock()
+	if x.stopStats != nil {
+		close(x.stopStats)
+	}
+	if x.config.CloseTimeout < 0 {
+		return
+	}
+	finished := make(chan struct{})
+	go func() {
+		x.workers.Wait()
+		close(finished)
+	}()
+	timer := time.NewTimer(x.config.CloseTimeout)
+	defer timer.Stop()
+	select {
+	case <-finished:
+	case <-timer.C:
+	}
+}
+
+// reportStats sets the gauges to the stats every StatsInterval until close. See ExecutorConfig.StatsName.
+func (x *executor) reportStats() {
+	prefix := "go_sdk.executor." + sanitizeStatName(x.config.StatsName) + "."
+	setGauge := func(name string, value uint64) {
+		name = prefix + name
+		hostStatsSetGauge(stringPtr(name), len(name), value)
+	}
+	ticker := time.NewTicker(x.config.StatsInterval)
+	defer ticker.Stop()
+	for {
+		s := x.stats()
+		setGauge("running", uint64(s.Running))
+		setGauge("queued", uint64(s.Queued))
+		setGauge("rejected", s.Rejected)
+		setGauge("failed_open", s.FailedOpen)
+		select {
+		case <-x.stopStats:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Submit queues the task on the executor of the HttpFilter, see ExecutorProvider. The task is responsible for
+// resuming the stream, e.g. by calling ContinueRequest, which is no-op if the stream has gone away in the meantime.
+// This never blocks, so it can be called in the event callbacks.
+//
+// Returns true if the caller must return a Stop status from the event callback, i.e. the task is queued or the 503
+// response is sent by OverloadReject. Returns false without running the task if the task is dropped by
+// OverloadFailOpen, the HttpFilter doesn't implement ExecutorProvider, or the stream or the HttpFilter is already
+// destroyed, in which case the caller should return the Continue status from the event callback if any.
+func (c *envoyFilterInstance) Submit(task func()) bool {
+	if c.filter.executor == nil || c.Err() != nil {
+		return false
+	}
+	return c.filter.executor.submit(c, task)
+}
+
+// ExecutorStats returns the snapshot of the state of the executor of the HttpFilter, or the zero value if the
+// HttpFilter doesn't implement ExecutorProvider. This can be called from any Goroutine.
+func (c *envoyFilterInstance) ExecutorStats() ExecutorStats {
+	if c.filter.executor == nil {
+		return ExecutorStats{}
+	}
+	return c.filter.executor.stats()
+}
@@ -0,0 +1,231 @@
+//go:build !cgo
+
+package envoy
+
+import (
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// executorHttpFilter is the HttpFilter implementing ExecutorProvider with the config.
+type executorHttpFilter struct {
+	bodyHttpFilter
+	config  ExecutorConfig
+	destroy func()
+}
+
+func (f executorHttpFilter) Executor() ExecutorConfig { return f.config }
+
+func (f executorHttpFilter) Destroy() {
+	if f.destroy != nil {
+		f.destroy()
+	}
+}
+
+// fakeLocalReplies sets FakeHost.HttpSendResponse for the test, and returns the status codes of the local replies
+// by the pointer of the EnvoyFilterInstance.
+func fakeLocalReplies(t *testing.T) func() map[uintptr]uint32 {
+	var mu sync.Mutex
+	replies := map[uintptr]uint32{}
+	prev := FakeHost.HttpSendResponse
+	FakeHost.HttpSendResponse = func(envoyFilterInstancePtr uintptr, statusCode uint32, _ unsafe.Pointer, _ int,
+		_ unsafe.Pointer, _ int) {
+		mu.Lock()
+		defer mu.Unlock()
+		replies[envoyFilterInstancePtr] = statusCode
+	}
+	t.Cleanup(func() { FakeHost.HttpSendResponse = prev })
+	return func() map[uintptr]uint32 {
+		mu.Lock()
+		defer mu.Unlock()
+		return replies
+	}
+}
+
+func TestExecutorOverload(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		policy OverloadPolicy
+		// submitted and replied are the results of Submit and the local reply of the stream overloading.
+		submitted bool
+		replied   uint32
+		// ran is the number of the tasks run in the end.
+		ran                  int
+		rejected, failedOpen uint64
+	}{
+		{name: "reject", policy: OverloadReject, submitted: true, replied: 503, ran: 2, rejected: 1},
+		{name: "fail open", policy: OverloadFailOpen, submitted: false, ran: 2, failedOpen: 1},
+		{name: "wait", policy: OverloadWait, submitted: true, ran: 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			replies := fakeLocalReplies(t)
+			filter := newTestHttpFilter(t, executorHttpFilter{
+				config: ExecutorConfig{MaxConcurrency: 1, MaxQueue: 1, OverloadPolicy: tc.policy},
+			})
+			var instances [3]uintptr
+			for i := range instances {
+				instances[i] = eventHttpFilterInstanceInit(uintptr(i+1), filter)
+				defer eventHttpFilterInstanceDestroy(instances[i])
+			}
+			envoyOf := func(i int) EnvoyFilterInstance { return unwrapRawPinHttpFilterInstance(instances[i]).envoy }
+
+			release := make(chan struct{})
+			var ran sync.WaitGroup
+			var mu sync.Mutex
+			var order []int
+			task := func(i int) func() {
+				return func() {
+					<-release
+					mu.Lock()
+					order = append(order, i)
+					mu.Unlock()
+					ran.Done()
+				}
+			}
+			// The first task occupies the only worker, and the second one fills the queue.
+			ran.Add(2)
+			if !envoyOf(0).Submit(task(0)) || !envoyOf(1).Submit(task(1)) {
+				t.Fatal("Submit within the capacity returned false")
+			}
+			if tc.policy == OverloadWait {
+				ran.Add(1)
+			}
+			if got := envoyOf(2).Submit(task(2)); got != tc.submitted {
+				t.Fatalf("Submit beyond the capacity = %t, want %t", got, tc.submitted)
+			}
+			if got := replies()[3]; got != tc.replied {
+				t.Fatalf("local reply of the overloading stream = %d, want %d", got, tc.replied)
+			}
+			close(release)
+			ran.Wait()
+			if len(order) != tc.ran || order[0] != 0 || order[1] != 1 {
+				t.Fatalf("tasks ran in %v, want %d tasks in order", order, tc.ran)
+			}
+			stats := envoyOf(0).ExecutorStats()
+			if stats.Rejected != tc.rejected || stats.FailedOpen != tc.failedOpen || stats.Running != 0 ||
+				stats.Queued != 0 {
+				t.Fatalf("stats = %+v", stats)
+			}
+		})
+	}
+}
+
+func TestExecutorShutdown(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	prev := NewHttpFilter
+	NewHttpFilter = func(string) HttpFilter {
+		return executorHttpFilter{
+			config:  ExecutorConfig{MaxConcurrency: 1, MaxQueue: 4},
+			destroy: func() { record("destroy") },
+		}
+	}
+	config := t.Name()
+	filter := eventHttpFilterInit(uintptr(unsafe.Pointer(unsafe.StringData(config))), len(config))
+	NewHttpFilter = prev
+
+	instance := eventHttpFilterInstanceInit(1, filter)
+	e := unwrapRawPinHttpFilterInstance(instance).envoy
+	started := make(chan struct{})
+	release := make(chan struct{})
+	e.Submit(func() {
+		close(started)
+		<-release
+		record("running")
+	})
+	e.Submit(func() { record("queued") })
+	<-started
+	if stats := e.ExecutorStats(); stats.Running != 1 || stats.Queued != 1 {
+		t.Fatalf("stats = %+v, want the running and queued tasks", stats)
+	}
+	eventHttpFilterInstanceDestroy(instance)
+
+	// The destroy of the HttpFilter drops the queued task, and waits for the running one before calling
+	// HttpFilter.Destroy.
+	destroyed := make(chan struct{})
+	go func() {
+		eventHttpFilterDestroy(filter)
+		close(destroyed)
+	}()
+	// The queued task is dropped by the close before the running one is released.
+	for e.ExecutorStats().Queued != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	<-destroyed
+	if len(events) != 2 || events[0] != "running" || events[1] != "destroy" {
+		t.Fatalf("events = %v, want the running task followed by destroy", events)
+	}
+}
+
+func TestExecutorCloseTimeout(t *testing.T) {
+	destroyed := make(chan struct{})
+	prev := NewHttpFilter
+	NewHttpFilter = func(string) HttpFilter {
+		return executorHttpFilter{
+			config:  ExecutorConfig{MaxConcurrency: 1, OverloadPolicy: OverloadWait, CloseTimeout: 10 * time.Millisecond},
+			destroy: func() { close(destroyed) },
+		}
+	}
+	config := t.Name()
+	filter := eventHttpFilterInit(uintptr(unsafe.Pointer(unsafe.StringData(config))), len(config))
+	NewHttpFilter = prev
+
+	instance := eventHttpFilterInstanceInit(1, filter)
+	e := unwrapRawPinHttpFilterInstance(instance).envoy
+	started, stuck, finished := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	defer close(stuck)
+	e.Submit(func() {
+		close(started)
+		<-stuck
+		close(finished)
+	})
+	<-started
+	// The tasks queued beyond MaxQueue by OverloadWait are dropped as well.
+	for range 100 {
+		e.Submit(func() { t.Error("the queued task ran after the close") })
+	}
+	eventHttpFilterInstanceDestroy(instance)
+
+	// The destroy of the HttpFilter doesn't wait for the stuck task beyond CloseTimeout.
+	go eventHttpFilterDestroy(filter)
+	select {
+	case <-destroyed:
+	case <-time.After(time.Second):
+		t.Fatal("the destroy waited for the stuck task")
+	}
+	select {
+	case <-finished:
+		t.Fatal("the stuck task finished before the destroy")
+	default:
+	}
+	if stats := e.ExecutorStats(); stats.Running != 1 || stats.Queued != 0 {
+		t.Fatalf("stats = %+v, want only the stuck task", stats)
+	}
+}
+
+func TestExecutorSubmitAfterDestroy(t *testing.T) {
+	filter := newTestHttpFilter(t, executorHttpFilter{config: ExecutorConfig{MaxConcurrency: 1}})
+	instance := eventHttpFilterInstanceInit(1, filter)
+	e := unwrapRawPinHttpFilterInstance(instance).envoy
+	eventHttpFilterInstanceDestroy(instance)
+	if e.Submit(func() { t.Error("the task of the destroyed stream ran") }) {
+		t.Fatal("Submit after the stream is destroyed returned true")
+	}
+
+	// The HttpFilter without ExecutorProvider has no executor.
+	filter = newTestHttpFilter(t, bodyHttpFilter{})
+	instance = eventHttpFilterInstanceInit(1, filter)
+	defer eventHttpFilterInstanceDestroy(instance)
+	e = unwrapRawPinHttpFilterInstance(instance).envoy
+	if e.Submit(func() { t.Error("the task ran without the executor") }) {
+		t.Fatal("Submit without ExecutorProvider returned true")
+	}
+}
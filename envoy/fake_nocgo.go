@@ -0,0 +1,66 @@
+//go:build !cgo
+
+package envoy
+
+// FakeEvent is a fake event callback in the builds without cgo, which is used together with FakeHost to test
+// the module code without Envoy. The objects created by FakeEvent are only valid until End is called, in the same
+// way as the objects passed to the real event callbacks. With the envoydebug build tag, using them or the
+// HeaderValue(s) retrieved from them after End panics, e.g.
+//
+//	event := envoy.NewFakeEvent()
+//	status := instance.RequestHeaders(event.RequestHeaders(1), true)
+//	event.End()
+type FakeEvent struct {
+	stamp viewStamp
+}
+
+// NewFakeEvent starts a new fake event callback.
+func NewFakeEvent() *FakeEvent {
+	return &FakeEvent{stamp: newViewStamp()}
+}
+
+// RequestHeaders returns the RequestHeaders valid during the event. `raw` is passed to the FakeHost functions
+// as the pointer to the header map, so it can be used to distinguish the maps in the fake implementations.
+func (e *FakeEvent) RequestHeaders(raw uintptr) RequestHeaders {
+	return RequestHeaders{stamp: e.stamp, raw: raw}
+}
+
+// ResponseHeaders returns the ResponseHeaders valid during the event. See RequestHeaders for `raw`.
+func (e *FakeEvent) ResponseHeaders(raw uintptr) ResponseHeaders {
+	return ResponseHeaders{stamp: e.stamp, raw: raw}
+}
+
+// RequestBody returns the RequestBodyBuffer valid during the event. See RequestHeaders for `raw`.
+func (e *FakeEvent) RequestBody(raw uintptr) RequestBodyBuffer {
+	return RequestBodyBuffer{stamp: e.stamp, raw: raw}
+}
+
+// ResponseBody returns the ResponseBodyBuffer valid during the event. See RequestHeaders for `raw`.
+func (e *FakeEvent) ResponseBody(raw uintptr) ResponseBodyBuffer {
+	return ResponseBodyBuffer{stamp: e.stamp, raw: raw}
+}
+
+// AccessLogEntry returns the AccessLogEntry valid during the event. See RequestHeaders for `raw`.
+func (e *FakeEvent) AccessLogEntry(raw uintptr) AccessLogEntry {
+	return AccessLogEntry{stamp: e.stamp, raw: raw}
+}
+
+// NetworkReadBuffer returns the NetworkReadBuffer valid during the event. See RequestHeaders for `raw`.
+func (e *FakeEvent) NetworkReadBuffer(raw uintptr) NetworkReadBuffer {
+	return NetworkReadBuffer{stamp: e.stamp, raw: raw}
+}
+
+// NetworkWriteBuffer returns the NetworkWriteBuffer valid during the event. See RequestHeaders for `raw`.
+func (e *FakeEvent) NetworkWriteBuffer(raw uintptr) NetworkWriteBuffer {
+	return NetworkWriteBuffer{stamp: e.stamp, raw: raw}
+}
+
+// ListenerPeekBuffer returns the ListenerPeekBuffer valid during the event. See RequestHeaders for `raw`.
+func (e *FakeEvent) ListenerPeekBuffer(raw uintptr) ListenerPeekBuffer {
+	return ListenerPeekBuffer{stamp: e.stamp, raw: raw}
+}
+
+// End ends the event callback.
+func (e *FakeEvent) End() {
+	e.stamp.expire()
+}
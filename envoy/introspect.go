@@ -0,0 +1,322 @@
+package envoy
+
+import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// PinnedObjects is a snapshot of the objects that are alive in the module because Envoy holds them,
+// e.g. the HttpFilterInstance(s) of the streams that are not destroyed yet. Monitoring this over time
+// helps detecting leaks, e.g. the instances that are never destroyed by Envoy.
+//
+// The snapshot is taken shard by shard without stopping the Envoy worker threads, so the numbers can be
+// slightly inconsistent with each other while the objects are created and destroyed concurrently.
+type PinnedObjects struct {
+	HttpFilters             int
+	HttpFilterInstances     int
+	NetworkFilters          int
+	NetworkFilterInstances  int
+	ListenerFilters         int
+	ListenerFilterInstances int
+	AccessLoggers           int
+	// HttpFilterInstanceAges is the histogram of the ages of all the HttpFilterInstances.
+	HttpFilterInstanceAges AgeHistogram
+	// HttpFilterConfigs is the breakdown of the HttpFilters and HttpFilterInstances by the config,
+	// sorted by the config and then the downstream filters first.
+	HttpFilterConfigs []PinnedHttpFilterConfig
+}
+
+// PinnedHttpFilterConfig is the breakdown of PinnedObjects for a config of HttpFilter.
+type PinnedHttpFilterConfig struct {
+	// Config is the config string passed to NewHttpFilter or NewUpstreamHttpFilter.
+	Config string
+	// Upstream is true if the filters are created by NewUpstreamHttpFilter.
+	Upstream bool
+	// StatName is the name of the config in the gauges of ReportPinnedObjects. See StatNameProvider.
+	StatName string
+	// HttpFilters is the number of the live HttpFilters created from the config. This can be more than one
+	// when the same config is used in multiple places in the Envoy configuration, or while Envoy drains the
+	// old listeners after the configuration update.
+	HttpFilters int
+	// HttpFilterInstances is the number of the live HttpFilterInstances created by the HttpFilters.
+	HttpFilterInstances int
+	// HttpFilterInstanceAges is the histogram of the ages of the HttpFilterInstances.
+	HttpFilterInstanceAges AgeHistogram
+}
+
+// AgeHistogramBounds are the upper bounds of the buckets of AgeHistogram except the last bucket.
+var AgeHistogramBounds = [...]time.Duration{time.Second, 10 * time.Second, time.Minute, 10 * time.Minute, time.Hour}
+
+// AgeHistogram is the histogram of the ages of the objects.
+type AgeHistogram struct {
+	// Counts[i] is the number of the objects whose age is less than AgeHistogramBounds[i] and not less than
+	// AgeHistogramBounds[i-1]. The last is the number of the objects older than all the bounds.
+	Counts [len(AgeHistogramBounds) + 1]int
+	// Oldest is the age of the oldest object, or zero if there's no object.
+	Oldest time.Duration
+}
+
+// observe adds the age to the histogram.
+func (h *AgeHistogram) observe(age time.Duration) {
+	i := 0
+	for i < len(AgeHistogramBounds) && age >= AgeHistogramBounds[i] {
+		i++
+	}
+	h.Counts[i]++
+	h.Oldest = max(h.Oldest, age)
+}
+
+// OlderThan returns the number of the objects whose age is not less than AgeHistogramBounds[i].
+func (h *AgeHistogram) OlderThan(i int) (n int) {
+	for _, c := range h.Counts[i+1:] {
+		n += c
+	}
+	return
+}
+
+// SnapshotPinnedObjects returns the snapshot of the objects that are alive in the module. This iterates over all
+// the live HttpFilterInstances, so it is supposed to be called periodically, not per request.
+func SnapshotPinnedObjects() PinnedObjects {
+	m := &memManager
+	ret := PinnedObjects{
+		NetworkFilters:          m.networkFilters.len(),
+		NetworkFilterInstances:  m.networkFilterInstances.len(),
+		ListenerFilters:         m.listenerFilters.len(),
+		ListenerFilterInstances: m.listenerFilterInstances.len(),
+		AccessLoggers:           m.accessLoggers.len(),
+	}
+
+	configs := map[*pinedHttpFilter]*PinnedHttpFilterConfig{}
+	byConfig := map[PinnedHttpFilterConfig]*PinnedHttpFilterConfig{}
+	configOf := func(filter *pinedHttpFilter) *PinnedHttpFilterConfig {
+		if c, ok := configs[filter]; ok {
+			return c
+		}
+		key := PinnedHttpFilterConfig{Config: filter.config, Upstream: filter.upstream, StatName: filter.statName}
+		c, ok := byConfig[key]
+		if !ok {
+			c = &key
+			byConfig[key] = c
+		}
+		configs[filter] = c
+		return c
+	}
+	m.httpFilters.forEach(func(link *pinLink) {
+		ret.HttpFilters++
+		configOf((*pinedHttpFilter)(unsafe.Pointer(link))).HttpFilters++
+	})
+
+	now := time.Since(memEpoch)
+	m.httpFilterInstances.forEach(func(link *pinLink) {
+		instance := (*pinedHttpFilterInstance)(unsafe.Pointer(link))
+		age := now - instance.created
+		ret.HttpFilterInstances++
+		ret.HttpFilterInstanceAges.observe(age)
+		c := configOf(instance.filter)
+		c.HttpFilterInstances++
+		c.HttpFilterInstanceAges.observe(age)
+	})
+
+	ret.HttpFilterConfigs = make([]PinnedHttpFilterConfig, 0, len(byConfig))
+	for _, c := range byConfig {
+		ret.HttpFilterConfigs = append(ret.HttpFilterConfigs, *c)
+	}
+	slices.SortFunc(ret.HttpFilterConfigs, func(a, b PinnedHttpFilterConfig) int {
+		if c := strings.Compare(a.Config, b.Config); c != 0 {
+			return c
+		}
+		return cmp.Compare(boolToInt(a.Upstream), boolToInt(b.Upstream))
+	})
+	return ret
+}
+
+// TrackHttpFilterInstanceStacks enables or disables recording the stack where each HttpFilterInstance is created,
+// which is reported by PinnedHttpFilterInstances. This is a debug mode to find out the leaking instances, and it
+// costs a stack walk and an allocation per instance while enabled. The instances created before enabling it don't
+// have the stacks.
+func TrackHttpFilterInstanceStacks(enabled bool) {
+	trackHttpFilterInstanceStacks.Store(enabled)
+}
+
+// PinnedHttpFilterInstance is a live HttpFilterInstance returned by PinnedHttpFilterInstances.
+type PinnedHttpFilterInstance struct {
+	// Config is the config of the HttpFilter that created the instance.
+	Config string
+	// Upstream is true if the HttpFilter is created by NewUpstreamHttpFilter.
+	Upstream bool
+	// Age is the time elapsed since the instance was created.
+	Age time.Duration
+	// Instance is the HttpFilterInstance itself. This must not be used to call the methods of the instance
+	// as it may be used by the Envoy worker thread concurrently. This is to identify the instance,
+	// e.g. by printing it with %+v.
+	Instance HttpFilterInstance
+	// Stack is the formatted stack where the instance was created, or empty if TrackHttpFilterInstanceStacks was
+	// not enabled at that time.
+	Stack string
+}
+
+// PinnedHttpFilterInstances returns the live HttpFilterInstances that are older than or equal to minAge,
+// sorted by the age in descending order. The instances that live much longer than the expected stream duration
+// are likely leaking, e.g. Envoy never delivered the destroy event.
+func PinnedHttpFilterInstances(minAge time.Duration) []PinnedHttpFilterInstance {
+	type item struct {
+		PinnedHttpFilterInstance
+		stack []uintptr
+	}
+	var items []item
+	now := time.Since(memEpoch)
+	memManager.httpFilterInstances.forEach(func(link *pinLink) {
+		instance := (*pinedHttpFilterInstance)(unsafe.Pointer(link))
+		if age := now - instance.created; age >= minAge {
+			items = append(items, item{PinnedHttpFilterInstance{
+				Config:   instance.filter.config,
+				Upstream: instance.filter.upstream,
+				Age:      age,
+				Instance: instance.filterInstance,
+			}, instance.stack})
+		}
+	})
+	// Formatting the stacks is done after releasing the locks of the shards.
+	ret := make([]PinnedHttpFilterInstance, len(items))
+	for i := range items {
+		ret[i] = items[i].PinnedHttpFilterInstance
+		ret[i].Stack = formatStack(items[i].stack)
+	}
+	slices.SortStableFunc(ret, func(a, b PinnedHttpFilterInstance) int {
+		return cmp.Compare(b.Age, a.Age)
+	})
+	return ret
+}
+
+// formatStack formats the program counters in the same way as runtime/debug.Stack without the goroutine header.
+func formatStack(pcs []uintptr) string {
+	if len(pcs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		b.WriteString(frame.Function)
+		b.WriteString("()\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		b.WriteByte('\n')
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// ReportPinnedObjects starts a Goroutine that sets the Envoy gauges to SnapshotPinnedObjects every interval,
+// and returns the function that stops it. This is supposed to be called in the hook of OnProgramInit.
+// This does nothing if the host doesn't support FeatureStats.
+//
+// The gauges are in the stats scope of the module:
+//   - go_sdk.http_filters, go_sdk.http_filter_instances, and so on for the other kinds of PinnedObjects.
+//   - go_sdk.http_filter_instances.older_than_<bound>, e.g. older_than_1m0s, for each of AgeHistogramBounds.
+//   - go_sdk.http_filter_instances.config.<name>, and go_sdk.upstream_http_filter_instances.config.<name> for
+//     the upstream filters. <name> is the stat name of the HttpFilter. See StatNameProvider.
+func ReportPinnedObjects(interval time.Duration) (stop func()) {
+	if !HostSupports(FeatureStats) {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		// The per-config gauges reported previously, so that they are reset to zero when the config is gone.
+		reported := map[string]bool{}
+		for {
+			reportPinnedObjects(SnapshotPinnedObjects(), reported)
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// reportPinnedObjects sets the gauges to the snapshot. See ReportPinnedObjects.
+func reportPinnedObjects(s PinnedObjects, reported map[string]bool) {
+	setGauge := func(name string, value int) {
+		hostStatsSetGauge(stringPtr(name), len(name), uint64(value))
+	}
+	setGauge("go_sdk.http_filters", s.HttpFilters)
+	setGauge("go_sdk.http_filter_instances", s.HttpFilterInstances)
+	setGauge("go_sdk.network_filters", s.NetworkFilters)
+	setGauge("go_sdk.network_filter_instances", s.NetworkFilterInstances)
+	setGauge("go_sdk.listener_filters", s.ListenerFilters)
+	setGauge("go_sdk.listener_filter_instances", s.ListenerFilterInstances)
+	setGauge("go_sdk.access_loggers", s.AccessLoggers)
+	for i, bound := range AgeHistogramBounds {
+		setGauge("go_sdk.http_filter_instances.older_than_"+bound.String(), s.HttpFilterInstanceAges.OlderThan(i))
+	}
+
+	current := make(map[string]int, len(s.HttpFilterConfigs))
+	for _, c := range s.HttpFilterConfigs {
+		prefix := "go_sdk.http_filter_instances.config."
+		if c.Upstream {
+			prefix = "go_sdk.upstream_http_filter_instances.config."
+		}
+		// Different configs can have the same stat name, so the values are summed.
+		current[prefix+c.StatName] += c.HttpFilterInstances
+	}
+	for name, value := range current {
+		setGauge(name, value)
+		reported[name] = true
+	}
+	for name := range reported {
+		if _, ok := current[name]; !ok {
+			setGauge(name, 0)
+			delete(reported, name)
+		}
+	}
+}
+
+// StatNameProvider is an optional interface that can be implemented by HttpFilter to name it in the stats of the SDK,
+// e.g. the gauges of ReportPinnedObjects and BodyLimitStats. This is detected when the HttpFilter is created.
+//
+// Without this, the stat name is "config_" followed by the hex of the 32-bit FNV-1a hash of the whole config, so that
+// the filters with different configs don't share the gauges and the config itself is never exposed in the stats.
+type StatNameProvider interface {
+	// StatName returns the name of the HttpFilter in the stats. The characters other than [a-zA-Z0-9_-] are
+	// replaced with '_'. This is called once when the HttpFilter is created.
+	StatName() string
+}
+
+// httpFilterStatName returns the stat name of the HttpFilter created from the config. See StatNameProvider.
+func httpFilterStatName(filter HttpFilter, config string) string {
+	if p, ok := filter.(StatNameProvider); ok {
+		return sanitizeStatName(p.StatName())
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(config))
+	return fmt.Sprintf("config_%08x", h.Sum32())
+}
+
+// sanitizeStatName makes the name usable as a part of the stat name.
+func sanitizeStatName(name string) string {
+	if name == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,129 @@
+//go:build !cgo
+
+package envoy
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// fakeGauges sets FakeHost.StatsSetGauge for the test, and returns the gauges set by the SDK.
+func fakeGauges(t *testing.T) map[string]uint64 {
+	gauges := map[string]uint64{}
+	prev := FakeHost.StatsSetGauge
+	FakeHost.StatsSetGauge = func(name unsafe.Pointer, nameLength int, value uint64) {
+		gauges[unsafe.String((*byte)(name), nameLength)] = value
+	}
+	t.Cleanup(func() { FakeHost.StatsSetGauge = prev })
+	return gauges
+}
+
+func TestAgeHistogram(t *testing.T) {
+	var h AgeHistogram
+	for _, age := range []time.Duration{
+		// < 1s
+		0, 500 * time.Millisecond,
+		// < 10s
+		time.Second, 9 * time.Second,
+		// < 1m
+		30 * time.Second,
+		// < 1h
+		10 * time.Minute, 59 * time.Minute,
+		// >= 1h
+		2 * time.Hour,
+	} {
+		h.observe(age)
+	}
+	if want := [...]int{2, 2, 1, 0, 2, 1}; h.Counts != want {
+		t.Fatalf("Counts = %v, want %v", h.Counts, want)
+	}
+	if h.Oldest != 2*time.Hour {
+		t.Fatalf("Oldest = %v, want 2h", h.Oldest)
+	}
+	for i, want := range []int{6, 4, 3, 3, 1} {
+		if got := h.OlderThan(i); got != want {
+			t.Errorf("OlderThan(%d) = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestReportPinnedObjectsZeroesGoneConfigs(t *testing.T) {
+	gauges := fakeGauges(t)
+	reported := map[string]bool{}
+	reportPinnedObjects(PinnedObjects{
+		HttpFilterInstances: 5,
+		HttpFilterConfigs: []PinnedHttpFilterConfig{
+			{Config: "a", StatName: "a", HttpFilterInstances: 2},
+			{Config: "b", StatName: "b", HttpFilterInstances: 3},
+			{Config: "b", StatName: "b", Upstream: true, HttpFilterInstances: 1},
+		},
+	}, reported)
+	for name, want := range map[string]uint64{
+		"go_sdk.http_filter_instances":                   5,
+		"go_sdk.http_filter_instances.config.a":          2,
+		"go_sdk.http_filter_instances.config.b":          3,
+		"go_sdk.upstream_http_filter_instances.config.b": 1,
+	} {
+		if gauges[name] != want {
+			t.Errorf("%s = %d, want %d", name, gauges[name], want)
+		}
+	}
+
+	// The config b is gone, so its gauges are set to zero once and forgotten.
+	reportPinnedObjects(PinnedObjects{
+		HttpFilterInstances: 4,
+		HttpFilterConfigs:   []PinnedHttpFilterConfig{{Config: "a", StatName: "a", HttpFilterInstances: 4}},
+	}, reported)
+	for name, want := range map[string]uint64{
+		"go_sdk.http_filter_instances.config.a":          4,
+		"go_sdk.http_filter_instances.config.b":          0,
+		"go_sdk.upstream_http_filter_instances.config.b": 0,
+	} {
+		if gauges[name] != want {
+			t.Errorf("%s = %d, want %d", name, gauges[name], want)
+		}
+	}
+	if len(reported) != 1 || !reported["go_sdk.http_filter_instances.config.a"] {
+		t.Errorf("reported = %v, want only the config a", reported)
+	}
+}
+
+type statNameHttpFilter struct {
+	nopHttpFilter
+	name string
+}
+
+func (f statNameHttpFilter) StatName() string { return f.name }
+
+type nopHttpFilter struct{}
+
+func (nopHttpFilter) NewInstance(EnvoyFilterInstance) HttpFilterInstance { return nil }
+
+func (nopHttpFilter) Destroy() {}
+
+func TestHttpFilterStatName(t *testing.T) {
+	// The configs sharing a long prefix get different names, which don't contain the config.
+	prefix := `{"api_key":"secret","rules":[` + strings.Repeat(`{"match":"x"},`, 8)
+	a := httpFilterStatName(nopHttpFilter{}, prefix+`{"match":"a"}]}`)
+	b := httpFilterStatName(nopHttpFilter{}, prefix+`{"match":"b"}]}`)
+	if a == b {
+		t.Fatalf("different configs got the same stat name %q", a)
+	}
+	for _, name := range []string{a, b} {
+		if !strings.HasPrefix(name, "config_") || len(name) != len("config_")+8 || strings.Contains(name, "secret") {
+			t.Fatalf("stat name %q is not config_ followed by the hash", name)
+		}
+	}
+	if got := httpFilterStatName(nopHttpFilter{}, prefix); got != httpFilterStatName(nopHttpFilter{}, prefix) {
+		t.Fatalf("stat name %q is not stable", got)
+	}
+
+	if got := httpFilterStatName(statNameHttpFilter{name: "my.filter v2"}, prefix); got != "my_filter_v2" {
+		t.Fatalf("StatNameProvider: got %q, want my_filter_v2", got)
+	}
+	if got := httpFilterStatName(statNameHttpFilter{}, prefix); got != "_" {
+		t.Fatalf("empty StatName: got %q, want _", got)
+	}
+}
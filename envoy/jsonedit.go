@@ -0,0 +1,766 @@
+package envoy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// JSONEditOp is the operation of JSONEdit.
+type JSONEditOp int
+
+const (
+	// JSONEditSet replaces the value at the path with Value. If the path has no wildcards, the missing members
+	// along the path are added as objects to the end of their parent objects. The arrays are never created or
+	// extended, so an index segment of the missing members is added as the member name, e.g. "a.0" sets {} to
+	// {"a":{"0":Value}}, and the missing element of an existing array is not added.
+	JSONEditSet JSONEditOp = iota
+	// JSONEditDelete deletes the member or the element at the path.
+	JSONEditDelete
+	// JSONEditRename renames the member at the path to To.
+	JSONEditRename
+	// JSONEditRedact replaces the substrings matching Pattern with Replacement in the string values at the path and
+	// in all the string values under it, or replaces the entire value with the string Replacement if Pattern is nil.
+	JSONEditRedact
+)
+
+// JSONEdit is an edit of the JSON body.
+type JSONEdit struct {
+	Op JSONEditOp
+	// Path is the dot-separated path of the values to edit from the root, e.g. "user.email" or "items.0.id".
+	// A segment is a member name of an object or an index of an array. "*" matches any member or element, and "**"
+	// matches any number of the segments including zero, e.g. "**.ssn" matches the "ssn" members at any depth.
+	// A backslash escapes the following character, e.g. "a\.b" is the member "a.b".
+	Path string
+	// Value is the value marshaled with encoding/json for JSONEditSet.
+	Value any
+	// To is the new member name for JSONEditRename.
+	To string
+	// Pattern and Replacement are for JSONEditRedact. Replacement can refer to the submatches of Pattern in the same
+	// way as regexp.Regexp.ReplaceAllString.
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// JSONEditor is the compiled list of JSONEdit, which is immutable and shared between the streams. This is typically
+// created in envoy.NewHttpFilter, and JSONBodyEditor is created for each stream.
+//
+// Each value in the document is edited by the first edit of JSONEditSet, JSONEditDelete or JSONEditRedact whose path
+// matches the value, and its member is renamed by the first matching JSONEditRename. The paths are matched against
+// the original document, so the edits don't see the results of the other edits, and the values replaced or deleted
+// are not edited further. The output is compact, i.e. the whitespace between the tokens is removed, and the tokens
+// not edited are emitted as is.
+type JSONEditor struct {
+	edits []jsonEdit
+	// creates is true if any JSONEditSet can add the missing members.
+	creates bool
+}
+
+type jsonEdit struct {
+	op   JSONEditOp
+	path []jsonSegment
+	// literal is true if the path has no wildcards.
+	literal bool
+	// value is the marshaled Value of JSONEditSet, or the marshaled Replacement of JSONEditRedact without Pattern.
+	value []byte
+	// to is the marshaled To of JSONEditRename.
+	to          []byte
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// jsonSegment is a segment of the path, which is a member name and an index if it's a non-negative integer.
+type jsonSegment struct {
+	key       string
+	index     int
+	any, deep bool
+}
+
+// NewJSONEditor compiles the edits, and returns an error if any edit is invalid.
+func NewJSONEditor(edits ...JSONEdit) (*JSONEditor, error) {
+	ret := &JSONEditor{}
+	for i, e := range edits {
+		path, err := parseJSONPath(e.Path)
+		if err != nil {
+			return nil, fmt.Errorf("envoy: edit %d: %w", i, err)
+		}
+		edit := jsonEdit{op: e.Op, path: path, literal: true, pattern: e.Pattern, replacement: e.Replacement}
+		for _, s := range path {
+			edit.literal = edit.literal && !s.any && !s.deep
+		}
+		switch e.Op {
+		case JSONEditSet:
+			if edit.value, err = json.Marshal(e.Value); err != nil {
+				return nil, fmt.Errorf("envoy: edit %d: %w", i, err)
+			}
+			ret.creates = ret.creates || edit.literal
+		case JSONEditDelete:
+		case JSONEditRename:
+			edit.to, _ = json.Marshal(e.To)
+		case JSONEditRedact:
+			if e.Pattern == nil {
+				edit.value, _ = json.Marshal(e.Replacement)
+			}
+		default:
+			return nil, fmt.Errorf("envoy: edit %d: unknown JSONEditOp %d", i, e.Op)
+		}
+		ret.edits = append(ret.edits, edit)
+	}
+	return ret, nil
+}
+
+// parseJSONPath parses the dot-separated path.
+func parseJSONPath(path string) ([]jsonSegment, error) {
+	var ret []jsonSegment
+	var key strings.Builder
+	escaped := false
+	flush := func() error {
+		s := key.String()
+		key.Reset()
+		switch {
+		case s == "" && !escaped:
+			return fmt.Errorf("empty segment in path %q", path)
+		case s == "*" && !escaped:
+			ret = append(ret, jsonSegment{any: true})
+		case s == "**" && !escaped:
+			ret = append(ret, jsonSegment{deep: true})
+		default:
+			segment := jsonSegment{key: s, index: -1}
+			if n, err := strconv.Atoi(s); err == nil && n >= 0 && strconv.Itoa(n) == s {
+				segment.index = n
+			}
+			ret = append(ret, segment)
+		}
+		escaped = false
+		return nil
+	}
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\' && i+1 < len(path):
+			i++
+			key.WriteByte(path[i])
+			escaped = true
+		case c == '.':
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		default:
+			key.WriteByte(c)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Edit returns the entire document edited, which is the same as the concatenation of the chunks edited by
+// JSONBodyEditor in the streaming mode regardless of how the document is split. This returns an error if the data
+// is not a valid JSON document. The empty data or the data of only whitespace is returned as the empty document.
+func (j *JSONEditor) Edit(data []byte) ([]byte, error) {
+	s := jsonStream{editor: j}
+	return s.write(data, true)
+}
+
+// JSONBodyEditor edits the JSON request or response body of a stream with JSONEditor. By default, the body is
+// buffered in Envoy until the end of stream, and the entire body is replaced with the edited one, so the body is left
+// untouched if it's not a valid JSON document. In the streaming mode, which is for very large documents, each chunk is
+// edited and emitted as it arrives by keeping only the incomplete token and the path to the current value.
+//
+//	func (h *myHttpFilterInstance) RequestHeaders(headers envoy.RequestHeaders, endOfStream bool) envoy.RequestHeadersStatus {
+//		h.editor = h.filter.editor.NewBodyEditor(h.envoyFilter, false)
+//		h.editor.PrepareRequestHeaders(headers)
+//		return envoy.HeadersStatusContinue
+//	}
+//
+//	func (h *myHttpFilterInstance) RequestBody(body envoy.RequestBodyBuffer, endOfStream bool) envoy.RequestBodyStatus {
+//		status, err := h.editor.EditRequestBody(body, endOfStream)
+//		if err != nil {
+//			h.envoyFilter.SendResponse(400, nil, []byte(err.Error()))
+//		}
+//		return status
+//	}
+//
+// The compressed body is not edited. To edit it, call JSONEditor.Edit in the callback of EncodedBody instead.
+//
+// The methods return an error if the body is not a valid JSON document. Then the body is left untouched, and the
+// subsequent chunks pass through with the same error. As the part of the document before the error may have been
+// emitted in the streaming mode, the stream should be reset or replied locally on error.
+type JSONBodyEditor struct {
+	envoyFilter EnvoyFilterInstance
+	streaming   bool
+	enabled     bool
+	stream      jsonStream
+	err         error
+}
+
+// NewBodyEditor creates a new JSONBodyEditor for a direction of the stream of the EnvoyFilterInstance. If streaming
+// is true, the body is edited chunk by chunk instead of being buffered until the end of stream.
+func (j *JSONEditor) NewBodyEditor(e EnvoyFilterInstance, streaming bool) *JSONBodyEditor {
+	return &JSONBodyEditor{envoyFilter: e, streaming: streaming, stream: jsonStream{editor: j}}
+}
+
+// PrepareRequestHeaders must be called in RequestHeadersHandler.RequestHeaders before editing the request body.
+// This is the same as PrepareResponseHeaders for the request.
+func (b *JSONBodyEditor) PrepareRequestHeaders(headers RequestHeaders) bool {
+	contentType, _ := headers.Get("content-type")
+	contentEncoding, _ := headers.Get("content-encoding")
+	if !b.enable(contentType.String(), contentEncoding.String()) {
+		return false
+	}
+	headers.Remove("content-length")
+	return true
+}
+
+// PrepareResponseHeaders must be called in ResponseHeadersHandler.ResponseHeaders before editing the response body.
+// This removes the content-length header as the length of the edited body is unknown until the end.
+//
+// This returns false and disables the editing unless the content-type is JSON, i.e. application/json or a media
+// type with the +json suffix, and the body is not compressed. EditResponseBody passes through the body in that case.
+func (b *JSONBodyEditor) PrepareResponseHeaders(headers ResponseHeaders) bool {
+	contentType, _ := headers.Get("content-type")
+	contentEncoding, _ := headers.Get("content-encoding")
+	if !b.enable(contentType.String(), contentEncoding.String()) {
+		return false
+	}
+	headers.Remove("content-length")
+	return true
+}
+
+func (b *JSONBodyEditor) enable(contentType, contentEncoding string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	b.enabled = (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) &&
+		(contentEncoding == "" || strings.EqualFold(contentEncoding, "identity"))
+	return b.enabled
+}
+
+// EditRequestBody edits the request body, and returns the status that RequestBodyHandler.RequestBody must return.
+// This must be called in RequestBodyHandler.RequestBody.
+func (b *JSONBodyEditor) EditRequestBody(body RequestBodyBuffer, endOfStream bool) (RequestBodyStatus, error) {
+	switch {
+	case !b.enabled:
+		return RequestBodyStatusContinue, nil
+	case b.err != nil:
+		return RequestBodyStatusContinue, b.err
+	case b.streaming:
+		out, err := b.write(body.Copy(), endOfStream)
+		if err == nil {
+			body.Replace(out)
+		}
+		return RequestBodyStatusContinue, err
+	case !endOfStream:
+		return RequestBodyStatusStopIterationAndBuffer, nil
+	}
+	data, buffered := entireRequestBody(b.envoyFilter, body)
+	out, err := b.write(data, true)
+	if err != nil {
+		return RequestBodyStatusContinue, err
+	}
+	if buffered.raw != 0 {
+		buffered.Drain(buffered.Length())
+	}
+	body.Replace(out)
+	return RequestBodyStatusContinue, nil
+}
+
+// EditResponseBody edits the response body, and returns the status that ResponseBodyHandler.ResponseBody must return.
+// This must be called in ResponseBodyHandler.ResponseBody.
+func (b *JSONBodyEditor) EditResponseBody(body ResponseBodyBuffer, endOfStream bool) (ResponseBodyStatus, error) {
+	switch {
+	case !b.enabled:
+		return ResponseBodyStatusContinue, nil
+	case b.err != nil:
+		return ResponseBodyStatusContinue, b.err
+	case b.streaming:
+		out, err := b.write(body.Copy(), endOfStream)
+		if err == nil {
+			body.Replace(out)
+		}
+		return ResponseBodyStatusContinue, err
+	case !endOfStream:
+		return ResponseBodyStatusStopIterationAndBuffer, nil
+	}
+	data, buffered := entireResponseBody(b.envoyFilter, body)
+	out, err := b.write(data, true)
+	if err != nil {
+		return ResponseBodyStatusContinue, err
+	}
+	if buffered.raw != 0 {
+		buffered.Drain(buffered.Length())
+	}
+	body.Replace(out)
+	return ResponseBodyStatusContinue, nil
+}
+
+func (b *JSONBodyEditor) write(data []byte, endOfStream bool) ([]byte, error) {
+	out, err := b.stream.write(data, endOfStream)
+	if err != nil {
+		b.err = err
+	}
+	return out, err
+}
+
+// jsonState is the next token expected by jsonStream.
+type jsonState int
+
+const (
+	jsonStateValue jsonState = iota
+	// jsonStateValueOrEnd is after '[', and jsonStateKeyOrEnd is after '{'.
+	jsonStateValueOrEnd
+	jsonStateKeyOrEnd
+	jsonStateKey
+	jsonStateColon
+	jsonStateCommaOrEnd
+	// jsonStateDone is after the top-level value.
+	jsonStateDone
+)
+
+// jsonToken is the kind of the token in progress.
+type jsonToken int
+
+const (
+	jsonTokenNone jsonToken = iota
+	// jsonTokenKey is a member name, which is kept until the value begins.
+	jsonTokenKey
+	// jsonTokenString is a string value emitted as it arrives, or dropped if muted.
+	jsonTokenString
+	// jsonTokenRedact is a string value kept until the end to be redacted.
+	jsonTokenRedact
+	// jsonTokenLiteral is a number, true, false or null, which is kept until the end to be validated.
+	jsonTokenLiteral
+)
+
+// jsonFrame is an object or an array enclosing the current value.
+type jsonFrame struct {
+	object bool
+	// muted is true if the container is deleted or replaced, so nothing is emitted until the end of it.
+	muted bool
+	// redact is the JSONEditRedact with Pattern applied to the string values in the container.
+	redact *jsonEdit
+	// key and rawKey are the name of the current member, and index is the index of the current element.
+	key    string
+	rawKey []byte
+	index  int
+	// emitted is the number of the members or the elements emitted, which is used to emit the commas.
+	emitted int
+	// creates are the members added at the end of the object unless present.
+	creates []jsonCreate
+}
+
+type jsonCreate struct {
+	key     string
+	value   []byte
+	present bool
+}
+
+// jsonStream is the incremental tokenizer and editor of a JSON document, which is fed the chunks of the document.
+type jsonStream struct {
+	editor *JSONEditor
+	stack  []jsonFrame
+	state  jsonState
+	// started is true after the first byte of the top-level value.
+	started bool
+	// token, mute and buf are the kind, whether it's dropped, and the bytes kept of the token in progress.
+	token jsonToken
+	mute  bool
+	buf   []byte
+	// escape is true after a backslash in a string, and hex is the number of the hex digits remaining of \uXXXX.
+	escape bool
+	hex    int
+	// redact is the JSONEditRedact of the string in progress of jsonTokenRedact.
+	redact *jsonEdit
+	out    []byte
+	err    error
+}
+
+var errJSONUnexpectedEnd = errors.New("envoy: unexpected end of JSON input")
+
+// write edits the chunk and returns the output, which is valid until the next call.
+func (s *jsonStream) write(data []byte, endOfStream bool) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.out = s.out[:0]
+	for i := 0; i < len(data); i++ {
+		if err := s.step(data, &i); err != nil {
+			s.err = err
+			return nil, err
+		}
+	}
+	if endOfStream {
+		if s.token == jsonTokenLiteral {
+			if err := s.endLiteral(); err != nil {
+				s.err = err
+				return nil, err
+			}
+		}
+		if s.started && (s.state != jsonStateDone || s.token != jsonTokenNone) {
+			s.err = errJSONUnexpectedEnd
+			return nil, s.err
+		}
+	}
+	return s.out, nil
+}
+
+// step consumes data[*i], or more bytes of a string by advancing *i.
+func (s *jsonStream) step(data []byte, i *int) error {
+	c := data[*i]
+	switch s.token {
+	case jsonTokenLiteral:
+		if isJSONLiteralByte(c) {
+			s.buf = append(s.buf, c)
+			return nil
+		}
+		if err := s.endLiteral(); err != nil {
+			return err
+		}
+	case jsonTokenNone:
+	default:
+		return s.stepString(data, i)
+	}
+
+	switch c {
+	case ' ', '\t', '\n', '\r':
+		return nil
+	}
+	switch s.state {
+	case jsonStateValue:
+		return s.beginValue(c)
+	case jsonStateValueOrEnd:
+		if c == ']' {
+			return s.endContainer()
+		}
+		return s.beginValue(c)
+	case jsonStateKeyOrEnd, jsonStateKey:
+		switch {
+		case c == '}' && s.state == jsonStateKeyOrEnd:
+			return s.endContainer()
+		case c == '"':
+			s.token, s.buf = jsonTokenKey, append(s.buf[:0], c)
+			return nil
+		}
+	case jsonStateColon:
+		if c == ':' {
+			s.state = jsonStateValue
+			return nil
+		}
+	case jsonStateCommaOrEnd:
+		top := &s.stack[len(s.stack)-1]
+		switch {
+		case c == ',' && top.object:
+			s.state = jsonStateKey
+			return nil
+		case c == ',':
+			s.state = jsonStateValue
+			return nil
+		case c == '}' && top.object, c == ']' && !top.object:
+			return s.endContainer()
+		}
+	}
+	return fmt.Errorf("envoy: invalid character %q in JSON", c)
+}
+
+// stepString consumes the bytes of the string in progress up to the closing quote.
+func (s *jsonStream) stepString(data []byte, i *int) error {
+	start := *i
+	for ; *i < len(data); *i++ {
+		c := data[*i]
+		switch {
+		case c < 0x20:
+			return fmt.Errorf("envoy: invalid character %q in JSON string", c)
+		case s.hex > 0:
+			if !isHex(c) {
+				return fmt.Errorf("envoy: invalid character %q in \\u escape in JSON string", c)
+			}
+			s.hex--
+		case s.escape:
+			s.escape = false
+			switch c {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				s.hex = 4
+			default:
+				return fmt.Errorf("envoy: invalid escape %q in JSON string", c)
+			}
+		case c == '\\':
+			s.escape = true
+		case c == '"':
+			s.keep(data[start : *i+1])
+			return s.endString()
+		}
+	}
+	s.keep(data[start:])
+	*i = len(data) - 1
+	return nil
+}
+
+// keep emits or keeps the bytes of the string in progress.
+func (s *jsonStream) keep(b []byte) {
+	switch {
+	case s.token == jsonTokenString && !s.mute:
+		s.out = append(s.out, b...)
+	case s.token == jsonTokenKey, s.token == jsonTokenRedact:
+		s.buf = append(s.buf, b...)
+	}
+}
+
+func (s *jsonStream) endString() error {
+	token := s.token
+	s.token = jsonTokenNone
+	switch token {
+	case jsonTokenKey:
+		top := &s.stack[len(s.stack)-1]
+		if err := json.Unmarshal(s.buf, &top.key); err != nil {
+			return fmt.Errorf("envoy: invalid JSON string: %w", err)
+		}
+		top.rawKey = append(top.rawKey[:0], s.buf...)
+		for i := range top.creates {
+			top.creates[i].present = top.creates[i].present || top.creates[i].key == top.key
+		}
+		s.state = jsonStateColon
+		return nil
+	case jsonTokenRedact:
+		var str string
+		if err := json.Unmarshal(s.buf, &str); err != nil {
+			return fmt.Errorf("envoy: invalid JSON string: %w", err)
+		}
+		if redacted := s.redact.pattern.ReplaceAllString(str, s.redact.replacement); redacted != str {
+			b, _ := json.Marshal(redacted)
+			s.out = append(s.out, b...)
+		} else {
+			s.out = append(s.out, s.buf...)
+		}
+	}
+	s.endValue()
+	return nil
+}
+
+func (s *jsonStream) endLiteral() error {
+	s.token = jsonTokenNone
+	if !json.Valid(s.buf) {
+		return fmt.Errorf("envoy: invalid JSON literal %q", s.buf)
+	}
+	if !s.mute {
+		s.out = append(s.out, s.buf...)
+	}
+	s.endValue()
+	return nil
+}
+
+// beginValue begins the value of the first byte c at the current path, and decides how to edit it.
+func (s *jsonStream) beginValue(c byte) error {
+	if s.state == jsonStateDone || s.started && len(s.stack) == 0 {
+		return fmt.Errorf("envoy: invalid character %q after top-level JSON value", c)
+	}
+	s.started = true
+	mute, redact := s.decide()
+	switch {
+	case c == '{' || c == '[':
+		frame := jsonFrame{object: c == '{', muted: mute, redact: redact, index: -1}
+		if !mute {
+			s.out = append(s.out, c)
+			if frame.object && s.editor.creates {
+				frame.creates = s.creates()
+			}
+		}
+		s.stack = append(s.stack, frame)
+		if frame.object {
+			s.state = jsonStateKeyOrEnd
+		} else {
+			s.state = jsonStateValueOrEnd
+		}
+	case c == '"':
+		s.token, s.mute = jsonTokenString, mute
+		if !mute && redact != nil {
+			s.token, s.redact, s.buf = jsonTokenRedact, redact, s.buf[:0]
+		}
+		s.keep([]byte{c})
+	case c == '-' || c >= '0' && c <= '9' || c == 't' || c == 'f' || c == 'n':
+		s.token, s.mute, s.buf = jsonTokenLiteral, mute, append(s.buf[:0], c)
+	default:
+		return fmt.Errorf("envoy: invalid character %q in JSON", c)
+	}
+	return nil
+}
+
+// decide returns whether the value beginning at the current path is muted, and the JSONEditRedact applied to the
+// strings in it. This emits the comma, the member name and the replaced value as needed.
+func (s *jsonStream) decide() (mute bool, redact *jsonEdit) {
+	if len(s.stack) == 0 {
+		return false, nil
+	}
+	parent := &s.stack[len(s.stack)-1]
+	if !parent.object {
+		parent.index++
+	}
+	if parent.muted {
+		return true, nil
+	}
+	redact = parent.redact
+	var action, rename *jsonEdit
+	for i := range s.editor.edits {
+		e := &s.editor.edits[i]
+		if e.op == JSONEditRename && (rename != nil || !parent.object) || e.op != JSONEditRename && action != nil ||
+			!matchJSONPath(e.path, s.stack) {
+			continue
+		}
+		if e.op == JSONEditRename {
+			rename = e
+		} else {
+			action = e
+		}
+	}
+	switch {
+	case action == nil:
+	case action.op == JSONEditDelete:
+		return true, nil
+	case action.op == JSONEditSet || action.pattern == nil:
+		mute = true
+	default:
+		redact = action
+	}
+	if parent.emitted > 0 {
+		s.out = append(s.out, ',')
+	}
+	parent.emitted++
+	if parent.object {
+		if rename != nil {
+			s.out = append(s.out, rename.to...)
+		} else {
+			s.out = append(s.out, parent.rawKey...)
+		}
+		s.out = append(s.out, ':')
+	}
+	if mute {
+		s.out = append(s.out, action.value...)
+	}
+	return mute, redact
+}
+
+// creates returns the members that the literal JSONEditSet can add to the object beginning at the current path.
+func (s *jsonStream) creates() []jsonCreate {
+	var ret []jsonCreate
+	for i := range s.editor.edits {
+		e := &s.editor.edits[i]
+		if e.op != JSONEditSet || !e.literal || len(e.path) <= len(s.stack) ||
+			!matchJSONPath(e.path[:len(s.stack)], s.stack) {
+			continue
+		}
+		key := e.path[len(s.stack)].key
+		found := false
+		for _, c := range ret {
+			found = found || c.key == key
+		}
+		if !found {
+			ret = append(ret, jsonCreate{key: key, value: s.editor.createValue(e.path[:len(s.stack)+1])})
+		}
+	}
+	return ret
+}
+
+// createValue returns the value of the missing member at the literal path, which is the value of the first
+// JSONEditSet of the path, or the object of the members under the path otherwise.
+func (j *JSONEditor) createValue(path []jsonSegment) []byte {
+	var keys []string
+	for _, e := range j.edits {
+		if e.op != JSONEditSet || !e.literal || len(e.path) < len(path) || !equalJSONPath(e.path[:len(path)], path) {
+			continue
+		}
+		if len(e.path) == len(path) {
+			return e.value
+		}
+		if key := e.path[len(path)].key; !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	ret := []byte{'{'}
+	for i, key := range keys {
+		if i > 0 {
+			ret = append(ret, ',')
+		}
+		name, _ := json.Marshal(key)
+		ret = append(append(ret, name...), ':')
+		ret = append(ret, j.createValue(append(path[:len(path):len(path)], jsonSegment{key: key}))...)
+	}
+	return append(ret, '}')
+}
+
+// endContainer ends the object or the array at the top of the stack.
+func (s *jsonStream) endContainer() error {
+	top := &s.stack[len(s.stack)-1]
+	if !top.muted {
+		for _, c := range top.creates {
+			if c.present {
+				continue
+			}
+			if top.emitted > 0 {
+				s.out = append(s.out, ',')
+			}
+			top.emitted++
+			name, _ := json.Marshal(c.key)
+			s.out = append(append(append(s.out, name...), ':'), c.value...)
+		}
+		if top.object {
+			s.out = append(s.out, '}')
+		} else {
+			s.out = append(s.out, ']')
+		}
+	}
+	s.stack = s.stack[:len(s.stack)-1]
+	s.endValue()
+	return nil
+}
+
+func (s *jsonStream) endValue() {
+	if len(s.stack) == 0 {
+		s.state = jsonStateDone
+	} else {
+		s.state = jsonStateCommaOrEnd
+	}
+}
+
+// matchJSONPath returns true if the path matches the current members and elements of the frames.
+func matchJSONPath(path []jsonSegment, frames []jsonFrame) bool {
+	for len(path) > 0 {
+		if path[0].deep {
+			for i := 0; i <= len(frames); i++ {
+				if matchJSONPath(path[1:], frames[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(frames) == 0 {
+			return false
+		}
+		switch f := &frames[0]; {
+		case path[0].any:
+		case f.object && path[0].key != f.key, !f.object && path[0].index != f.index:
+			return false
+		}
+		path, frames = path[1:], frames[1:]
+	}
+	return len(frames) == 0
+}
+
+func equalJSONPath(a, b []jsonSegment) bool {
+	for i := range a {
+		if a[i].key != b[i].key {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+func isJSONLiteralByte(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-' || c == '+' || c == '.'
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
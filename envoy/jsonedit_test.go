@@ -0,0 +1,138 @@
+package envoy
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestJSONEditorNestedArrays(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		edits []JSONEdit
+		data  string
+		want  string
+	}{
+		{
+			name:  "set element of nested array",
+			edits: []JSONEdit{{Op: JSONEditSet, Path: "items.1.tags.0", Value: "X"}},
+			data:  `{"items": [{"tags": ["a", "b"]}, {"tags": ["c", ["d"]]}]}`,
+			want:  `{"items":[{"tags":["a","b"]},{"tags":["X",["d"]]}]}`,
+		},
+		{
+			name:  "delete middle element",
+			edits: []JSONEdit{{Op: JSONEditDelete, Path: "m.0.1"}},
+			data:  `{"m": [[1, 2, 3], [4, 5]]}`,
+			want:  `{"m":[[1,3],[4,5]]}`,
+		},
+		{
+			name:  "delete first and last elements",
+			edits: []JSONEdit{{Op: JSONEditDelete, Path: "0.0"}, {Op: JSONEditDelete, Path: "1.1"}},
+			data:  `[[1, 2], [3, 4], [5]]`,
+			want:  `[[2],[3],[5]]`,
+		},
+		{
+			name:  "delete only element",
+			edits: []JSONEdit{{Op: JSONEditDelete, Path: "0.0.0"}},
+			data:  `[[[1]], [[2]]]`,
+			want:  `[[[]],[[2]]]`,
+		},
+		{
+			name:  "delete by wildcard",
+			edits: []JSONEdit{{Op: JSONEditDelete, Path: "m.*.0"}},
+			data:  `{"m": [[1, 2, 3], [4, 5], []]}`,
+			want:  `{"m":[[2,3],[5],[]]}`,
+		},
+		{
+			name:  "set by wildcards",
+			edits: []JSONEdit{{Op: JSONEditSet, Path: "m.*.*", Value: 0}},
+			data:  `{"m": [[1, {"a": 2}], [[3]], "s"]}`,
+			want:  `{"m":[[0,0],[0],"s"]}`,
+		},
+		{
+			name:  "index out of range",
+			edits: []JSONEdit{{Op: JSONEditSet, Path: "m.0.2", Value: 0}, {Op: JSONEditDelete, Path: "m.2"}},
+			data:  `{"m": [[1, 2]]}`,
+			want:  `{"m":[[1,2]]}`,
+		},
+		{
+			name:  "index of object",
+			edits: []JSONEdit{{Op: JSONEditDelete, Path: "m.0.0"}},
+			data:  `{"m": [{"0": 1, "1": 2}]}`,
+			want:  `{"m":[{"1":2}]}`,
+		},
+		{
+			name:  "create index as member",
+			edits: []JSONEdit{{Op: JSONEditSet, Path: "a.0", Value: 1}, {Op: JSONEditSet, Path: "m.5", Value: 2}},
+			data:  `{"m": [0]}`,
+			want:  `{"m":[0],"a":{"0":1}}`,
+		},
+		{
+			name:  "rename in elements",
+			edits: []JSONEdit{{Op: JSONEditRename, Path: "items.*.id", To: "key"}},
+			data:  `{"items": [{"id": 1, "v": [{"id": 2}]}, {"id": 3}]}`,
+			want:  `{"items":[{"key":1,"v":[{"id":2}]},{"key":3}]}`,
+		},
+		{
+			name: "redact at any depth",
+			edits: []JSONEdit{{
+				Op: JSONEditRedact, Path: "**.ssn", Pattern: regexp.MustCompile(`\d`), Replacement: "*",
+			}},
+			data: `{"a": [{"ssn": "12-3"}, [{"ssn": ["4", {"x": "5"}]}]], "ssn2": "6"}`,
+			want: `{"a":[{"ssn":"**-*"},[{"ssn":["*",{"x":"*"}]}]],"ssn2":"6"}`,
+		},
+		{
+			name: "first edit wins",
+			edits: []JSONEdit{
+				{Op: JSONEditDelete, Path: "m.1"},
+				{Op: JSONEditSet, Path: "m.*", Value: true},
+			},
+			data: `{"m": [1, 2, 3]}`,
+			want: `{"m":[true,true]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			j, err := NewJSONEditor(tc.edits...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := j.Edit([]byte(tc.data))
+			if err != nil || string(got) != tc.want {
+				t.Fatalf("Edit = %s, %v, want %s", got, err, tc.want)
+			}
+			// The streaming edit produces the same document regardless of the chunk boundaries.
+			for i := 0; i <= len(tc.data); i++ {
+				for k := i; k <= len(tc.data); k++ {
+					s := jsonStream{editor: j}
+					var out []byte
+					for n, chunk := range []string{tc.data[:i], tc.data[i:k], tc.data[k:]} {
+						edited, err := s.write([]byte(chunk), n == 2)
+						if err != nil {
+							t.Fatalf("chunks split at %d and %d: %v", i, k, err)
+						}
+						out = append(out, edited...)
+					}
+					if string(out) != tc.want {
+						t.Fatalf("chunks split at %d and %d = %s, want %s", i, k, out, tc.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestJSONEditorInvalid(t *testing.T) {
+	for _, path := range []string{"", "a..b", "a.", ".a"} {
+		if _, err := NewJSONEditor(JSONEdit{Op: JSONEditDelete, Path: path}); err == nil {
+			t.Errorf("NewJSONEditor with path %q succeeded", path)
+		}
+	}
+	j, err := NewJSONEditor(JSONEdit{Op: JSONEditDelete, Path: "a.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range []string{`{"a": [1, 2}`, `{"a": [1,, 2]}`, `[1] [2]`, `{"a": [1, 2]`} {
+		if _, err := j.Edit([]byte(data)); err == nil {
+			t.Errorf("Edit(%s) succeeded", data)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+//go:build !envoydebug
+
+package envoy
+
+import "unsafe"
+
+// viewStamp is the stamp of the objects that are only valid during the event callback that they are passed to
+// or retrieved in, e.g. RequestHeaders and HeaderValue. This is a zero-sized no-op without the envoydebug build tag,
+// and see lifetime_envoydebug.go for the checks in the envoydebug build.
+type viewStamp struct{}
+
+// newViewStamp returns the stamp for a new event callback.
+func newViewStamp() viewStamp { return viewStamp{} }
+
+// expire is called when the event callback returns.
+func (viewStamp) expire() {}
+
+// check panics if the callback of the stamp has returned. `what` is the name of the object used after that.
+func (viewStamp) check(what string) {}
+
+// streamStamp is the stamp of the body buffers retrieved via EnvoyFilterInstance, which is zero-sized no-op
+// without the envoydebug build tag.
+type streamStamp struct{}
+
+// renew is called at the beginning of an event callback of the stream, and returns the stamp of the callback.
+func (*streamStamp) renew() viewStamp { return viewStamp{} }
+
+// load returns the stamp of the current event callback, or the stamp valid until the next one.
+func (*streamStamp) load() viewStamp { return viewStamp{} }
+
+// expire is called when the stream is destroyed.
+func (*streamStamp) expire() {}
+
+// iterView calls iter with the view of the Envoy-owned memory, which must not be used after iter returns.
+// The modification of the view is reflected to the Envoy-owned memory unless readOnly is true.
+func iterView(ptr *byte, size int, readOnly bool, iter func(view []byte)) {
+	iter(unsafe.Slice(ptr, size))
+}
+
+// panicOnViewFault makes the access to the protected views panic instead of crashing the program in the current
+// goroutine, and returns the previous setting which must be passed to recoverViewFault deferred by the caller.
+func panicOnViewFault() bool { return false }
+
+// recoverViewFault restores the setting of panicOnViewFault and translates the fault at a protected view into
+// the panic describing the misuse. The other panics are propagated as is.
+func recoverViewFault(prev bool) {}
@@ -0,0 +1,177 @@
+//go:build envoydebug
+
+package envoy
+
+// This file implements the checks of the lifetimes of the Envoy-owned memory in the envoydebug build, e.g.
+//
+//	go test -tags envoydebug ./...
+//
+// Retaining the views of the Envoy-owned memory beyond their lifetimes results in the use-after-free in Envoy,
+// which usually shows up as a rare memory corruption in production. In this build, such misuse is detected as
+// follows at the cost of the performance:
+//
+//   - RequestHeaders, ResponseHeaders, RequestBodyBuffer, ResponseBodyBuffer, NetworkReadBuffer, NetworkWriteBuffer,
+//     ListenerPeekBuffer, AccessLogEntry and the HeaderValue(s) retrieved from them carry the stamp of the event
+//     callback, which is expired when the callback returns. Any use after that panics. The body buffers returned by
+//     EnvoyFilterInstance.GetRequestBodyBuffer and GetResponseBodyBuffer carry the stamp of the current event
+//     callback, or the one expired at the beginning of the next event callback if retrieved outside the callbacks.
+//     After the stream is destroyed, they are no-op without the check as they no longer call Envoy.
+//   - The views passed to the iterators of the Slices methods are the copies of the Envoy-owned memory in the
+//     dedicated pages, which are protected when the iterator returns. The access after that in the HTTP, network and
+//     listener filter event callbacks panics with the description of the misuse. The access from the other goroutines
+//     crashes the program with "unexpected fault address" at the access. The modification of the views is written
+//     back to Envoy.
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+// viewStamp is the stamp of the objects that are only valid during the event callback that they are passed to
+// or retrieved in, e.g. RequestHeaders and HeaderValue. The zero value is never expired, e.g. the objects created
+// outside the event callbacks.
+type viewStamp struct {
+	expired *atomic.Bool
+}
+
+// newViewStamp returns the stamp for a new event callback.
+func newViewStamp() viewStamp { return viewStamp{expired: new(atomic.Bool)} }
+
+// expire is called when the event callback returns.
+func (s viewStamp) expire() {
+	if s.expired != nil {
+		s.expired.Store(true)
+	}
+}
+
+// check panics if the callback of the stamp has returned. `what` is the name of the object used after that.
+func (s viewStamp) check(what string) {
+	if s.expired != nil && s.expired.Load() {
+		panic(fmt.Sprintf("envoy: %s is used after the event callback where it was retrieved returned. "+
+			"The memory is owned by Envoy and may have been freed. Copy the data during the callback instead, "+
+			"e.g. via HeaderValue.String", what))
+	}
+}
+
+// streamStamp is the stamp of the body buffers retrieved via EnvoyFilterInstance, which can be from any goroutine.
+type streamStamp struct {
+	mux     sync.Mutex
+	current viewStamp
+}
+
+// renew is called at the beginning of an event callback of the stream, and returns the stamp of the callback.
+func (s *streamStamp) renew() viewStamp {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.current.expire()
+	s.current = newViewStamp()
+	return s.current
+}
+
+// load returns the stamp of the current event callback, or the stamp valid until the next one.
+func (s *streamStamp) load() viewStamp {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.current.expired == nil || s.current.expired.Load() {
+		s.current = newViewStamp()
+	}
+	return s.current
+}
+
+// expire is called when the stream is destroyed.
+func (s *streamStamp) expire() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.current.expire()
+}
+
+// iterView calls iter with the view of the Envoy-owned memory, which must not be used after iter returns.
+// The modification of the view is reflected to the Envoy-owned memory unless readOnly is true.
+func iterView(ptr *byte, size int, readOnly bool, iter func(view []byte)) {
+	if size == 0 {
+		iter(nil)
+		return
+	}
+	pageSize := os.Getpagesize()
+	mapping, err := syscall.Mmap(-1, 0, (size+pageSize-1)/pageSize*pageSize,
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		panic(fmt.Sprintf("envoy: failed to map the view: %v", err))
+	}
+	envoyOwned := unsafe.Slice(ptr, size)
+	// The view is placed at the end of the pages so that the access beyond the length faults as well.
+	view := mapping[len(mapping)-size:]
+	copy(view, envoyOwned)
+	iter(view)
+	if !readOnly {
+		copy(envoyOwned, view)
+	}
+	if err = syscall.Mprotect(mapping, syscall.PROT_NONE); err != nil {
+		panic(fmt.Sprintf("envoy: failed to protect the view: %v", err))
+	}
+	quarantineView(mapping)
+}
+
+// maxQuarantinedViews is the maximum number of the protected mappings of the views kept mapped. The older ones
+// are unmapped so that the address space is not exhausted, and the access to them is no longer detected as
+// the address may be reused.
+const maxQuarantinedViews = 4096
+
+var (
+	quarantinedViews     [maxQuarantinedViews][]byte
+	quarantinedViewsNext int
+	quarantinedViewsMux  sync.Mutex
+)
+
+// quarantineView keeps the protected mapping mapped and unmaps the oldest one if the quarantine is full.
+func quarantineView(mapping []byte) {
+	quarantinedViewsMux.Lock()
+	oldest := quarantinedViews[quarantinedViewsNext]
+	quarantinedViews[quarantinedViewsNext] = mapping
+	quarantinedViewsNext = (quarantinedViewsNext + 1) % maxQuarantinedViews
+	quarantinedViewsMux.Unlock()
+	if oldest != nil {
+		_ = syscall.Munmap(oldest)
+	}
+}
+
+// quarantined returns true if the address is in one of the protected mappings of the views.
+func quarantined(addr uintptr) bool {
+	quarantinedViewsMux.Lock()
+	defer quarantinedViewsMux.Unlock()
+	for _, mapping := range quarantinedViews {
+		if len(mapping) == 0 {
+			continue
+		}
+		start := uintptr(unsafe.Pointer(unsafe.SliceData(mapping)))
+		if addr >= start && addr < start+uintptr(len(mapping)) {
+			return true
+		}
+	}
+	return false
+}
+
+// panicOnViewFault makes the access to the protected views panic instead of crashing the program in the current
+// goroutine, and returns the previous setting which must be passed to recoverViewFault deferred by the caller.
+func panicOnViewFault() bool { return debug.SetPanicOnFault(true) }
+
+// recoverViewFault restores the setting of panicOnViewFault and translates the fault at a protected view into
+// the panic describing the misuse. The other panics are propagated as is.
+func recoverViewFault(prev bool) {
+	debug.SetPanicOnFault(prev)
+	r := recover()
+	if r == nil {
+		return
+	}
+	if fault, ok := r.(interface{ Addr() uintptr }); ok && quarantined(fault.Addr()) {
+		panic(fmt.Sprintf("envoy: the view passed to the iterator of a Slices method is used at %#x after the "+
+			"iterator returned. The memory is owned by Envoy and may have been freed. Copy the data in the iterator "+
+			"instead, e.g. via the Copy method of the buffer", fault.Addr()))
+	}
+	panic(r)
+}
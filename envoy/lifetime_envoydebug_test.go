@@ -0,0 +1,364 @@
+//go:build envoydebug && !cgo
+
+package envoy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// viewSink keeps the reads of the views in the tests from being optimized away.
+var viewSink byte
+
+// panicMessage runs f and returns the message of the panic, or an empty string if f doesn't panic.
+func panicMessage(f func()) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg = fmt.Sprint(r)
+		}
+	}()
+	f()
+	return ""
+}
+
+func TestLifetimeBodyBuffers(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// retain is run in the first body event, and returns the misuse run in the second one.
+		retain func(e EnvoyFilterInstance, body RequestBodyBuffer) func()
+		want   string
+	}{
+		{
+			name: "event body",
+			retain: func(_ EnvoyFilterInstance, body RequestBodyBuffer) func() {
+				return func() { body.Length() }
+			},
+			want: "envoy: RequestBodyBuffer is used after the event callback",
+		},
+		{
+			name: "buffered body",
+			retain: func(e EnvoyFilterInstance, _ RequestBodyBuffer) func() {
+				buffered := e.GetRequestBodyBuffer()
+				return func() { buffered.Append([]byte("x")) }
+			},
+			want: "envoy: RequestBodyBuffer is used after the event callback",
+		},
+		{
+			name: "view",
+			retain: func(_ EnvoyFilterInstance, body RequestBodyBuffer) func() {
+				var view []byte
+				body.Slices(func(v []byte) { view = v })
+				return func() { viewSink = view[0] }
+			},
+			want: "envoy: the view passed to the iterator of a Slices method is used",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			bodies := fakeBodies(t)
+			var misuse func()
+			filter := newTestHttpFilter(t, bodyHttpFilter{
+				requestBody: func(e EnvoyFilterInstance, body RequestBodyBuffer, endOfStream bool) RequestBodyStatus {
+					if misuse == nil {
+						misuse = tc.retain(e, body)
+					} else {
+						misuse()
+					}
+					return RequestBodyStatusContinue
+				},
+			})
+			instance := eventHttpFilterInstanceInit(1, filter)
+			defer eventHttpFilterInstanceDestroy(instance)
+			bodies[10] = &fakeBody{slices: [][]byte{[]byte("first")}}
+			bodies[20] = &fakeBody{slices: [][]byte{[]byte("second")}}
+			if msg := panicMessage(func() { eventHttpFilterInstanceRequestBody(instance, 10, false) }); msg != "" {
+				t.Fatalf("the first event panicked: %s", msg)
+			}
+			msg := panicMessage(func() { eventHttpFilterInstanceRequestBody(instance, 20, true) })
+			if !strings.HasPrefix(msg, tc.want) {
+				t.Fatalf("the misuse panicked with %q, want %q", msg, tc.want)
+			}
+		})
+	}
+}
+
+func TestLifetimeBodyBufferOutsideCallbacks(t *testing.T) {
+	bodies := fakeBodies(t)
+	var e EnvoyFilterInstance
+	filter := newTestHttpFilter(t, bodyHttpFilter{
+		requestBody: func(envoy EnvoyFilterInstance, _ RequestBodyBuffer, _ bool) RequestBodyStatus {
+			e = envoy
+			return RequestBodyStatusStopIterationAndBuffer
+		},
+	})
+	instance := eventHttpFilterInstanceInit(1, filter)
+	eventHttpFilterInstanceRequestBody(instance, 10, false)
+
+	// The buffered body retrieved after the callback, e.g. by a goroutine, is valid until the next callback.
+	buffered := e.GetRequestBodyBuffer()
+	buffered.Append([]byte("buffered"))
+	if got := string(bodies[requestBufferOf(1)].bytes()); got != "buffered" {
+		t.Fatalf("buffered body = %q, want buffered", got)
+	}
+	eventHttpFilterInstanceRequestBody(instance, 10, true)
+	if msg := panicMessage(func() { buffered.Length() }); !strings.HasPrefix(msg, "envoy: RequestBodyBuffer is used") {
+		t.Fatalf("the use after the next callback panicked with %q", msg)
+	}
+
+	// The use after the destruction is no-op without calling Envoy.
+	buffered = e.GetRequestBodyBuffer()
+	eventHttpFilterInstanceDestroy(instance)
+	if msg := panicMessage(func() { buffered.Length() }); msg != "" {
+		t.Fatalf("the use after the destruction panicked with %q", msg)
+	}
+}
+
+// bufferNetworkFilter is the NetworkFilter whose instances run the functions of the test in the read and write
+// events.
+type bufferNetworkFilter struct {
+	read  func(buffer NetworkReadBuffer)
+	write func(buffer NetworkWriteBuffer)
+}
+
+func (f bufferNetworkFilter) NewInstance(EnvoyNetworkFilterInstance) NetworkFilterInstance {
+	return &bufferNetworkFilterInstance{filter: f}
+}
+
+func (bufferNetworkFilter) Destroy() {}
+
+type bufferNetworkFilterInstance struct{ filter bufferNetworkFilter }
+
+func (*bufferNetworkFilterInstance) OnNewConnection() NetworkFilterStatus {
+	return NetworkFilterStatusContinue
+}
+
+func (i *bufferNetworkFilterInstance) OnRead(buffer NetworkReadBuffer, _ bool) NetworkFilterStatus {
+	i.filter.read(buffer)
+	return NetworkFilterStatusContinue
+}
+
+func (i *bufferNetworkFilterInstance) OnWrite(buffer NetworkWriteBuffer, _ bool) NetworkFilterStatus {
+	i.filter.write(buffer)
+	return NetworkFilterStatusContinue
+}
+
+func (*bufferNetworkFilterInstance) OnClose(ConnectionEvent) {}
+
+func (*bufferNetworkFilterInstance) Destroy() {}
+
+func TestLifetimeNetworkBuffers(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// retain is run in the first write event with the buffers of the read event before it and the write event,
+		// and returns the misuse run in the second write event.
+		retain func(read NetworkReadBuffer, write NetworkWriteBuffer) func()
+		want   string
+	}{
+		{
+			name: "read buffer",
+			retain: func(read NetworkReadBuffer, _ NetworkWriteBuffer) func() {
+				return func() { read.Length() }
+			},
+			want: "envoy: NetworkReadBuffer is used after the event callback",
+		},
+		{
+			name: "write buffer",
+			retain: func(_ NetworkReadBuffer, write NetworkWriteBuffer) func() {
+				return func() { write.Append([]byte("x")) }
+			},
+			want: "envoy: NetworkWriteBuffer is used after the event callback",
+		},
+		{
+			name: "view",
+			retain: func(_ NetworkReadBuffer, write NetworkWriteBuffer) func() {
+				var view []byte
+				write.Slices(func(v []byte) { view = v })
+				return func() { viewSink = view[0] }
+			},
+			want: "envoy: the view passed to the iterator of a Slices method is used",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buffers := fakeNetworkBuffers(t)
+			var read NetworkReadBuffer
+			var misuse func()
+			filter := newTestNetworkFilter(t, bufferNetworkFilter{
+				read: func(buffer NetworkReadBuffer) { read = buffer },
+				write: func(buffer NetworkWriteBuffer) {
+					if misuse == nil {
+						misuse = tc.retain(read, buffer)
+					} else {
+						misuse()
+					}
+				},
+			})
+			instance := eventNetworkFilterInstanceInit(1, filter)
+			defer eventNetworkFilterInstanceDestroy(instance)
+			buffers[10] = &fakeBody{slices: [][]byte{[]byte("read")}}
+			buffers[20] = &fakeBody{slices: [][]byte{[]byte("write")}}
+			eventNetworkFilterInstanceRead(instance, 10, false)
+			if msg := panicMessage(func() { eventNetworkFilterInstanceWrite(instance, 20, false) }); msg != "" {
+				t.Fatalf("the first event panicked: %s", msg)
+			}
+			msg := panicMessage(func() { eventNetworkFilterInstanceWrite(instance, 20, false) })
+			if !strings.HasPrefix(msg, tc.want) {
+				t.Fatalf("the misuse panicked with %q, want %q", msg, tc.want)
+			}
+		})
+	}
+}
+
+// peekListenerFilter is the ListenerFilter whose instances run the function of the test in the data events.
+type peekListenerFilter struct {
+	data func(buffer ListenerPeekBuffer)
+}
+
+func (f peekListenerFilter) NewInstance(EnvoyListenerFilterInstance) ListenerFilterInstance {
+	return &peekListenerFilterInstance{filter: f}
+}
+
+func (peekListenerFilter) Destroy() {}
+
+type peekListenerFilterInstance struct{ filter peekListenerFilter }
+
+func (*peekListenerFilterInstance) MaxReadBytes() int { return 16 }
+
+func (*peekListenerFilterInstance) OnAccept() ListenerFilterStatus {
+	return ListenerFilterStatusStopIteration
+}
+
+func (i *peekListenerFilterInstance) OnData(buffer ListenerPeekBuffer) ListenerFilterStatus {
+	i.filter.data(buffer)
+	return ListenerFilterStatusStopIteration
+}
+
+func (*peekListenerFilterInstance) Destroy() {}
+
+func TestLifetimeListenerPeekBuffer(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// retain is run in the first data event, and returns the misuse run in the second one.
+		retain func(buffer ListenerPeekBuffer) func()
+		want   string
+	}{
+		{
+			name: "peek buffer",
+			retain: func(buffer ListenerPeekBuffer) func() {
+				return func() { buffer.Copy() }
+			},
+			want: "envoy: ListenerPeekBuffer is used after the event callback",
+		},
+		{
+			name: "view",
+			retain: func(buffer ListenerPeekBuffer) func() {
+				var view []byte
+				buffer.Slices(func(v []byte) { view = v })
+				return func() { viewSink = view[0] }
+			},
+			want: "envoy: the view passed to the iterator of a Slices method is used",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buffers := fakeListenerBuffers(t)
+			var misuse func()
+			filter := newTestListenerFilter(t, peekListenerFilter{
+				data: func(buffer ListenerPeekBuffer) {
+					if misuse == nil {
+						misuse = tc.retain(buffer)
+					} else {
+						misuse()
+					}
+				},
+			})
+			instance := eventListenerFilterInstanceInit(1, filter)
+			defer eventListenerFilterInstanceDestroy(instance)
+			buffers[10] = &fakeBody{slices: [][]byte{[]byte("peek")}}
+			if msg := panicMessage(func() { eventListenerFilterInstanceData(instance, 10) }); msg != "" {
+				t.Fatalf("the first event panicked: %s", msg)
+			}
+			msg := panicMessage(func() { eventListenerFilterInstanceData(instance, 10) })
+			if !strings.HasPrefix(msg, tc.want) {
+				t.Fatalf("the misuse panicked with %q, want %q", msg, tc.want)
+			}
+		})
+	}
+}
+
+// retainAccessLogger is the AccessLogger that runs the function of the test in the log events.
+type retainAccessLogger struct{ log func(entry AccessLogEntry) }
+
+func (l retainAccessLogger) Log(entry AccessLogEntry) { l.log(entry) }
+
+func (retainAccessLogger) Destroy() {}
+
+func TestLifetimeAccessLogEntry(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// retain is run in the first log event, and returns the misuse run in the second one.
+		retain func(entry AccessLogEntry) func()
+		want   string
+	}{
+		{
+			name: "entry",
+			retain: func(entry AccessLogEntry) func() {
+				return func() { entry.BytesSent() }
+			},
+			want: "envoy: AccessLogEntry is used after the event callback",
+		},
+		{
+			name: "headers",
+			retain: func(entry AccessLogEntry) func() {
+				headers := entry.RequestHeaders()
+				return func() { headers.Get(":path") }
+			},
+			want: "envoy: AccessLogHeaders is used after the event callback",
+		},
+		{
+			name: "header value",
+			retain: func(entry AccessLogEntry) func() {
+				path, _ := entry.RequestHeaders().Get(":path")
+				return func() { _ = path.String() }
+			},
+			want: "envoy: HeaderValue is used after the event callback",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			entries := fakeLogEntries(t)
+			entries[1] = &fakeLogEntry{headers: [4][][2]string{abiAccessLogHeadersRequest: {{":path", "/"}}}}
+			var misuse func()
+			logger, destroy := newTestAccessLogger(t, retainAccessLogger{
+				log: func(entry AccessLogEntry) {
+					if misuse == nil {
+						misuse = tc.retain(entry)
+					} else {
+						misuse()
+					}
+				},
+			})
+			defer destroy()
+			if msg := panicMessage(func() { eventAccessLoggerLog(logger, 1) }); msg != "" {
+				t.Fatalf("the first event panicked: %s", msg)
+			}
+			msg := panicMessage(func() { eventAccessLoggerLog(logger, 1) })
+			if !strings.HasPrefix(msg, tc.want) {
+				t.Fatalf("the misuse panicked with %q, want %q", msg, tc.want)
+			}
+		})
+	}
+}
+
+func TestLifetimeViewFaultOtherPanics(t *testing.T) {
+	// The panics other than the faults at the views are propagated as is.
+	filter := newTestHttpFilter(t, bodyHttpFilter{
+		requestBody: func(EnvoyFilterInstance, RequestBodyBuffer, bool) RequestBodyStatus {
+			var p *int
+			return RequestBodyStatus(*p)
+		},
+	})
+	instance := eventHttpFilterInstanceInit(1, filter)
+	defer eventHttpFilterInstanceDestroy(instance)
+	msg := panicMessage(func() { eventHttpFilterInstanceRequestBody(instance, 10, true) })
+	if !strings.Contains(msg, "nil pointer dereference") {
+		t.Fatalf("the nil dereference panicked with %q", msg)
+	}
+}
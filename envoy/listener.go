@@ -0,0 +1,55 @@
+package envoy
+
+// NewListenerFilter is a function that creates a new ListenerFilter that corresponds to each listener filter configuration
+// in the Envoy listener. This is a global variable that should be set in the init function in the program once
+// if the module is used as a listener filter.
+//
+// The function is only called by the main thread, so it does not need to be thread-safe.
+//
+// `config` is the configuration string that is passed to the module that is set in the Envoy configuration.
+var NewListenerFilter func(config string) ListenerFilter
+
+// ListenerFilter is an interface that represents a single listener filter in the Envoy listener.
+// Listener filters run right after a socket is accepted and before any network or http filter,
+// so they can inspect the initial bytes of the connection to make routing decisions, e.g. by sniffing
+// a custom protocol preamble or fingerprinting the TLS ClientHello.
+//
+// This is only created once per filter configuration via the NewListenerFilter function.
+type ListenerFilter interface {
+	// NewInstance is called for each accepted socket.
+	// Note that this must be concurrency-safe as it can be called concurrently for multiple sockets.
+	//
+	// * `EnvoyListenerFilterInstance` is the Envoy filter object that is used to interact with the underlying socket.
+	//  This object is unique for each socket, and its methods are no-op after ListenerFilterInstance.Destroy is called.
+	NewInstance(EnvoyListenerFilterInstance) ListenerFilterInstance
+
+	// Destroy is called when this filter is destroyed. E.g. the listener configuration is updated and removed from the Envoy.
+	Destroy()
+}
+
+// ListenerFilterInstance is an interface that represents each accepted socket.
+//
+// This is created for each accepted socket and is destroyed when the listener filter chain is completed
+// or the socket is closed.
+type ListenerFilterInstance interface {
+	// MaxReadBytes returns the maximum number of bytes the filter wants to peek from the socket.
+	// This is called once right after the instance is created. Returning 0 means that the filter doesn't need
+	// the data and OnData is never called.
+	MaxReadBytes() int
+	// OnAccept is called when the socket is accepted.
+	// The function should return the status of the operation. To peek the initial bytes, return
+	// ListenerFilterStatusStopIteration so that OnData is called when the data is available.
+	OnAccept() ListenerFilterStatus
+	// OnData is called when data is available on the socket after OnAccept returned ListenerFilterStatusStopIteration.
+	// The function should return the status of the operation. To wait for more data, return
+	// ListenerFilterStatusStopIteration.
+	//
+	//  * `buffer` holds all the data peeked so far, up to MaxReadBytes. The data is not consumed from the socket,
+	//  so the subsequent filters see the same data.
+	//
+	// To reject the socket, call EnvoyListenerFilterInstance.CloseSocket and return ListenerFilterStatusStopIteration.
+	OnData(buffer ListenerPeekBuffer) ListenerFilterStatus
+
+	// Destroy is called when the filter instance is destroyed.
+	Destroy()
+}
@@ -0,0 +1,260 @@
+//go:build !cgo
+
+package envoy
+
+import (
+	"slices"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// fakeListenerBuffers sets the peek buffer functions of FakeHost for the test to operate on the buffers of
+// fakeBodies, and returns them by the pointers passed to the data event.
+func fakeListenerBuffers(t *testing.T) map[uintptr]*fakeBody {
+	bodies := fakeBodies(t)
+	FakeHost.ListenerGetPeekBufferLength = FakeHost.HttpGetRequestBodyBufferLength
+	FakeHost.ListenerGetPeekBufferSlicesCount = FakeHost.HttpGetRequestBodyBufferSlicesCount
+	FakeHost.ListenerGetPeekBufferSlice = FakeHost.HttpGetRequestBodyBufferSlice
+	FakeHost.ListenerCopyOutPeekBuffer = FakeHost.HttpCopyOutRequestBodyBuffer
+	return bodies
+}
+
+// fakeListenerSocket sets the socket functions of FakeHost for the test, and returns the calls made to them.
+func fakeListenerSocket(t *testing.T) *[]string {
+	var calls []string
+	prev := FakeHost
+	FakeHost.ListenerSetDetectedTransportProtocol = func(_ uintptr, protocol unsafe.Pointer, protocolLength int) {
+		calls = append(calls, "protocol "+string(unsafe.Slice((*byte)(protocol), protocolLength)))
+	}
+	FakeHost.ListenerSetRequestedServerName = func(_ uintptr, serverName unsafe.Pointer, serverNameLength int) {
+		calls = append(calls, "server name "+string(unsafe.Slice((*byte)(serverName), serverNameLength)))
+	}
+	FakeHost.ListenerContinueFilterChain = func(_ uintptr, success int) {
+		if success != 0 {
+			calls = append(calls, "continue accept")
+		} else {
+			calls = append(calls, "continue reject")
+		}
+	}
+	FakeHost.ListenerCloseSocket = func(uintptr) { calls = append(calls, "close") }
+	FakeHost.ListenerGetRemoteAddress = func(_ uintptr, resultBufferPtr unsafe.Pointer,
+		resultBufferLengthPtr unsafe.Pointer) int {
+		const addr = "127.0.0.1:1234"
+		*(**byte)(resultBufferPtr) = unsafe.StringData(addr)
+		*(*int)(resultBufferLengthPtr) = len(addr)
+		return 1
+	}
+	t.Cleanup(func() { FakeHost = prev })
+	return &calls
+}
+
+// newTestListenerFilter creates the listener filter of the test via the event hook, which is destroyed on cleanup.
+func newTestListenerFilter(t *testing.T, filter ListenerFilter) uintptr {
+	prev := NewListenerFilter
+	NewListenerFilter = func(string) ListenerFilter { return filter }
+	config := t.Name()
+	raw := eventListenerFilterInit(uintptr(unsafe.Pointer(unsafe.StringData(config))), len(config))
+	NewListenerFilter = prev
+	t.Cleanup(func() { eventListenerFilterDestroy(raw) })
+	return raw
+}
+
+// sniffListenerFilter is the ListenerFilter whose instances sniff the "proto:name\n" preamble of the socket, and
+// record the events.
+type sniffListenerFilter struct {
+	events *[]string
+	// instances receives the EnvoyListenerFilterInstance of each new instance if not nil.
+	instances chan EnvoyListenerFilterInstance
+}
+
+func (f sniffListenerFilter) NewInstance(e EnvoyListenerFilterInstance) ListenerFilterInstance {
+	if f.instances != nil {
+		f.instances <- e
+	}
+	return &sniffListenerFilterInstance{envoy: e, events: f.events}
+}
+
+func (sniffListenerFilter) Destroy() {}
+
+type sniffListenerFilterInstance struct {
+	envoy  EnvoyListenerFilterInstance
+	events *[]string
+}
+
+func (i *sniffListenerFilterInstance) MaxReadBytes() int { return 64 }
+
+func (i *sniffListenerFilterInstance) OnAccept() ListenerFilterStatus {
+	addr, _ := i.envoy.RemoteAddress()
+	*i.events = append(*i.events, "accept "+addr)
+	return ListenerFilterStatusStopIteration
+}
+
+func (i *sniffListenerFilterInstance) OnData(buffer ListenerPeekBuffer) ListenerFilterStatus {
+	data := buffer.Copy()
+	*i.events = append(*i.events, "data "+string(data))
+	end := slices.Index(data, '\n')
+	if end < 0 {
+		return ListenerFilterStatusStopIteration
+	}
+	colon := slices.Index(data[:end], ':')
+	if colon < 0 {
+		i.envoy.CloseSocket()
+		return ListenerFilterStatusStopIteration
+	}
+	i.envoy.SetDetectedTransportProtocol(string(data[:colon]))
+	i.envoy.SetRequestedServerName(string(data[colon+1 : end]))
+	return ListenerFilterStatusContinue
+}
+
+func (i *sniffListenerFilterInstance) Destroy() {
+	*i.events = append(*i.events, "destroy")
+}
+
+func TestListenerFilter(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// peeks are the data peeked so far in each data event.
+		peeks      []string
+		wantStatus ListenerFilterStatus
+		wantEvents []string
+		wantCalls  []string
+	}{
+		{
+			name:       "sniffed",
+			peeks:      []string{"tl", "tls:example.com\nGET"},
+			wantStatus: ListenerFilterStatusContinue,
+			wantEvents: []string{
+				"accept 127.0.0.1:1234", "data tl", "data tls:example.com\nGET", "destroy",
+			},
+			wantCalls: []string{"protocol tls", "server name example.com"},
+		},
+		{
+			name:       "rejected",
+			peeks:      []string{"garbage\n"},
+			wantStatus: ListenerFilterStatusStopIteration,
+			wantEvents: []string{"accept 127.0.0.1:1234", "data garbage\n", "destroy"},
+			wantCalls:  []string{"close"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buffers := fakeListenerBuffers(t)
+			calls := fakeListenerSocket(t)
+			var events []string
+			filter := newTestListenerFilter(t, sniffListenerFilter{events: &events})
+			instance := eventListenerFilterInstanceInit(1, filter)
+
+			if n := eventListenerFilterInstanceMaxReadBytes(instance); n != 64 {
+				t.Fatalf("max read bytes = %d", n)
+			}
+			if status := eventListenerFilterInstanceAccept(instance); status != int(ListenerFilterStatusStopIteration) {
+				t.Fatalf("status of accept = %d", status)
+			}
+			var status int
+			for _, peek := range tc.peeks {
+				// The peeked data is split into the slices to exercise the copy across them.
+				buffers[10] = &fakeBody{slices: [][]byte{[]byte(peek[:len(peek)/2]), []byte(peek[len(peek)/2:])}}
+				status = eventListenerFilterInstanceData(instance, 10)
+			}
+			eventListenerFilterInstanceDestroy(instance)
+
+			if status != int(tc.wantStatus) {
+				t.Fatalf("status of the last data = %d, want %d", status, tc.wantStatus)
+			}
+			if !slices.Equal(events, tc.wantEvents) {
+				t.Fatalf("events = %q, want %q", events, tc.wantEvents)
+			}
+			if !slices.Equal(*calls, tc.wantCalls) {
+				t.Fatalf("calls = %q, want %q", *calls, tc.wantCalls)
+			}
+			if peek := tc.peeks[len(tc.peeks)-1]; string(buffers[10].bytes()) != peek {
+				t.Fatalf("the peek buffer is consumed: %q", buffers[10].bytes())
+			}
+		})
+	}
+}
+
+func TestListenerFilterMaxReadBytesNegative(t *testing.T) {
+	filter := newTestListenerFilter(t, negativeListenerFilter{})
+	instance := eventListenerFilterInstanceInit(1, filter)
+	defer eventListenerFilterInstanceDestroy(instance)
+	if n := eventListenerFilterInstanceMaxReadBytes(instance); n != 0 {
+		t.Fatalf("max read bytes = %d, want 0", n)
+	}
+}
+
+// negativeListenerFilter is the ListenerFilter whose instances return the negative MaxReadBytes.
+type negativeListenerFilter struct{}
+
+func (negativeListenerFilter) NewInstance(EnvoyListenerFilterInstance) ListenerFilterInstance {
+	return negativeListenerFilterInstance{}
+}
+
+func (negativeListenerFilter) Destroy() {}
+
+type negativeListenerFilterInstance struct{}
+
+func (negativeListenerFilterInstance) MaxReadBytes() int { return -1 }
+
+func (negativeListenerFilterInstance) OnAccept() ListenerFilterStatus {
+	return ListenerFilterStatusContinue
+}
+
+func (negativeListenerFilterInstance) OnData(ListenerPeekBuffer) ListenerFilterStatus {
+	return ListenerFilterStatusContinue
+}
+
+func (negativeListenerFilterInstance) Destroy() {}
+
+func TestListenerFilterInstanceAfterDestroy(t *testing.T) {
+	calls := fakeListenerSocket(t)
+	var events []string
+	instances := make(chan EnvoyListenerFilterInstance, 2)
+	filter := newTestListenerFilter(t, sniffListenerFilter{events: &events, instances: instances})
+
+	// The destroy waits for the call in flight on another Goroutine, e.g. the asynchronous classification.
+	instance := eventListenerFilterInstanceInit(1, filter)
+	e := <-instances
+	continuing, release := make(chan struct{}), make(chan struct{})
+	FakeHost.ListenerContinueFilterChain = func(uintptr, int) {
+		close(continuing)
+		<-release
+	}
+	go e.ContinueFilterChain(true)
+	<-continuing
+	destroyed := make(chan struct{})
+	go func() {
+		eventListenerFilterInstanceDestroy(instance)
+		close(destroyed)
+	}()
+	select {
+	case <-destroyed:
+		t.Fatal("the destroy returned during the continue")
+	case <-time.After(30 * time.Millisecond):
+	}
+	close(release)
+	<-destroyed
+
+	// Then the methods are no-op without calling Envoy.
+	FakeHost.ListenerContinueFilterChain = func(uintptr, int) { t.Error("ContinueFilterChain called Envoy after destroy") }
+	if e.Err() != ErrSocketDestroyed {
+		t.Fatalf("Err() = %v after the destroy", e.Err())
+	}
+	e.ContinueFilterChain(false)
+	e.CloseSocket()
+	e.SetDetectedTransportProtocol("tls")
+	e.SetRequestedServerName("example.com")
+	if addr, ok := e.RemoteAddress(); ok || addr != "" {
+		t.Fatalf("RemoteAddress() = %q, %t after the destroy", addr, ok)
+	}
+	if len(*calls) != 0 {
+		t.Fatalf("calls = %q", *calls)
+	}
+
+	// The instance of the live socket is not affected.
+	instance = eventListenerFilterInstanceInit(2, filter)
+	defer eventListenerFilterInstanceDestroy(instance)
+	if e := <-instances; e.Err() != nil {
+		t.Fatalf("Err() = %v before the destroy", e.Err())
+	}
+}
@@ -1,72 +1,228 @@
 package envoy
 
 import (
+	"runtime"
 	"sync"
+	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
 var memManager memoryManager
 
 type (
-	// memoryManager manages the heap allocated objects.
-	// It is used to pin the objects to the heap to avoid them being garbage collected by the Go runtime.
+	// memoryManager manages the heap allocated objects whose pointers are passed to Envoy.
+	// It is used to pin the objects to the heap to avoid them being garbage collected by the Go runtime
+	// while Envoy holds the pointers.
 	//
-	// TODO: shard the linked lists to reduce contention.
-	//
-	// TODO: is this really necessary? Pinning a pointer to the interface might work? e.g.
-	// 	...
-	//   pinner := runtime.Pinner{}
-	//   wrapper := &pinedHttpFilterInstance{ctx: ctx}
-	//   pinn.Pinned(wrapper)
-	// 	...
-	//  does this work even when the data inside the interface contains pointers?
+	// The pointer to the pinned wrapper itself is passed to Envoy, so unwrapping it in each event is a
+	// pointer conversion without any lookup, unlike cgo.Handle. The set of the pinned objects is sharded
+	// so that the concurrent pin and unpin from the Envoy worker threads rarely contend on the same lock.
 	memoryManager struct {
-		// httpFilters holds a linked lists of HttpFilter.
-		httpFilters      *pinedHttpFilter
-		httpFiltersMutex sync.Mutex
-
-		// httpFilterInstances holds a linked lists of HttpFilterInstance.
-		httpFilterInstances      *pinedHttpFilterInstance
-		httpFilterInstancesMutex sync.Mutex
+		httpFilters             pinSet
+		httpFilterInstances     pinSet
+		networkFilters          pinSet
+		networkFilterInstances  pinSet
+		listenerFilters         pinSet
+		listenerFilterInstances pinSet
+		accessLoggers           pinSet
 	}
 
 	// pinedHttpFilter holds a pinned HttpFilter managed by the memory manager.
 	pinedHttpFilter struct {
-		filter     HttpFilter
-		next, prev *pinedHttpFilter
+		pinLink
+		filter HttpFilter
+		// config and upstream are the arguments of NewHttpFilter or NewUpstreamHttpFilter, used by the introspection.
+		config   string
+		upstream bool
+		// statName is the name of the filter in the stats. See StatNameProvider.
+		statName string
+		// instancePool is the pool of the instances implementing Resetter.
+		instancePool sync.Pool
+		// watchdog is the configuration of the watchdog if filter implements WatchdogProvider.
+		watchdog Watchdog
+		// bodyLimit is the configuration of the body limit if filter implements BodyLimitProvider.
+		bodyLimit         BodyLimit
+		bodyLimitCounters bodyLimitCounters
+		// executor is the executor of the tasks of the instances if filter implements ExecutorProvider.
+		executor *executor
 	}
 
 	// pinedHttpFilterInstance holds a pinned HttpFilterInstance managed by the memory manager.
 	pinedHttpFilterInstance struct {
+		pinLink
 		filterInstance HttpFilterInstance
-		next, prev     *pinedHttpFilterInstance
+		// The optional handlers implemented by filterInstance. nil if not implemented.
+		requestHeaders  RequestHeadersHandler
+		requestBody     RequestBodyHandler
+		responseHeaders ResponseHeadersHandler
+		responseBody    ResponseBodyHandler
+		watermark       WatermarkHandler
+		reset           Resetter
+		// bypass is the mask of the events not handled by filterInstance.
+		bypass httpFilterEventMask
+		// envoy is the EnvoyFilterInstance of the current stream, which is passed to NewInstance or Resetter.Reset.
+		envoy *envoyFilterInstance
+		// filter is the HttpFilter that created this instance, used by the introspection.
+		filter  *pinedHttpFilter
+		created time.Duration
+		// stack is the creation stack of this instance, recorded only when TrackHttpFilterInstanceStacks is enabled.
+		stack []uintptr
+	}
+
+	// pinedNetworkFilter holds a pinned NetworkFilter managed by the memory manager.
+	pinedNetworkFilter struct {
+		pinLink
+		filter NetworkFilter
+	}
+
+	// pinedNetworkFilterInstance holds a pinned NetworkFilterInstance managed by the memory manager.
+	pinedNetworkFilterInstance struct {
+		pinLink
+		filterInstance NetworkFilterInstance
+		// envoy is the EnvoyNetworkFilterInstance passed to NewInstance, which is detached on the destroy.
+		envoy *envoyNetworkFilterInstance
+	}
+
+	// pinedListenerFilter holds a pinned ListenerFilter managed by the memory manager.
+	pinedListenerFilter struct {
+		pinLink
+		filter ListenerFilter
+	}
+
+	// pinedListenerFilterInstance holds a pinned ListenerFilterInstance managed by the memory manager.
+	pinedListenerFilterInstance struct {
+		pinLink
+		filterInstance ListenerFilterInstance
+		// envoy is the EnvoyListenerFilterInstance passed to NewInstance, which is detached on the destroy.
+		envoy *envoyListenerFilterInstance
+	}
+
+	// pinedAccessLogger holds a pinned AccessLogger managed by the memory manager.
+	pinedAccessLogger struct {
+		pinLink
+		logger AccessLogger
 	}
 )
 
-// pinHttpFilter pins the HttpFilter to the memory manager.
-func (m *memoryManager) pinHttpFilter(filter HttpFilter) *pinedHttpFilter {
-	m.httpFiltersMutex.Lock()
-	defer m.httpFiltersMutex.Unlock()
+// pinSetShards is the number of the shards of pinSet. This is larger than the typical number of
+// the Envoy worker threads so that the concurrent operations rarely hit the same shard.
+const pinSetShards = 64
 
-	item := &pinedHttpFilter{filter: filter, next: m.httpFilters, prev: nil}
-	if m.httpFilters != nil {
-		m.httpFilters.prev = item
+// pinSet is a set of the pinned objects. The objects are linked into the set via the embedded pinLink,
+// and are reachable from the set so that they are not garbage collected until unpinned.
+//
+// The set is sharded by the address of the object, which never changes as the Go heap objects are not moved.
+// Each shard is a doubly linked list so that pin and unpin are O(1) without any allocation.
+type pinSet struct {
+	shards [pinSetShards]pinSetShard
+}
+
+// pinSetShard is a shard of pinSet.
+type pinSetShard struct {
+	mux   sync.Mutex
+	head  *pinLink
+	count int
+	// Pads the shard to the cache line size so that the adjacent shards don't share the cache line.
+	_ [64 - unsafe.Sizeof(sync.Mutex{}) - unsafe.Sizeof(uintptr(0)) - unsafe.Sizeof(int(0))]byte
+}
+
+// pinLink is embedded into the objects pinned in pinSet. This must be the first field of the object
+// so that the object is reachable from the link.
+type pinLink struct {
+	next, prev *pinLink
+}
+
+// shard returns the shard of the link.
+func (s *pinSet) shard(link *pinLink) *pinSetShard {
+	// Fibonacci hashing of the address as the lower bits are aligned.
+	h := uint64(uintptr(unsafe.Pointer(link))) * 0x9e3779b97f4a7c15
+	return &s.shards[h>>58]
+}
+
+// pin adds the link to the set.
+func (s *pinSet) pin(link *pinLink) {
+	shard := s.shard(link)
+	shard.mux.Lock()
+	defer shard.mux.Unlock()
+	link.next, link.prev = shard.head, nil
+	if shard.head != nil {
+		shard.head.prev = link
 	}
-	m.httpFilters = item
-	return item
+	shard.head = link
+	shard.count++
 }
 
-func (m *memoryManager) unpinHttpFilter(filter *pinedHttpFilter) {
-	m.httpFiltersMutex.Lock()
-	defer m.httpFiltersMutex.Unlock()
-	if filter.prev != nil {
-		filter.prev.next = filter.next
+// unpin removes the link from the set.
+func (s *pinSet) unpin(link *pinLink) {
+	shard := s.shard(link)
+	shard.mux.Lock()
+	defer shard.mux.Unlock()
+	if link.prev != nil {
+		link.prev.next = link.next
 	} else {
-		m.httpFilters = filter.next
+		shard.head = link.next
 	}
-	if filter.next != nil {
-		filter.next.prev = filter.prev
+	if link.next != nil {
+		link.next.prev = link.prev
 	}
+	link.next, link.prev = nil, nil
+	shard.count--
+}
+
+// len returns the number of the links in the set. This locks the shards one by one, so the result is
+// not an atomic snapshot of the whole set while pin and unpin are running concurrently.
+func (s *pinSet) len() (n int) {
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mux.Lock()
+		n += shard.count
+		shard.mux.Unlock()
+	}
+	return
+}
+
+// forEach calls f for each link in the set while holding the lock of its shard, so f must not pin or unpin.
+func (s *pinSet) forEach(f func(link *pinLink)) {
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mux.Lock()
+		for link := shard.head; link != nil; link = link.next {
+			f(link)
+		}
+		shard.mux.Unlock()
+	}
+}
+
+// memEpoch is the origin of the creation times of the pinned objects. time.Since(memEpoch) uses the
+// monotonic clock and is smaller to store than time.Time.
+var memEpoch = time.Now()
+
+// trackHttpFilterInstanceStacks is set by TrackHttpFilterInstanceStacks.
+var trackHttpFilterInstanceStacks atomic.Bool
+
+// maxHttpFilterInstanceStackDepth is the maximum depth of the recorded creation stacks.
+const maxHttpFilterInstanceStackDepth = 32
+
+// pinHttpFilter pins the HttpFilter created from the config to the memory manager.
+func (m *memoryManager) pinHttpFilter(filter HttpFilter, config string, upstream bool) *pinedHttpFilter {
+	item := &pinedHttpFilter{filter: filter, config: config, upstream: upstream, statName: httpFilterStatName(filter, config)}
+	if p, ok := filter.(WatchdogProvider); ok {
+		item.watchdog = p.Watchdog()
+	}
+	if p, ok := filter.(BodyLimitProvider); ok {
+		item.bodyLimit = p.BodyLimit()
+	}
+	if p, ok := filter.(ExecutorProvider); ok {
+		item.executor = newExecutor(p.Executor())
+	}
+	m.httpFilters.pin(&item.pinLink)
+	return item
+}
+
+// unpinHttpFilter unpins the HttpFilter from the memory manager.
+func (m *memoryManager) unpinHttpFilter(filter *pinedHttpFilter) {
+	m.httpFilters.unpin(&filter.pinLink)
 }
 
 // unwrapPinnedHttpFilter unwraps the pinned http filter.
@@ -74,33 +230,172 @@ func (m *memoryManager) unwrapPinnedHttpFilter(raw uintptr) *pinedHttpFilter {
 	return (*pinedHttpFilter)(unsafe.Pointer(raw))
 }
 
-// pinHttpFilterInstance pins the http filter instance to the memory manager.
-func (m *memoryManager) pinHttpFilterInstance(filterInstance HttpFilterInstance) *pinedHttpFilterInstance {
-	m.httpFilterInstancesMutex.Lock()
-	defer m.httpFilterInstancesMutex.Unlock()
-	item := &pinedHttpFilterInstance{filterInstance: filterInstance, next: m.httpFilterInstances, prev: nil}
-	if m.httpFilterInstances != nil {
-		m.httpFilterInstances.prev = item
-	}
-	m.httpFilterInstances = item
+// newHttpFilterInstance returns the http filter instance for the new stream of the filter, which is reused from
+// the pool of the filter if available, otherwise created via HttpFilter.NewInstance. The returned instance is
+// not pinned yet.
+//
+// The EnvoyFilterInstance is always new so that the Goroutines still holding the one of the previous stream of
+// a pooled instance never operate on the new stream.
+func (m *memoryManager) newHttpFilterInstance(filter *pinedHttpFilter, envoyFilterPtr uintptr) *pinedHttpFilterInstance {
+	envoyPtr := &envoyFilterInstance{raw: envoyFilterPtr, filter: filter}
+	if item, ok := filter.instancePool.Get().(*pinedHttpFilterInstance); ok {
+		item.envoy = envoyPtr
+		item.reset.Reset(envoyPtr)
+		return item
+	}
+	item := &pinedHttpFilterInstance{filterInstance: filter.filter.NewInstance(envoyPtr), envoy: envoyPtr, filter: filter}
+	item.bypass = item.detectHandlers()
 	return item
 }
 
-// unwrapPinnedHttpFilterInstance unwraps the pinned http filter instance from the memory manager.
-func (m *memoryManager) unpinHttpFilterInstance(filterInstance *pinedHttpFilterInstance) {
-	m.httpFilterInstancesMutex.Lock()
-	defer m.httpFilterInstancesMutex.Unlock()
-	if filterInstance.prev != nil {
-		filterInstance.prev.next = filterInstance.next
-	} else {
-		m.httpFilterInstances = filterInstance.next
+// releaseHttpFilterInstance puts the unpinned http filter instance back to the pool of the filter if it implements
+// Resetter. Otherwise, the instance is left to the garbage collector.
+func (m *memoryManager) releaseHttpFilterInstance(item *pinedHttpFilterInstance) {
+	if item.reset == nil {
+		return
 	}
-	if filterInstance.next != nil {
-		filterInstance.next.prev = filterInstance.prev
+	item.envoy = nil
+	item.stack = nil
+	item.filter.instancePool.Put(item)
+}
+
+// pinHttpFilterInstance pins the http filter instance to the memory manager.
+func (m *memoryManager) pinHttpFilterInstance(item *pinedHttpFilterInstance) {
+	item.created = time.Since(memEpoch)
+	if trackHttpFilterInstanceStacks.Load() {
+		var pcs [maxHttpFilterInstanceStackDepth]uintptr
+		// Skips runtime.Callers, pinHttpFilterInstance and eventHttpFilterInstanceInit.
+		n := runtime.Callers(3, pcs[:])
+		item.stack = pcs[:n:n]
 	}
+	m.httpFilterInstances.pin(&item.pinLink)
+}
+
+// unpinHttpFilterInstance unpins the http filter instance from the memory manager.
+func (m *memoryManager) unpinHttpFilterInstance(filterInstance *pinedHttpFilterInstance) {
+	m.httpFilterInstances.unpin(&filterInstance.pinLink)
 }
 
 // unwrapRawPinHttpFilterInstance unwraps the raw pointer to the pinned http filter instance.
 func unwrapRawPinHttpFilterInstance(raw uintptr) *pinedHttpFilterInstance {
 	return (*pinedHttpFilterInstance)(unsafe.Pointer(raw))
 }
+
+// pinNetworkFilter pins the NetworkFilter to the memory manager.
+func (m *memoryManager) pinNetworkFilter(filter NetworkFilter) *pinedNetworkFilter {
+	item := &pinedNetworkFilter{filter: filter}
+	m.networkFilters.pin(&item.pinLink)
+	return item
+}
+
+// unpinNetworkFilter unpins the NetworkFilter from the memory manager.
+func (m *memoryManager) unpinNetworkFilter(filter *pinedNetworkFilter) {
+	m.networkFilters.unpin(&filter.pinLink)
+}
+
+// unwrapPinnedNetworkFilter unwraps the pinned network filter.
+func (m *memoryManager) unwrapPinnedNetworkFilter(raw uintptr) *pinedNetworkFilter {
+	return (*pinedNetworkFilter)(unsafe.Pointer(raw))
+}
+
+// pinNetworkFilterInstance pins the network filter instance to the memory manager.
+func (m *memoryManager) pinNetworkFilterInstance(filterInstance NetworkFilterInstance,
+	envoy *envoyNetworkFilterInstance) *pinedNetworkFilterInstance {
+	item := &pinedNetworkFilterInstance{filterInstance: filterInstance, envoy: envoy}
+	m.networkFilterInstances.pin(&item.pinLink)
+	return item
+}
+
+// unpinNetworkFilterInstance unpins the network filter instance from the memory manager.
+func (m *memoryManager) unpinNetworkFilterInstance(filterInstance *pinedNetworkFilterInstance) {
+	m.networkFilterInstances.unpin(&filterInstance.pinLink)
+}
+
+// unwrapRawPinNetworkFilterInstance unwraps the raw pointer to the pinned network filter instance.
+func unwrapRawPinNetworkFilterInstance(raw uintptr) *pinedNetworkFilterInstance {
+	return (*pinedNetworkFilterInstance)(unsafe.Pointer(raw))
+}
+
+// pinListenerFilter pins the ListenerFilter to the memory manager.
+func (m *memoryManager) pinListenerFilter(filter ListenerFilter) *pinedListenerFilter {
+	item := &pinedListenerFilter{filter: filter}
+	m.listenerFilters.pin(&item.pinLink)
+	return item
+}
+
+// unpinListenerFilter unpins the ListenerFilter from the memory manager.
+func (m *memoryManager) unpinListenerFilter(filter *pinedListenerFilter) {
+	m.listenerFilters.unpin(&filter.pinLink)
+}
+
+// unwrapPinnedListenerFilter unwraps the pinned listener filter.
+func (m *memoryManager) unwrapPinnedListenerFilter(raw uintptr) *pinedListenerFilter {
+	return (*pinedListenerFilter)(unsafe.Pointer(raw))
+}
+
+// pinListenerFilterInstance pins the listener filter instance to the memory manager.
+func (m *memoryManager) pinListenerFilterInstance(filterInstance ListenerFilterInstance,
+	envoy *envoyListenerFilterInstance) *pinedListenerFilterInstance {
+	item := &pinedListenerFilterInstance{filterInstance: filterInstance, envoy: envoy}
+	m.listenerFilterInstances.pin(&item.pinLink)
+	return item
+}
+
+// unpinListenerFilterInstance unpins the listener filter instance from the memory manager.
+func (m *memoryManager) unpinListenerFilterInstance(filterInstance *pinedListenerFilterInstance) {
+	m.listenerFilterInstances.unpin(&filterInstance.pinLink)
+}
+
+// unwrapRawPinListenerFilterInstance unwraps the raw pointer to the pinned listener filter instance.
+func unwrapRawPinListenerFilterInstance(raw uintptr) *pinedListenerFilterInstance {
+	return (*pinedListenerFilterInstance)(unsafe.Pointer(raw))
+}
+
+// pinAccessLogger pins the AccessLogger to the memory manager.
+func (m *memoryManager) pinAccessLogger(logger AccessLogger) *pinedAccessLogger {
+	item := &pinedAccessLogger{logger: logger}
+	m.accessLoggers.pin(&item.pinLink)
+	return item
+}
+
+// unpinAccessLogger unpins the AccessLogger from the memory manager.
+func (m *memoryManager) unpinAccessLogger(logger *pinedAccessLogger) {
+	m.accessLoggers.unpin(&logger.pinLink)
+}
+
+// unwrapPinnedAccessLogger unwraps the pinned access logger.
+func (m *memoryManager) unwrapPinnedAccessLogger(raw uintptr) *pinedAccessLogger {
+	return (*pinedAccessLogger)(unsafe.Pointer(raw))
+}
+
+// detectHandlers detects the optional handlers implemented by the filter instance,
+// and returns the mask of the events that are not handled by the instance.
+func (p *pinedHttpFilterInstance) detectHandlers() (bypass httpFilterEventMask) {
+	var ok bool
+	if p.requestHeaders, ok = p.filterInstance.(RequestHeadersHandler); !ok {
+		bypass |= httpFilterEventRequestHeaders
+	}
+	if p.requestBody, ok = p.filterInstance.(RequestBodyHandler); !ok {
+		bypass |= httpFilterEventRequestBody
+	}
+	if p.responseHeaders, ok = p.filterInstance.(ResponseHeadersHandler); !ok {
+		bypass |= httpFilterEventResponseHeaders
+	}
+	if p.responseBody, ok = p.filterInstance.(ResponseBodyHandler); !ok {
+		bypass |= httpFilterEventResponseBody
+	}
+	if p.watermark, ok = p.filterInstance.(WatermarkHandler); !ok {
+		bypass |= httpFilterEventWatermark
+	}
+	p.reset, _ = p.filterInstance.(Resetter)
+	// The headers events are needed to check the content-length headers for the body limit.
+	if limit := &p.filter.bodyLimit; limit.CheckContentLength {
+		if limit.MaxRequestBodySize > 0 && p.requestBody != nil {
+			bypass &^= httpFilterEventRequestHeaders
+		}
+		if limit.MaxResponseBodySize > 0 && p.responseBody != nil {
+			bypass &^= httpFilterEventResponseHeaders
+		}
+	}
+	return
+}
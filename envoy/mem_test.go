@@ -0,0 +1,187 @@
+//go:build !cgo
+
+package envoy
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// pinList is the set of the pinned objects before pinSet was sharded, i.e. a single doubly linked list protected by
+// a mutex. This is kept as the baseline of BenchmarkPinLatency.
+type pinList struct {
+	mux  sync.Mutex
+	head *pinLink
+}
+
+func (l *pinList) pin(link *pinLink) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	link.next, link.prev = l.head, nil
+	if l.head != nil {
+		l.head.prev = link
+	}
+	l.head = link
+}
+
+func (l *pinList) unpin(link *pinLink) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if link.prev != nil {
+		link.prev.next = link.next
+	} else {
+		l.head = link.next
+	}
+	if link.next != nil {
+		link.next.prev = link.prev
+	}
+	link.next, link.prev = nil, nil
+}
+
+func TestPinSet(t *testing.T) {
+	var s pinSet
+	objects := make([]pinedNetworkFilter, 1000)
+	for i := range objects {
+		s.pin(&objects[i].pinLink)
+	}
+	for i := 0; i < len(objects); i += 2 {
+		s.unpin(&objects[i].pinLink)
+	}
+	if n := s.len(); n != len(objects)/2 {
+		t.Fatalf("len() = %d, want %d", n, len(objects)/2)
+	}
+	seen := map[*pinLink]bool{}
+	s.forEach(func(link *pinLink) { seen[link] = true })
+	for i := range objects {
+		if want := i%2 == 1; seen[&objects[i].pinLink] != want {
+			t.Fatalf("object %d pinned %t, want %t", i, seen[&objects[i].pinLink], want)
+		}
+	}
+	for i := 1; i < len(objects); i += 2 {
+		s.unpin(&objects[i].pinLink)
+	}
+	if n := s.len(); n != 0 {
+		t.Fatalf("len() = %d after unpinning all, want 0", n)
+	}
+}
+
+// BenchmarkPinLatency measures the latency of pin and unpin of the sharded pinSet against the baseline pinList,
+// where each goroutine keeps the fixed number of objects pinned as the concurrent streams of an Envoy worker.
+// The percentiles are reported as the metrics, and the contention shows up with more goroutines than CPUs, e.g.
+//
+//	go test -run '^$' -bench PinLatency -cpu 1,8,32 ./envoy
+func BenchmarkPinLatency(b *testing.B) {
+	b.Run("list", func(b *testing.B) {
+		var l pinList
+		benchmarkPinLatency(b, l.pin, l.unpin)
+	})
+	b.Run("sharded", func(b *testing.B) {
+		var s pinSet
+		benchmarkPinLatency(b, s.pin, s.unpin)
+	})
+}
+
+func benchmarkPinLatency(b *testing.B, pin, unpin func(link *pinLink)) {
+	const liveStreams = 64
+	var mux sync.Mutex
+	var samples []int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		objects := make([]pinedHttpFilterInstance, liveStreams)
+		for i := range objects {
+			pin(&objects[i].pinLink)
+		}
+		local := make([]int64, 0, 1024)
+		for next := 0; pb.Next(); next = (next + 1) % liveStreams {
+			start := time.Now()
+			unpin(&objects[next].pinLink)
+			pin(&objects[next].pinLink)
+			local = append(local, int64(time.Since(start)))
+		}
+		for i := range objects {
+			unpin(&objects[i].pinLink)
+		}
+		mux.Lock()
+		samples = append(samples, local...)
+		mux.Unlock()
+	})
+	b.StopTimer()
+	if len(samples) == 0 {
+		return
+	}
+	slices.Sort(samples)
+	for _, p := range []struct {
+		name string
+		q    float64
+	}{{"p50-ns", 0.5}, {"p99-ns", 0.99}, {"p999-ns", 0.999}} {
+		b.ReportMetric(float64(samples[int(float64(len(samples)-1)*p.q)]), p.name)
+	}
+}
+
+// newTestHttpFilter creates the HttpFilter via the event hook as Envoy does, and destroys it at the end of the test.
+func newTestHttpFilter(t *testing.T, filter HttpFilter) uintptr {
+	prev := NewHttpFilter
+	NewHttpFilter = func(string) HttpFilter { return filter }
+	config := t.Name()
+	raw := eventHttpFilterInit(uintptr(unsafe.Pointer(unsafe.StringData(config))), len(config))
+	NewHttpFilter = prev
+	t.Cleanup(func() { eventHttpFilterDestroy(raw) })
+	return raw
+}
+
+type pooledHttpFilter struct{ nopHttpFilter }
+
+func (pooledHttpFilter) NewInstance(e EnvoyFilterInstance) HttpFilterInstance {
+	return &pooledHttpFilterInstance{envoy: e}
+}
+
+type pooledHttpFilterInstance struct {
+	envoy  EnvoyFilterInstance
+	resets int
+}
+
+func (*pooledHttpFilterInstance) Destroy() {}
+
+func (p *pooledHttpFilterInstance) Reset(e EnvoyFilterInstance) {
+	p.envoy = e
+	p.resets++
+}
+
+func TestHttpFilterInstancePoolingStaleEnvoyFilterInstance(t *testing.T) {
+	var continued []uintptr
+	FakeHost.HttpContinueRequest = func(envoyFilterInstancePtr uintptr) {
+		continued = append(continued, envoyFilterInstancePtr)
+	}
+	t.Cleanup(func() { FakeHost.HttpContinueRequest = nil })
+	filter := newTestHttpFilter(t, pooledHttpFilter{})
+
+	first := eventHttpFilterInstanceInit(1, filter)
+	instance := unwrapRawPinHttpFilterInstance(first).filterInstance.(*pooledHttpFilterInstance)
+	stale := instance.envoy
+	eventHttpFilterInstanceDestroy(first)
+
+	second := eventHttpFilterInstanceInit(2, filter)
+	defer eventHttpFilterInstanceDestroy(second)
+	if unwrapRawPinHttpFilterInstance(second).filterInstance != HttpFilterInstance(instance) {
+		t.Skip("sync.Pool dropped the instance")
+	}
+	if instance.resets != 1 || instance.envoy == stale {
+		t.Fatalf("Reset called %d times with the stale EnvoyFilterInstance %t", instance.resets, instance.envoy == stale)
+	}
+	// A Goroutine of the previous stream must not continue the new stream.
+	stale.ContinueRequest()
+	if err := stale.Err(); err != ErrStreamDestroyed {
+		t.Fatalf("Err of the previous stream = %v, want ErrStreamDestroyed", err)
+	}
+	instance.envoy.ContinueRequest()
+	if err := instance.envoy.Err(); err != nil {
+		t.Fatalf("Err of the new stream = %v", err)
+	}
+	if len(continued) != 1 || continued[0] != 2 {
+		t.Fatalf("continued %v, want only the new stream 2", continued)
+	}
+}
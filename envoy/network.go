@@ -0,0 +1,54 @@
+package envoy
+
+// NewNetworkFilter is a function that creates a new NetworkFilter that corresponds to each network filter configuration
+// in the Envoy listener filter chain. This is a global variable that should be set in the init function in the program once
+// if the module is used as a network filter.
+//
+// The function is only called by the main thread, so it does not need to be thread-safe.
+//
+// `config` is the configuration string that is passed to the module that is set in the Envoy configuration.
+var NewNetworkFilter func(config string) NetworkFilter
+
+// NetworkFilter is an interface that represents a single network (L4) filter in the Envoy filter chain.
+// It is used to create NetworkFilterInstance(s) that correspond to each downstream connection.
+//
+// This is only created once per filter configuration via the NewNetworkFilter function.
+type NetworkFilter interface {
+	// NewInstance is called for each new downstream connection.
+	// Note that this must be concurrency-safe as it can be called concurrently for multiple connections.
+	//
+	// * `EnvoyNetworkFilterInstance` is the Envoy filter object that is used to interact with the underlying connection.
+	//  This object is unique for each connection, and its methods are no-op after NetworkFilterInstance.Destroy is
+	//  called. See EnvoyNetworkFilterInstance.
+	NewInstance(EnvoyNetworkFilterInstance) NetworkFilterInstance
+
+	// Destroy is called when this filter is destroyed. E.g. the filter chain configuration is updated and removed from the Envoy.
+	Destroy()
+}
+
+// NetworkFilterInstance is an interface that represents each downstream connection.
+//
+// This is created for each new connection and is destroyed when the connection is closed.
+type NetworkFilterInstance interface {
+	// OnNewConnection is called when the connection is established and before any data is read.
+	// The function should return the status of the operation.
+	OnNewConnection() NetworkFilterStatus
+	// OnRead is called when data is read from the downstream connection.
+	// The function should return the status of the operation.
+	//
+	//  * `buffer` holds all the data that has been read and not yet consumed by the subsequent filters. To consume
+	//  the data, e.g. when the module handles the protocol by itself, drain it from the buffer.
+	//  * `endOfStream` is a boolean that indicates if the downstream half-closed the connection.
+	OnRead(buffer NetworkReadBuffer, endOfStream bool) NetworkFilterStatus
+	// OnWrite is called when data is to be written to the downstream connection.
+	// The function should return the status of the operation.
+	//
+	//  * `buffer` holds the data to be written.
+	//  * `endOfStream` is a boolean that indicates if this is the last data to be written.
+	OnWrite(buffer NetworkWriteBuffer, endOfStream bool) NetworkFilterStatus
+	// OnClose is called when the connection is closed with the reason of the close.
+	OnClose(event ConnectionEvent)
+
+	// Destroy is called when the filter instance is destroyed after the connection is closed.
+	Destroy()
+}
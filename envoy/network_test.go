@@ -0,0 +1,198 @@
+//go:build !cgo
+
+package envoy
+
+import (
+	"slices"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// fakeNetworkBuffers sets the network buffer functions of FakeHost for the test to operate on the buffers of
+// fakeBodies, and returns them by the pointers passed to the event hooks.
+func fakeNetworkBuffers(t *testing.T) map[uintptr]*fakeBody {
+	bodies := fakeBodies(t)
+	FakeHost.NetworkGetReadBufferLength = FakeHost.HttpGetRequestBodyBufferLength
+	FakeHost.NetworkGetReadBufferSlicesCount = FakeHost.HttpGetRequestBodyBufferSlicesCount
+	FakeHost.NetworkGetReadBufferSlice = FakeHost.HttpGetRequestBodyBufferSlice
+	FakeHost.NetworkCopyOutReadBuffer = FakeHost.HttpCopyOutRequestBodyBuffer
+	FakeHost.NetworkAppendReadBuffer = FakeHost.HttpAppendRequestBodyBuffer
+	FakeHost.NetworkPrependReadBuffer = FakeHost.HttpPrependRequestBodyBuffer
+	FakeHost.NetworkDrainReadBuffer = FakeHost.HttpDrainRequestBodyBuffer
+	FakeHost.NetworkGetWriteBufferLength = FakeHost.HttpGetResponseBodyBufferLength
+	FakeHost.NetworkGetWriteBufferSlicesCount = FakeHost.HttpGetResponseBodyBufferSlicesCount
+	FakeHost.NetworkGetWriteBufferSlice = FakeHost.HttpGetResponseBodyBufferSlice
+	FakeHost.NetworkCopyOutWriteBuffer = FakeHost.HttpCopyOutResponseBodyBuffer
+	FakeHost.NetworkAppendWriteBuffer = FakeHost.HttpAppendResponseBodyBuffer
+	FakeHost.NetworkPrependWriteBuffer = FakeHost.HttpPrependResponseBodyBuffer
+	FakeHost.NetworkDrainWriteBuffer = FakeHost.HttpDrainResponseBodyBuffer
+	return bodies
+}
+
+// newTestNetworkFilter creates the network filter of the test via the event hook, which is destroyed on cleanup.
+func newTestNetworkFilter(t *testing.T, filter NetworkFilter) uintptr {
+	prev := NewNetworkFilter
+	NewNetworkFilter = func(string) NetworkFilter { return filter }
+	config := t.Name()
+	raw := eventNetworkFilterInit(uintptr(unsafe.Pointer(unsafe.StringData(config))), len(config))
+	NewNetworkFilter = prev
+	t.Cleanup(func() { eventNetworkFilterDestroy(raw) })
+	return raw
+}
+
+// echoNetworkFilter is the NetworkFilter whose instances echo the read data back to the downstream, and record
+// the events.
+type echoNetworkFilter struct {
+	events *[]string
+	// instances receives the EnvoyNetworkFilterInstance of each new instance if not nil.
+	instances chan EnvoyNetworkFilterInstance
+}
+
+func (f echoNetworkFilter) NewInstance(e EnvoyNetworkFilterInstance) NetworkFilterInstance {
+	if f.instances != nil {
+		f.instances <- e
+	}
+	return &echoNetworkFilterInstance{envoy: e, events: f.events}
+}
+
+func (echoNetworkFilter) Destroy() {}
+
+type echoNetworkFilterInstance struct {
+	envoy  EnvoyNetworkFilterInstance
+	events *[]string
+}
+
+func (i *echoNetworkFilterInstance) OnNewConnection() NetworkFilterStatus {
+	addr, _ := i.envoy.RemoteAddress()
+	*i.events = append(*i.events, "connection "+addr)
+	return NetworkFilterStatusContinue
+}
+
+func (i *echoNetworkFilterInstance) OnRead(buffer NetworkReadBuffer, endOfStream bool) NetworkFilterStatus {
+	data := buffer.Copy()
+	*i.events = append(*i.events, "read "+string(data))
+	buffer.Drain(len(data))
+	i.envoy.Write(data, endOfStream)
+	return NetworkFilterStatusStopIteration
+}
+
+func (i *echoNetworkFilterInstance) OnWrite(buffer NetworkWriteBuffer, _ bool) NetworkFilterStatus {
+	*i.events = append(*i.events, "write "+string(buffer.Copy()))
+	buffer.Prepend([]byte("> "))
+	return NetworkFilterStatusContinue
+}
+
+func (i *echoNetworkFilterInstance) OnClose(event ConnectionEvent) {
+	*i.events = append(*i.events, "close")
+}
+
+func (i *echoNetworkFilterInstance) Destroy() {
+	*i.events = append(*i.events, "destroy")
+}
+
+// fakeNetworkConnection sets the connection functions of FakeHost for the test, and returns the data written to
+// the connection.
+func fakeNetworkConnection(t *testing.T) *[]string {
+	var written []string
+	prev := FakeHost
+	FakeHost.NetworkWrite = func(_ uintptr, data unsafe.Pointer, dataLength int, endOfStream bool) {
+		written = append(written, string(unsafe.Slice((*byte)(data), dataLength)))
+	}
+	FakeHost.NetworkGetRemoteAddress = func(_ uintptr, resultBufferPtr unsafe.Pointer,
+		resultBufferLengthPtr unsafe.Pointer) int {
+		const addr = "127.0.0.1:1234"
+		*(**byte)(resultBufferPtr) = unsafe.StringData(addr)
+		*(*int)(resultBufferLengthPtr) = len(addr)
+		return 1
+	}
+	t.Cleanup(func() { FakeHost = prev })
+	return &written
+}
+
+func TestNetworkFilter(t *testing.T) {
+	buffers := fakeNetworkBuffers(t)
+	written := fakeNetworkConnection(t)
+	var events []string
+	filter := newTestNetworkFilter(t, echoNetworkFilter{events: &events})
+	instance := eventNetworkFilterInstanceInit(1, filter)
+
+	if status := eventNetworkFilterInstanceNewConnection(instance); status != int(NetworkFilterStatusContinue) {
+		t.Fatalf("status of new connection = %d", status)
+	}
+	buffers[10] = &fakeBody{slices: [][]byte{[]byte("hel"), []byte("lo")}}
+	if status := eventNetworkFilterInstanceRead(instance, 10, false); status != int(NetworkFilterStatusStopIteration) {
+		t.Fatalf("status of read = %d", status)
+	}
+	buffers[20] = &fakeBody{slices: [][]byte{[]byte("world")}}
+	if status := eventNetworkFilterInstanceWrite(instance, 20, false); status != int(NetworkFilterStatusContinue) {
+		t.Fatalf("status of write = %d", status)
+	}
+	eventNetworkFilterInstanceClose(instance, int(ConnectionEventRemoteClose))
+	eventNetworkFilterInstanceDestroy(instance)
+
+	want := []string{"connection 127.0.0.1:1234", "read hello", "write world", "close", "destroy"}
+	if !slices.Equal(events, want) {
+		t.Fatalf("events = %q, want %q", events, want)
+	}
+	if read, write := string(buffers[10].bytes()), string(buffers[20].bytes()); read != "" || write != "> world" {
+		t.Fatalf("read buffer = %q, write buffer = %q", read, write)
+	}
+	if !slices.Equal(*written, []string{"hello"}) {
+		t.Fatalf("written %q, want the echo", *written)
+	}
+}
+
+func TestNetworkFilterInstanceAfterDestroy(t *testing.T) {
+	written := fakeNetworkConnection(t)
+	var events []string
+	instances := make(chan EnvoyNetworkFilterInstance, 2)
+	filter := newTestNetworkFilter(t, echoNetworkFilter{events: &events, instances: instances})
+
+	// The destroy waits for the call in flight on another Goroutine.
+	instance := eventNetworkFilterInstanceInit(1, filter)
+	e := <-instances
+	writing, release := make(chan struct{}), make(chan struct{})
+	FakeHost.NetworkWrite = func(uintptr, unsafe.Pointer, int, bool) {
+		close(writing)
+		<-release
+	}
+	go e.Write([]byte("late"), false)
+	<-writing
+	destroyed := make(chan struct{})
+	go func() {
+		eventNetworkFilterInstanceDestroy(instance)
+		close(destroyed)
+	}()
+	select {
+	case <-destroyed:
+		t.Fatal("the destroy returned during the write")
+	case <-time.After(30 * time.Millisecond):
+	}
+	close(release)
+	<-destroyed
+
+	// Then the methods are no-op without calling Envoy.
+	FakeHost.NetworkWrite = func(uintptr, unsafe.Pointer, int, bool) { t.Error("Write called Envoy after destroy") }
+	FakeHost.NetworkContinueReading = func(uintptr) { t.Error("ContinueReading called Envoy after destroy") }
+	FakeHost.NetworkClose = func(uintptr, int) { t.Error("Close called Envoy after destroy") }
+	if e.Err() != ErrConnectionDestroyed {
+		t.Fatalf("Err() = %v after the destroy", e.Err())
+	}
+	e.Write([]byte("data"), true)
+	e.ContinueReading()
+	e.Close(true)
+	if addr, ok := e.RemoteAddress(); ok || addr != "" {
+		t.Fatalf("RemoteAddress() = %q, %t after the destroy", addr, ok)
+	}
+	if len(*written) != 0 {
+		t.Fatalf("written %q", *written)
+	}
+
+	// The instance of the live connection is not affected.
+	instance = eventNetworkFilterInstanceInit(2, filter)
+	defer eventNetworkFilterInstanceDestroy(instance)
+	if e := <-instances; e.Err() != nil {
+		t.Fatalf("Err() = %v before the destroy", e.Err())
+	}
+}
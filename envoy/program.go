@@ -0,0 +1,95 @@
+package envoy
+
+import "sync"
+
+// ABIVersion is the version of the ABI that this SDK is built with. The version implemented by the Envoy
+// that loads the module can be retrieved via HostABIVersion.
+const ABIVersion = abiVersion
+
+// OnProgramInit registers the hook that is called exactly once when the module is loaded by Envoy,
+// before any filter or logger is created. This is supposed to be called in the init function in the program,
+// and can be called multiple times to register multiple hooks which are called in the registration order.
+//
+// If a hook returns an error, the remaining hooks are not called and Envoy refuses to load the module.
+// This can be used for global setups as well as to reject an incompatible Envoy by checking HostABIVersion
+// and HostSupports.
+func OnProgramInit(hook func() error) {
+	programInitHooksMutex.Lock()
+	defer programInitHooksMutex.Unlock()
+	programInitHooks = append(programInitHooks, hook)
+}
+
+var (
+	programInitHooks      []func() error
+	programInitHooksMutex sync.Mutex
+)
+
+// runProgramInitHooks calls the hooks registered via OnProgramInit.
+func runProgramInitHooks() error {
+	programInitHooksMutex.Lock()
+	defer programInitHooksMutex.Unlock()
+	for _, hook := range programInitHooks {
+		if err := hook(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Feature is a set of the optional Envoy APIs that may not be implemented by the Envoy that loads the module,
+// e.g. the older versions of Envoy. Use HostSupports to check if the feature is available.
+//
+// The methods that depend on a missing feature are no-op, and the events of a missing feature are never called.
+type Feature uint64
+
+const (
+	// FeatureFlowControl is the feature of EnvoyFilterInstance.ReadDisableRequest/ReadDisableResponse.
+	FeatureFlowControl Feature = 1 << iota
+	// FeatureDataInjection is the feature of EnvoyFilterInstance.InjectRequestData/InjectResponseData.
+	FeatureDataInjection
+	// FeatureAddBody is the feature of EnvoyFilterInstance.AddRequestBody/AddResponseBody.
+	FeatureAddBody
+	// FeatureBypassEvents is the feature that bypasses the events of the handlers that are not implemented by
+	// HttpFilterInstance. Without this, the events are still called and return the continue status.
+	FeatureBypassEvents
+	// FeatureUpstreamInfo is the feature of EnvoyFilterInstance.UpstreamInfo.
+	FeatureUpstreamInfo
+	// FeatureNetworkFilter is the feature of NetworkFilter.
+	FeatureNetworkFilter
+	// FeatureListenerFilter is the feature of ListenerFilter.
+	FeatureListenerFilter
+	// FeatureAccessLogger is the feature of AccessLogger.
+	FeatureAccessLogger
+	// FeatureStats is the feature of the Envoy stats used by ReportPinnedObjects, the executors, the body limits
+	// and the watchdogs.
+	FeatureStats
+	// FeatureHeaderBatch is the feature of RequestHeaders.Apply/GetMany and ResponseHeaders.Apply/GetMany
+	// done in a single call to Envoy.
+	FeatureHeaderBatch
+	// FeatureResetStream is the feature of resetting the stream by the Watchdog. Without this, WatchdogReset
+	// falls back to WatchdogLocalReply.
+	FeatureResetStream
+)
+
+// HostABIVersion returns the ABI version implemented by the Envoy that loads the module.
+// This returns 1 for the Envoy that predates the version negotiation.
+func HostABIVersion() int {
+	host := getHostInfo()
+	return host.abiVersion
+}
+
+// HostSupports returns true if the Envoy that loads the module implements all the given features.
+func HostSupports(features Feature) bool {
+	host := getHostInfo()
+	return host.features&features == features
+}
+
+// hostInfo is the information about the Envoy that loads the module.
+type hostInfo struct {
+	abiVersion int
+	features   Feature
+}
+
+// getHostInfo returns the information about the Envoy that loads the module. This is detected once and cached
+// as the host never changes during the lifetime of the module.
+var getHostInfo = sync.OnceValue(detectHostInfo)
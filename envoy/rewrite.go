@@ -0,0 +1,411 @@
+package envoy
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Replacement is a pair of the string to find and its replacement.
+type Replacement struct {
+	Old, New string
+}
+
+// RewriterConfig is the configuration of Rewriter. Exactly one of Replacements and Regexp must be set.
+type RewriterConfig struct {
+	// Replacements is the strings to replace, which are matched all at once with the Aho-Corasick algorithm.
+	// The matches don't overlap, and the leftmost one is replaced first. Among the matches starting at the same
+	// position, the longest one is replaced.
+	Replacements []Replacement
+	// CaseInsensitive makes Replacements match ignoring the ASCII case.
+	CaseInsensitive bool
+
+	// Regexp is the pattern to replace. The matches are found with the leftmost-first semantics of the regexp
+	// package, and the empty matches are ignored. The anchors such as ^, $ and \b are evaluated against the
+	// part of the body seen in the callback, not the entire body, so they should be avoided.
+	Regexp *regexp.Regexp
+	// Template is the replacement of the Regexp matches, in which $1 and ${name} are expanded as regexp.Regexp.Expand.
+	Template string
+	// MaxMatchLength is the maximum length of the Regexp matches in bytes, and must be positive with Regexp. Up to
+	// MaxMatchLength-1 bytes are carried over between the callbacks, and a longer match might not be replaced when
+	// it straddles the chunks.
+	MaxMatchLength int
+}
+
+// Rewriter is the compiled find-and-replace of the streamed body, which is immutable and shared between the streams.
+// This is typically created in envoy.NewHttpFilter, and BodyRewriter is created for each stream.
+type Rewriter struct {
+	replacements []Replacement
+	// nodes and delta are the deterministic Aho-Corasick automaton of the Old strings of the replacements, whose
+	// root is nodes[0]. The next node of the node n with the byte c is delta[n*classes+class[c]].
+	nodes   []rewriteNode
+	delta   []int32
+	class   [256]int32
+	classes int32
+
+	re             *regexp.Regexp
+	template       []byte
+	maxMatchLength int
+
+	// lengthPreserving is true if the replacements never change the length of the body.
+	lengthPreserving bool
+}
+
+// rewriteNode is a node of the automaton, which represents a prefix of the Old strings.
+type rewriteNode struct {
+	// depth is the length of the prefix.
+	depth int32
+	// match is the index of the longest replacement whose Old is a suffix of the prefix, or -1.
+	match int32
+	// leaf is true if the prefix is not a prefix of any longer Old.
+	leaf bool
+}
+
+// NewRewriter compiles the configuration, and returns an error if the configuration is invalid.
+func NewRewriter(config RewriterConfig) (*Rewriter, error) {
+	switch {
+	case (len(config.Replacements) == 0) == (config.Regexp == nil):
+		return nil, errors.New("envoy: exactly one of Replacements and Regexp must be set")
+	case config.Regexp != nil && config.MaxMatchLength <= 0:
+		return nil, errors.New("envoy: MaxMatchLength must be positive with Regexp")
+	case config.Regexp != nil:
+		return &Rewriter{re: config.Regexp, template: []byte(config.Template), maxMatchLength: config.MaxMatchLength}, nil
+	}
+
+	r := &Rewriter{replacements: config.Replacements, lengthPreserving: true}
+	fold := func(c byte) byte {
+		if config.CaseInsensitive && 'A' <= c && c <= 'Z' {
+			return c + 'a' - 'A'
+		}
+		return c
+	}
+	// The bytes not in any Old share the class 0 so that the size of the transition table is proportional to
+	// the number of the distinct bytes in the Old strings instead of 256.
+	r.classes = 1
+	for _, rep := range config.Replacements {
+		if rep.Old == "" {
+			return nil, errors.New("envoy: Old of Replacement must not be empty")
+		}
+		for i := 0; i < len(rep.Old); i++ {
+			if c := fold(rep.Old[i]); r.class[c] == 0 {
+				r.class[c] = r.classes
+				r.classes++
+			}
+		}
+	}
+	for c := range r.class {
+		r.class[c] = r.class[fold(byte(c))]
+	}
+
+	// The trie is built first, where the missing transitions are -1.
+	r.nodes = []rewriteNode{{match: -1, leaf: true}}
+	r.delta = r.newRow()
+	for i, rep := range config.Replacements {
+		r.lengthPreserving = r.lengthPreserving && len(rep.Old) == len(rep.New)
+		var n int32
+		for j := 0; j < len(rep.Old); j++ {
+			at := n*r.classes + r.class[rep.Old[j]]
+			if r.delta[at] < 0 {
+				r.delta[at] = int32(len(r.nodes))
+				r.nodes[n].leaf = false
+				r.nodes = append(r.nodes, rewriteNode{depth: r.nodes[n].depth + 1, match: -1, leaf: true})
+				r.delta = append(r.delta, r.newRow()...)
+			}
+			n = r.delta[at]
+		}
+		// The first one wins if Old is duplicated.
+		if r.nodes[n].match < 0 {
+			r.nodes[n].match = int32(i)
+		}
+	}
+	// Then the missing transitions are filled with the ones of the failure links, i.e. the nodes of the longest
+	// proper suffixes, in the breadth-first order so that the rows of the shallower nodes are complete.
+	fail := make([]int32, len(r.nodes))
+	queue := []int32{0}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for c := int32(0); c < r.classes; c++ {
+			at := n*r.classes + c
+			child := r.delta[at]
+			if child < 0 {
+				r.delta[at] = r.delta[fail[n]*r.classes+c]
+				if n == 0 {
+					r.delta[at] = 0
+				}
+				continue
+			}
+			if n != 0 {
+				fail[child] = r.delta[fail[n]*r.classes+c]
+			}
+			if r.nodes[child].match < 0 {
+				r.nodes[child].match = r.nodes[fail[child]].match
+			}
+			queue = append(queue, child)
+		}
+	}
+	return r, nil
+}
+
+// newRow returns the row of the transitions of a new node without any transition.
+func (r *Rewriter) newRow() []int32 {
+	row := make([]int32, r.classes)
+	for c := range row {
+		row[c] = -1
+	}
+	return row
+}
+
+// Rewrite returns the entire data rewritten, which is the same as the concatenation of the chunks rewritten by
+// BodyRewriter regardless of how the data is split. This can be used for the buffered bodies.
+func (r *Rewriter) Rewrite(data []byte) []byte {
+	out, _, _ := r.scan(data, true)
+	return out
+}
+
+// scan returns the rewritten bytes of the window and the index of the window up to which the bytes are final.
+// The rest of the window must be carried over to the next window unless endOfStream. The returned bytes are
+// window[:rest] itself if nothing is replaced.
+func (r *Rewriter) scan(window []byte, endOfStream bool) (out []byte, rest int, replaced bool) {
+	if r.re != nil {
+		return r.scanRegexp(window, endOfStream)
+	}
+	pos := 0
+	for {
+		// bestStart and best are the leftmost-longest match seen so far, which is replaced once no match starting
+		// at or before it can be in progress.
+		bestStart, best := 0, int32(-1)
+		var n int32
+		i := pos
+		for ; i < len(window); i++ {
+			n = r.delta[n*r.classes+r.class[window[i]]]
+			node := &r.nodes[n]
+			if node.match >= 0 {
+				if start := i + 1 - len(r.replacements[node.match].Old); best < 0 || start <= bestStart {
+					bestStart, best = start, node.match
+				}
+			}
+			// progress is the start of the longest match in progress.
+			progress := i + 1 - int(node.depth)
+			if best >= 0 && (bestStart < progress || bestStart == progress && node.leaf) {
+				break
+			}
+		}
+		if best >= 0 && (i < len(window) || endOfStream) {
+			out = append(out, window[pos:bestStart]...)
+			out = append(out, r.replacements[best].New...)
+			pos, replaced = bestStart+len(r.replacements[best].Old), true
+			continue
+		}
+		rest = len(window)
+		if !endOfStream {
+			rest -= int(r.nodes[n].depth)
+			if best >= 0 {
+				rest = min(rest, bestStart)
+			}
+		}
+		if !replaced {
+			return window[:rest], rest, false
+		}
+		return append(out, window[pos:rest]...), rest, true
+	}
+}
+
+// scanRegexp is scan for Regexp.
+func (r *Rewriter) scanRegexp(window []byte, endOfStream bool) (out []byte, rest int, replaced bool) {
+	// pos is the index of the window up to which the bytes are appended to out, and from is where the next match
+	// is searched, which is different after the empty match.
+	pos, from := 0, 0
+	// final is the index of the window before which no match can start unless endOfStream.
+	final := len(window)
+	if !endOfStream {
+		final = len(window) - r.maxMatchLength + 1
+	}
+	for from < len(window) {
+		loc := r.re.FindSubmatchIndex(window[from:])
+		if loc == nil {
+			break
+		}
+		start, end := from+loc[0], from+loc[1]
+		// The match is final if all the bytes that a match starting at or before it can consume have arrived.
+		if !endOfStream && start+r.maxMatchLength > len(window) {
+			final = min(final, start)
+			break
+		}
+		if start == end {
+			from = start + 1
+			continue
+		}
+		out = append(out, window[pos:start]...)
+		out = r.re.Expand(out, r.template, window[from:], loc)
+		pos, from, replaced = end, end, true
+	}
+	rest = max(pos, final)
+	if !replaced {
+		return window[:rest], rest, false
+	}
+	return append(out, window[pos:rest]...), rest, true
+}
+
+// BodyRewriter rewrites the request or response body of a stream chunk by chunk with Rewriter, and holds only the
+// bytes that can be a part of a match straddling the chunks until the next chunk arrives.
+//
+//	func (h *myHttpFilterInstance) ResponseHeaders(headers envoy.ResponseHeaders, endOfStream bool) envoy.ResponseHeadersStatus {
+//		h.rewriter = h.filter.rewriter.NewBodyRewriter(h.envoyFilter)
+//		h.rewriter.PrepareResponseHeaders(headers)
+//		return envoy.ResponseHeadersStatusContinue
+//	}
+//
+//	func (h *myHttpFilterInstance) ResponseBody(body envoy.ResponseBodyBuffer, endOfStream bool) envoy.ResponseBodyStatus {
+//		return h.rewriter.RewriteResponseBody(body, endOfStream)
+//	}
+//
+// The held bytes are left in the body buffered in Envoy by returning the StopIterationAndBuffer status, and the rest
+// of the chunk is emitted via EnvoyFilterInstance.InjectRequestData or InjectResponseData. So, if the body ends with
+// the trailers, which the ABI has no event for, Envoy emits the held bytes as they are instead of losing them.
+// If the host doesn't support FeatureDataInjection, the rewritten bytes are held in Envoy along with them until
+// a chunk ends without such bytes, which is every chunk but the last with Regexp.
+type BodyRewriter struct {
+	envoyFilter EnvoyFilterInstance
+	rewriter    *Rewriter
+	// carry is the bytes that can be a part of a match straddling the chunks, and pending is the rewritten bytes
+	// held before them without FeatureDataInjection. Both are held in the body buffered in Envoy.
+	carry, pending []byte
+	disabled       bool
+}
+
+// NewBodyRewriter creates a new BodyRewriter for a direction of the stream of the EnvoyFilterInstance.
+func (r *Rewriter) NewBodyRewriter(e EnvoyFilterInstance) *BodyRewriter {
+	return &BodyRewriter{envoyFilter: e, rewriter: r}
+}
+
+// PrepareRequestHeaders must be called in RequestHeadersHandler.RequestHeaders before rewriting the request body.
+// This is the same as PrepareResponseHeaders for the request.
+func (b *BodyRewriter) PrepareRequestHeaders(headers RequestHeaders) bool {
+	contentType, _ := headers.Get("content-type")
+	contentEncoding, _ := headers.Get("content-encoding")
+	if b.disable(contentType.String(), contentEncoding.String()) {
+		return false
+	}
+	if !b.rewriter.lengthPreserving {
+		headers.Remove("content-length")
+	}
+	return true
+}
+
+// PrepareResponseHeaders must be called in ResponseHeadersHandler.ResponseHeaders before rewriting the response body.
+// This removes the content-length header unless the replacements never change the length of the body.
+//
+// This returns false and disables the rewriting if the body cannot be rewritten, i.e. the body is encoded as shown
+// by the content-encoding header, e.g. gzip, or is gRPC. RewriteResponseBody passes through the body in that case.
+func (b *BodyRewriter) PrepareResponseHeaders(headers ResponseHeaders) bool {
+	contentType, _ := headers.Get("content-type")
+	contentEncoding, _ := headers.Get("content-encoding")
+	if b.disable(contentType.String(), contentEncoding.String()) {
+		return false
+	}
+	if !b.rewriter.lengthPreserving {
+		headers.Remove("content-length")
+	}
+	return true
+}
+
+// disable disables the rewriting if the body of the content type and encoding cannot be rewritten.
+func (b *BodyRewriter) disable(contentType, contentEncoding string) bool {
+	b.disabled = contentEncoding != "" && !strings.EqualFold(contentEncoding, "identity") ||
+		strings.HasPrefix(strings.ToLower(contentType), "application/grpc")
+	return b.disabled
+}
+
+// RewriteRequestBody rewrites the chunk of the request body in place, and returns the status that
+// RequestBodyHandler.RequestBody must return. This must be called in RequestBodyHandler.RequestBody.
+func (b *BodyRewriter) RewriteRequestBody(body RequestBodyBuffer, endOfStream bool) RequestBodyStatus {
+	if b.disabled {
+		return RequestBodyStatusContinue
+	}
+	chunk := body.Copy()
+	if held := len(b.pending) + len(b.carry); held > 0 {
+		// The bytes held by the previous chunk are emitted with this chunk instead.
+		if buffered := b.envoyFilter.GetRequestBodyBuffer(); buffered.raw == body.raw {
+			chunk = chunk[held:]
+		} else {
+			buffered.Drain(held)
+		}
+	}
+	out, hold, ok := b.rewrite(chunk, endOfStream, HostSupports(FeatureDataInjection))
+	if !ok {
+		return RequestBodyStatusContinue
+	}
+	if len(hold) == 0 {
+		body.Replace(out)
+		return RequestBodyStatusContinue
+	}
+	if len(out) > 0 {
+		b.envoyFilter.InjectRequestData(out, false)
+	}
+	body.Replace(hold)
+	return RequestBodyStatusStopIterationAndBuffer
+}
+
+// RewriteResponseBody rewrites the chunk of the response body in place, and returns the status that
+// ResponseBodyHandler.ResponseBody must return. This must be called in ResponseBodyHandler.ResponseBody.
+func (b *BodyRewriter) RewriteResponseBody(body ResponseBodyBuffer, endOfStream bool) ResponseBodyStatus {
+	if b.disabled {
+		return ResponseBodyStatusContinue
+	}
+	chunk := body.Copy()
+	if held := len(b.pending) + len(b.carry); held > 0 {
+		// The bytes held by the previous chunk are emitted with this chunk instead.
+		if buffered := b.envoyFilter.GetResponseBodyBuffer(); buffered.raw == body.raw {
+			chunk = chunk[held:]
+		} else {
+			buffered.Drain(held)
+		}
+	}
+	out, hold, ok := b.rewrite(chunk, endOfStream, HostSupports(FeatureDataInjection))
+	if !ok {
+		return ResponseBodyStatusContinue
+	}
+	if len(hold) == 0 {
+		body.Replace(out)
+		return ResponseBodyStatusContinue
+	}
+	if len(out) > 0 {
+		b.envoyFilter.InjectResponseData(out, false)
+	}
+	body.Replace(hold)
+	return ResponseBodyStatusStopIterationAndBuffer
+}
+
+// rewrite returns the bytes to emit now, which are the final bytes of the held bytes and the chunk rewritten, and
+// the bytes to hold in Envoy until the next chunk, which include the rewritten ones too unless inject is true.
+// This returns false if the chunk must be left untouched as nothing is held, replaced or carried over.
+func (b *BodyRewriter) rewrite(chunk []byte, endOfStream, inject bool) (out, hold []byte, ok bool) {
+	window := chunk
+	held := len(b.pending) > 0 || len(b.carry) > 0
+	if len(b.carry) > 0 {
+		// The window is allocated so that the returned bytes, which can be a part of the window, are not
+		// overwritten by the next carry.
+		window = append(b.carry[:len(b.carry):len(b.carry)], chunk...)
+	}
+	final, rest, replaced := b.rewriter.scan(window, endOfStream)
+	if !held && !replaced && rest == len(window) {
+		return nil, nil, false
+	}
+	if len(b.pending) > 0 {
+		final = append(b.pending, final...)
+	}
+	b.carry = append(b.carry[:0], window[rest:]...)
+	switch {
+	case len(b.carry) == 0:
+		b.pending = nil
+		return final, nil, true
+	case inject:
+		b.pending = nil
+		return final, b.carry, true
+	default:
+		// final doesn't share the memory with the carry as the window is either the chunk or a new slice.
+		b.pending = final
+		return nil, append(final[:len(final):len(final)], b.carry...), true
+	}
+}
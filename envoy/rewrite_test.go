@@ -0,0 +1,283 @@
+//go:build !cgo
+
+package envoy
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// naiveRewrite is the reference of the Replacements, which replaces the leftmost-longest matches one by one.
+func naiveRewrite(config RewriterConfig, data string) string {
+	fold := func(s string) string {
+		if config.CaseInsensitive {
+			return strings.ToLower(s)
+		}
+		return s
+	}
+	var out strings.Builder
+	for i := 0; i < len(data); {
+		best := -1
+		for j, rep := range config.Replacements {
+			longer := best < 0 || len(rep.Old) > len(config.Replacements[best].Old)
+			if longer && strings.HasPrefix(fold(data[i:]), fold(rep.Old)) {
+				best = j
+			}
+		}
+		if best < 0 {
+			out.WriteByte(data[i])
+			i++
+			continue
+		}
+		out.WriteString(config.Replacements[best].New)
+		i += len(config.Replacements[best].Old)
+	}
+	return out.String()
+}
+
+// rewriteChunks streams the chunks as the request body through the filter rewriting it with a BodyRewriter, and
+// returns the body emitted by the fake Envoy. If trailers is true, the body ends with the trailers instead of the
+// chunk of the end of stream, so the body buffered in Envoy is emitted as is after the last chunk.
+func rewriteChunks(t *testing.T, r *Rewriter, chunks []string, trailers bool) string {
+	bodies := fakeBodies(t)
+	var emitted []byte
+	FakeHost.HttpInjectRequestData = func(_ uintptr, data unsafe.Pointer, length int, _ bool) {
+		emitted = append(emitted, unsafe.Slice((*byte)(data), length)...)
+	}
+	var b *BodyRewriter
+	filter := newTestHttpFilter(t, bodyHttpFilter{
+		requestBody: func(e EnvoyFilterInstance, body RequestBodyBuffer, endOfStream bool) RequestBodyStatus {
+			if b == nil {
+				b = r.NewBodyRewriter(e)
+			}
+			return b.RewriteRequestBody(body, endOfStream)
+		},
+	})
+	instance := eventHttpFilterInstanceInit(1, filter)
+	defer eventHttpFilterInstanceDestroy(instance)
+	buffered := requestBufferOf(1)
+	bodies[buffered] = &fakeBody{}
+	for i, chunk := range chunks {
+		event := uintptr(10 + i)
+		bodies[event] = &fakeBody{slices: [][]byte{[]byte(chunk)}}
+		status := eventHttpFilterInstanceRequestBody(instance, event, i == len(chunks)-1 && !trailers)
+		if status == int(RequestBodyStatusStopIterationAndBuffer) {
+			bodies[buffered].slices = append(bodies[buffered].slices, bodies[event].bytes())
+			continue
+		}
+		emitted = append(append(emitted, bodies[buffered].bytes()...), bodies[event].bytes()...)
+		bodies[buffered] = &fakeBody{}
+	}
+	return string(append(emitted, bodies[buffered].bytes()...))
+}
+
+// forEachInjection runs f with and without the host supporting FeatureDataInjection.
+func forEachInjection(t *testing.T, f func(t *testing.T)) {
+	t.Run("injection", f)
+	t.Run("no injection", func(t *testing.T) {
+		withoutFeatures(t, FeatureDataInjection)
+		f(t)
+	})
+}
+
+// splits returns all the ways to split the data into two and three chunks including the empty ones.
+func splits(data string) [][]string {
+	var ret [][]string
+	for i := 0; i <= len(data); i++ {
+		ret = append(ret, []string{data[:i], data[i:]})
+		for j := i; j <= len(data); j++ {
+			ret = append(ret, []string{data[:i], data[i:j], data[j:]})
+		}
+	}
+	return ret
+}
+
+func TestRewriterChunkBoundaries(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config RewriterConfig
+		data   string
+		want   string
+	}{
+		{
+			name:   "single",
+			config: RewriterConfig{Replacements: []Replacement{{Old: "abc", New: "X"}}},
+			data:   "xxabcxxabcabx",
+			want:   "xxXxxXabx",
+		},
+		{
+			name: "leftmost",
+			config: RewriterConfig{Replacements: []Replacement{
+				{Old: "he", New: "1"}, {Old: "she", New: "2"}, {Old: "hers", New: "3"}, {Old: "his", New: "4"},
+			}},
+			data: "ushers hishe",
+			want: "u2rs 41",
+		},
+		{
+			name: "longest",
+			config: RewriterConfig{Replacements: []Replacement{
+				{Old: "a", New: "1"}, {Old: "ab", New: "2"}, {Old: "abc", New: "3"},
+			}},
+			data: "abcababa",
+			want: "3221",
+		},
+		{
+			name:   "failure link",
+			config: RewriterConfig{Replacements: []Replacement{{Old: "aab", New: "<>"}, {Old: "bb", New: "!"}}},
+			data:   "aaabbaaab",
+			want:   "a<>ba<>",
+		},
+		{
+			name:   "case insensitive",
+			config: RewriterConfig{Replacements: []Replacement{{Old: "Foo", New: "bar"}}, CaseInsensitive: true},
+			data:   "FOO foo fOo fo",
+			want:   "bar bar bar fo",
+		},
+		{
+			name:   "duplicated",
+			config: RewriterConfig{Replacements: []Replacement{{Old: "ab", New: "1"}, {Old: "ab", New: "2"}}},
+			data:   "abab",
+			want:   "11",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := naiveRewrite(tc.config, tc.data); got != tc.want {
+				t.Fatalf("the reference = %q, want %q", got, tc.want)
+			}
+			r, err := NewRewriter(tc.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(r.Rewrite([]byte(tc.data))); got != tc.want {
+				t.Fatalf("Rewrite = %q, want %q", got, tc.want)
+			}
+			forEachInjection(t, func(t *testing.T) {
+				for _, chunks := range splits(tc.data) {
+					if got := rewriteChunks(t, r, chunks, false); got != tc.want {
+						t.Fatalf("chunks %q = %q, want %q", chunks, got, tc.want)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestRewriterRegexpChunkBoundaries(t *testing.T) {
+	r, err := NewRewriter(RewriterConfig{
+		Regexp:         regexp.MustCompile(`(\d+)-(\d+)`),
+		Template:       "$2-$1",
+		MaxMatchLength: 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const data, want = "a1-2b34-56c-7", "a2-1b56-34c-7"
+	if got := string(r.Rewrite([]byte(data))); got != want {
+		t.Fatalf("Rewrite = %q, want %q", got, want)
+	}
+	forEachInjection(t, func(t *testing.T) {
+		for _, chunks := range splits(data) {
+			if got := rewriteChunks(t, r, chunks, false); got != want {
+				t.Fatalf("chunks %q = %q, want %q", chunks, got, want)
+			}
+		}
+	})
+}
+
+func TestRewriterTrailers(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config RewriterConfig
+		// want is the data rewritten except for the bytes held in Envoy when the trailers arrive.
+		data, want string
+	}{
+		{
+			name:   "replacements",
+			config: RewriterConfig{Replacements: []Replacement{{Old: "abc", New: "X"}}},
+			data:   "xxabcxxab",
+			want:   "xxXxxab",
+		},
+		{
+			name:   "regexp",
+			config: RewriterConfig{Regexp: regexp.MustCompile(`(\d+)-(\d+)`), Template: "$2-$1", MaxMatchLength: 5},
+			data:   "1-2 ab3-4",
+			want:   "2-1 ab3-4",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewRewriter(tc.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			forEachInjection(t, func(t *testing.T) {
+				for _, chunks := range splits(tc.data) {
+					if got := rewriteChunks(t, r, chunks, true); got != tc.want {
+						t.Fatalf("chunks %q = %q, want %q", chunks, got, tc.want)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestRewriteRequestBody(t *testing.T) {
+	bodies := fakeBodies(t)
+	var injected []string
+	FakeHost.HttpInjectRequestData = func(_ uintptr, data unsafe.Pointer, length int, _ bool) {
+		injected = append(injected, string(unsafe.Slice((*byte)(data), length)))
+	}
+	r, err := NewRewriter(RewriterConfig{Replacements: []Replacement{{Old: "hello", New: "bye"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b *BodyRewriter
+	filter := newTestHttpFilter(t, bodyHttpFilter{
+		requestBody: func(e EnvoyFilterInstance, body RequestBodyBuffer, endOfStream bool) RequestBodyStatus {
+			if b == nil {
+				b = r.NewBodyRewriter(e)
+			}
+			return b.RewriteRequestBody(body, endOfStream)
+		},
+	})
+	instance := eventHttpFilterInstanceInit(1, filter)
+	defer eventHttpFilterInstanceDestroy(instance)
+
+	// The bytes that can be a part of a match are held in Envoy, and the rest is injected.
+	bodies[10] = &fakeBody{slices: [][]byte{[]byte("say "), []byte("hel")}}
+	status := eventHttpFilterInstanceRequestBody(instance, 10, false)
+	if got := string(bodies[10].bytes()); got != "hel" || len(injected) != 1 || injected[0] != "say " ||
+		status != int(RequestBodyStatusStopIterationAndBuffer) {
+		t.Fatalf("held %q, injected %q, status %d", got, injected, status)
+	}
+
+	// Then, the held bytes are drained from the buffered body and emitted with the next chunk.
+	bodies[requestBufferOf(1)] = &fakeBody{slices: [][]byte{[]byte("hel")}}
+	bodies[20] = &fakeBody{slices: [][]byte{[]byte("lo, hell")}}
+	status = eventHttpFilterInstanceRequestBody(instance, 20, true)
+	buffered, chunk := string(bodies[requestBufferOf(1)].bytes()), string(bodies[20].bytes())
+	if buffered != "" || chunk != "bye, hell" || status != int(RequestBodyStatusContinue) {
+		t.Fatalf("buffered %q, chunk %q, status %d", buffered, chunk, status)
+	}
+}
+
+func TestBodyRewriterDisabled(t *testing.T) {
+	r, err := NewRewriter(RewriterConfig{Replacements: []Replacement{{Old: "a", New: "bb"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		contentType, contentEncoding string
+		want                         bool
+	}{
+		{contentType: "text/plain", want: true},
+		{contentType: "text/plain", contentEncoding: "identity", want: true},
+		{contentType: "text/plain", contentEncoding: "gzip"},
+		{contentType: "application/grpc+proto"},
+	} {
+		if got := !r.NewBodyRewriter(nil).disable(tc.contentType, tc.contentEncoding); got != tc.want {
+			t.Errorf("rewriting %q encoded in %q = %t, want %t", tc.contentType, tc.contentEncoding, got, tc.want)
+		}
+	}
+}
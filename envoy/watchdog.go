@@ -0,0 +1,232 @@
+package envoy
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// WatchdogAction is the action taken by the watchdog when a paused stream is not resumed before the deadline.
+type WatchdogAction int
+
+const (
+	// WatchdogLocalReply sends the local reply to the downstream via EnvoyFilterInstance.SendResponse.
+	WatchdogLocalReply WatchdogAction = iota
+	// WatchdogContinue resumes the stream via EnvoyFilterInstance.ContinueRequest or ContinueResponse
+	// as if the module code did.
+	WatchdogContinue
+	// WatchdogReset resets the stream. This falls back to WatchdogLocalReply if the host doesn't support
+	// FeatureResetStream.
+	WatchdogReset
+)
+
+// String implements fmt.Stringer.
+func (a WatchdogAction) String() string {
+	switch a {
+	case WatchdogLocalReply:
+		return "local_reply"
+	case WatchdogContinue:
+		return "continue"
+	case WatchdogReset:
+		return "reset"
+	default:
+		return "unknown"
+	}
+}
+
+// Watchdog is the configuration of the watchdog of the paused streams of an HttpFilter.
+//
+// A stream is paused when the event callback of HttpFilterInstance returns a Stop status, and resumed when
+// EnvoyFilterInstance.ContinueRequest or ContinueResponse is called, the end of stream is injected, or the next event
+// callback in the same direction returns the Continue status. If the module code forgets to resume the stream or the
+// Goroutine resuming it is stuck, the stream would hang until the stream timeout of Envoy. The watchdog takes Action
+// instead when the stream is not resumed within Deadline, and reports the incident.
+type Watchdog struct {
+	// Deadline is the default deadline of the pauses. Zero disables the watchdog except for the pauses whose deadline
+	// is set by EnvoyFilterInstance.SetPauseDeadline.
+	Deadline time.Duration
+	// Action is the action taken when the deadline is exceeded.
+	Action WatchdogAction
+	// LocalReplyStatus is the status code of the local reply sent by WatchdogLocalReply.
+	// Defaults to 504 Gateway Timeout.
+	LocalReplyStatus int
+	// OnIncident is called after the action is taken, on the Goroutine of the watchdog. Defaults to printing the
+	// incident to stderr. Each incident also increments the go_sdk.watchdog_incidents counter if the host supports
+	// FeatureStats.
+	OnIncident func(WatchdogIncident)
+}
+
+// WatchdogProvider is an optional interface that can be implemented by HttpFilter to enable the watchdog of the paused
+// streams of its HttpFilterInstances. This is detected when the HttpFilter is created.
+type WatchdogProvider interface {
+	// Watchdog returns the configuration of the watchdog. This is called once when the HttpFilter is created.
+	Watchdog() Watchdog
+}
+
+// WatchdogIncident is the report of a paused stream whose deadline is exceeded.
+type WatchdogIncident struct {
+	// Config is the config of the HttpFilter of the stream.
+	Config string
+	// Upstream is true if the HttpFilter is created by NewUpstreamHttpFilter.
+	Upstream bool
+	// Response is true if the response is paused, otherwise the request is paused.
+	Response bool
+	// Paused is the duration of the pause until the action is taken.
+	Paused time.Duration
+	// Action is the action taken, which can differ from Watchdog.Action because of the fallback of WatchdogReset.
+	Action WatchdogAction
+}
+
+// String implements fmt.Stringer.
+func (i WatchdogIncident) String() string {
+	direction := "request"
+	if i.Response {
+		direction = "response"
+	}
+	return fmt.Sprintf("watchdog: %s of http filter %q paused for %s, took %s", direction, i.Config, i.Paused, i.Action)
+}
+
+// pauseDirection is the index of envoyFilterInstance.pauses and bodyLimits.
+type pauseDirection int
+
+const (
+	pauseRequest pauseDirection = iota
+	pauseResponse
+)
+
+// pause is the state of the watchdog for a direction of the stream.
+type pause struct {
+	// timer is created on the first pause and reused afterwards including the reuse of the instance by the pooling.
+	timer *time.Timer
+	// since is the time when the stream is paused, or zero if not paused. deadline is when the action is taken.
+	since, deadline time.Time
+}
+
+// SetPauseDeadline overrides the deadline of the watchdog for the pause started by the Stop status returned from
+// the current event callback, and must be called in the event callback. Negative disables the watchdog for the pause.
+//
+// This works even if the HttpFilter doesn't implement WatchdogProvider, in which case the watchdog takes
+// WatchdogLocalReply with the default configuration.
+func (c *envoyFilterInstance) SetPauseDeadline(d time.Duration) {
+	c.pauseDeadline = d
+}
+
+// watchPause is called on the Envoy worker thread after the event callback in the direction returns, and starts or
+// stops watching the pause of the direction. resumes is the number of the resumes of the direction loaded before
+// the callback was called.
+func (c *envoyFilterInstance) watchPause(dir pauseDirection, paused bool, resumes uint64) {
+	deadline := c.filter.watchdog.Deadline
+	if c.pauseDeadline != 0 {
+		deadline, c.pauseDeadline = c.pauseDeadline, 0
+	}
+	if paused && deadline > 0 {
+		c.armPause(dir, deadline, resumes)
+	} else if c.watching.Load() {
+		c.disarmPause(dir)
+	}
+}
+
+// resume is called when the module code resumes the direction, and stops watching the pause of the direction.
+// This can be called from any Goroutine, including while the event callback that is about to pause is running.
+func (c *envoyFilterInstance) resume(dir pauseDirection) {
+	c.resumes[dir].Add(1)
+	if c.watching.Load() {
+		c.disarmPause(dir)
+	}
+}
+
+// armPause starts watching the pause of the direction, or restarts it if already watching. Nothing is watched if
+// the direction has been resumed since resumes was loaded, e.g. by a Goroutine that resumed the stream before
+// the event callback returned the Stop status, in which case resume has already stopped watching the direction.
+func (c *envoyFilterInstance) armPause(dir pauseDirection, deadline time.Duration, resumes uint64) {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	// watching is set before checking the resumes so that resume either sees it and waits for watchMu to disarm
+	// the pause, or is seen here.
+	c.watching.Store(true)
+	p := &c.pauses[dir]
+	if c.resumes[dir].Load() != resumes {
+		return
+	}
+	p.since = time.Now()
+	p.deadline = p.since.Add(deadline)
+	if p.timer == nil {
+		p.timer = time.AfterFunc(deadline, func() { c.pauseExpired(dir) })
+	} else {
+		p.timer.Reset(deadline)
+	}
+}
+
+// disarmPause stops watching the pause of the direction. This can be called from any Goroutine.
+func (c *envoyFilterInstance) disarmPause(dir pauseDirection) {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	p := &c.pauses[dir]
+	p.since = time.Time{}
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+}
+
+// pauseExpired is called by the timer of the direction, and takes the action if the pause is still being watched.
+func (c *envoyFilterInstance) pauseExpired(dir pauseDirection) {
+	c.watchMu.Lock()
+	p := &c.pauses[dir]
+	now := time.Now()
+	// The pause might have been resumed, or restarted with the new deadline after the timer fired.
+	if p.since.IsZero() || now.Before(p.deadline) {
+		c.watchMu.Unlock()
+		return
+	}
+	paused := now.Sub(p.since)
+	p.since = time.Time{}
+	// The action is taken while holding watchMu so that the destroy of the stream, which stops watching the pauses
+	// first, waits for it. Otherwise, the action could be taken on the next stream of the pooled instance.
+	w := &c.filter.watchdog
+	incident := WatchdogIncident{
+		Config:   c.filter.config,
+		Upstream: c.filter.upstream,
+		Response: dir == pauseResponse,
+		Paused:   paused,
+		Action:   w.Action,
+	}
+	if incident.Action == WatchdogReset && !HostSupports(FeatureResetStream) {
+		incident.Action = WatchdogLocalReply
+	}
+	raw, ok := c.acquire()
+	if !ok {
+		// The stream has completed in the meantime, e.g. the client has gone away.
+		c.watchMu.Unlock()
+		return
+	}
+	switch incident.Action {
+	case WatchdogContinue:
+		if incident.Response {
+			hostHttpContinueResponse(raw)
+		} else {
+			hostHttpContinueRequest(raw)
+		}
+	case WatchdogReset:
+		hostHttpResetStream(raw)
+	default:
+		status := w.LocalReplyStatus
+		if status == 0 {
+			status = http.StatusGatewayTimeout
+		}
+		body := "paused stream deadline exceeded"
+		hostHttpSendResponse(raw, uint32(status), nil, 0, stringPtr(body), len(body))
+	}
+	c.release()
+	c.watchMu.Unlock()
+
+	if HostSupports(FeatureStats) {
+		const name = "go_sdk.watchdog_incidents"
+		hostStatsIncrementCounter(stringPtr(name), len(name), 1)
+	}
+	if w.OnIncident != nil {
+		w.OnIncident(incident)
+	} else {
+		fmt.Fprintf(os.Stderr, "envoy dynamic module: %s\n", incident)
+	}
+}
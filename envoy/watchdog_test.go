@@ -0,0 +1,282 @@
+//go:build !cgo
+
+package envoy
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// watchdogHttpFilter is the HttpFilter implementing WatchdogProvider with the config.
+type watchdogHttpFilter struct {
+	bodyHttpFilter
+	config Watchdog
+}
+
+func (f watchdogHttpFilter) Watchdog() Watchdog { return f.config }
+
+// fakeWatchdogHost sets the FakeHost functions called by the watchdog actions for the test, and returns the calls
+// made so far and the counters incremented by the SDK.
+func fakeWatchdogHost(t *testing.T) (calls func() []string, counters func() map[string]uint64) {
+	var mu sync.Mutex
+	var made []string
+	incremented := map[string]uint64{}
+	record := func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		made = append(made, fmt.Sprintf(format, args...))
+	}
+	prev := FakeHost
+	FakeHost.HttpContinueRequest = func(e uintptr) { record("continue_request %d", e) }
+	FakeHost.HttpContinueResponse = func(e uintptr) { record("continue_response %d", e) }
+	FakeHost.HttpResetStream = func(e uintptr) { record("reset %d", e) }
+	FakeHost.HttpSendResponse = func(e uintptr, statusCode uint32, _ unsafe.Pointer, _ int, _ unsafe.Pointer, _ int) {
+		record("send_response %d %d", e, statusCode)
+	}
+	FakeHost.StatsIncrementCounter = func(name unsafe.Pointer, nameLength int, value uint64) {
+		mu.Lock()
+		defer mu.Unlock()
+		incremented[unsafe.String((*byte)(name), nameLength)] += value
+	}
+	t.Cleanup(func() { FakeHost = prev })
+	calls = func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), made...)
+	}
+	counters = func() map[string]uint64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return incremented
+	}
+	return calls, counters
+}
+
+// pausingHttpFilter returns the watchdogHttpFilter whose instances pause both directions in the body events, and
+// the channel of the incidents reported by the watchdog.
+func pausingHttpFilter(config Watchdog) (watchdogHttpFilter, chan WatchdogIncident) {
+	incidents := make(chan WatchdogIncident, 8)
+	config.OnIncident = func(incident WatchdogIncident) { incidents <- incident }
+	return watchdogHttpFilter{
+		bodyHttpFilter: bodyHttpFilter{
+			requestBody: func(EnvoyFilterInstance, RequestBodyBuffer, bool) RequestBodyStatus {
+				return RequestBodyStatusStopIterationAndBuffer
+			},
+			responseBody: func(EnvoyFilterInstance, ResponseBodyBuffer, bool) ResponseBodyStatus {
+				return ResponseBodyStatusStopIterationAndBuffer
+			},
+		},
+		config: config,
+	}, incidents
+}
+
+// noIncident fails the test if an incident is reported within the duration.
+func noIncident(t *testing.T, incidents chan WatchdogIncident, d time.Duration) {
+	t.Helper()
+	select {
+	case incident := <-incidents:
+		t.Fatalf("unexpected incident: %s", incident)
+	case <-time.After(d):
+	}
+}
+
+func TestWatchdogExpiry(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		response bool
+		config   Watchdog
+		// without is the features unsupported by the host.
+		without Feature
+		want    string
+		action  WatchdogAction
+	}{
+		{name: "local reply", config: Watchdog{Action: WatchdogLocalReply}, want: "send_response 1 504"},
+		{
+			name:   "local reply status",
+			config: Watchdog{Action: WatchdogLocalReply, LocalReplyStatus: 503},
+			want:   "send_response 1 503",
+		},
+		{name: "continue request", config: Watchdog{Action: WatchdogContinue}, want: "continue_request 1"},
+		{
+			name:     "continue response",
+			response: true,
+			config:   Watchdog{Action: WatchdogContinue},
+			want:     "continue_response 1",
+		},
+		{name: "reset", config: Watchdog{Action: WatchdogReset}, want: "reset 1"},
+		{
+			name:    "reset fallback",
+			config:  Watchdog{Action: WatchdogReset},
+			without: FeatureResetStream,
+			want:    "send_response 1 504",
+			action:  WatchdogLocalReply,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls, counters := fakeWatchdogHost(t)
+			withoutFeatures(t, tc.without)
+			tc.config.Deadline = 10 * time.Millisecond
+			f, incidents := pausingHttpFilter(tc.config)
+			filter := newTestHttpFilter(t, f)
+			instance := eventHttpFilterInstanceInit(1, filter)
+			defer eventHttpFilterInstanceDestroy(instance)
+			if tc.response {
+				eventHttpFilterInstanceResponseBody(instance, 10, false)
+			} else {
+				eventHttpFilterInstanceRequestBody(instance, 10, false)
+			}
+
+			incident := <-incidents
+			if got := calls(); len(got) != 1 || got[0] != tc.want {
+				t.Fatalf("calls = %v, want [%s]", got, tc.want)
+			}
+			want := tc.config.Action
+			if tc.without != 0 {
+				want = tc.action
+			}
+			if incident.Config != t.Name() || incident.Response != tc.response || incident.Action != want ||
+				incident.Paused < tc.config.Deadline {
+				t.Fatalf("incident = %+v", incident)
+			}
+			if got := counters()["go_sdk.watchdog_incidents"]; got != 1 {
+				t.Fatalf("go_sdk.watchdog_incidents = %d, want 1", got)
+			}
+			// The action is taken once per pause.
+			noIncident(t, incidents, 30*time.Millisecond)
+		})
+	}
+}
+
+func TestWatchdogResumed(t *testing.T) {
+	calls, _ := fakeWatchdogHost(t)
+	f, incidents := pausingHttpFilter(Watchdog{Deadline: 20 * time.Millisecond})
+	filter := newTestHttpFilter(t, f)
+	instance := eventHttpFilterInstanceInit(1, filter)
+	defer eventHttpFilterInstanceDestroy(instance)
+	e := unwrapRawPinHttpFilterInstance(instance).envoy
+
+	eventHttpFilterInstanceRequestBody(instance, 10, false)
+	e.ContinueRequest()
+	noIncident(t, incidents, 50*time.Millisecond)
+	if got := calls(); len(got) != 1 || got[0] != "continue_request 1" {
+		t.Fatalf("calls = %v, want only the one by the module", got)
+	}
+}
+
+func TestWatchdogResumedDuringCallback(t *testing.T) {
+	calls, _ := fakeWatchdogHost(t)
+	f, incidents := pausingHttpFilter(Watchdog{Deadline: 20 * time.Millisecond})
+	// The Goroutines resume the stream before the callbacks return the Stop status.
+	resumeOn := func(resume func()) {
+		done := make(chan struct{})
+		go func() {
+			resume()
+			close(done)
+		}()
+		<-done
+	}
+	f.requestBody = func(e EnvoyFilterInstance, _ RequestBodyBuffer, _ bool) RequestBodyStatus {
+		resumeOn(e.ContinueRequest)
+		return RequestBodyStatusStopIterationAndBuffer
+	}
+	f.responseBody = func(e EnvoyFilterInstance, _ ResponseBodyBuffer, _ bool) ResponseBodyStatus {
+		resumeOn(func() { e.InjectResponseData(nil, true) })
+		return ResponseBodyStatusStopIterationAndBuffer
+	}
+	filter := newTestHttpFilter(t, f)
+	instance := eventHttpFilterInstanceInit(1, filter)
+	defer eventHttpFilterInstanceDestroy(instance)
+
+	eventHttpFilterInstanceRequestBody(instance, 10, false)
+	eventHttpFilterInstanceResponseBody(instance, 20, false)
+	noIncident(t, incidents, 50*time.Millisecond)
+	if got := calls(); len(got) != 1 || got[0] != "continue_request 1" {
+		t.Fatalf("calls = %v, want only the one by the module", got)
+	}
+}
+
+func TestWatchdogRearm(t *testing.T) {
+	fakeWatchdogHost(t)
+	var deadlines []time.Duration
+	f, incidents := pausingHttpFilter(Watchdog{Deadline: 20 * time.Millisecond})
+	f.requestBody = func(e EnvoyFilterInstance, _ RequestBodyBuffer, _ bool) RequestBodyStatus {
+		if len(deadlines) > 0 {
+			e.SetPauseDeadline(deadlines[0])
+			deadlines = deadlines[1:]
+		}
+		return RequestBodyStatusStopIterationAndBuffer
+	}
+	filter := newTestHttpFilter(t, f)
+	instance := eventHttpFilterInstanceInit(1, filter)
+	defer eventHttpFilterInstanceDestroy(instance)
+	e := unwrapRawPinHttpFilterInstance(instance).envoy
+
+	// The next pause before the deadline restarts watching with its own deadline.
+	eventHttpFilterInstanceRequestBody(instance, 10, false)
+	deadlines = []time.Duration{time.Hour}
+	eventHttpFilterInstanceRequestBody(instance, 10, false)
+	// The timer of the previous deadline firing after the restart takes no action.
+	e.pauseExpired(pauseRequest)
+	noIncident(t, incidents, 50*time.Millisecond)
+
+	deadlines = []time.Duration{10 * time.Millisecond}
+	start := time.Now()
+	eventHttpFilterInstanceRequestBody(instance, 10, false)
+	incident := <-incidents
+	if incident.Paused < 10*time.Millisecond || incident.Paused > time.Since(start) {
+		t.Fatalf("paused = %s, want from the last pause", incident.Paused)
+	}
+
+	// The negative deadline disables the watchdog for the pause.
+	deadlines = []time.Duration{-1}
+	eventHttpFilterInstanceRequestBody(instance, 10, false)
+	noIncident(t, incidents, 50*time.Millisecond)
+}
+
+func TestWatchdogDestroyRace(t *testing.T) {
+	fakeWatchdogHost(t)
+	acting, release := make(chan uintptr, 1), make(chan struct{})
+	FakeHost.HttpSendResponse = func(e uintptr, _ uint32, _ unsafe.Pointer, _ int, _ unsafe.Pointer, _ int) {
+		acting <- e
+		<-release
+	}
+	f, incidents := pausingHttpFilter(Watchdog{Deadline: time.Millisecond})
+	filter := newTestHttpFilter(t, f)
+
+	// The destroy during the action waits for it, so the action never outlives the stream.
+	instance := eventHttpFilterInstanceInit(1, filter)
+	e := unwrapRawPinHttpFilterInstance(instance).envoy
+	eventHttpFilterInstanceRequestBody(instance, 10, false)
+	if got := <-acting; got != 1 {
+		t.Fatalf("action on %d, want 1", got)
+	}
+	destroyed := make(chan struct{})
+	go func() {
+		eventHttpFilterInstanceDestroy(instance)
+		close(destroyed)
+	}()
+	select {
+	case <-destroyed:
+		t.Fatal("the destroy returned during the action")
+	case <-time.After(30 * time.Millisecond):
+	}
+	close(release)
+	<-destroyed
+	<-incidents
+	if e.Err() != ErrStreamDestroyed {
+		t.Fatalf("Err() = %v after the destroy", e.Err())
+	}
+
+	// The destroy before the deadline stops watching, and the timer firing afterwards takes no action.
+	f.config.Deadline = 20 * time.Millisecond
+	filter = newTestHttpFilter(t, f)
+	instance = eventHttpFilterInstanceInit(2, filter)
+	e = unwrapRawPinHttpFilterInstance(instance).envoy
+	eventHttpFilterInstanceRequestBody(instance, 10, false)
+	eventHttpFilterInstanceDestroy(instance)
+	e.pauseExpired(pauseRequest)
+	noIncident(t, incidents, 50*time.Millisecond)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+)
+
+// jsonAccessLogger implements envoy.AccessLogger.
+//
+// This is to demonstrate how to use access logger APIs. This writes the access logs as JSON lines to the stdout
+// in batches on a background Goroutine.
+type jsonAccessLogger struct {
+	batcher *envoy.AccessLogBatcher[jsonAccessLog]
+}
+
+// jsonAccessLog is a single line of the access log. The data is copied out from envoy.AccessLogEntry.
+type jsonAccessLog struct {
+	StartTime     time.Time `json:"start_time"`
+	Method        string    `json:"method"`
+	Path          string    `json:"path"`
+	ResponseCode  int       `json:"response_code"`
+	ResponseFlags string    `json:"response_flags"`
+	BytesReceived uint64    `json:"bytes_received"`
+	BytesSent     uint64    `json:"bytes_sent"`
+	DurationMs    int64     `json:"duration_ms"`
+}
+
+func newJsonAccessLogger(string) envoy.AccessLogger {
+	w := bufio.NewWriter(os.Stdout)
+	enc := json.NewEncoder(w)
+	batcher := envoy.NewAccessLogBatcher(envoy.AccessLogBatcherConfig{MaxBatchSize: 64, FlushInterval: time.Second},
+		func(batch []jsonAccessLog) {
+			for i := range batch {
+				_ = enc.Encode(&batch[i])
+			}
+			_ = w.Flush()
+		})
+	return &jsonAccessLogger{batcher: batcher}
+}
+
+// Log implements envoy.AccessLogger.
+func (l *jsonAccessLogger) Log(entry envoy.AccessLogEntry) {
+	var log jsonAccessLog
+	log.StartTime = entry.StartTime()
+	if v, ok := entry.RequestHeaders().Get(":method"); ok {
+		log.Method = v.String()
+	}
+	if v, ok := entry.RequestHeaders().Get(":path"); ok {
+		log.Path = v.String()
+	}
+	log.ResponseCode, _ = entry.ResponseCode()
+	log.ResponseFlags = entry.ResponseFlags().String()
+	log.BytesReceived = entry.BytesReceived()
+	log.BytesSent = entry.BytesSent()
+	if d, ok := entry.Timing(envoy.AccessLogTimingRequestComplete); ok {
+		log.DurationMs = d.Milliseconds()
+	}
+	l.batcher.Add(log)
+}
+
+// Destroy implements envoy.AccessLogger.
+func (l *jsonAccessLogger) Destroy() { l.batcher.Close() }
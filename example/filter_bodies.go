@@ -27,12 +27,12 @@ type bodiesHttpFilterInstance struct {
 	envoyFilter envoy.EnvoyFilterInstance
 }
 
-// RequestHeaders implements envoy.HttpFilterInstance.
+// RequestHeaders implements envoy.RequestHeadersHandler.
 func (h *bodiesHttpFilterInstance) RequestHeaders(envoy.RequestHeaders, bool) envoy.RequestHeadersStatus {
 	return envoy.HeadersStatusContinue
 }
 
-// RequestBody implements envoy.HttpFilterInstance.
+// RequestBody implements envoy.RequestBodyHandler.
 func (h *bodiesHttpFilterInstance) RequestBody(body envoy.RequestBodyBuffer, endOfStream bool) envoy.RequestBodyStatus {
 	fmt.Printf("new request body frame: %s\n", string(body.Copy()))
 	if !endOfStream {
@@ -65,12 +65,12 @@ func (h *bodiesHttpFilterInstance) RequestBody(body envoy.RequestBodyBuffer, end
 	return envoy.RequestBodyStatusContinue
 }
 
-// ResponseHeaders implements envoy.HttpFilterInstance.
+// ResponseHeaders implements envoy.ResponseHeadersHandler.
 func (h *bodiesHttpFilterInstance) ResponseHeaders(envoy.ResponseHeaders, bool) envoy.ResponseHeadersStatus {
 	return envoy.ResponseHeadersStatusContinue
 }
 
-// ResponseBody implements envoy.HttpFilterInstance.
+// ResponseBody implements envoy.ResponseBodyHandler.
 func (h *bodiesHttpFilterInstance) ResponseBody(body envoy.ResponseBodyBuffer, endOfStream bool) envoy.ResponseBodyStatus {
 	fmt.Printf("new request body frame: %s\n", string(body.Copy()))
 	if !endOfStream {
@@ -28,7 +28,7 @@ type bodiesReplaceHttpFilterInstance struct {
 	responseAppend, responsePrepend, responseReplace string
 }
 
-// RequestHeaders implements envoy.HttpFilterInstance.
+// RequestHeaders implements envoy.RequestHeadersHandler.
 func (h *bodiesReplaceHttpFilterInstance) RequestHeaders(headers envoy.RequestHeaders, _ bool) envoy.RequestHeadersStatus {
 	append, ok := headers.Get("append")
 	if ok {
@@ -46,7 +46,7 @@ func (h *bodiesReplaceHttpFilterInstance) RequestHeaders(headers envoy.RequestHe
 	return envoy.HeadersStatusContinue
 }
 
-// RequestBody implements envoy.HttpFilterInstance.
+// RequestBody implements envoy.RequestBodyHandler.
 func (h *bodiesReplaceHttpFilterInstance) RequestBody(body envoy.RequestBodyBuffer, endOfStream bool) envoy.RequestBodyStatus {
 	if !endOfStream {
 		// Wait for the end of the stream to see the full body.
@@ -66,7 +66,7 @@ func (h *bodiesReplaceHttpFilterInstance) RequestBody(body envoy.RequestBodyBuff
 	return envoy.RequestBodyStatusContinue
 }
 
-// ResponseHeaders implements envoy.HttpFilterInstance.
+// ResponseHeaders implements envoy.ResponseHeadersHandler.
 func (h *bodiesReplaceHttpFilterInstance) ResponseHeaders(headers envoy.ResponseHeaders, _ bool) envoy.ResponseHeadersStatus {
 	append, ok := headers.Get("append")
 	if ok {
@@ -84,7 +84,7 @@ func (h *bodiesReplaceHttpFilterInstance) ResponseHeaders(headers envoy.Response
 	return envoy.ResponseHeadersStatusContinue
 }
 
-// ResponseBody implements envoy.HttpFilterInstance.
+// ResponseBody implements envoy.ResponseBodyHandler.
 func (h *bodiesReplaceHttpFilterInstance) ResponseBody(body envoy.ResponseBodyBuffer, endOfStream bool) envoy.ResponseBodyStatus {
 	fmt.Printf("new request body frame: %s\n", string(body.Copy()))
 	if !endOfStream {
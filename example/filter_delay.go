@@ -10,7 +10,8 @@ import (
 
 // delayHttpFilter implements envoy.HttpFilter.
 //
-// This is to demonstrate how to delay the request and response by using Gorooutines.
+// This is to demonstrate how to delay the request and response by using Goroutines of the executor of the filter,
+// which bounds the number of the Goroutines regardless of the load.
 type delayHttpFilter struct{ requestCounts atomic.Int32 }
 
 func newDelayHttpFilter(string) envoy.HttpFilter { return &delayHttpFilter{} }
@@ -23,6 +24,24 @@ func (m *delayHttpFilter) NewInstance(e envoy.EnvoyFilterInstance) envoy.HttpFil
 	return &delayHttpFilterInstance{id: id, envoyFilter: e}
 }
 
+// Executor implements envoy.ExecutorProvider.
+func (m *delayHttpFilter) Executor() envoy.ExecutorConfig {
+	return envoy.ExecutorConfig{
+		MaxConcurrency: 16,
+		MaxQueue:       128,
+		OverloadPolicy: envoy.OverloadReject,
+		StatsName:      "delay",
+	}
+}
+
+// Watchdog implements envoy.WatchdogProvider.
+//
+// This sends 504 to the client if the stream is not resumed in 5 seconds after it is paused, e.g. the executor is
+// so busy that the tasks wait for long in the queue.
+func (m *delayHttpFilter) Watchdog() envoy.Watchdog {
+	return envoy.Watchdog{Deadline: 5 * time.Second, Action: envoy.WatchdogLocalReply}
+}
+
 // Destroy implements envoy.HttpFilter.
 func (m *delayHttpFilter) Destroy() {
 	fmt.Println("Destroy called")
@@ -34,17 +53,21 @@ type delayHttpFilterInstance struct {
 	envoyFilter envoy.EnvoyFilterInstance
 }
 
-// RequestHeaders implements envoy.HttpFilterInstance.
+// RequestHeaders implements envoy.RequestHeadersHandler.
 func (h *delayHttpFilterInstance) RequestHeaders(_ envoy.RequestHeaders, _ bool) envoy.RequestHeadersStatus {
 	if h.id == 1 {
-		go func() {
+		if !h.envoyFilter.Submit(func() {
 			fmt.Println("blocking for 1 second at RequestHeaders with id", h.id)
 			time.Sleep(1 * time.Second)
 			fmt.Println("calling ContinueRequest with id", h.id)
-			if h.envoyFilter != nil { // By calling ContinueResponse, the filter might have been destroyed.
-				h.envoyFilter.ContinueRequest()
+			// The stream might have been destroyed while sleeping, e.g. the client has gone away.
+			h.envoyFilter.ContinueRequest()
+			if err := h.envoyFilter.Err(); err != nil {
+				fmt.Println("ContinueRequest was no-op with id", h.id, ":", err)
 			}
-		}()
+		}) {
+			return envoy.HeadersStatusContinue
+		}
 		fmt.Println("RequestHeaders returning StopAllIterationAndBuffer with id", h.id)
 		return envoy.RequestHeadersStatusStopAllIterationAndBuffer
 	}
@@ -52,17 +75,21 @@ func (h *delayHttpFilterInstance) RequestHeaders(_ envoy.RequestHeaders, _ bool)
 	return envoy.HeadersStatusContinue
 }
 
-// RequestBody implements envoy.HttpFilterInstance.
+// RequestBody implements envoy.RequestBodyHandler.
 func (h *delayHttpFilterInstance) RequestBody(_ envoy.RequestBodyBuffer, _ bool) envoy.RequestBodyStatus {
 	if h.id == 2 {
-		go func() {
+		if !h.envoyFilter.Submit(func() {
 			fmt.Println("blocking for 1 second at RequestBody with id", h.id)
 			time.Sleep(1 * time.Second)
 			fmt.Println("calling ContinueRequest with id", h.id)
-			if h.envoyFilter != nil { // By calling ContinueResponse, the filter might have been destroyed.
-				h.envoyFilter.ContinueRequest()
+			// The stream might have been destroyed while sleeping, e.g. the client has gone away.
+			h.envoyFilter.ContinueRequest()
+			if err := h.envoyFilter.Err(); err != nil {
+				fmt.Println("ContinueRequest was no-op with id", h.id, ":", err)
 			}
-		}()
+		}) {
+			return envoy.RequestBodyStatusContinue
+		}
 		fmt.Println("RequestBody returning StopIterationAndBuffer with id", h.id)
 		return envoy.RequestBodyStatusStopIterationAndBuffer
 	}
@@ -70,17 +97,21 @@ func (h *delayHttpFilterInstance) RequestBody(_ envoy.RequestBodyBuffer, _ bool)
 	return envoy.RequestBodyStatusContinue
 }
 
-// ResponseHeaders implements envoy.HttpFilterInstance.
+// ResponseHeaders implements envoy.ResponseHeadersHandler.
 func (h *delayHttpFilterInstance) ResponseHeaders(_ envoy.ResponseHeaders, _ bool) envoy.ResponseHeadersStatus {
 	if h.id == 3 {
-		go func() {
+		if !h.envoyFilter.Submit(func() {
 			fmt.Println("blocking for 1 second at ResponseHeaders with id", h.id)
 			time.Sleep(1 * time.Second)
 			fmt.Println("calling ContinueResponse with id", h.id)
-			if h.envoyFilter != nil { // By calling ContinueResponse, the filter might have been destroyed.
-				h.envoyFilter.ContinueResponse()
+			// The stream might have been destroyed while sleeping, e.g. the client has gone away.
+			h.envoyFilter.ContinueResponse()
+			if err := h.envoyFilter.Err(); err != nil {
+				fmt.Println("ContinueResponse was no-op with id", h.id, ":", err)
 			}
-		}()
+		}) {
+			return envoy.ResponseHeadersStatusContinue
+		}
 		fmt.Println("ResponseHeaders returning StopAllIterationAndBuffer with id", h.id)
 		return envoy.ResponseHeadersStatusStopAllIterationAndBuffer
 	}
@@ -88,17 +119,21 @@ func (h *delayHttpFilterInstance) ResponseHeaders(_ envoy.ResponseHeaders, _ boo
 	return envoy.ResponseHeadersStatusContinue
 }
 
-// ResponseBody implements envoy.HttpFilterInstance.
+// ResponseBody implements envoy.ResponseBodyHandler.
 func (h *delayHttpFilterInstance) ResponseBody(_ envoy.ResponseBodyBuffer, _ bool) envoy.ResponseBodyStatus {
 	if h.id == 4 {
-		go func() {
+		if !h.envoyFilter.Submit(func() {
 			fmt.Println("blocking for 1 second at ResponseBody with id", h.id)
 			time.Sleep(1 * time.Second)
 			fmt.Println("calling ContinueResponse with id", h.id)
-			if h.envoyFilter != nil { // By calling ContinueResponse, the filter might have been destroyed.
-				h.envoyFilter.ContinueResponse()
+			// The stream might have been destroyed while sleeping, e.g. the client has gone away.
+			h.envoyFilter.ContinueResponse()
+			if err := h.envoyFilter.Err(); err != nil {
+				fmt.Println("ContinueResponse was no-op with id", h.id, ":", err)
 			}
-		}()
+		}) {
+			return envoy.ResponseBodyStatusContinue
+		}
 		fmt.Println("ResponseBody returning StopIterationAndBuffer with id", h.id)
 		return envoy.ResponseBodyStatusStopIterationAndBuffer
 	}
@@ -108,6 +143,5 @@ func (h *delayHttpFilterInstance) ResponseBody(_ envoy.ResponseBodyBuffer, _ boo
 
 // Destroy implements envoy.HttpFilterInstance.
 func (h *delayHttpFilterInstance) Destroy() {
-	// After the request is done, we can clean up the filter instance.
-	h.envoyFilter = nil
+	// Nothing to clean up. The calls to envoyFilter by the Goroutines outliving the stream are no-op.
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+)
+
+// encodedRewriteHttpFilter implements envoy.HttpFilter.
+//
+// This is to demonstrate how to use envoy.EncodedBody to modify the compressed response body. The entire body is
+// decoded according to the content-encoding header, rewritten, and encoded again with the same content-coding.
+type encodedRewriteHttpFilter struct {
+	rewriter *envoy.Rewriter
+}
+
+func newEncodedRewriteHttpFilter(string) envoy.HttpFilter {
+	rewriter, err := envoy.NewRewriter(envoy.RewriterConfig{
+		Replacements: []envoy.Replacement{{Old: "hello", New: "bonjour"}},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &encodedRewriteHttpFilter{rewriter: rewriter}
+}
+
+// NewInstance implements envoy.HttpFilter.
+func (f *encodedRewriteHttpFilter) NewInstance(e envoy.EnvoyFilterInstance) envoy.HttpFilterInstance {
+	return &encodedRewriteHttpFilterInstance{
+		rewriter:    f.rewriter,
+		envoyFilter: e,
+		body:        envoy.NewEncodedBody(e, envoy.EncodedBodyConfig{Mode: envoy.EncodedBodyModeReencode}),
+	}
+}
+
+// Destroy implements envoy.HttpFilter.
+func (f *encodedRewriteHttpFilter) Destroy() {}
+
+// encodedRewriteHttpFilterInstance implements envoy.HttpFilterInstance.
+type encodedRewriteHttpFilterInstance struct {
+	rewriter    *envoy.Rewriter
+	envoyFilter envoy.EnvoyFilterInstance
+	body        *envoy.EncodedBody
+}
+
+// ResponseHeaders implements envoy.ResponseHeadersHandler.
+func (h *encodedRewriteHttpFilterInstance) ResponseHeaders(headers envoy.ResponseHeaders, _ bool) envoy.ResponseHeadersStatus {
+	// This removes the content-length header, and keeps the content-encoding header as the body is encoded again.
+	// The body passes through if the content-coding is not supported.
+	h.body.PrepareResponseHeaders(headers)
+	return envoy.ResponseHeadersStatusContinue
+}
+
+// ResponseBody implements envoy.ResponseBodyHandler.
+func (h *encodedRewriteHttpFilterInstance) ResponseBody(body envoy.ResponseBodyBuffer, endOfStream bool) envoy.ResponseBodyStatus {
+	status, err := h.body.TransformResponseBody(body, endOfStream, func(decoded []byte, _ bool) []byte {
+		return h.rewriter.Rewrite(decoded)
+	})
+	if err != nil {
+		h.envoyFilter.SendResponse(502, nil, []byte(err.Error()))
+	}
+	return status
+}
+
+// Destroy implements envoy.HttpFilterInstance.
+func (h *encodedRewriteHttpFilterInstance) Destroy() {
+	h.body.Close()
+}
@@ -22,10 +22,12 @@ func (f *headersHttpFilter) NewInstance(envoy.EnvoyFilterInstance) envoy.HttpFil
 // Destroy implements envoy.HttpFilter.
 func (f *headersHttpFilter) Destroy() {}
 
-// headersHttpFilterInstance implements envoy.HttpFilterInstance.
+// headersHttpFilterInstance implements envoy.HttpFilterInstance, envoy.RequestHeadersHandler and envoy.ResponseHeadersHandler.
+//
+// Since this doesn't implement envoy.RequestBodyHandler and envoy.ResponseBodyHandler, the body events are bypassed.
 type headersHttpFilterInstance struct{}
 
-// RequestHeaders implements envoy.HttpFilterInstance.
+// RequestHeaders implements envoy.RequestHeadersHandler.
 func (h *headersHttpFilterInstance) RequestHeaders(headers envoy.RequestHeaders, _ bool) envoy.RequestHeadersStatus {
 	fooValue, _ := headers.Get("foo")
 	if !fooValue.Equal("value") {
@@ -39,12 +41,7 @@ func (h *headersHttpFilterInstance) RequestHeaders(headers envoy.RequestHeaders,
 	return envoy.HeadersStatusContinue
 }
 
-// RequestBody implements envoy.HttpFilterInstance.
-func (h *headersHttpFilterInstance) RequestBody(envoy.RequestBodyBuffer, bool) envoy.RequestBodyStatus {
-	return envoy.RequestBodyStatusContinue
-}
-
-// ResponseHeaders implements envoy.HttpFilterInstance.
+// ResponseHeaders implements envoy.ResponseHeadersHandler.
 func (h *headersHttpFilterInstance) ResponseHeaders(headers envoy.ResponseHeaders, _ bool) envoy.ResponseHeadersStatus {
 	headers.Values("this-is", func(value envoy.HeaderValue) {
 		if !value.Equal("response-header") {
@@ -60,10 +57,5 @@ func (h *headersHttpFilterInstance) ResponseHeaders(headers envoy.ResponseHeader
 	return envoy.ResponseHeadersStatusContinue
 }
 
-// ResponseBody implements envoy.HttpFilterInstance.
-func (h *headersHttpFilterInstance) ResponseBody(envoy.ResponseBodyBuffer, bool) envoy.ResponseBodyStatus {
-	return envoy.ResponseBodyStatusContinue
-}
-
 // Destroy implements envoy.HttpFilterInstance.
 func (h *headersHttpFilterInstance) Destroy() {}
@@ -25,25 +25,25 @@ func (f *helloWorldFilter) Destroy() { fmt.Println("helloWorldFilter.Destroy cal
 // helloWorldHttpFilterInstance implements envoy.HttpFilterInstance.
 type helloWorldHttpFilterInstance struct{}
 
-// RequestHeaders implements envoy.HttpFilterInstance.
+// RequestHeaders implements envoy.RequestHeadersHandler.
 func (h *helloWorldHttpFilterInstance) RequestHeaders(envoy.RequestHeaders, bool) envoy.RequestHeadersStatus {
 	fmt.Println("helloWorldHttpFilterInstance.EventHttpRequestHeaders called")
 	return envoy.HeadersStatusContinue
 }
 
-// RequestBody implements envoy.HttpFilterInstance.
+// RequestBody implements envoy.RequestBodyHandler.
 func (h *helloWorldHttpFilterInstance) RequestBody(envoy.RequestBodyBuffer, bool) envoy.RequestBodyStatus {
 	fmt.Println("helloWorldHttpFilterInstance.EventHttpRequestBody called")
 	return envoy.RequestBodyStatusContinue
 }
 
-// ResponseHeaders implements envoy.HttpFilterInstance.
+// ResponseHeaders implements envoy.ResponseHeadersHandler.
 func (h *helloWorldHttpFilterInstance) ResponseHeaders(envoy.ResponseHeaders, bool) envoy.ResponseHeadersStatus {
 	fmt.Println("helloWorldHttpFilterInstance.EventHttpResponseHeaders called")
 	return envoy.ResponseHeadersStatusContinue
 }
 
-// ResponseBody implements envoy.HttpFilterInstance.
+// ResponseBody implements envoy.ResponseBodyHandler.
 func (h *helloWorldHttpFilterInstance) ResponseBody(envoy.ResponseBodyBuffer, bool) envoy.ResponseBodyStatus {
 	fmt.Println("helloWorldHttpFilterInstance.EventHttpResponseBody called")
 	return envoy.ResponseBodyStatusContinue
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"regexp"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+)
+
+// jsonEditHttpFilter implements envoy.HttpFilter.
+//
+// This is to demonstrate how to use envoy.JSONEditor to edit the JSON request body. The passwords at any depth are
+// deleted, the digits of the SSN are redacted, and the tenant ID is injected into the body buffered until the end.
+type jsonEditHttpFilter struct {
+	editor *envoy.JSONEditor
+}
+
+func newJSONEditHttpFilter(string) envoy.HttpFilter {
+	editor, err := envoy.NewJSONEditor(
+		envoy.JSONEdit{Op: envoy.JSONEditDelete, Path: "**.password"},
+		envoy.JSONEdit{Op: envoy.JSONEditRedact, Path: "user.ssn", Pattern: regexp.MustCompile(`\d`), Replacement: "*"},
+		envoy.JSONEdit{Op: envoy.JSONEditSet, Path: "tenant.id", Value: "example"},
+	)
+	if err != nil {
+		panic(err)
+	}
+	return &jsonEditHttpFilter{editor: editor}
+}
+
+// BodyLimit implements envoy.BodyLimitProvider.
+//
+// The request body larger than 1MiB is rejected with 413 instead of being buffered until the buffer limit of Envoy,
+// and the request is rejected before the body arrives if the content-length header exceeds the limit.
+func (f *jsonEditHttpFilter) BodyLimit() envoy.BodyLimit {
+	return envoy.BodyLimit{
+		MaxRequestBodySize: 1 << 20,
+		Action:             envoy.BodyLimitLocalReply,
+		CheckContentLength: true,
+		LocalReplyHeaders:  [][2]string{{"content-type", "text/plain"}},
+	}
+}
+
+// NewInstance implements envoy.HttpFilter.
+func (f *jsonEditHttpFilter) NewInstance(e envoy.EnvoyFilterInstance) envoy.HttpFilterInstance {
+	return &jsonEditHttpFilterInstance{envoyFilter: e, body: f.editor.NewBodyEditor(e, false)}
+}
+
+// Destroy implements envoy.HttpFilter.
+func (f *jsonEditHttpFilter) Destroy() {}
+
+// jsonEditHttpFilterInstance implements envoy.HttpFilterInstance.
+type jsonEditHttpFilterInstance struct {
+	envoyFilter envoy.EnvoyFilterInstance
+	body        *envoy.JSONBodyEditor
+}
+
+// RequestHeaders implements envoy.RequestHeadersHandler.
+func (h *jsonEditHttpFilterInstance) RequestHeaders(headers envoy.RequestHeaders, _ bool) envoy.RequestHeadersStatus {
+	// This removes the content-length header as the length changes. The body passes through if it's not JSON.
+	h.body.PrepareRequestHeaders(headers)
+	return envoy.HeadersStatusContinue
+}
+
+// RequestBody implements envoy.RequestBodyHandler.
+func (h *jsonEditHttpFilterInstance) RequestBody(body envoy.RequestBodyBuffer, endOfStream bool) envoy.RequestBodyStatus {
+	status, err := h.body.EditRequestBody(body, endOfStream)
+	if err != nil {
+		h.envoyFilter.SendResponse(400, nil, []byte(err.Error()))
+	}
+	return status
+}
+
+// Destroy implements envoy.HttpFilterInstance.
+func (h *jsonEditHttpFilterInstance) Destroy() {}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+)
+
+// jsonStreamHttpFilter implements envoy.HttpFilter.
+//
+// This is to demonstrate how to use envoy.BodyReader and envoy.BodyWriter to transform the streamed request body
+// with the standard decoders. The request body is a stream of JSON objects, e.g. newline-delimited JSON, and
+// each object is forwarded to the upstream with the "seen_by" field added without buffering the entire body.
+type jsonStreamHttpFilter struct{}
+
+func newJSONStreamHttpFilter(string) envoy.HttpFilter { return &jsonStreamHttpFilter{} }
+
+// NewInstance implements envoy.HttpFilter.
+func (f *jsonStreamHttpFilter) NewInstance(envoyFilter envoy.EnvoyFilterInstance) envoy.HttpFilterInstance {
+	return &jsonStreamHttpFilterInstance{envoyFilter: envoyFilter}
+}
+
+// Destroy implements envoy.HttpFilter.
+func (f *jsonStreamHttpFilter) Destroy() {}
+
+// jsonStreamHttpFilterInstance implements envoy.HttpFilterInstance.
+type jsonStreamHttpFilterInstance struct {
+	envoyFilter envoy.EnvoyFilterInstance
+	body        *envoy.BodyReader
+}
+
+// RequestHeaders implements envoy.RequestHeadersHandler.
+func (h *jsonStreamHttpFilterInstance) RequestHeaders(headers envoy.RequestHeaders, endOfStream bool) envoy.RequestHeadersStatus {
+	if endOfStream || !envoy.HostSupports(envoy.FeatureDataInjection) {
+		return envoy.HeadersStatusContinue
+	}
+	// The length of the transformed body is unknown until the end.
+	headers.Remove("content-length")
+	h.body = envoy.NewRequestBodyReader(h.envoyFilter)
+	go h.transform(h.body, envoy.NewRequestBodyWriter(h.envoyFilter))
+	return envoy.HeadersStatusContinue
+}
+
+// RequestBody implements envoy.RequestBodyHandler.
+func (h *jsonStreamHttpFilterInstance) RequestBody(body envoy.RequestBodyBuffer, endOfStream bool) envoy.RequestBodyStatus {
+	if h.body == nil {
+		return envoy.RequestBodyStatusContinue
+	}
+	return h.body.FeedRequestBody(body, endOfStream)
+}
+
+// transform runs on its own Goroutine, and copies the JSON objects from r to w adding the "seen_by" field.
+func (h *jsonStreamHttpFilterInstance) transform(r io.ReadCloser, w io.WriteCloser) {
+	// Closing the reader discards the rest of the body if this returns early, e.g. on an invalid JSON.
+	defer r.Close()
+	defer w.Close()
+	buffered := bufio.NewWriter(w)
+	defer buffered.Flush()
+	decoder, encoder := json.NewDecoder(r), json.NewEncoder(buffered)
+	for {
+		var object map[string]any
+		if err := decoder.Decode(&object); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, envoy.ErrStreamDestroyed) {
+				fmt.Println("json_stream: invalid request body:", err)
+			}
+			return
+		}
+		object["seen_by"] = "envoy-dynamic-modules-go-sdk"
+		if err := encoder.Encode(object); err != nil {
+			return
+		}
+	}
+}
+
+// Destroy implements envoy.HttpFilterInstance.
+func (h *jsonStreamHttpFilterInstance) Destroy() {
+	if h.body != nil {
+		// Unblocks the Goroutine if the stream is destroyed before the end of the body, e.g. the client has gone away.
+		h.body.CloseWithError(envoy.ErrStreamDestroyed)
+	}
+}
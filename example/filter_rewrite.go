@@ -0,0 +1,58 @@
+package main
+
+import (
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+)
+
+// rewriteHttpFilter implements envoy.HttpFilter.
+//
+// This is to demonstrate how to use envoy.Rewriter to replace the strings in the streamed response body without
+// buffering the entire body. The replacements are matched regardless of the case, and the matches straddling
+// the chunks of the body are also replaced.
+type rewriteHttpFilter struct {
+	rewriter *envoy.Rewriter
+}
+
+func newRewriteHttpFilter(string) envoy.HttpFilter {
+	// The Rewriter is compiled once per filter, and shared between the instances.
+	rewriter, err := envoy.NewRewriter(envoy.RewriterConfig{
+		Replacements: []envoy.Replacement{
+			{Old: "hello", New: "bonjour"},
+			{Old: "world", New: "le monde"},
+		},
+		CaseInsensitive: true,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &rewriteHttpFilter{rewriter: rewriter}
+}
+
+// NewInstance implements envoy.HttpFilter.
+func (f *rewriteHttpFilter) NewInstance(e envoy.EnvoyFilterInstance) envoy.HttpFilterInstance {
+	return &rewriteHttpFilterInstance{rewriter: f.rewriter.NewBodyRewriter(e)}
+}
+
+// Destroy implements envoy.HttpFilter.
+func (f *rewriteHttpFilter) Destroy() {}
+
+// rewriteHttpFilterInstance implements envoy.HttpFilterInstance.
+type rewriteHttpFilterInstance struct {
+	rewriter *envoy.BodyRewriter
+}
+
+// ResponseHeaders implements envoy.ResponseHeadersHandler.
+func (h *rewriteHttpFilterInstance) ResponseHeaders(headers envoy.ResponseHeaders, _ bool) envoy.ResponseHeadersStatus {
+	// This removes the content-length header as the length of the body changes, or disables the rewriting
+	// if the body is compressed.
+	h.rewriter.PrepareResponseHeaders(headers)
+	return envoy.ResponseHeadersStatusContinue
+}
+
+// ResponseBody implements envoy.ResponseBodyHandler.
+func (h *rewriteHttpFilterInstance) ResponseBody(body envoy.ResponseBodyBuffer, endOfStream bool) envoy.ResponseBodyStatus {
+	return h.rewriter.RewriteResponseBody(body, endOfStream)
+}
+
+// Destroy implements envoy.HttpFilterInstance.
+func (h *rewriteHttpFilterInstance) Destroy() {}
@@ -27,7 +27,7 @@ type sendResponseFilterInstance struct {
 	onResponse  bool
 }
 
-// RequestHeaders implements envoy.HttpFilterInstance.
+// RequestHeaders implements envoy.RequestHeadersHandler.
 func (h *sendResponseFilterInstance) RequestHeaders(headers envoy.RequestHeaders, _ bool) envoy.RequestHeadersStatus {
 	if v, _ := headers.Get(":path"); v.Equal("/on_request") {
 		h.envoyFilter.SendResponse(http.StatusUnauthorized, [][2]string{{"foo", "bar"}, {"bar", "baz"}}, []byte("local response at request headers"))
@@ -38,12 +38,12 @@ func (h *sendResponseFilterInstance) RequestHeaders(headers envoy.RequestHeaders
 	return envoy.HeadersStatusContinue
 }
 
-// RequestBody implements envoy.HttpFilterInstance.
+// RequestBody implements envoy.RequestBodyHandler.
 func (h *sendResponseFilterInstance) RequestBody(body envoy.RequestBodyBuffer, endOfStream bool) envoy.RequestBodyStatus {
 	return envoy.RequestBodyStatusContinue
 }
 
-// ResponseHeaders implements envoy.HttpFilterInstance.
+// ResponseHeaders implements envoy.ResponseHeadersHandler.
 func (h *sendResponseFilterInstance) ResponseHeaders(headers envoy.ResponseHeaders, _ bool) envoy.ResponseHeadersStatus {
 	if h.onResponse {
 		h.envoyFilter.SendResponse(http.StatusInternalServerError, [][2]string{{"dog", "cat"}}, []byte("local response at response headers"))
@@ -51,7 +51,7 @@ func (h *sendResponseFilterInstance) ResponseHeaders(headers envoy.ResponseHeade
 	return envoy.ResponseHeadersStatusContinue
 }
 
-// ResponseBody implements envoy.HttpFilterInstance.
+// ResponseBody implements envoy.ResponseBodyHandler.
 func (h *sendResponseFilterInstance) ResponseBody(body envoy.ResponseBodyBuffer, endOfStream bool) envoy.ResponseBodyStatus {
 	return envoy.ResponseBodyStatusContinue
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+)
+
+// upstreamAttemptHttpFilter implements envoy.HttpFilter.
+//
+// This is to demonstrate how to use upstream http filter APIs. This adds the attempt number and the cluster name
+// to the request headers for each upstream request attempt, so retried requests can be distinguished by the upstream.
+type upstreamAttemptHttpFilter struct{}
+
+func newUpstreamAttemptHttpFilter(string) envoy.HttpFilter { return &upstreamAttemptHttpFilter{} }
+
+// NewInstance implements envoy.HttpFilter.
+func (f *upstreamAttemptHttpFilter) NewInstance(envoyFilter envoy.EnvoyFilterInstance) envoy.HttpFilterInstance {
+	return &upstreamAttemptHttpFilterInstance{envoyFilter: envoyFilter}
+}
+
+// Destroy implements envoy.HttpFilter.
+func (f *upstreamAttemptHttpFilter) Destroy() {}
+
+// upstreamAttemptHttpFilterInstance implements envoy.HttpFilterInstance and envoy.RequestHeadersHandler.
+type upstreamAttemptHttpFilterInstance struct {
+	envoyFilter envoy.EnvoyFilterInstance
+}
+
+// RequestHeaders implements envoy.RequestHeadersHandler.
+func (h *upstreamAttemptHttpFilterInstance) RequestHeaders(headers envoy.RequestHeaders, _ bool) envoy.RequestHeadersStatus {
+	info, ok := h.envoyFilter.UpstreamInfo()
+	if !ok {
+		// Not running as an upstream filter, so there's nothing to do.
+		return envoy.HeadersStatusContinue
+	}
+	headers.Set("x-upstream-attempt", strconv.Itoa(info.Attempt))
+	headers.Set("x-upstream-cluster", info.Cluster)
+	return envoy.HeadersStatusContinue
+}
+
+// Destroy implements envoy.HttpFilterInstance.
+func (h *upstreamAttemptHttpFilterInstance) Destroy() {}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+)
+
+// tlsSniffListenerFilter implements envoy.ListenerFilter.
+//
+// This is to demonstrate how to use listener filter APIs. This peeks the first byte of the connection
+// and sets the detected transport protocol to "tls" if it looks like a TLS handshake record, otherwise "raw_buffer".
+type tlsSniffListenerFilter struct{}
+
+func newTlsSniffListenerFilter(string) envoy.ListenerFilter { return &tlsSniffListenerFilter{} }
+
+// NewInstance implements envoy.ListenerFilter.
+func (f *tlsSniffListenerFilter) NewInstance(envoyFilter envoy.EnvoyListenerFilterInstance) envoy.ListenerFilterInstance {
+	return &tlsSniffListenerFilterInstance{envoyFilter: envoyFilter}
+}
+
+// Destroy implements envoy.ListenerFilter.
+func (f *tlsSniffListenerFilter) Destroy() {}
+
+// tlsSniffListenerFilterInstance implements envoy.ListenerFilterInstance.
+type tlsSniffListenerFilterInstance struct {
+	envoyFilter envoy.EnvoyListenerFilterInstance
+}
+
+// tlsHandshakeRecordType is the content type of the TLS record that carries the ClientHello.
+const tlsHandshakeRecordType = 0x16
+
+// MaxReadBytes implements envoy.ListenerFilterInstance.
+func (h *tlsSniffListenerFilterInstance) MaxReadBytes() int { return 1 }
+
+// OnAccept implements envoy.ListenerFilterInstance.
+func (h *tlsSniffListenerFilterInstance) OnAccept() envoy.ListenerFilterStatus {
+	// Wait for the first byte to arrive.
+	return envoy.ListenerFilterStatusStopIteration
+}
+
+// OnData implements envoy.ListenerFilterInstance.
+func (h *tlsSniffListenerFilterInstance) OnData(buffer envoy.ListenerPeekBuffer) envoy.ListenerFilterStatus {
+	var first [1]byte
+	if n, _ := buffer.ReadAt(first[:], 0); n == 0 {
+		return envoy.ListenerFilterStatusStopIteration
+	}
+	if first[0] == tlsHandshakeRecordType {
+		h.envoyFilter.SetDetectedTransportProtocol("tls")
+	} else {
+		h.envoyFilter.SetDetectedTransportProtocol("raw_buffer")
+	}
+	return envoy.ListenerFilterStatusContinue
+}
+
+// Destroy implements envoy.ListenerFilterInstance.
+func (h *tlsSniffListenerFilterInstance) Destroy() {}
@@ -1,13 +1,35 @@
 package main
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
 )
 
 func main() {} // main function must be present but empty.
 
-// Set the envoy.NewHttpFilter function to create a new http filter.
-func init() { envoy.NewHttpFilter = newHttpFilter }
+// Set the envoy.NewHttpFilter, envoy.NewUpstreamHttpFilter, envoy.NewNetworkFilter and envoy.NewListenerFilter
+// functions to create new filters, and envoy.NewAccessLogger to create new access loggers.
+func init() {
+	envoy.NewHttpFilter = newHttpFilter
+	envoy.NewUpstreamHttpFilter = newUpstreamHttpFilter
+	envoy.NewNetworkFilter = newNetworkFilter
+	envoy.NewListenerFilter = newListenerFilter
+	envoy.NewAccessLogger = newAccessLogger
+	envoy.OnProgramInit(programInit)
+}
+
+// programInit is called once when the module is loaded by Envoy. Returning an error makes Envoy refuse to load the module.
+func programInit() error {
+	fmt.Printf("loaded by Envoy with ABI version %d (module ABI version %d)\n", envoy.HostABIVersion(), envoy.ABIVersion)
+	if !envoy.HostSupports(envoy.FeatureBypassEvents) {
+		fmt.Println("the host doesn't support bypassing events, so all the http filter events are called")
+	}
+	// Reports the number of the live filters and instances as the Envoy gauges to detect leaks.
+	envoy.ReportPinnedObjects(10 * time.Second)
+	return nil
+}
 
 // newHttpFilter creates a new http filter based on the config.
 //
@@ -26,7 +48,64 @@ func newHttpFilter(config string) envoy.HttpFilter {
 		return newbodiesReplaceHttpFilter(config)
 	case "send_response":
 		return newSendResponseFilter(config)
+	case "json_stream":
+		return newJSONStreamHttpFilter(config)
+	case "rewrite":
+		return newRewriteHttpFilter(config)
+	case "encoded_rewrite":
+		return newEncodedRewriteHttpFilter(config)
+	case "json_edit":
+		return newJSONEditHttpFilter(config)
 	default:
 		panic("unknown filter: " + config)
 	}
 }
+
+// newUpstreamHttpFilter creates a new upstream http filter based on the config.
+// The downstream http filters can be used as upstream http filters as well.
+//
+// `config` is the configuration string that is specified in the Envoy configuration.
+func newUpstreamHttpFilter(config string) envoy.HttpFilter {
+	switch config {
+	case "upstream_attempt":
+		return newUpstreamAttemptHttpFilter(config)
+	default:
+		return newHttpFilter(config)
+	}
+}
+
+// newNetworkFilter creates a new network filter based on the config.
+//
+// `config` is the configuration string that is specified in the Envoy configuration.
+func newNetworkFilter(config string) envoy.NetworkFilter {
+	switch config {
+	case "echo":
+		return newEchoNetworkFilter(config)
+	default:
+		panic("unknown network filter: " + config)
+	}
+}
+
+// newListenerFilter creates a new listener filter based on the config.
+//
+// `config` is the configuration string that is specified in the Envoy configuration.
+func newListenerFilter(config string) envoy.ListenerFilter {
+	switch config {
+	case "tls_sniff":
+		return newTlsSniffListenerFilter(config)
+	default:
+		panic("unknown listener filter: " + config)
+	}
+}
+
+// newAccessLogger creates a new access logger based on the config.
+//
+// `config` is the configuration string that is specified in the Envoy configuration.
+func newAccessLogger(config string) envoy.AccessLogger {
+	switch config {
+	case "json":
+		return newJsonAccessLogger(config)
+	default:
+		panic("unknown access logger: " + config)
+	}
+}
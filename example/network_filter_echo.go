@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+)
+
+// echoNetworkFilter implements envoy.NetworkFilter.
+//
+// This is to demonstrate how to use network filter APIs. This echoes back the data read from the downstream.
+type echoNetworkFilter struct{}
+
+func newEchoNetworkFilter(string) envoy.NetworkFilter { return &echoNetworkFilter{} }
+
+// NewInstance implements envoy.NetworkFilter.
+func (f *echoNetworkFilter) NewInstance(envoyFilter envoy.EnvoyNetworkFilterInstance) envoy.NetworkFilterInstance {
+	return &echoNetworkFilterInstance{envoyFilter: envoyFilter}
+}
+
+// Destroy implements envoy.NetworkFilter.
+func (f *echoNetworkFilter) Destroy() {}
+
+// echoNetworkFilterInstance implements envoy.NetworkFilterInstance.
+type echoNetworkFilterInstance struct {
+	envoyFilter envoy.EnvoyNetworkFilterInstance
+}
+
+// OnNewConnection implements envoy.NetworkFilterInstance.
+func (h *echoNetworkFilterInstance) OnNewConnection() envoy.NetworkFilterStatus {
+	if addr, ok := h.envoyFilter.RemoteAddress(); ok {
+		fmt.Println("new connection from", addr)
+	}
+	return envoy.NetworkFilterStatusContinue
+}
+
+// OnRead implements envoy.NetworkFilterInstance.
+func (h *echoNetworkFilterInstance) OnRead(buffer envoy.NetworkReadBuffer, endOfStream bool) envoy.NetworkFilterStatus {
+	h.envoyFilter.Write(buffer.Copy(), endOfStream)
+	// Consume the data so that it won't be passed to the subsequent filters.
+	buffer.Drain(buffer.Length())
+	return envoy.NetworkFilterStatusStopIteration
+}
+
+// OnWrite implements envoy.NetworkFilterInstance.
+func (h *echoNetworkFilterInstance) OnWrite(envoy.NetworkWriteBuffer, bool) envoy.NetworkFilterStatus {
+	return envoy.NetworkFilterStatusContinue
+}
+
+// OnClose implements envoy.NetworkFilterInstance.
+func (h *echoNetworkFilterInstance) OnClose(event envoy.ConnectionEvent) {
+	fmt.Println("connection closed with event", event)
+}
+
+// Destroy implements envoy.NetworkFilterInstance.
+func (h *echoNetworkFilterInstance) Destroy() {}
@@ -0,0 +1,565 @@
+// Command abigen generates the Go bindings of the ABI defined in envoy/abi.h.
+//
+// This parses the function prototypes, typedefs and integer constants in abi.h, and emits the following files
+// into the output directory:
+//
+//   - abi_gen.go: The event hooks exported to Envoy, each of which converts the arguments to Go types and calls
+//     the hand-written event handler of the corresponding name, e.g. __envoy_dynamic_module_v1_event_http_filter_init
+//     calls eventHttpFilterInit. Also the host-call wrappers, e.g. hostHttpContinueRequest, that call the Envoy API
+//     with Go types. The results of a single value, e.g. DataSlicePtrResult, are received on the C stack by the
+//     static C shims in the preamble and stored to the Go pointers, as the goroutine stack can be moved during
+//     the cgo call. This is only included in the builds with cgo.
+//   - abi_gen_nocgo.go: The fakes of the host-call wrappers that delegate to the FakeHost functions. This is only
+//     included in the builds without cgo, so the module code can be tested without Envoy.
+//   - abi_gen_consts.go: The integer constants defined in abi.h.
+//
+// With the -stub flag, this instead emits the C source that defines all the Envoy API functions as weak no-ops
+// returning zero values. This is used by the host stub of the benchmarks where the functions of interest are
+// overridden by the strong definitions.
+//
+// The C types are mapped to Go types as follows:
+//
+//   - Pointers owned by Envoy and all pointers passed to the event hooks are uintptr.
+//   - Pointers owned by the module and the result pointers passed to the Envoy API are unsafe.Pointer.
+//   - __envoy_dynamic_module_v1_type_EndOfStream is bool.
+//   - size_t and the other typedefs of size_t are int.
+//   - Fixed-size integers are the Go integers of the same size.
+//
+// This is invoked via `go generate` in the envoy package.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	functionPrefix = "__envoy_dynamic_module_v1_"
+	eventPrefix    = functionPrefix + "event_"
+	typePrefix     = functionPrefix + "type_"
+	constPrefix    = "__ENVOY_DYNAMIC_MODULE_V1_"
+	rawPointerType = functionPrefix + "raw_pointer"
+	endOfStream    = typePrefix + "EndOfStream"
+)
+
+func main() {
+	header := flag.String("header", "abi.h", "path to abi.h")
+	out := flag.String("out", ".", "output directory of the generated files")
+	stub := flag.String("stub", "", "if set, generates only the C host stub into this path instead of the Go bindings")
+	flag.Parse()
+
+	src, err := os.ReadFile(*header)
+	if err != nil {
+		log.Fatal(err)
+	}
+	parsed, err := parse(string(src))
+	if err != nil {
+		log.Fatalf("failed to parse %s: %v", *header, err)
+	}
+
+	if *stub != "" {
+		var buf bytes.Buffer
+		genStub(&buf, parsed)
+		if err := os.WriteFile(*stub, buf.Bytes(), 0o644); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	for name, gen := range map[string]func(*bytes.Buffer, *abi){
+		"abi_gen.go":        genCgo,
+		"abi_gen_nocgo.go":  genNocgo,
+		"abi_gen_consts.go": genConsts,
+	} {
+		var buf bytes.Buffer
+		buf.WriteString("// Code generated by internal/abigen from abi.h. DO NOT EDIT.\n\n")
+		gen(&buf, parsed)
+		formatted, err := format.Source(buf.Bytes())
+		if err != nil {
+			log.Fatalf("failed to format %s: %v\n%s", name, err, buf.String())
+		}
+		if err := os.WriteFile(filepath.Join(*out, name), formatted, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// abi is the parsed abi.h.
+type abi struct {
+	// typedefs maps the typedef names to their definitions.
+	typedefs map[string]typedef
+	// events are the event hooks implemented by the module.
+	events []function
+	// hostFunctions are the Envoy API functions called by the module.
+	hostFunctions []function
+	// consts are the integer constants.
+	consts []constant
+}
+
+type typedef struct {
+	base          string
+	ownedByModule bool
+}
+
+type function struct {
+	name   string
+	ret    string
+	params []param
+	// weak is true if the function is an optional Envoy API declared as a weak symbol.
+	weak bool
+}
+
+type param struct {
+	cType, name string
+}
+
+type constant struct {
+	name, value string
+}
+
+var (
+	lineCommentRe = regexp.MustCompile(`//[^\n]*`)
+	typedefRe     = regexp.MustCompile(`typedef\s+(\w+)\s+(` + typePrefix + `\w+)\s*(OWNED_BY_ENVOY|OWNED_BY_MODULE)?\s*;`)
+	prototypeRe   = regexp.MustCompile(`(\w+)\s+(` + functionPrefix + `\w+)\s*\(([^)]*)\)\s*;`)
+	weakRe        = regexp.MustCompile(`#pragma weak (\w+)`)
+	constRe       = regexp.MustCompile(`(?m)^#define (` + constPrefix + `\w+) ([0-9()<< ]+)$`)
+)
+
+// The markers of the sections in abi.h. The module section is the #else branch of the Envoy section.
+const (
+	envoySectionBegin  = "#ifdef ENVOY_DYNAMIC_MODULE"
+	moduleSectionBegin = "#else"
+	moduleSectionEnd   = "#undef OWNED_BY_ENVOY"
+	hostSectionBegin   = "#undef OWNED_BY_MODULE"
+)
+
+func parse(src string) (*abi, error) {
+	ret := &abi{typedefs: map[string]typedef{}}
+	weak := map[string]bool{}
+	for _, m := range weakRe.FindAllStringSubmatch(src, -1) {
+		weak[m[1]] = true
+	}
+	for _, m := range constRe.FindAllStringSubmatch(src, -1) {
+		ret.consts = append(ret.consts, constant{name: m[1], value: m[2]})
+	}
+
+	src = lineCommentRe.ReplaceAllString(src, "")
+	for _, m := range typedefRe.FindAllStringSubmatch(src, -1) {
+		ret.typedefs[m[2]] = typedef{base: m[1], ownedByModule: m[3] == "OWNED_BY_MODULE"}
+	}
+
+	envoyBegin := strings.Index(src, envoySectionBegin)
+	if envoyBegin < 0 {
+		return nil, fmt.Errorf("section markers not found")
+	}
+	moduleBegin := envoyBegin + strings.Index(src[envoyBegin:], moduleSectionBegin)
+	moduleEnd := strings.Index(src, moduleSectionEnd)
+	hostBegin := strings.Index(src, hostSectionBegin)
+	if moduleBegin < envoyBegin || moduleEnd < moduleBegin || hostBegin < moduleEnd {
+		return nil, fmt.Errorf("section markers not found")
+	}
+	var err error
+	if ret.events, err = parsePrototypes(src[moduleBegin:moduleEnd], weak); err != nil {
+		return nil, err
+	}
+	if ret.hostFunctions, err = parsePrototypes(src[hostBegin:], weak); err != nil {
+		return nil, err
+	}
+	for _, f := range append(ret.events, ret.hostFunctions...) {
+		for _, p := range append([]param{{cType: f.ret}}, f.params...) {
+			if _, err := ret.goType(p.cType, false); err != nil {
+				return nil, fmt.Errorf("%s: %w", f.name, err)
+			}
+		}
+	}
+	return ret, nil
+}
+
+func parsePrototypes(src string, weak map[string]bool) ([]function, error) {
+	var ret []function
+	for _, m := range prototypeRe.FindAllStringSubmatch(src, -1) {
+		f := function{ret: m[1], name: m[2], weak: weak[m[2]]}
+		for i, p := range strings.Split(m[3], ",") {
+			fields := strings.Fields(p)
+			switch len(fields) {
+			case 0:
+				continue
+			case 1:
+				fields = append(fields, fmt.Sprintf("p%d", i))
+			case 2:
+			default:
+				return nil, fmt.Errorf("%s: unsupported parameter %q", f.name, p)
+			}
+			f.params = append(f.params, param{cType: fields[0], name: lowerCamel(fields[1])})
+		}
+		ret = append(ret, f)
+	}
+	return ret, nil
+}
+
+// goType returns the Go type of the C type.
+// `host` is true for the types in the Envoy API, and false for the event hooks.
+func (a *abi) goType(cType string, host bool) (string, error) {
+	switch cType {
+	case "void":
+		return "", nil
+	case "size_t":
+		return "int", nil
+	case "int64_t", "uint64_t", "uint32_t", "int32_t":
+		return strings.TrimSuffix(cType, "_t"), nil
+	case endOfStream:
+		return "bool", nil
+	}
+	def, ok := a.typedefs[cType]
+	if !ok {
+		return "", fmt.Errorf("unknown type %s", cType)
+	}
+	isResult := strings.HasSuffix(cType, "Result")
+	switch def.base {
+	case rawPointerType:
+		if host && (def.ownedByModule || isResult) {
+			return "unsafe.Pointer", nil
+		}
+		return "uintptr", nil
+	case "size_t":
+		if host && isResult {
+			return "unsafe.Pointer", nil
+		}
+		return "int", nil
+	default:
+		return "", fmt.Errorf("unsupported typedef %s of %s", cType, def.base)
+	}
+}
+
+// cType returns the name of the C type in Go.
+func cType(t string) string {
+	return "C." + t
+}
+
+// toC returns the Go expression converting the Go value `expr` to the C type.
+func (a *abi) toC(t, expr string, host bool) string {
+	goType, _ := a.goType(t, host)
+	switch goType {
+	case "bool":
+		return fmt.Sprintf("%s(boolToInt(%s))", cType(t), expr)
+	case "unsafe.Pointer":
+		return fmt.Sprintf("%s(uintptr(%s))", cType(t), expr)
+	default:
+		return fmt.Sprintf("%s(%s)", cType(t), expr)
+	}
+}
+
+// fromC returns the Go expression converting the C value `expr` to the Go type.
+func (a *abi) fromC(t, expr string, host bool) string {
+	goType, _ := a.goType(t, host)
+	switch goType {
+	case "bool":
+		return expr + " != 0"
+	default:
+		return fmt.Sprintf("%s(%s)", goType, expr)
+	}
+}
+
+// signature returns the Go parameters and the result of the function.
+func (a *abi) signature(f function, host bool) (params, result string) {
+	var ps []string
+	for _, p := range f.params {
+		t, _ := a.goType(p.cType, host)
+		ps = append(ps, p.name+" "+t)
+	}
+	result, _ = a.goType(f.ret, host)
+	return strings.Join(ps, ", "), result
+}
+
+func genCgo(buf *bytes.Buffer, a *abi) {
+	buf.WriteString(`//go:build cgo
+
+package envoy
+
+/*
+#include "abi.h"
+`)
+	for _, f := range a.hostFunctions {
+		if a.hasScalarResults(f) {
+			a.genShim(buf, f)
+		}
+	}
+	buf.WriteString(`*/
+import "C"
+import (
+	"runtime"
+	"unsafe"
+)
+
+var _ = runtime.KeepAlive
+var _ unsafe.Pointer
+
+// The pointers are passed to Envoy as integers, so the compiler may keep the memory they point to on the goroutine
+// stack, which can be moved by the stack growth in the cgo call before Envoy accesses it. To prevent this, the results
+// are received on the C stack by the shims in the preamble, and the other pointers are made to escape to the heap
+// by escape, in the same way as cgo does for the pointer arguments.
+var (
+	escapeAlwaysFalse bool
+	escapeSink        unsafe.Pointer
+)
+
+func escape(p unsafe.Pointer) {
+	if escapeAlwaysFalse {
+		escapeSink = p
+	}
+}
+
+`)
+	for _, f := range a.events {
+		var cParams, args []string
+		for _, p := range f.params {
+			cParams = append(cParams, p.name+" "+cType(p.cType))
+			args = append(args, a.fromC(p.cType, p.name, false))
+		}
+		call := fmt.Sprintf("%s(%s)", handlerName(f.name), strings.Join(args, ", "))
+		fmt.Fprintf(buf, "//export %s\nfunc %s(%s) ", f.name, f.name, strings.Join(cParams, ", "))
+		if f.ret == "void" {
+			fmt.Fprintf(buf, "{\n\t%s\n}\n\n", call)
+		} else {
+			fmt.Fprintf(buf, "%s {\n\treturn %s(%s)\n}\n\n", cType(f.ret), cType(f.ret), call)
+		}
+	}
+	for _, f := range a.hostFunctions {
+		params, result := a.signature(f, true)
+		writeHostDoc(buf, f)
+		fmt.Fprintf(buf, "func %s(%s) %s {\n", hostName(f.name), params, result)
+		if a.hasScalarResults(f) {
+			a.genShimCall(buf, f, result)
+			continue
+		}
+		var args, keepAlive []string
+		for _, p := range f.params {
+			args = append(args, a.toC(p.cType, p.name, true))
+			if t, _ := a.goType(p.cType, true); t == "unsafe.Pointer" {
+				fmt.Fprintf(buf, "\tescape(%s)\n", p.name)
+				keepAlive = append(keepAlive, fmt.Sprintf("\truntime.KeepAlive(%s)\n", p.name))
+			}
+		}
+		call := fmt.Sprintf("C.%s(%s)", f.name, strings.Join(args, ", "))
+		switch {
+		case result == "":
+			fmt.Fprintf(buf, "\t%s\n%s", call, strings.Join(keepAlive, ""))
+		case len(keepAlive) == 0:
+			fmt.Fprintf(buf, "\treturn %s\n", a.fromC(f.ret, call, true))
+		default:
+			fmt.Fprintf(buf, "\tret := %s\n%s\treturn %s\n", call, strings.Join(keepAlive, ""), a.fromC(f.ret, "ret", true))
+		}
+		buf.WriteString("}\n\n")
+	}
+}
+
+// scalarResults maps the result types pointing to a single value to the C type of the value.
+var scalarResults = map[string]string{
+	typePrefix + "DataSlicePtrResult":    rawPointerType,
+	typePrefix + "DataSliceLengthResult": "size_t",
+}
+
+// hasScalarResults returns true if the function takes the scalar results, which are received by the shim.
+func (a *abi) hasScalarResults(f function) bool {
+	for _, p := range f.params {
+		if _, ok := scalarResults[p.cType]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// shimName returns the name of the C shim of the Envoy API, e.g. envoy_go_http_get_request_body_buffer_slice.
+func shimName(name string) string {
+	return "envoy_go_" + strings.TrimPrefix(name, functionPrefix)
+}
+
+// genShim emits the C shim that calls the Envoy API with the scalar results on the C stack, and returns them
+// together with the return value in a struct.
+func (a *abi) genShim(buf *bytes.Buffer, f function) {
+	name := shimName(f.name)
+	buf.WriteString("\ntypedef struct {\n")
+	if f.ret != "void" {
+		fmt.Fprintf(buf, "\t%s ret;\n", f.ret)
+	}
+	var params, args []string
+	for _, p := range f.params {
+		if t, ok := scalarResults[p.cType]; ok {
+			fmt.Fprintf(buf, "\t%s %s;\n", t, p.name)
+			args = append(args, fmt.Sprintf("(%s)&r.%s", p.cType, p.name))
+			continue
+		}
+		params = append(params, p.cType+" "+p.name)
+		args = append(args, p.name)
+	}
+	fmt.Fprintf(buf, "} %s_results;\n\n", name)
+	fmt.Fprintf(buf, "static inline %s_results %s(%s) {\n", name, name, strings.Join(params, ", "))
+	fmt.Fprintf(buf, "\t%s_results r = {0};\n\t", name)
+	if f.ret != "void" {
+		buf.WriteString("r.ret = ")
+	}
+	fmt.Fprintf(buf, "%s(%s);\n\treturn r;\n}\n", f.name, strings.Join(args, ", "))
+}
+
+// genShimCall emits the body of the host-call wrapper that calls the shim and stores the scalar results.
+func (a *abi) genShimCall(buf *bytes.Buffer, f function, result string) {
+	var args, stores, keepAlive []string
+	for _, p := range f.params {
+		switch t, ok := scalarResults[p.cType]; {
+		case ok && t == rawPointerType:
+			stores = append(stores, fmt.Sprintf("\t*(*uintptr)(%s) = uintptr(r.%s)\n", p.name, p.name))
+		case ok:
+			stores = append(stores, fmt.Sprintf("\t*(*int)(%s) = int(r.%s)\n", p.name, p.name))
+		default:
+			args = append(args, a.toC(p.cType, p.name, true))
+			if t, _ := a.goType(p.cType, true); t == "unsafe.Pointer" {
+				fmt.Fprintf(buf, "\tescape(%s)\n", p.name)
+				keepAlive = append(keepAlive, fmt.Sprintf("\truntime.KeepAlive(%s)\n", p.name))
+			}
+		}
+	}
+	fmt.Fprintf(buf, "\tr := C.%s(%s)\n%s%s", shimName(f.name), strings.Join(args, ", "),
+		strings.Join(keepAlive, ""), strings.Join(stores, ""))
+	if result != "" {
+		fmt.Fprintf(buf, "\treturn %s\n", a.fromC(f.ret, "r.ret", true))
+	}
+	buf.WriteString("}\n\n")
+}
+
+func genNocgo(buf *bytes.Buffer, a *abi) {
+	buf.WriteString(`//go:build !cgo
+
+package envoy
+
+import "unsafe"
+
+var _ unsafe.Pointer
+
+// FakeHostFuncs is the set of the fake Envoy API functions. Each field corresponds to the function
+// in abi.h of the same name without the __envoy_dynamic_module_v1_ prefix.
+type FakeHostFuncs struct {
+`)
+	for _, f := range a.hostFunctions {
+		params, result := a.signature(f, true)
+		fmt.Fprintf(buf, "\t%s func(%s) %s\n", fakeName(f.name), params, result)
+	}
+	buf.WriteString(`}
+
+// FakeHost is the fake Envoy used in the builds without cgo, e.g. CGO_ENABLED=0 go test, where there's no Envoy
+// that loads the module. The nil functions behave as the Envoy API returning zero values.
+//
+// This allows testing the module code without Envoy by setting the fake implementations of the Envoy API.
+var FakeHost FakeHostFuncs
+
+`)
+	for _, f := range a.hostFunctions {
+		params, result := a.signature(f, true)
+		var args []string
+		for _, p := range f.params {
+			args = append(args, p.name)
+		}
+		writeHostDoc(buf, f)
+		fmt.Fprintf(buf, "func %s(%s) %s {\n", hostName(f.name), params, result)
+		fmt.Fprintf(buf, "\tif f := FakeHost.%s; f != nil {\n", fakeName(f.name))
+		if result == "" {
+			fmt.Fprintf(buf, "\t\tf(%s)\n\t}\n}\n\n", strings.Join(args, ", "))
+		} else {
+			fmt.Fprintf(buf, "\t\treturn f(%s)\n\t}\n\treturn %s\n}\n\n", strings.Join(args, ", "), zeroValue(result))
+		}
+	}
+}
+
+func genConsts(buf *bytes.Buffer, a *abi) {
+	buf.WriteString("package envoy\n\n// The constants defined in abi.h.\nconst (\n")
+	for _, c := range a.consts {
+		fmt.Fprintf(buf, "\t%s = %s\n", constName(c.name), c.value)
+	}
+	buf.WriteString(")\n")
+}
+
+func genStub(buf *bytes.Buffer, a *abi) {
+	buf.WriteString(`// Code generated by internal/abigen from abi.h. DO NOT EDIT.
+
+// The default no-op definitions of the Envoy API. Each function can be overridden by a strong definition.
+
+#define ENVOY_DYNAMIC_MODULE
+#include "abi.h"
+`)
+	for _, f := range a.hostFunctions {
+		var params []string
+		for _, p := range f.params {
+			params = append(params, p.cType+" "+p.name)
+		}
+		fmt.Fprintf(buf, "\n__attribute__((weak)) %s %s(%s) {", f.ret, f.name, strings.Join(params, ", "))
+		if f.ret == "void" {
+			buf.WriteString("}\n")
+		} else {
+			buf.WriteString(" return 0; }\n")
+		}
+	}
+}
+
+func writeHostDoc(buf *bytes.Buffer, f function) {
+	fmt.Fprintf(buf, "// %s calls %s in abi.h.\n", hostName(f.name), f.name)
+	if f.weak {
+		buf.WriteString("// This is an optional Envoy API, so the caller must check the corresponding Feature beforehand.\n")
+	}
+}
+
+func zeroValue(goType string) string {
+	switch goType {
+	case "bool":
+		return "false"
+	case "unsafe.Pointer":
+		return "nil"
+	default:
+		return "0"
+	}
+}
+
+// handlerName returns the name of the hand-written Go function that handles the event hook.
+func handlerName(name string) string {
+	return "event" + upperCamel(strings.TrimPrefix(name, eventPrefix))
+}
+
+// hostName returns the name of the Go function that calls the Envoy API.
+func hostName(name string) string {
+	return "host" + fakeName(name)
+}
+
+// fakeName returns the name of the FakeHostFuncs field for the Envoy API.
+func fakeName(name string) string {
+	return upperCamel(strings.TrimPrefix(name, functionPrefix))
+}
+
+// constName returns the Go name of the constant, e.g. abiHeaderStatusContinue.
+func constName(name string) string {
+	camel := upperCamel(strings.ToLower(strings.TrimPrefix(name, constPrefix)))
+	return "abi" + strings.TrimPrefix(camel, "Abi")
+}
+
+func upperCamel(snake string) string {
+	var b strings.Builder
+	for _, w := range strings.Split(snake, "_") {
+		if w == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+func lowerCamel(snake string) string {
+	camel := upperCamel(snake)
+	if camel == "" {
+		return camel
+	}
+	return strings.ToLower(camel[:1]) + camel[1:]
+}
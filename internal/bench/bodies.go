@@ -0,0 +1,126 @@
+//go:build cgo
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/internal/hoststub"
+)
+
+const (
+	// bodySliceSize and bodySlices are the size and the number of the slices of the body in the body benchmarks,
+	// which are the typical ones of Envoy's buffer for a large body.
+	bodySliceSize = 16 << 10
+	bodySlices    = 4
+	// readAtSize is the size of the reads in RequestBodyReadAt.
+	readAtSize = 4 << 10
+)
+
+func init() {
+	httpFilters["body_slices"] = bodyHttpFilter{requestBody: func(body envoy.RequestBodyBuffer) {
+		var n int
+		body.Slices(func(view []byte) { n += len(view) })
+	}}
+	httpFilters["body_copy"] = bodyHttpFilter{requestBody: func(body envoy.RequestBodyBuffer) {
+		body.Copy()
+	}}
+	var readAtBuf [readAtSize]byte
+	httpFilters["body_read_at"] = bodyHttpFilter{requestBody: func(body envoy.RequestBodyBuffer) {
+		for off := int64(0); ; off += readAtSize {
+			if _, err := body.ReadAt(readAtBuf[:], off); err != nil {
+				return
+			}
+		}
+	}}
+	httpFilters["body_buffered_copy"] = bodyHttpFilter{buffered: true, requestBody: func(body envoy.RequestBodyBuffer) {
+		body.Copy()
+	}}
+	httpFilters["body_append_drain"] = bodyHttpFilter{requestBody: func(body envoy.RequestBodyBuffer) {
+		body.Append(readAtBuf[:])
+		body.Drain(readAtSize)
+	}}
+	rewriter, err := envoy.NewRewriter(envoy.RewriterConfig{
+		Replacements:    []envoy.Replacement{{Old: "needle", New: "pin"}, {Old: "haystack", New: "hay"}},
+		CaseInsensitive: true,
+	})
+	if err != nil {
+		panic(err)
+	}
+	httpFilters["body_rewrite"] = bodyHttpFilter{newRequestBody: func(e envoy.EnvoyFilterInstance) func(envoy.RequestBodyBuffer) {
+		bodyRewriter := rewriter.NewBodyRewriter(e)
+		return func(body envoy.RequestBodyBuffer) { bodyRewriter.RewriteRequestBody(body, false) }
+	}}
+
+	benchmarks = append(benchmarks,
+		benchmark{name: "RequestBodySlices", bench: benchmarkRequestBody("body_slices", true)},
+		benchmark{name: "RequestBodyCopy", bench: benchmarkRequestBody("body_copy", true)},
+		benchmark{name: "RequestBodyReadAt", bench: benchmarkRequestBody("body_read_at", true)},
+		benchmark{name: "RequestBodyBufferedCopy", bench: benchmarkRequestBody("body_buffered_copy", true)},
+		benchmark{name: "RequestBodyAppendDrain", bench: benchmarkRequestBody("body_append_drain", false)},
+		benchmark{name: "RequestBodyRewrite", bench: benchmarkRequestBody("body_rewrite", true)},
+	)
+}
+
+// bodyHttpFilter is the http filter whose instances call requestBody with the request body in the request body
+// event, or with the buffered request body if buffered is true. If newRequestBody is set, requestBody of each
+// instance is created by it instead.
+type bodyHttpFilter struct {
+	buffered       bool
+	requestBody    func(body envoy.RequestBodyBuffer)
+	newRequestBody func(e envoy.EnvoyFilterInstance) func(body envoy.RequestBodyBuffer)
+}
+
+func (f bodyHttpFilter) NewInstance(e envoy.EnvoyFilterInstance) envoy.HttpFilterInstance {
+	if f.newRequestBody != nil {
+		f.requestBody = f.newRequestBody(e)
+	}
+	return &bodyHttpFilterInstance{bodyHttpFilter: f, envoyFilter: e}
+}
+
+func (bodyHttpFilter) Destroy() {}
+
+type bodyHttpFilterInstance struct {
+	bodyHttpFilter
+	envoyFilter envoy.EnvoyFilterInstance
+}
+
+func (h *bodyHttpFilterInstance) RequestBody(body envoy.RequestBodyBuffer, _ bool) envoy.RequestBodyStatus {
+	if h.buffered {
+		body = h.envoyFilter.GetRequestBodyBuffer()
+	}
+	h.requestBody(body)
+	return envoy.RequestBodyStatusContinue
+}
+
+func (*bodyHttpFilterInstance) Destroy() {}
+
+// benchmarkRequestBody returns the benchmark of the request body event of the http filter of the config with
+// the body of bodySlices slices. If throughput is true, the size of the body is reported as the bytes per op.
+func benchmarkRequestBody(config string, throughput bool) func(b *testing.B) {
+	return func(b *testing.B) {
+		slices := make([][]byte, bodySlices)
+		for i := range slices {
+			slices[i] = bytes.Repeat([]byte{byte('a' + i)}, bodySliceSize)
+		}
+		body := hoststub.NewBuffer(slices...)
+		defer body.Free()
+		stream := hoststub.NewStream(body, hoststub.Buffer{})
+		defer stream.Free()
+
+		filter := hoststub.HttpFilterInit(config)
+		defer hoststub.HttpFilterDestroy(filter)
+		instance := hoststub.HttpFilterInstanceInit(filter, stream)
+		defer hoststub.HttpFilterInstanceDestroy(instance)
+		if throughput {
+			b.SetBytes(bodySliceSize * bodySlices)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			hoststub.HttpFilterInstanceRequestBody(instance, body, false)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+//go:build cgo
+
+package main
+
+import (
+	"testing"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/internal/hoststub"
+)
+
+func init() {
+	httpFilters["all_handlers"] = allHandlersHttpFilter{}
+	benchmarks = append(benchmarks,
+		benchmark{name: "EventRequestHeaders", bench: benchmarkEvent("all_handlers", eventRequestHeaders)},
+		benchmark{name: "EventRequestBody", bench: benchmarkEvent("all_handlers", eventRequestBody)},
+		benchmark{name: "EventResponseHeaders", bench: benchmarkEvent("all_handlers", eventResponseHeaders)},
+		benchmark{name: "EventResponseBody", bench: benchmarkEvent("all_handlers", eventResponseBody)},
+		// The events for the handlers not implemented are bypassed by Envoy, but the host stub still calls them
+		// to measure the cost of the SDK side in case the host doesn't support envoy.FeatureBypassEvents.
+		benchmark{name: "EventRequestHeadersUnhandled", bench: benchmarkEvent("nop", eventRequestHeaders)},
+		benchmark{name: "EventRequestBodyUnhandled", bench: benchmarkEvent("nop", eventRequestBody)},
+	)
+}
+
+// allHandlersHttpFilter is the http filter whose instances implement all the handlers that do nothing,
+// which is used to measure the per-callback overhead of the SDK and cgo.
+type allHandlersHttpFilter struct{}
+
+func (allHandlersHttpFilter) NewInstance(envoy.EnvoyFilterInstance) envoy.HttpFilterInstance {
+	return &allHandlersHttpFilterInstance{}
+}
+
+func (allHandlersHttpFilter) Destroy() {}
+
+type allHandlersHttpFilterInstance struct{}
+
+func (*allHandlersHttpFilterInstance) RequestHeaders(envoy.RequestHeaders, bool) envoy.RequestHeadersStatus {
+	return envoy.HeadersStatusContinue
+}
+
+func (*allHandlersHttpFilterInstance) RequestBody(envoy.RequestBodyBuffer, bool) envoy.RequestBodyStatus {
+	return envoy.RequestBodyStatusContinue
+}
+
+func (*allHandlersHttpFilterInstance) ResponseHeaders(envoy.ResponseHeaders, bool) envoy.ResponseHeadersStatus {
+	return envoy.ResponseHeadersStatusContinue
+}
+
+func (*allHandlersHttpFilterInstance) ResponseBody(envoy.ResponseBodyBuffer, bool) envoy.ResponseBodyStatus {
+	return envoy.ResponseBodyStatusContinue
+}
+
+func (*allHandlersHttpFilterInstance) Destroy() {}
+
+// event calls one of the event hooks of the instance with the headers and the buffer.
+type event func(instance uintptr, headers hoststub.Headers, buffer hoststub.Buffer)
+
+func eventRequestHeaders(instance uintptr, headers hoststub.Headers, _ hoststub.Buffer) {
+	hoststub.HttpFilterInstanceRequestHeaders(instance, headers, false)
+}
+
+func eventRequestBody(instance uintptr, _ hoststub.Headers, buffer hoststub.Buffer) {
+	hoststub.HttpFilterInstanceRequestBody(instance, buffer, false)
+}
+
+func eventResponseHeaders(instance uintptr, headers hoststub.Headers, _ hoststub.Buffer) {
+	hoststub.HttpFilterInstanceResponseHeaders(instance, headers, false)
+}
+
+func eventResponseBody(instance uintptr, _ hoststub.Headers, buffer hoststub.Buffer) {
+	hoststub.HttpFilterInstanceResponseBody(instance, buffer, false)
+}
+
+// benchmarkEvent returns the benchmark of the event of the instance of the http filter of the config.
+func benchmarkEvent(config string, call event) func(b *testing.B) {
+	return func(b *testing.B) {
+		filter := hoststub.HttpFilterInit(config)
+		defer hoststub.HttpFilterDestroy(filter)
+		instance := hoststub.HttpFilterInstanceInit(filter, hoststub.Stream{})
+		defer hoststub.HttpFilterInstanceDestroy(instance)
+		headers := hoststub.NewHeaders(nil)
+		defer headers.Free()
+		buffer := hoststub.NewBuffer()
+		defer buffer.Free()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			call(instance, headers, buffer)
+		}
+	}
+}
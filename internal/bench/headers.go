@@ -0,0 +1,105 @@
+//go:build cgo
+
+package main
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/internal/hoststub"
+)
+
+// headersCount is the number of the headers read or written per request in the header benchmarks,
+// e.g. a header-rewrite filter.
+const headersCount = 20
+
+var (
+	// headerKeys are the keys read by the header benchmarks.
+	headerKeys []string
+	// headerMutation sets the headers of headerKeys.
+	headerMutation envoy.HeaderMutation
+	// requestHeaders are the request headers passed to the filters in the header benchmarks.
+	requestHeaders [][2]string
+)
+
+func init() {
+	for i := 0; i < headersCount; i++ {
+		key := "x-header-" + strconv.Itoa(i)
+		headerKeys = append(headerKeys, key)
+		headerMutation.Set = append(headerMutation.Set, [2]string{key, "value-" + strconv.Itoa(i)})
+		requestHeaders = append(requestHeaders, [2]string{key, "original-" + strconv.Itoa(i)})
+	}
+
+	httpFilters["headers_set"] = headersHttpFilter{requestHeaders: func(headers envoy.RequestHeaders) {
+		for _, kv := range headerMutation.Set {
+			headers.Set(kv[0], kv[1])
+		}
+	}}
+	httpFilters["headers_apply"] = headersHttpFilter{requestHeaders: func(headers envoy.RequestHeaders) {
+		headers.Apply(headerMutation)
+	}}
+	httpFilters["headers_get"] = headersHttpFilter{requestHeaders: func(headers envoy.RequestHeaders) {
+		for _, key := range headerKeys {
+			headers.Get(key)
+		}
+	}}
+	httpFilters["headers_get_one"] = headersHttpFilter{requestHeaders: func(headers envoy.RequestHeaders) {
+		headers.Get(headerKeys[0])
+	}}
+	httpFilters["headers_set_one"] = headersHttpFilter{requestHeaders: func(headers envoy.RequestHeaders) {
+		headers.Set(headerKeys[0], "value")
+	}}
+	httpFilters["headers_get_many"] = headersHttpFilter{requestHeaders: func(headers envoy.RequestHeaders) {
+		headers.GetMany(headerKeys...)
+	}}
+
+	benchmarks = append(benchmarks,
+		benchmark{name: "RequestHeadersGetOne", bench: benchmarkRequestHeaders("headers_get_one")},
+		benchmark{name: "RequestHeadersSetOne", bench: benchmarkRequestHeaders("headers_set_one")},
+		benchmark{name: "RequestHeadersSet", bench: benchmarkRequestHeaders("headers_set")},
+		benchmark{name: "RequestHeadersApply", bench: benchmarkRequestHeaders("headers_apply")},
+		benchmark{name: "RequestHeadersGet", bench: benchmarkRequestHeaders("headers_get")},
+		benchmark{name: "RequestHeadersGetMany", bench: benchmarkRequestHeaders("headers_get_many")},
+	)
+}
+
+// headersHttpFilter is the http filter whose instances call requestHeaders in the request headers event.
+type headersHttpFilter struct {
+	requestHeaders func(headers envoy.RequestHeaders)
+}
+
+func (f headersHttpFilter) NewInstance(envoy.EnvoyFilterInstance) envoy.HttpFilterInstance {
+	return &headersHttpFilterInstance{requestHeaders: f.requestHeaders}
+}
+
+func (headersHttpFilter) Destroy() {}
+
+type headersHttpFilterInstance struct {
+	requestHeaders func(headers envoy.RequestHeaders)
+}
+
+func (h *headersHttpFilterInstance) RequestHeaders(headers envoy.RequestHeaders, _ bool) envoy.RequestHeadersStatus {
+	h.requestHeaders(headers)
+	return envoy.HeadersStatusContinue
+}
+
+func (*headersHttpFilterInstance) Destroy() {}
+
+// benchmarkRequestHeaders returns the benchmark of the request headers event of the http filter of the config
+// with the headersCount request headers.
+func benchmarkRequestHeaders(config string) func(b *testing.B) {
+	return func(b *testing.B) {
+		filter := hoststub.HttpFilterInit(config)
+		defer hoststub.HttpFilterDestroy(filter)
+		instance := hoststub.HttpFilterInstanceInit(filter, hoststub.Stream{})
+		defer hoststub.HttpFilterInstanceDestroy(instance)
+		headers := hoststub.NewHeaders(requestHeaders)
+		defer headers.Free()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			hoststub.HttpFilterInstanceRequestHeaders(instance, headers, true)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+//go:build cgo
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/internal/hoststub"
+)
+
+func init() {
+	httpFilters["nop"] = nopHttpFilter{}
+	httpFilters["pooled"] = pooledHttpFilter{}
+	benchmarks = append(benchmarks,
+		benchmark{name: "HttpFilterInstanceInitDestroy", bench: benchmarkHttpFilterInstanceInitDestroy("nop")},
+		benchmark{name: "HttpFilterInstanceInitDestroyPooled", bench: benchmarkHttpFilterInstanceInitDestroy("pooled")},
+		benchmark{name: "HttpFilterInstanceInitDestroyParallel", bench: benchmarkHttpFilterInstanceInitDestroyParallel("nop")},
+		benchmark{name: "HttpFilterInstanceInitDestroyParallelPooled", bench: benchmarkHttpFilterInstanceInitDestroyParallel("pooled")},
+		benchmark{name: "HttpFilterInstanceLatency", latency: latencyHttpFilterInstance},
+	)
+}
+
+// nopHttpFilter is the http filter whose instances don't handle any event.
+type nopHttpFilter struct{}
+
+func (nopHttpFilter) NewInstance(envoy.EnvoyFilterInstance) envoy.HttpFilterInstance {
+	return &nopHttpFilterInstance{}
+}
+
+func (nopHttpFilter) Destroy() {}
+
+type nopHttpFilterInstance struct{}
+
+func (*nopHttpFilterInstance) Destroy() {}
+
+// pooledHttpFilter is the http filter whose instances are pooled via envoy.Resetter. The instances have some
+// per-stream state as the real filters do.
+type pooledHttpFilter struct{}
+
+func (pooledHttpFilter) NewInstance(e envoy.EnvoyFilterInstance) envoy.HttpFilterInstance {
+	return &pooledHttpFilterInstance{envoy: e}
+}
+
+func (pooledHttpFilter) Destroy() {}
+
+type pooledHttpFilterInstance struct {
+	envoy   envoy.EnvoyFilterInstance
+	path    string
+	buffer  []byte
+	counter int
+}
+
+func (*pooledHttpFilterInstance) Destroy() {}
+
+func (p *pooledHttpFilterInstance) Reset(e envoy.EnvoyFilterInstance) {
+	p.envoy, p.path, p.buffer, p.counter = e, "", p.buffer[:0], 0
+}
+
+// benchmarkHttpFilterInstanceInitDestroy returns the benchmark of the init and destroy of the instance of
+// the http filter of the config.
+func benchmarkHttpFilterInstanceInitDestroy(config string) func(b *testing.B) {
+	return func(b *testing.B) {
+		filter := hoststub.HttpFilterInit(config)
+		defer hoststub.HttpFilterDestroy(filter)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			hoststub.HttpFilterInstanceDestroy(hoststub.HttpFilterInstanceInit(filter, hoststub.Stream{}))
+		}
+	}
+}
+
+// benchmarkHttpFilterInstanceInitDestroyParallel is benchmarkHttpFilterInstanceInitDestroy run in parallel.
+func benchmarkHttpFilterInstanceInitDestroyParallel(config string) func(b *testing.B) {
+	return func(b *testing.B) {
+		filter := hoststub.HttpFilterInit(config)
+		defer hoststub.HttpFilterDestroy(filter)
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				hoststub.HttpFilterInstanceDestroy(hoststub.HttpFilterInstanceInit(filter, hoststub.Stream{}))
+			}
+		})
+	}
+}
+
+// latencyHttpFilterInstance measures the latency of the instance init and destroy where each goroutine keeps
+// the fixed number of instances alive as the concurrent streams of an Envoy worker. The pinning alone is compared
+// with the unsharded linked list of the previous releases by BenchmarkPinLatency in the envoy package.
+func latencyHttpFilterInstance(concurrency, iterations int) []latencyResult {
+	const liveStreams = 64
+	filter := hoststub.HttpFilterInit("nop")
+	defer hoststub.HttpFilterDestroy(filter)
+	var all [][]uintptr
+	results := runLatency(concurrency, iterations, []string{"init", "destroy"}, func() func(record func(op int, d time.Duration)) {
+		streams := make([]uintptr, liveStreams)
+		for i := range streams {
+			streams[i] = hoststub.HttpFilterInstanceInit(filter, hoststub.Stream{})
+		}
+		all = append(all, streams)
+		next := 0
+		return func(record func(op int, d time.Duration)) {
+			start := time.Now()
+			hoststub.HttpFilterInstanceDestroy(streams[next])
+			record(1, time.Since(start))
+			start = time.Now()
+			streams[next] = hoststub.HttpFilterInstanceInit(filter, hoststub.Stream{})
+			record(0, time.Since(start))
+			next = (next + 1) % liveStreams
+		}
+	})
+	for _, streams := range all {
+		for _, instance := range streams {
+			hoststub.HttpFilterInstanceDestroy(instance)
+		}
+	}
+	return results
+}
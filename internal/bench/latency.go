@@ -0,0 +1,64 @@
+//go:build cgo
+
+package main
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// latencyResult is the latency distribution of an operation in nanoseconds.
+type latencyResult struct {
+	op                  string
+	count               int
+	p50, p99, p999, max int64
+}
+
+// runLatency runs the iteration created by `newIteration` on `concurrency` goroutines for `iterations` times each,
+// and returns the latency distribution of each operation. The iteration records the latency of each operation
+// via `record` with the index of the operation in `ops`. `newIteration` is called once per goroutine so that
+// the iteration can have the per-goroutine state.
+func runLatency(concurrency, iterations int, ops []string, newIteration func() func(record func(op int, d time.Duration))) []latencyResult {
+	samples := make([][][]int64, concurrency)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for g := 0; g < concurrency; g++ {
+		perOp := make([][]int64, len(ops))
+		for i := range perOp {
+			perOp[i] = make([]int64, 0, iterations)
+		}
+		samples[g] = perOp
+		iteration := newIteration()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			record := func(op int, d time.Duration) { perOp[op] = append(perOp[op], int64(d)) }
+			for i := 0; i < iterations; i++ {
+				iteration(record)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	results := make([]latencyResult, len(ops))
+	for i, op := range ops {
+		var all []int64
+		for g := range samples {
+			all = append(all, samples[g][i]...)
+		}
+		slices.Sort(all)
+		results[i] = latencyResult{
+			op: op, count: len(all),
+			p50: percentile(all, 0.5), p99: percentile(all, 0.99), p999: percentile(all, 0.999), max: all[len(all)-1],
+		}
+	}
+	return results
+}
+
+// percentile returns the p-th percentile of the sorted samples.
+func percentile(sorted []int64, p float64) int64 {
+	return sorted[int(float64(len(sorted)-1)*p)]
+}
@@ -0,0 +1,100 @@
+//go:build cgo
+
+// Command bench runs the benchmarks of the SDK against the host stub in internal/hoststub, which calls the
+// event hooks through cgo in the same way as Envoy does. The results are printed in the format of `go test -bench`
+// so they can be compared with benchstat between releases.
+//
+// This is a command instead of the Go benchmarks as the event hooks are only exported to C in the cgo build.
+// The flags of `go test` such as -test.benchtime are also accepted, e.g. to smoke-test the benchmarks in CI:
+//
+//	go run ./internal/bench -test.benchtime 100x -iterations 1000
+//
+//	go run ./internal/bench -run 'Instance' -concurrency 32
+//
+// To compare the results between releases, run the benchmarks multiple times on each release and compare
+// them with benchstat:
+//
+//	go run ./internal/bench -count 10 > new.txt
+//	benchstat old.txt new.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+)
+
+var (
+	runFlag         = flag.String("run", ".", "regular expression to select the benchmarks to run")
+	concurrencyFlag = flag.Int("concurrency", runtime.GOMAXPROCS(0), "number of goroutines in the concurrent benchmarks, e.g. the number of Envoy workers")
+	iterationsFlag  = flag.Int("iterations", 100000, "number of iterations per goroutine in the latency benchmarks")
+	countFlag       = flag.Int("count", 1, "number of times to run each benchmark")
+)
+
+// benchmark is a single benchmark. Exactly one of bench and latency is set.
+type benchmark struct {
+	name string
+	// bench is run by testing.Benchmark and reports ns/op and allocs/op.
+	bench func(b *testing.B)
+	// latency is run by runLatency and reports the percentiles of the latency of each operation.
+	latency func(concurrency, iterations int) []latencyResult
+}
+
+// benchmarks is the list of all benchmarks.
+var benchmarks []benchmark
+
+// httpFilters is the http filters used by the benchmarks keyed by the config passed to hoststub.HttpFilterInit.
+var httpFilters = map[string]envoy.HttpFilter{}
+
+func init() {
+	envoy.NewHttpFilter = func(config string) envoy.HttpFilter {
+		filter, ok := httpFilters[config]
+		if !ok {
+			panic("unknown filter: " + config)
+		}
+		return filter
+	}
+}
+
+func main() {
+	testing.Init()
+	flag.Parse()
+	run, err := regexp.Compile(*runFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -run: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("goos: %s\ngoarch: %s\ncpu: %d\n", runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+	for _, bm := range benchmarks {
+		if !run.MatchString(bm.name) {
+			continue
+		}
+		for i := 0; i < *countFlag; i++ {
+			runBenchmark(bm)
+		}
+	}
+}
+
+// runBenchmark runs the benchmark once and prints the result.
+func runBenchmark(bm benchmark) {
+	if bm.bench != nil {
+		result := testing.Benchmark(bm.bench)
+		if result.N == 0 {
+			// testing.Benchmark returns the zero result if the benchmark fails, which has been logged to stdout.
+			fmt.Fprintf(os.Stderr, "FAIL: %s\n", bm.name)
+			os.Exit(1)
+		}
+		fmt.Printf("Benchmark%s-%d\t%s\t%s\n", bm.name, runtime.GOMAXPROCS(0), result.String(), result.MemString())
+		return
+	}
+	for _, r := range bm.latency(*concurrencyFlag, *iterationsFlag) {
+		fmt.Printf("Benchmark%s/%s-%d\t%d\t%d p50-ns\t%d p99-ns\t%d p999-ns\t%d max-ns\n",
+			bm.name, r.op, *concurrencyFlag, r.count, r.p50, r.p99, r.p999, r.max)
+	}
+}
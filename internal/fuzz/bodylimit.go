@@ -0,0 +1,248 @@
+//go:build cgo
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/internal/hoststub"
+)
+
+// bodyLimitConfig is the config of the http filter of the BodyLimit suite, which is created for each iteration with
+// currentBodyLimit.
+const bodyLimitConfig = "body_limit"
+
+var currentBodyLimit envoy.BodyLimit
+
+// runBodyLimit streams a random body split into random chunks through a new http filter with a random
+// envoy.BodyLimit in the request or response direction, whose instance buffers the body until the end of stream.
+// The delivered chunks, the emitted body, the local reply and the counters are checked against the model.
+func runBodyLimit(_ uintptr, rng *rand.Rand) ([]string, error) {
+	var log []string
+	response := rng.IntN(2) == 0
+	body := randomBytes(rng, rng.IntN(512))
+	maxSize := 0
+	if rng.IntN(8) != 0 {
+		maxSize = 1 + rng.IntN(512)
+	}
+	limit := envoy.BodyLimit{
+		Action:             envoy.BodyLimitAction(rng.IntN(2)),
+		CheckContentLength: rng.IntN(2) == 0,
+	}
+	if response {
+		limit.MaxResponseBodySize = maxSize
+		limit.MaxRequestBodySize = rng.IntN(2)
+	} else {
+		limit.MaxRequestBodySize = maxSize
+		limit.MaxResponseBodySize = rng.IntN(2)
+	}
+	if rng.IntN(2) == 0 {
+		limit.LocalReplyHeaders = [][2]string{{"content-type", "text/plain"}}
+		limit.LocalReplyBody = randomString(rng, 1+rng.IntN(16))
+	}
+	contentLength := -1
+	switch rng.IntN(4) {
+	case 0:
+	case 1:
+		contentLength = rng.IntN(1024)
+	default:
+		contentLength = len(body)
+	}
+	log = append(log, fmt.Sprintf("response %t, max %d, action %s, check content-length %t, content-length %d, body %s",
+		response, maxSize, limit.Action, limit.CheckContentLength, contentLength, describe(body)))
+
+	currentBodyLimit = limit
+	filter := hoststub.HttpFilterInit(bodyLimitConfig)
+	defer hoststub.HttpFilterDestroy(filter)
+	stream := hoststub.NewStream(hoststub.Buffer{}, hoststub.Buffer{})
+	defer stream.Free()
+	var handler *bodyLimitHttpFilterInstance
+	newBodyLimitHandler = func(h *bodyLimitHttpFilterInstance) { handler = h }
+	instance := hoststub.HttpFilterInstanceInit(filter, stream)
+	newBodyLimitHandler = nil
+
+	var kvs [][2]string
+	if contentLength >= 0 {
+		kvs = append(kvs, [2]string{"content-length", strconv.Itoa(contentLength)})
+	}
+	headers := hoststub.NewHeaders(kvs)
+	defer headers.Free()
+	if response {
+		hoststub.HttpFilterInstanceResponseHeaders(instance, headers, false)
+	} else {
+		hoststub.HttpFilterInstanceRequestHeaders(instance, headers, false)
+	}
+
+	chunks := randomSlices(rng, body)
+	for len(chunks) == 0 || rng.IntN(4) == 0 {
+		chunks = append(chunks, nil)
+	}
+	// buffered is the body buffered in Envoy, which the instance never reads.
+	var emitted, buffered []byte
+	for i, chunk := range chunks {
+		if _, _, _, ok := stream.LocalReply(); ok {
+			// Envoy stops the filter chain after the local reply.
+			break
+		}
+		endOfStream := i == len(chunks)-1
+		log = append(log, fmt.Sprintf("Body(%s, %t)", describe(chunk), endOfStream))
+		event := hoststub.NewBuffer(chunk)
+		var status int
+		if response {
+			status = hoststub.HttpFilterInstanceResponseBody(instance, event, endOfStream)
+		} else {
+			status = hoststub.HttpFilterInstanceRequestBody(instance, event, endOfStream)
+		}
+		if status == int(envoy.RequestBodyStatusStopIterationAndBuffer) {
+			buffered = append(buffered, event.Bytes()...)
+		} else {
+			// Envoy sends the buffered body along with the chunk.
+			emitted = append(append(emitted, buffered...), event.Bytes()...)
+			buffered = nil
+		}
+		event.Free()
+	}
+	hoststub.HttpFilterInstanceDestroy(instance)
+
+	// The model: the limit is exceeded early by the content-length, or by the first chunk beyond the limit.
+	var want struct {
+		headers, exceeded, early bool
+		delivered                []byte
+		emitted                  []byte
+		size                     int
+	}
+	want.headers = true
+	switch {
+	case maxSize > 0 && limit.CheckContentLength && contentLength > maxSize:
+		want.exceeded, want.early, want.size = true, true, contentLength
+		want.headers = limit.Action == envoy.BodyLimitPassThrough
+		if want.headers {
+			want.emitted = body
+		}
+	default:
+		for _, chunk := range chunks {
+			want.size += len(chunk)
+			if maxSize > 0 && want.size > maxSize {
+				want.exceeded = true
+				break
+			}
+			want.delivered = append(want.delivered, chunk...)
+		}
+		if !want.exceeded || limit.Action == envoy.BodyLimitPassThrough {
+			want.emitted = body
+		}
+	}
+	if handler.headers != want.headers {
+		return log, fmt.Errorf("headers handler called %t, want %t", handler.headers, want.headers)
+	}
+	if !bytes.Equal(handler.delivered, want.delivered) {
+		return log, fmt.Errorf("delivered %s, want %s", describe(handler.delivered), describe(want.delivered))
+	}
+	if !bytes.Equal(emitted, want.emitted) {
+		return log, fmt.Errorf("emitted %s, want %s", describe(emitted), describe(want.emitted))
+	}
+
+	status, replyHeaders, replyBody, replied := stream.LocalReply()
+	wantReplied := want.exceeded && limit.Action == envoy.BodyLimitLocalReply
+	if replied != wantReplied {
+		return log, fmt.Errorf("local reply %t, want %t", replied, wantReplied)
+	}
+	if replied {
+		wantStatus, wantBody := 413, "request body too large"
+		if response {
+			wantStatus, wantBody = 502, "response body too large"
+		}
+		if limit.LocalReplyBody != "" {
+			wantBody = limit.LocalReplyBody
+		}
+		if status != wantStatus || string(replyBody) != wantBody ||
+			len(replyHeaders) != len(limit.LocalReplyHeaders) || !slices.Equal(replyHeaders, limit.LocalReplyHeaders) {
+			return log, fmt.Errorf("local reply %d %q %q, want %d %q %q",
+				status, replyHeaders, replyBody, wantStatus, limit.LocalReplyHeaders, wantBody)
+		}
+	}
+
+	wantStats := envoy.BodyLimitStats{}
+	switch {
+	case !want.exceeded:
+	case limit.Action == envoy.BodyLimitPassThrough:
+		wantStats.PassThroughs = 1
+	case want.early:
+		wantStats.LocalReplies, wantStats.EarlyLocalReplies = 1, 1
+	default:
+		wantStats.LocalReplies = 1
+	}
+	state := handler.state
+	size, exceeded := state.RequestBodySize, state.RequestExceeded
+	if response {
+		size, exceeded = state.ResponseBodySize, state.ResponseExceeded
+	}
+	if maxSize == 0 {
+		want.size = 0
+	}
+	if state.Limit.MaxRequestBodySize != limit.MaxRequestBodySize || state.Stats != wantStats ||
+		size != want.size || exceeded != want.exceeded {
+		return log, fmt.Errorf("state %+v, want size %d, exceeded %t, stats %+v", state, want.size, want.exceeded, wantStats)
+	}
+	return log, nil
+}
+
+// newBodyLimitHandler is called with the new instance of the http filter of the BodyLimit suite.
+var newBodyLimitHandler func(h *bodyLimitHttpFilterInstance)
+
+// bodyLimitHttpFilter implements envoy.HttpFilter and envoy.BodyLimitProvider.
+type bodyLimitHttpFilter struct{}
+
+func (bodyLimitHttpFilter) BodyLimit() envoy.BodyLimit { return currentBodyLimit }
+
+func (bodyLimitHttpFilter) NewInstance(e envoy.EnvoyFilterInstance) envoy.HttpFilterInstance {
+	h := &bodyLimitHttpFilterInstance{envoyFilter: e}
+	newBodyLimitHandler(h)
+	return h
+}
+
+func (bodyLimitHttpFilter) Destroy() {}
+
+// bodyLimitHttpFilterInstance buffers the body until the end of stream in both directions, and records the events
+// delivered, and the state of the body limit on Destroy.
+type bodyLimitHttpFilterInstance struct {
+	envoyFilter envoy.EnvoyFilterInstance
+	headers     bool
+	delivered   []byte
+	state       envoy.BodyLimitState
+}
+
+func (h *bodyLimitHttpFilterInstance) RequestHeaders(envoy.RequestHeaders, bool) envoy.RequestHeadersStatus {
+	h.headers = true
+	return envoy.HeadersStatusContinue
+}
+
+func (h *bodyLimitHttpFilterInstance) RequestBody(body envoy.RequestBodyBuffer, endOfStream bool) envoy.RequestBodyStatus {
+	h.delivered = append(h.delivered, body.Copy()...)
+	if endOfStream {
+		return envoy.RequestBodyStatusContinue
+	}
+	return envoy.RequestBodyStatusStopIterationAndBuffer
+}
+
+func (h *bodyLimitHttpFilterInstance) ResponseHeaders(envoy.ResponseHeaders, bool) envoy.ResponseHeadersStatus {
+	h.headers = true
+	return envoy.ResponseHeadersStatusContinue
+}
+
+func (h *bodyLimitHttpFilterInstance) ResponseBody(body envoy.ResponseBodyBuffer, endOfStream bool) envoy.ResponseBodyStatus {
+	h.delivered = append(h.delivered, body.Copy()...)
+	if endOfStream {
+		return envoy.ResponseBodyStatusContinue
+	}
+	return envoy.ResponseBodyStatusStopIterationAndBuffer
+}
+
+func (h *bodyLimitHttpFilterInstance) Destroy() {
+	h.state = h.envoyFilter.BodyLimitState()
+}
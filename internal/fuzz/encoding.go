@@ -0,0 +1,251 @@
+//go:build cgo
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/internal/hoststub"
+)
+
+// encodings are the content-encoding headers of the Encoding suite. br is not registered, so the body passes through.
+var encodings = []string{"", "identity", "gzip", "x-gzip", "GZIP", "deflate", "br"}
+
+var encodedBodyModes = []string{
+	envoy.EncodedBodyModeInspect:  "Inspect",
+	envoy.EncodedBodyModeDecode:   "Decode",
+	envoy.EncodedBodyModeReencode: "Reencode",
+}
+
+// runEncoding streams a random encoded body split into random chunks through envoy.EncodedBody in the response body
+// events of a new stream, and checks the emitted body and the headers. The callback upper-cases the decoded bytes,
+// which gives the same output regardless of how the body is split.
+func runEncoding(filter uintptr, rng *rand.Rand) ([]string, error) {
+	var log []string
+	encoding := encodings[rng.IntN(len(encodings))]
+	config := envoy.EncodedBodyConfig{Mode: envoy.EncodedBodyMode(rng.IntN(3)), Streaming: rng.IntN(2) == 0}
+	body := []byte(randomString(rng, rng.IntN(256)))
+	if rng.IntN(8) == 0 {
+		body = []byte(strings.Repeat(randomString(rng, 1+rng.IntN(64)), 1+rng.IntN(4096)))
+	}
+	supported := encoding != "br"
+	tooLarge := len(body) > 0 && rng.IntN(8) == 0
+	if tooLarge {
+		config.MaxDecodedSize = rng.IntN(len(body))
+		tooLarge = config.MaxDecodedSize > 0
+	}
+	encoded := encode(encoding, body)
+	coded := supported && encoding != "" && encoding != "identity"
+	corrupted := coded && rng.IntN(8) == 0
+	if corrupted {
+		if rng.IntN(2) == 0 {
+			encoded = encoded[:rng.IntN(len(encoded))]
+		} else {
+			encoded = append(encoded, "trailing"...)
+		}
+	}
+	log = append(log, fmt.Sprintf("encoding %q, mode %s, streaming %t, max %d, corrupted %t, body %s",
+		encoding, encodedBodyModes[config.Mode], config.Streaming, config.MaxDecodedSize, corrupted, describe(body)))
+
+	buffered := hoststub.NewBuffer()
+	defer buffered.Free()
+	stream := hoststub.NewStream(hoststub.Buffer{}, buffered)
+	defer stream.Free()
+	var handler *encodingHandler
+	newResponseHandler = func(e envoy.EnvoyFilterInstance) responseHandler {
+		handler = &encodingHandler{body: envoy.NewEncodedBody(e, config), mode: config.Mode}
+		return handler
+	}
+	instance := hoststub.HttpFilterInstanceInit(filter, stream)
+	newResponseHandler = nil
+	defer hoststub.HttpFilterInstanceDestroy(instance)
+	defer handler.body.Close()
+
+	var kvs [][2]string
+	if encoding != "" {
+		kvs = append(kvs, [2]string{"content-encoding", encoding})
+	}
+	kvs = append(kvs, [2]string{"content-length", strconv.Itoa(len(encoded))})
+	headers := hoststub.NewHeaders(kvs)
+	defer headers.Free()
+	hoststub.HttpFilterInstanceResponseHeaders(instance, headers, false)
+	if handler.prepared != supported {
+		return log, fmt.Errorf("PrepareResponseHeaders() = %t, want %t", handler.prepared, supported)
+	}
+	wantHeaders := kvs
+	switch {
+	case !supported || config.Mode == envoy.EncodedBodyModeInspect:
+	case config.Mode == envoy.EncodedBodyModeDecode:
+		wantHeaders = nil
+	case encoding != "":
+		wantHeaders = kvs[:1]
+	default:
+		wantHeaders = nil
+	}
+	if got := headers.All(); len(got) != len(wantHeaders) || len(got) > 0 && !slices.Equal(got, wantHeaders) {
+		return log, fmt.Errorf("headers = %q, want %q", got, wantHeaders)
+	}
+
+	chunks := randomSlices(rng, encoded)
+	for len(chunks) == 0 || rng.IntN(4) == 0 {
+		chunks = append(chunks, nil)
+	}
+	var emitted []byte
+	for i, chunk := range chunks {
+		endOfStream := i == len(chunks)-1
+		log = append(log, fmt.Sprintf("ResponseBody(%s, %t)", describe(chunk), endOfStream))
+		event := hoststub.NewBuffer(chunk)
+		status := hoststub.HttpFilterInstanceResponseBody(instance, event, endOfStream)
+		if status == int(envoy.ResponseBodyStatusStopIterationAndBuffer) {
+			if config.Streaming || endOfStream || !supported {
+				event.Free()
+				return log, fmt.Errorf("ResponseBody() = StopIterationAndBuffer in streaming %t, end of stream %t",
+					config.Streaming, endOfStream)
+			}
+			// Envoy buffers the chunk, which is emitted with the chunk of the end of stream.
+			buffered.Append(event.Bytes())
+		} else {
+			emitted = append(append(emitted, buffered.Bytes()...), event.Bytes()...)
+		}
+		event.Free()
+	}
+	if handler.callbackErr != nil {
+		return log, handler.callbackErr
+	}
+
+	switch {
+	case corrupted:
+		if handler.err == nil {
+			return log, errors.New("no error for the corrupted body")
+		}
+		return log, nil
+	case tooLarge && supported:
+		if !errors.Is(handler.err, envoy.ErrDecodedBodyTooLarge) {
+			return log, fmt.Errorf("error = %v, want ErrDecodedBodyTooLarge", handler.err)
+		}
+		return log, nil
+	case handler.err != nil:
+		return log, fmt.Errorf("error = %v", handler.err)
+	case !supported:
+		if handler.calls > 0 || !bytes.Equal(emitted, encoded) {
+			return log, fmt.Errorf("unsupported encoding: %d calls, emitted %s", handler.calls, describe(emitted))
+		}
+		return log, nil
+	case !handler.eos:
+		return log, errors.New("callback is not called with the end of stream")
+	case config.Mode == envoy.EncodedBodyModeInspect:
+		if !bytes.Equal(handler.decoded, body) {
+			return log, fmt.Errorf("inspected %s, want %s", describe(handler.decoded), describe(body))
+		}
+		if !bytes.Equal(emitted, encoded) {
+			return log, fmt.Errorf("emitted %s, want the original", describe(emitted))
+		}
+		return log, nil
+	}
+	want := bytes.ToUpper(body)
+	if config.Mode == envoy.EncodedBodyModeReencode && coded {
+		decoded, err := decode(encoding, emitted)
+		if err != nil {
+			return log, fmt.Errorf("decoding emitted %s: %v", describe(emitted), err)
+		}
+		emitted = decoded
+	}
+	if !bytes.Equal(emitted, want) {
+		return log, fmt.Errorf("emitted %s, want %s", describe(emitted), describe(want))
+	}
+	return log, nil
+}
+
+// encodingHandler processes the response body with envoy.EncodedBody, and records the results.
+type encodingHandler struct {
+	body     *envoy.EncodedBody
+	mode     envoy.EncodedBodyMode
+	prepared bool
+	// decoded is the concatenation of the decoded bytes passed to the callback, which is called calls times.
+	decoded []byte
+	calls   int
+	// eos is true after the callback is called with the end of stream.
+	eos bool
+	// err is the first error returned by EncodedBody, and callbackErr is the misuse of the callback.
+	err, callbackErr error
+}
+
+func (h *encodingHandler) ResponseHeaders(headers envoy.ResponseHeaders, _ bool) envoy.ResponseHeadersStatus {
+	h.prepared = h.body.PrepareResponseHeaders(headers)
+	return envoy.ResponseHeadersStatusContinue
+}
+
+func (h *encodingHandler) ResponseBody(body envoy.ResponseBodyBuffer, endOfStream bool) envoy.ResponseBodyStatus {
+	var status envoy.ResponseBodyStatus
+	var err error
+	if h.mode == envoy.EncodedBodyModeInspect {
+		status, err = h.body.InspectResponseBody(body, endOfStream, func(decoded []byte, endOfStream bool) {
+			h.callback(decoded, endOfStream)
+		})
+	} else {
+		status, err = h.body.TransformResponseBody(body, endOfStream, func(decoded []byte, endOfStream bool) []byte {
+			h.callback(decoded, endOfStream)
+			return bytes.ToUpper(decoded)
+		})
+	}
+	if h.err == nil {
+		h.err = err
+	}
+	return status
+}
+
+func (h *encodingHandler) callback(decoded []byte, endOfStream bool) {
+	switch {
+	case h.eos:
+		h.callbackErr = errors.New("callback is called after the end of stream")
+	case len(decoded) == 0 && !endOfStream:
+		h.callbackErr = errors.New("callback is called with empty bytes before the end of stream")
+	}
+	h.decoded = append(h.decoded, decoded...)
+	h.calls++
+	h.eos = endOfStream
+}
+
+// encode encodes the body in the reference way.
+func encode(encoding string, body []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch strings.ToLower(encoding) {
+	case "gzip", "x-gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	default:
+		return slices.Clone(body)
+	}
+	_, _ = w.Write(body)
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+// decode decodes the body in the reference way.
+func decode(encoding string, body []byte) ([]byte, error) {
+	var r io.ReadCloser
+	var err error
+	switch strings.ToLower(encoding) {
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	default:
+		r, err = zlib.NewReader(bytes.NewReader(body))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,506 @@
+//go:build cgo
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/internal/hoststub"
+)
+
+// jsonKeys are the member names of the random documents and the paths, so that the paths often match.
+var jsonKeys = []string{"a", "b", "id", "0", "1", "x.y", "é"}
+
+// jsonSetValues are the values of the random JSONEditSet.
+var jsonSetValues = []any{nil, 1, "s<&>", true, []int{1, 2}, map[string]any{"k": "v", "a": 0.5}}
+
+// jsonRedactPatterns are the patterns of the random JSONEditRedact, where the empty one means nil.
+var jsonRedactPatterns = []string{"", `a`, `\d+`, `[^x]`, `é+`, `^$`}
+
+// jsonContentTypes are the content-type headers of the JSON suite, where the first two are JSON.
+var jsonContentTypes = []string{"application/json", "application/problem+json; charset=utf-8", "text/plain", ""}
+
+// runJSON edits a random JSON document with random edits by envoy.JSONEditor.Edit, and by envoy.JSONBodyEditor in
+// the response body events of a new stream in the buffered or the streaming mode with the document split into random
+// chunks. The results are checked against the reference editor on the tree of the document. The document is
+// occasionally corrupted, and then the editors must fail iff the document is not valid.
+func runJSON(filter uintptr, rng *rand.Rand) ([]string, error) {
+	var log []string
+	edits := make([]envoy.JSONEdit, rng.IntN(6))
+	for i := range edits {
+		edits[i] = randomJSONEdit(rng)
+		log = append(log, fmt.Sprintf("edit %s", describeJSONEdit(edits[i])))
+	}
+	editor, err := envoy.NewJSONEditor(edits...)
+	if err != nil {
+		return log, fmt.Errorf("NewJSONEditor() = %v", err)
+	}
+
+	var doc []byte
+	var want []byte
+	root := randomJSONNode(rng, 0)
+	switch rng.IntN(16) {
+	case 0:
+		doc = []byte(strings.Repeat(" \n", rng.IntN(3)))
+	default:
+		doc = root.write(nil, rng)
+		want = jsonReference{edits: edits}.edit(root, nil, nil)
+	}
+	corrupted := rng.IntN(8) == 0
+	if corrupted {
+		doc = corruptJSON(rng, doc)
+	}
+	wantErr := !json.Valid(doc) && len(bytes.Trim(doc, " \t\n\r")) > 0
+	log = append(log, fmt.Sprintf("corrupted %t, document %s", corrupted, describe(doc)))
+	if corrupted && !wantErr {
+		// The corrupted document may still be valid, but it's no longer the tree.
+		want = nil
+	}
+
+	got, err := editor.Edit(doc)
+	switch {
+	case (err != nil) != wantErr:
+		return log, fmt.Errorf("Edit() = %v, want error %t", err, wantErr)
+	case !corrupted && !bytes.Equal(got, want):
+		return log, fmt.Errorf("Edit() = %s, want %s", describe(got), describe(want))
+	}
+	if err == nil {
+		want = slices.Clone(got)
+	}
+
+	contentType := jsonContentTypes[rng.IntN(len(jsonContentTypes))]
+	contentEncoding := ""
+	if rng.IntN(8) == 0 {
+		contentEncoding = "gzip"
+	}
+	streaming := rng.IntN(2) == 0
+	enabled := strings.Contains(contentType, "json") && contentEncoding == ""
+	log = append(log, fmt.Sprintf("content-type %q, content-encoding %q, streaming %t",
+		contentType, contentEncoding, streaming))
+
+	buffered := hoststub.NewBuffer()
+	defer buffered.Free()
+	stream := hoststub.NewStream(hoststub.Buffer{}, buffered)
+	defer stream.Free()
+	var handler *jsonHandler
+	newResponseHandler = func(e envoy.EnvoyFilterInstance) responseHandler {
+		handler = &jsonHandler{editor: editor.NewBodyEditor(e, streaming)}
+		return handler
+	}
+	instance := hoststub.HttpFilterInstanceInit(filter, stream)
+	newResponseHandler = nil
+	defer hoststub.HttpFilterInstanceDestroy(instance)
+
+	kvs := [][2]string{{"content-length", strconv.Itoa(len(doc))}}
+	if contentType != "" {
+		kvs = append(kvs, [2]string{"content-type", contentType})
+	}
+	if contentEncoding != "" {
+		kvs = append(kvs, [2]string{"content-encoding", contentEncoding})
+	}
+	headers := hoststub.NewHeaders(kvs)
+	defer headers.Free()
+	hoststub.HttpFilterInstanceResponseHeaders(instance, headers, false)
+	wantHeaders := kvs
+	if enabled {
+		wantHeaders = kvs[1:]
+	}
+	if handler.prepared != enabled {
+		return log, fmt.Errorf("PrepareResponseHeaders() = %t, want %t", handler.prepared, enabled)
+	}
+	if got := headers.All(); len(got) != len(wantHeaders) || len(got) > 0 && !slices.Equal(got, wantHeaders) {
+		return log, fmt.Errorf("headers = %q, want %q", got, wantHeaders)
+	}
+
+	chunks := randomSlices(rng, doc)
+	for len(chunks) == 0 || rng.IntN(4) == 0 {
+		chunks = append(chunks, nil)
+	}
+	var emitted []byte
+	for i, chunk := range chunks {
+		endOfStream := i == len(chunks)-1
+		log = append(log, fmt.Sprintf("ResponseBody(%s, %t)", describe(chunk), endOfStream))
+		event := hoststub.NewBuffer(chunk)
+		status := hoststub.HttpFilterInstanceResponseBody(instance, event, endOfStream)
+		if status == int(envoy.ResponseBodyStatusStopIterationAndBuffer) {
+			if streaming || endOfStream || !enabled {
+				event.Free()
+				return log, fmt.Errorf("ResponseBody() = StopIterationAndBuffer in streaming %t, end of stream %t",
+					streaming, endOfStream)
+			}
+			buffered.Append(event.Bytes())
+		} else {
+			emitted = append(append(emitted, buffered.Bytes()...), event.Bytes()...)
+		}
+		event.Free()
+	}
+
+	switch {
+	case !enabled:
+		if handler.err != nil || !bytes.Equal(emitted, doc) {
+			return log, fmt.Errorf("disabled: error %v, emitted %s", handler.err, describe(emitted))
+		}
+	case (handler.err != nil) != wantErr:
+		return log, fmt.Errorf("error = %v, want error %t", handler.err, wantErr)
+	case wantErr:
+		if !streaming && !bytes.Equal(emitted, doc) {
+			return log, fmt.Errorf("emitted %s, want the original on error", describe(emitted))
+		}
+	case !bytes.Equal(emitted, want):
+		return log, fmt.Errorf("emitted %s, want %s", describe(emitted), describe(want))
+	}
+	return log, nil
+}
+
+// jsonHandler edits the response body with envoy.JSONBodyEditor, and records the results.
+type jsonHandler struct {
+	editor   *envoy.JSONBodyEditor
+	prepared bool
+	// err is the first error returned by JSONBodyEditor.
+	err error
+}
+
+func (h *jsonHandler) ResponseHeaders(headers envoy.ResponseHeaders, _ bool) envoy.ResponseHeadersStatus {
+	h.prepared = h.editor.PrepareResponseHeaders(headers)
+	return envoy.ResponseHeadersStatusContinue
+}
+
+func (h *jsonHandler) ResponseBody(body envoy.ResponseBodyBuffer, endOfStream bool) envoy.ResponseBodyStatus {
+	status, err := h.editor.EditResponseBody(body, endOfStream)
+	if h.err == nil {
+		h.err = err
+	}
+	return status
+}
+
+func randomJSONEdit(rng *rand.Rand) envoy.JSONEdit {
+	segments := make([]string, 1+rng.IntN(3))
+	for i := range segments {
+		switch rng.IntN(8) {
+		case 0:
+			segments[i] = "*"
+		case 1:
+			segments[i] = "**"
+		default:
+			segments[i] = strings.ReplaceAll(jsonKeys[rng.IntN(len(jsonKeys))], ".", `\.`)
+		}
+	}
+	e := envoy.JSONEdit{Op: envoy.JSONEditOp(rng.IntN(4)), Path: strings.Join(segments, ".")}
+	switch e.Op {
+	case envoy.JSONEditSet:
+		e.Value = jsonSetValues[rng.IntN(len(jsonSetValues))]
+	case envoy.JSONEditRename:
+		e.To = jsonKeys[rng.IntN(len(jsonKeys))] + "_"
+	case envoy.JSONEditRedact:
+		if pattern := jsonRedactPatterns[rng.IntN(len(jsonRedactPatterns))]; pattern != "" {
+			e.Pattern = regexp.MustCompile(pattern)
+		}
+		e.Replacement = []string{"*", "", "<$0$0>"}[rng.IntN(3)]
+	}
+	return e
+}
+
+func describeJSONEdit(e envoy.JSONEdit) string {
+	op := []string{"Set", "Delete", "Rename", "Redact"}[e.Op]
+	return fmt.Sprintf("%s %q value %v to %q pattern %v replacement %q", op, e.Path, e.Value, e.To, e.Pattern, e.Replacement)
+}
+
+// jsonNode is a node of the tree of a random document, which keeps the raw tokens as written.
+type jsonNode struct {
+	// kind is one of '{', '[', '"' and 'l' for the literals.
+	kind     byte
+	raw      []byte
+	members  []jsonMember
+	elements []*jsonNode
+}
+
+type jsonMember struct {
+	key   string
+	raw   []byte
+	value *jsonNode
+}
+
+func randomJSONNode(rng *rand.Rand, depth int) *jsonNode {
+	kind := "{[\"l"[rng.IntN(4)]
+	if depth >= 4 {
+		kind = "\"l"[rng.IntN(2)]
+	}
+	n := &jsonNode{kind: kind}
+	switch kind {
+	case '{':
+		for i := rng.IntN(4); i > 0; i-- {
+			key := jsonKeys[rng.IntN(len(jsonKeys))]
+			n.members = append(n.members, jsonMember{key: key, raw: rawJSONString(rng, key), value: randomJSONNode(rng, depth+1)})
+		}
+	case '[':
+		for i := rng.IntN(4); i > 0; i-- {
+			n.elements = append(n.elements, randomJSONNode(rng, depth+1))
+		}
+	case '"':
+		n.raw = rawJSONString(rng, []string{"", "a1", "xé", "12 ax", "\u2028\"\\/\t", jsonKeys[rng.IntN(len(jsonKeys))]}[rng.IntN(6)])
+	default:
+		n.raw = []byte([]string{"true", "false", "null", "0", "-12", "3.5e-7", "1E+2"}[rng.IntN(7)])
+	}
+	return n
+}
+
+// rawJSONString encodes s as a JSON string, escaping the characters randomly.
+func rawJSONString(rng *rand.Rand, s string) []byte {
+	ret := []byte{'"'}
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			ret = append(ret, '\\', byte(r))
+		case r < 0x20 || rng.IntN(8) == 0:
+			if r > 0xffff {
+				panic("no surrogates in the test strings")
+			}
+			ret = append(ret, fmt.Sprintf(`\u%04x`, r)...)
+		case r == '/' && rng.IntN(2) == 0:
+			ret = append(ret, '\\', '/')
+		default:
+			ret = append(ret, string(r)...)
+		}
+	}
+	return append(ret, '"')
+}
+
+// write appends the document of the node with random whitespace between the tokens.
+func (n *jsonNode) write(b []byte, rng *rand.Rand) []byte {
+	space := func() {
+		for i := rng.IntN(4); i > 0 && rng.IntN(2) == 0; i-- {
+			b = append(b, " \t\n\r"[rng.IntN(4)])
+		}
+	}
+	space()
+	switch n.kind {
+	case '{':
+		b = append(b, '{')
+		for i, m := range n.members {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			space()
+			b = append(b, m.raw...)
+			space()
+			b = append(b, ':')
+			b = m.value.write(b, rng)
+		}
+		space()
+		b = append(b, '}')
+	case '[':
+		b = append(b, '[')
+		for i, e := range n.elements {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = e.write(b, rng)
+		}
+		space()
+		b = append(b, ']')
+	default:
+		b = append(b, n.raw...)
+	}
+	space()
+	return b
+}
+
+// corruptJSON truncates the document, or inserts or replaces a byte.
+func corruptJSON(rng *rand.Rand, doc []byte) []byte {
+	i := rng.IntN(len(doc) + 1)
+	c := "{}[],:\"\\ex0-\x01"[rng.IntN(13)]
+	switch rng.IntN(3) {
+	case 0:
+		return doc[:i]
+	case 1:
+		return slices.Insert(slices.Clone(doc), i, c)
+	}
+	if i == len(doc) {
+		return append(slices.Clone(doc), c)
+	}
+	doc = slices.Clone(doc)
+	doc[i] = c
+	return doc
+}
+
+// jsonStep is a member name or an index of the path of a node in the reference.
+type jsonStep struct {
+	key   string
+	index int
+	array bool
+}
+
+// jsonReference edits the tree of the document in the straightforward way.
+type jsonReference struct {
+	edits []envoy.JSONEdit
+}
+
+// edit returns the compact edited node at the path, where redact is the JSONEditRedact of the strings in the node.
+func (r jsonReference) edit(n *jsonNode, path []jsonStep, redact *envoy.JSONEdit) []byte {
+	switch n.kind {
+	case '{':
+		b := []byte{'{'}
+		var items [][]byte
+		for _, m := range n.members {
+			if item := r.child(m.value, append(path[:len(path):len(path)], jsonStep{key: m.key}), m.raw, redact); item != nil {
+				items = append(items, item)
+			}
+		}
+		for _, key := range r.createKeys(path) {
+			if !slices.ContainsFunc(n.members, func(m jsonMember) bool { return m.key == key }) {
+				name, _ := json.Marshal(key)
+				items = append(items, append(append(name, ':'), r.createValue(path, key)...))
+			}
+		}
+		b = append(b, bytes.Join(items, []byte{','})...)
+		return append(b, '}')
+	case '[':
+		var items [][]byte
+		for i, e := range n.elements {
+			if item := r.child(e, append(path[:len(path):len(path)], jsonStep{index: i, array: true}), nil, redact); item != nil {
+				items = append(items, item)
+			}
+		}
+		return append(append([]byte{'['}, bytes.Join(items, []byte{','})...), ']')
+	case '"':
+		if redact == nil {
+			return n.raw
+		}
+		var s string
+		_ = json.Unmarshal(n.raw, &s)
+		if redacted := redact.Pattern.ReplaceAllString(s, redact.Replacement); redacted != s {
+			b, _ := json.Marshal(redacted)
+			return b
+		}
+		return n.raw
+	}
+	return n.raw
+}
+
+// child returns the member or the element edited, or nil if deleted.
+func (r jsonReference) child(n *jsonNode, path []jsonStep, rawKey []byte, redact *envoy.JSONEdit) []byte {
+	var action, rename *envoy.JSONEdit
+	for i := range r.edits {
+		e := &r.edits[i]
+		if !referenceMatch(splitJSONPath(e.Path), path) {
+			continue
+		}
+		if e.Op == envoy.JSONEditRename {
+			if rename == nil && rawKey != nil {
+				rename = e
+			}
+		} else if action == nil {
+			action = e
+		}
+	}
+	var value []byte
+	switch {
+	case action == nil:
+		value = r.edit(n, path, redact)
+	case action.Op == envoy.JSONEditDelete:
+		return nil
+	case action.Op == envoy.JSONEditSet:
+		value, _ = json.Marshal(action.Value)
+	case action.Pattern == nil:
+		value, _ = json.Marshal(action.Replacement)
+	default:
+		value = r.edit(n, path, action)
+	}
+	if rawKey == nil {
+		return value
+	}
+	if rename != nil {
+		rawKey, _ = json.Marshal(rename.To)
+	}
+	return append(append(slices.Clone(rawKey), ':'), value...)
+}
+
+// createKeys returns the member names that the literal JSONEditSet adds to the object at the path if missing.
+func (r jsonReference) createKeys(path []jsonStep) []string {
+	var keys []string
+	for _, e := range r.edits {
+		segments := splitJSONPath(e.Path)
+		if e.Op != envoy.JSONEditSet || slices.Contains(segments, "*") || slices.Contains(segments, "**") ||
+			len(segments) <= len(path) || !referenceMatch(segments[:len(path)], path) {
+			continue
+		}
+		if !slices.Contains(keys, segments[len(path)]) {
+			keys = append(keys, segments[len(path)])
+		}
+	}
+	return keys
+}
+
+// createValue returns the value of the missing member key of the object at the path, whose descendants are created
+// by the literal JSONEditSet under it.
+func (r jsonReference) createValue(path []jsonStep, key string) []byte {
+	var prefix []string
+	for _, s := range path {
+		if s.array {
+			prefix = append(prefix, strconv.Itoa(s.index))
+		} else {
+			prefix = append(prefix, s.key)
+		}
+	}
+	prefix = append(prefix, key)
+	for _, e := range r.edits {
+		if segments := splitJSONPath(e.Path); e.Op == envoy.JSONEditSet && slices.Equal(segments, prefix) {
+			b, _ := json.Marshal(e.Value)
+			return b
+		}
+	}
+	var items [][]byte
+	child := append(path[:len(path):len(path)], jsonStep{key: key})
+	for _, k := range r.createKeys(child) {
+		name, _ := json.Marshal(k)
+		items = append(items, append(append(name, ':'), r.createValue(child, k)...))
+	}
+	return append(append([]byte{'{'}, bytes.Join(items, []byte{','})...), '}')
+}
+
+// splitJSONPath splits the path at the unescaped dots, and unescapes the segments.
+func splitJSONPath(path string) []string {
+	var ret []string
+	var cur []byte
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\':
+			i++
+			cur = append(cur, path[i])
+		case path[i] == '.':
+			ret, cur = append(ret, string(cur)), nil
+		default:
+			cur = append(cur, path[i])
+		}
+	}
+	return append(ret, string(cur))
+}
+
+// referenceMatch returns true if the segments match the path, trying every split of "**".
+func referenceMatch(segments []string, path []jsonStep) bool {
+	if len(segments) == 0 {
+		return len(path) == 0
+	}
+	switch s := segments[0]; {
+	case s == "**":
+		for i := 0; i <= len(path); i++ {
+			if referenceMatch(segments[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	case len(path) == 0:
+		return false
+	case s == "*", !path[0].array && s == path[0].key, path[0].array && s == strconv.Itoa(path[0].index):
+		return referenceMatch(segments[1:], path[1:])
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+//go:build cgo
+
+// Command fuzz runs the randomized differential tests of the body buffer and the local reply APIs against the host
+// stub in internal/hoststub, which calls the event hooks through cgo in the same way as Envoy does.
+//
+// The suites are:
+//
+//   - Buffer: each iteration creates a stream with random request and response bodies, and runs a random program of
+//     the operations such as ReadAt, Drain and Replace on the body buffers in the body event. The results are checked
+//     against the reference model of a byte slice, and the contents of the buffers of the host stub are checked after
+//     the event. The host stub aborts on the misuse of the Envoy API, e.g. copying out or draining beyond the buffer.
+//   - Rewrite: each iteration streams a random body split into random chunks through envoy.BodyRewriter with random
+//     replacements, and checks the output against the brute-force reference or regexp.Regexp.ReplaceAll.
+//   - Encoding: each iteration streams a random body encoded with a random content-coding, optionally corrupted,
+//     through envoy.EncodedBody in a random mode, and checks the emitted body and the headers.
+//   - JSON: each iteration edits a random JSON document, optionally corrupted, with random path-based edits by
+//     envoy.JSONEditor and envoy.JSONBodyEditor in the buffered or the streaming mode, and checks the output against
+//     the reference editor on the tree of the document.
+//   - BodyLimit: each iteration streams a random body through a new http filter with a random envoy.BodyLimit, and
+//     checks the body events delivered to the instance, the emitted body, the local reply and the counters.
+//
+// This is a command instead of the Go fuzz tests as the event hooks are only exported to C in the cgo build.
+// A failure prints the seed and the iteration to reproduce it:
+//
+//	go run ./internal/fuzz -run Buffer -seed 1234 -iteration 567
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/internal/hoststub"
+)
+
+var (
+	runFlag        = flag.String("run", ".", "regular expression to select the suites to run")
+	seedFlag       = flag.Uint64("seed", uint64(time.Now().UnixNano()), "seed of the random programs")
+	iterationsFlag = flag.Int("iterations", 20000, "number of the iterations, each of which runs a program on a new stream")
+	iterationFlag  = flag.Int("iteration", -1, "if non-negative, runs only the iteration, e.g. to reproduce a failure")
+	opsFlag        = flag.Int("ops", 32, "maximum number of the operations per program")
+)
+
+// suite is a fuzz suite, whose run runs an iteration and returns the failure.
+type suite struct {
+	name string
+	run  func(filter uintptr, rng *rand.Rand) (log []string, err error)
+}
+
+var suites = []suite{
+	{name: "Buffer", run: runBuffer},
+	{name: "Rewrite", run: runRewrite},
+	{name: "Encoding", run: runEncoding},
+	{name: "JSON", run: runJSON},
+	{name: "BodyLimit", run: runBodyLimit},
+}
+
+// currentProgram is run in the body events of the http filter instance of the current iteration.
+var currentProgram *program
+
+// newResponseHandler creates the handler of the response events of the http filter instance of the current
+// iteration if set, instead of running currentProgram.
+var newResponseHandler func(e envoy.EnvoyFilterInstance) responseHandler
+
+type responseHandler interface {
+	envoy.ResponseHeadersHandler
+	envoy.ResponseBodyHandler
+}
+
+func init() {
+	envoy.NewHttpFilter = func(config string) envoy.HttpFilter {
+		if config == bodyLimitConfig {
+			return bodyLimitHttpFilter{}
+		}
+		return fuzzHttpFilter{}
+	}
+}
+
+func main() {
+	flag.Parse()
+	run, err := regexp.Compile(*runFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -run: %v\n", err)
+		os.Exit(1)
+	}
+	filter := hoststub.HttpFilterInit("fuzz")
+	defer hoststub.HttpFilterDestroy(filter)
+
+	first, last := 0, *iterationsFlag
+	if *iterationFlag >= 0 {
+		first, last = *iterationFlag, *iterationFlag+1
+	}
+	for _, s := range suites {
+		if !run.MatchString(s.name) {
+			continue
+		}
+		var ops int
+		for i := first; i < last; i++ {
+			log, err := s.run(filter, rand.New(rand.NewPCG(*seedFlag, uint64(i))))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "FAIL: %s: seed %d, iteration %d: %v\n", s.name, *seedFlag, i, err)
+				fmt.Fprintf(os.Stderr, "operations:\n\t%s\n", strings.Join(log, "\n\t"))
+				os.Exit(1)
+			}
+			ops += len(log)
+		}
+		fmt.Printf("ok: %s: seed %d, %d iterations, %d operations\n", s.name, *seedFlag, last-first, ops)
+	}
+}
+
+// runBuffer runs a random program on a new stream in the request or response body event.
+func runBuffer(filter uintptr, rng *rand.Rand) ([]string, error) {
+	p := &program{
+		rng:      rng,
+		ops:      1 + rng.IntN(*opsFlag),
+		event:    randomData(rng),
+		request:  randomData(rng),
+		response: randomData(rng),
+	}
+	event := hoststub.NewBuffer(randomSlices(rng, p.event)...)
+	defer event.Free()
+	request := hoststub.NewBuffer(randomSlices(rng, p.request)...)
+	defer request.Free()
+	response := hoststub.NewBuffer(randomSlices(rng, p.response)...)
+	defer response.Free()
+	stream := hoststub.NewStream(request, response)
+	defer stream.Free()
+
+	instance := hoststub.HttpFilterInstanceInit(filter, stream)
+	defer hoststub.HttpFilterInstanceDestroy(instance)
+	currentProgram = p
+	if p.responseEvent = rng.IntN(2) == 1; p.responseEvent {
+		hoststub.HttpFilterInstanceResponseBody(instance, event, false)
+	} else {
+		hoststub.HttpFilterInstanceRequestBody(instance, event, false)
+	}
+	currentProgram = nil
+	if p.err == nil {
+		p.verifyHost(stream, event, request, response)
+	}
+	return p.log, p.err
+}
+
+// fuzzHttpFilter implements envoy.HttpFilter, and its instances run the current program or response handler in the
+// events.
+type fuzzHttpFilter struct{}
+
+func (fuzzHttpFilter) NewInstance(e envoy.EnvoyFilterInstance) envoy.HttpFilterInstance {
+	h := &fuzzHttpFilterInstance{envoyFilter: e}
+	if newResponseHandler != nil {
+		h.response = newResponseHandler(e)
+	}
+	return h
+}
+
+func (fuzzHttpFilter) Destroy() {}
+
+type fuzzHttpFilterInstance struct {
+	envoyFilter envoy.EnvoyFilterInstance
+	response    responseHandler
+}
+
+func (h *fuzzHttpFilterInstance) RequestBody(body envoy.RequestBodyBuffer, _ bool) envoy.RequestBodyStatus {
+	currentProgram.run(h.envoyFilter, body)
+	return envoy.RequestBodyStatusContinue
+}
+
+func (h *fuzzHttpFilterInstance) ResponseHeaders(headers envoy.ResponseHeaders, endOfStream bool) envoy.ResponseHeadersStatus {
+	return h.response.ResponseHeaders(headers, endOfStream)
+}
+
+func (h *fuzzHttpFilterInstance) ResponseBody(body envoy.ResponseBodyBuffer, endOfStream bool) envoy.ResponseBodyStatus {
+	if h.response != nil {
+		return h.response.ResponseBody(body, endOfStream)
+	}
+	currentProgram.run(h.envoyFilter, body)
+	return envoy.ResponseBodyStatusContinue
+}
+
+func (*fuzzHttpFilterInstance) Destroy() {}
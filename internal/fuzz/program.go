@@ -0,0 +1,247 @@
+//go:build cgo
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/internal/hoststub"
+)
+
+// bodyBuffer is the common interface of envoy.RequestBodyBuffer and envoy.ResponseBodyBuffer.
+type bodyBuffer interface {
+	io.ReaderAt
+	Length() int
+	Copy() []byte
+	Slices(iter func(view []byte))
+	Append(data []byte)
+	Prepend(data []byte)
+	Drain(length int)
+	Replace(data []byte)
+}
+
+// target is a body buffer under test and its reference model.
+type target struct {
+	name   string
+	buffer bodyBuffer
+	model  *[]byte
+}
+
+// program is a random sequence of the operations run in the body event of a stream.
+type program struct {
+	rng *rand.Rand
+	// ops is the number of the operations on the body buffers.
+	ops int
+	// responseEvent is true if the program is run in the response body event.
+	responseEvent bool
+	// event, request and response are the reference models of the body passed to the event, and the buffered
+	// request and response bodies of the stream.
+	event, request, response []byte
+	// localReply is the arguments of EnvoyFilterInstance.SendResponse if sent.
+	localReply *localReply
+	// log is the operations run so far, which is printed on failure.
+	log []string
+	err error
+}
+
+type localReply struct {
+	statusCode int
+	headers    [][2]string
+	body       []byte
+}
+
+// run runs the operations on the body buffers in the body event, and optionally sends the local reply at the end.
+func (p *program) run(e envoy.EnvoyFilterInstance, event bodyBuffer) {
+	request, response := e.GetRequestBodyBuffer(), e.GetResponseBodyBuffer()
+	targets := []target{
+		{name: "event", buffer: event, model: &p.event},
+		{name: "request", buffer: request, model: &p.request},
+		{name: "response", buffer: response, model: &p.response},
+	}
+	for i := 0; i < p.ops && p.err == nil; i++ {
+		p.step(targets[p.rng.IntN(len(targets))])
+		// Checking all the buffers after each operation catches the operations on the wrong buffer.
+		for _, t := range targets {
+			p.check(t)
+		}
+	}
+	if p.err == nil && p.rng.IntN(4) == 0 {
+		p.sendLocalReply(e)
+	}
+}
+
+// step runs a random operation on the buffer and the model.
+func (p *program) step(t target) {
+	model := *t.model
+	switch p.rng.IntN(6) {
+	case 0, 1:
+		size := p.rng.IntN(len(model) + 8)
+		off := int64(p.rng.IntN(len(model)+9)) - 4
+		p.logf("%s.ReadAt(len=%d, off=%d)", t.name, size, off)
+		var buf, want []byte
+		if size > 0 || p.rng.IntN(2) == 0 {
+			buf, want = make([]byte, size), make([]byte, size)
+		}
+		n, err := t.buffer.ReadAt(buf, off)
+		wantN, wantErr := bytes.NewReader(model).ReadAt(want, off)
+		if n != wantN || errorClass(err) != errorClass(wantErr) || !bytes.Equal(buf[:n], want[:n]) {
+			p.fail("%s.ReadAt(len=%d, off=%d) = (%d, %v) with %q, want (%d, %v) with %q",
+				t.name, size, off, n, err, buf[:n], wantN, wantErr, want[:wantN])
+		}
+	case 2:
+		length := p.rng.IntN(len(model)+9) - 4
+		p.logf("%s.Drain(%d)", t.name, length)
+		t.buffer.Drain(length)
+		*t.model = model[min(max(length, 0), len(model)):]
+	case 3:
+		data := randomData(p.rng)
+		p.logf("%s.Replace(%s)", t.name, describe(data))
+		t.buffer.Replace(data)
+		*t.model = slices.Clone(data)
+	case 4:
+		data := randomData(p.rng)
+		p.logf("%s.Append(%s)", t.name, describe(data))
+		t.buffer.Append(data)
+		*t.model = slices.Concat(model, data)
+	case 5:
+		data := randomData(p.rng)
+		p.logf("%s.Prepend(%s)", t.name, describe(data))
+		t.buffer.Prepend(data)
+		*t.model = slices.Concat(data, model)
+	}
+}
+
+// check checks the length and the contents of the buffer against the model.
+func (p *program) check(t target) {
+	model := *t.model
+	if n := t.buffer.Length(); n != len(model) {
+		p.fail("%s.Length() = %d, want %d", t.name, n, len(model))
+		return
+	}
+	if data := t.buffer.Copy(); !bytes.Equal(data, model) {
+		p.fail("%s.Copy() = %q, want %q", t.name, data, model)
+		return
+	}
+	var data []byte
+	t.buffer.Slices(func(view []byte) { data = append(data, view...) })
+	if !bytes.Equal(data, model) {
+		p.fail("%s.Slices() = %q, want %q", t.name, data, model)
+	}
+}
+
+// sendLocalReply sends the local reply with the random headers and body, each of which can be nil or empty.
+func (p *program) sendLocalReply(e envoy.EnvoyFilterInstance) {
+	reply := &localReply{statusCode: 200 + p.rng.IntN(400), body: randomData(p.rng)}
+	if n := p.rng.IntN(4); n > 0 {
+		reply.headers = make([][2]string, n-1)
+		for i := range reply.headers {
+			reply.headers[i] = [2]string{fmt.Sprintf("x-fuzz-%d", i), string(randomData(p.rng))}
+		}
+	}
+	p.logf("SendResponse(%d, %d headers, %s)", reply.statusCode, len(reply.headers), describe(reply.body))
+	e.SendResponse(reply.statusCode, reply.headers, reply.body)
+	p.localReply = reply
+}
+
+// verifyHost checks the buffers and the local reply of the host stub after the event against the models.
+func (p *program) verifyHost(stream hoststub.Stream, event, request, response hoststub.Buffer) {
+	for _, b := range []struct {
+		name   string
+		buffer hoststub.Buffer
+		model  []byte
+	}{
+		{"event", event, p.event},
+		{"request", request, p.request},
+		{"response", response, p.response},
+	} {
+		if data := b.buffer.Bytes(); !bytes.Equal(data, b.model) {
+			p.fail("host %s buffer = %q, want %q", b.name, data, b.model)
+			return
+		}
+	}
+	statusCode, headers, body, ok := stream.LocalReply()
+	switch {
+	case ok != (p.localReply != nil):
+		p.fail("host local reply sent = %t, want %t", ok, p.localReply != nil)
+	case !ok:
+	case statusCode != p.localReply.statusCode:
+		p.fail("host local reply status = %d, want %d", statusCode, p.localReply.statusCode)
+	case len(headers) != len(p.localReply.headers) || len(headers) > 0 && !slices.Equal(headers, p.localReply.headers):
+		p.fail("host local reply headers = %q, want %q", headers, p.localReply.headers)
+	case !bytes.Equal(body, p.localReply.body):
+		p.fail("host local reply body = %q, want %q", body, p.localReply.body)
+	}
+}
+
+func (p *program) logf(format string, args ...any) {
+	p.log = append(p.log, fmt.Sprintf(format, args...))
+}
+
+func (p *program) fail(format string, args ...any) {
+	if p.err == nil {
+		p.err = fmt.Errorf(format, args...)
+	}
+}
+
+// errorClass classifies the error of io.ReaderAt, as the error other than io.EOF differs between the implementations.
+func errorClass(err error) string {
+	switch {
+	case err == nil:
+		return "nil"
+	case errors.Is(err, io.EOF):
+		return "EOF"
+	default:
+		return "error"
+	}
+}
+
+// randomData returns nil, an empty slice, or random bytes of a random length which is occasionally larger than
+// a slice of Envoy's buffer.
+func randomData(rng *rand.Rand) []byte {
+	switch rng.IntN(8) {
+	case 0:
+		return nil
+	case 1:
+		return []byte{}
+	case 2:
+		return randomBytes(rng, 1+rng.IntN(32<<10))
+	default:
+		return randomBytes(rng, 1+rng.IntN(64))
+	}
+}
+
+func randomBytes(rng *rand.Rand, n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte('!' + rng.IntN('~'-'!'+1))
+	}
+	return data
+}
+
+// randomSlices splits the data into the random number of the slices as the initial slices of the buffer.
+func randomSlices(rng *rand.Rand, data []byte) [][]byte {
+	var ret [][]byte
+	for len(data) > 0 {
+		n := 1 + rng.IntN(len(data))
+		ret, data = append(ret, data[:n]), data[n:]
+	}
+	return ret
+}
+
+// describe returns the short description of the data in the operation log.
+func describe(data []byte) string {
+	switch {
+	case data == nil:
+		return "nil"
+	case len(data) > 16:
+		return fmt.Sprintf("%d bytes", len(data))
+	default:
+		return fmt.Sprintf("%q", data)
+	}
+}
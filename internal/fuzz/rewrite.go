@@ -0,0 +1,189 @@
+//go:build cgo
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+	"github.com/mathetake/envoy-dynamic-modules-go-sdk/internal/hoststub"
+)
+
+// rewriteAlphabet is the alphabet of the bodies and the replacements of the Rewrite suite, which is small so that
+// the matches frequently overlap and straddle the chunks.
+const rewriteAlphabet = "abcAB"
+
+// rewriteRegexps are the regular expressions of the Rewrite suite and their MaxMatchLength. They cannot match
+// the empty string, and don't have the anchors, so the results are the same as regexp.Regexp.ReplaceAll.
+var rewriteRegexps = []struct {
+	expr           string
+	maxMatchLength int
+}{
+	{`ab{1,3}`, 4},
+	{`(a|bb)c`, 3},
+	{`a[bc]{2}A`, 4},
+	{`(?i)(ab|ba)`, 2},
+	{`c(a+)c`, 64},
+}
+
+// runRewrite streams a random body through envoy.BodyRewriter in the response body events of a new stream, which
+// randomly ends with the trailers instead of the end of stream.
+func runRewrite(filter uintptr, rng *rand.Rand) ([]string, error) {
+	var log []string
+	config, want := randomRewriterConfig(rng, &log)
+	rewriter, err := envoy.NewRewriter(config)
+	if err != nil {
+		return log, fmt.Errorf("NewRewriter() = %v", err)
+	}
+	body := []byte(randomString(rng, rng.IntN(256)))
+	log = append(log, fmt.Sprintf("body %q", body))
+	expected := want(body)
+	if got := rewriter.Rewrite(body); !bytes.Equal(got, expected) {
+		return log, fmt.Errorf("Rewrite() = %q, want %q", got, expected)
+	}
+
+	buffered := hoststub.NewBuffer()
+	defer buffered.Free()
+	stream := hoststub.NewStream(hoststub.Buffer{}, buffered)
+	defer stream.Free()
+	newResponseHandler = func(e envoy.EnvoyFilterInstance) responseHandler {
+		return rewriteHandler{rewriter.NewBodyRewriter(e)}
+	}
+	instance := hoststub.HttpFilterInstanceInit(filter, stream)
+	newResponseHandler = nil
+	defer hoststub.HttpFilterInstanceDestroy(instance)
+
+	headers := hoststub.NewHeaders([][2]string{{"content-length", strconv.Itoa(len(body))}})
+	defer headers.Free()
+	hoststub.HttpFilterInstanceResponseHeaders(instance, headers, false)
+	preserving := len(config.Replacements) > 0
+	for _, r := range config.Replacements {
+		preserving = preserving && len(r.Old) == len(r.New)
+	}
+	if kept := len(headers.All()) == 1; kept != preserving {
+		return log, fmt.Errorf("content-length kept = %t, want %t", kept, preserving)
+	}
+
+	// out is the body emitted by Envoy, which is the injected data and the chunks continued with the body buffered
+	// by the StopIterationAndBuffer status.
+	var out []byte
+	var injected int
+	chunks := randomSlices(rng, body)
+	// The empty chunks are passed, e.g. the end of stream without data.
+	for len(chunks) == 0 || rng.IntN(4) == 0 {
+		chunks = append(chunks, nil)
+	}
+	trailers := rng.IntN(4) == 0
+	for i, chunk := range chunks {
+		endOfStream := i == len(chunks)-1 && !trailers
+		log = append(log, fmt.Sprintf("ResponseBody(%q, %t)", chunk, endOfStream))
+		buffer := hoststub.NewBuffer(chunk)
+		status := hoststub.HttpFilterInstanceResponseBody(instance, buffer, endOfStream)
+		_, response := stream.Injected()
+		out, injected = append(out, response[injected:]...), len(response)
+		switch {
+		case status != int(envoy.ResponseBodyStatusStopIterationAndBuffer):
+			out = append(append(out, buffered.Bytes()...), buffer.Bytes()...)
+		case endOfStream:
+			buffer.Free()
+			return log, fmt.Errorf("ResponseBody() = StopIterationAndBuffer at the end of stream")
+		default:
+			buffered.Append(buffer.Bytes())
+		}
+		buffer.Free()
+	}
+	if trailers {
+		// The body buffered in Envoy is emitted as is before the trailers, so the body is rewritten except for
+		// the tail held.
+		log = append(log, "ResponseTrailers()")
+		out = append(out, buffered.Bytes()...)
+		for held := 0; held <= len(body); held++ {
+			k := len(body) - held
+			if bytes.Equal(out, append(want(body[:k]), body[k:]...)) {
+				return log, nil
+			}
+		}
+		return log, fmt.Errorf("streamed body = %q, want %q except for the held tail", out, expected)
+	}
+	if !bytes.Equal(out, expected) {
+		return log, fmt.Errorf("streamed body = %q, want %q", out, expected)
+	}
+	return log, nil
+}
+
+// rewriteHandler rewrites the response body with envoy.BodyRewriter.
+type rewriteHandler struct {
+	*envoy.BodyRewriter
+}
+
+func (r rewriteHandler) ResponseHeaders(headers envoy.ResponseHeaders, _ bool) envoy.ResponseHeadersStatus {
+	r.PrepareResponseHeaders(headers)
+	return envoy.ResponseHeadersStatusContinue
+}
+
+func (r rewriteHandler) ResponseBody(body envoy.ResponseBodyBuffer, endOfStream bool) envoy.ResponseBodyStatus {
+	return r.RewriteResponseBody(body, endOfStream)
+}
+
+// randomRewriterConfig returns a random configuration and the function that rewrites the entire body in the
+// reference way.
+func randomRewriterConfig(rng *rand.Rand, log *[]string) (envoy.RewriterConfig, func([]byte) []byte) {
+	if rng.IntN(4) == 0 {
+		r := rewriteRegexps[rng.IntN(len(rewriteRegexps))]
+		re := regexp.MustCompile(r.expr)
+		template := []string{"", "<$0>", "[$1]", "xyz"}[rng.IntN(4)]
+		*log = append(*log, fmt.Sprintf("regexp %q, template %q", r.expr, template))
+		return envoy.RewriterConfig{Regexp: re, Template: template, MaxMatchLength: r.maxMatchLength},
+			func(body []byte) []byte { return re.ReplaceAll(body, []byte(template)) }
+	}
+	config := envoy.RewriterConfig{CaseInsensitive: rng.IntN(2) == 0}
+	for range 1 + rng.IntN(4) {
+		old := randomString(rng, 1+rng.IntN(5))
+		replacement := envoy.Replacement{Old: old, New: randomString(rng, rng.IntN(6))}
+		if rng.IntN(3) == 0 {
+			replacement.New = randomString(rng, len(old))
+		}
+		config.Replacements = append(config.Replacements, replacement)
+	}
+	*log = append(*log, fmt.Sprintf("replacements %q, case insensitive %t", config.Replacements, config.CaseInsensitive))
+	return config, func(body []byte) []byte { return replaceLeftmostLongest(body, config) }
+}
+
+// replaceLeftmostLongest is the brute-force reference of the replacements, which replaces the longest match at each
+// position from the left, and the first one among the replacements of the same Old.
+func replaceLeftmostLongest(body []byte, config envoy.RewriterConfig) []byte {
+	equal := bytes.Equal
+	if config.CaseInsensitive {
+		equal = bytes.EqualFold
+	}
+	var out []byte
+	for pos := 0; pos < len(body); {
+		best := -1
+		for i, r := range config.Replacements {
+			if len(r.Old) <= len(body)-pos && equal(body[pos:pos+len(r.Old)], []byte(r.Old)) &&
+				(best < 0 || len(r.Old) > len(config.Replacements[best].Old)) {
+				best = i
+			}
+		}
+		if best < 0 {
+			out = append(out, body[pos])
+			pos++
+			continue
+		}
+		out = append(out, config.Replacements[best].New...)
+		pos += len(config.Replacements[best].Old)
+	}
+	return out
+}
+
+func randomString(rng *rand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = rewriteAlphabet[rng.IntN(len(rewriteAlphabet))]
+	}
+	return string(b)
+}
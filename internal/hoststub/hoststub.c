@@ -0,0 +1,534 @@
+// The Envoy API implemented by the host stub. The others are the no-ops in hoststub_gen.c.
+
+#define ENVOY_DYNAMIC_MODULE
+#include "abi.h"
+
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+#include "hoststub.h"
+
+size_t __envoy_dynamic_module_v1_get_abi_version() { return __ENVOY_DYNAMIC_MODULE_V1_ABI_VERSION; }
+
+// hoststub_check aborts if the condition is false. This is used where Envoy asserts the arguments passed by
+// the module, e.g. the range of the body buffer, so that the misuse of the Envoy API by the SDK is caught.
+static void hoststub_check(int condition, const char* message) {
+  if (!condition) {
+    fprintf(stderr, "hoststub: %s\n", message);
+    abort();
+  }
+}
+
+// ---------------- Header map ----------------
+//
+// The header map is a vector of the key-value pairs in the insertion order, which is the same as
+// Envoy's HeaderMap from the module's point of view.
+
+hoststub_headers* hoststub_headers_new() { return calloc(1, sizeof(hoststub_headers)); }
+
+void hoststub_headers_free(hoststub_headers* headers) {
+  for (size_t i = 0; i < headers->size; i++) {
+    free(headers->entries[i].key);
+    free(headers->entries[i].value);
+  }
+  free(headers->entries);
+  free(headers);
+}
+
+static int hoststub_key_equal(const hoststub_header* entry, const char* key, size_t key_length) {
+  return entry->key_length == key_length && memcmp(entry->key, key, key_length) == 0;
+}
+
+void hoststub_headers_add(hoststub_headers* headers, const char* key, size_t key_length,
+                          const char* value, size_t value_length) {
+  if (headers->size == headers->capacity) {
+    headers->capacity = headers->capacity == 0 ? 16 : headers->capacity * 2;
+    headers->entries = realloc(headers->entries, headers->capacity * sizeof(hoststub_header));
+  }
+  hoststub_header* entry = &headers->entries[headers->size++];
+  entry->key = malloc(key_length + 1);
+  memcpy(entry->key, key, key_length);
+  entry->key_length = key_length;
+  entry->value = malloc(value_length + 1);
+  memcpy(entry->value, value, value_length);
+  entry->value_length = value_length;
+}
+
+static void hoststub_headers_remove(hoststub_headers* headers, const char* key, size_t key_length) {
+  size_t kept = 0;
+  for (size_t i = 0; i < headers->size; i++) {
+    if (hoststub_key_equal(&headers->entries[i], key, key_length)) {
+      free(headers->entries[i].key);
+      free(headers->entries[i].value);
+      continue;
+    }
+    headers->entries[kept++] = headers->entries[i];
+  }
+  headers->size = kept;
+}
+
+static void hoststub_headers_set(hoststub_headers* headers, const char* key, size_t key_length,
+                                 const char* value, size_t value_length) {
+  hoststub_headers_remove(headers, key, key_length);
+  if (value_length > 0) {
+    hoststub_headers_add(headers, key, key_length, value, value_length);
+  }
+}
+
+// hoststub_headers_get returns the number of the values of the key, and sets the nth value.
+static size_t hoststub_headers_get(hoststub_headers* headers, const char* key, size_t key_length,
+                                   size_t nth, char** value, size_t* value_length) {
+  size_t count = 0;
+  *value = NULL;
+  *value_length = 0;
+  for (size_t i = 0; i < headers->size; i++) {
+    if (hoststub_key_equal(&headers->entries[i], key, key_length)) {
+      if (count == nth) {
+        *value = headers->entries[i].value;
+        *value_length = headers->entries[i].value_length;
+      }
+      count++;
+    }
+  }
+  return count;
+}
+
+static void hoststub_headers_mutate(hoststub_headers* headers,
+                                    const __envoy_dynamic_module_v1_type_InModuleBuffer* remove,
+                                    size_t remove_size,
+                                    const __envoy_dynamic_module_v1_type_InModuleHeader* set,
+                                    size_t set_size,
+                                    const __envoy_dynamic_module_v1_type_InModuleHeader* add,
+                                    size_t add_size) {
+  for (size_t i = 0; i < remove_size; i++) {
+    hoststub_headers_remove(headers, (const char*)remove[i].buffer, remove[i].buffer_length);
+  }
+  for (size_t i = 0; i < set_size; i++) {
+    hoststub_headers_set(headers, (const char*)set[i].header_key, set[i].header_key_length,
+                         (const char*)set[i].header_value, set[i].header_value_length);
+  }
+  for (size_t i = 0; i < add_size; i++) {
+    hoststub_headers_add(headers, (const char*)add[i].header_key, add[i].header_key_length,
+                         (const char*)add[i].header_value, add[i].header_value_length);
+  }
+}
+
+static size_t hoststub_headers_get_values(hoststub_headers* headers,
+                                          const __envoy_dynamic_module_v1_type_InModuleBuffer* keys,
+                                          size_t keys_size,
+                                          __envoy_dynamic_module_v1_type_DataSlice* results) {
+  size_t found = 0;
+  for (size_t i = 0; i < keys_size; i++) {
+    char* value;
+    size_t value_length;
+    if (hoststub_headers_get(headers, (const char*)keys[i].buffer, keys[i].buffer_length, 0, &value,
+                             &value_length) > 0) {
+      found++;
+    }
+    results[i].data = (__envoy_dynamic_module_v1_type_DataSlicePtr)value;
+    results[i].data_length = value_length;
+  }
+  return found;
+}
+
+// ---------------- Header API ----------------
+
+size_t __envoy_dynamic_module_v1_http_get_request_header_value(
+    __envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr key,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength key_length,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr) {
+  return hoststub_headers_get((hoststub_headers*)headers, (const char*)key, key_length, 0,
+                              (char**)result_buffer_ptr, (size_t*)result_buffer_length_ptr);
+}
+
+void __envoy_dynamic_module_v1_http_get_request_header_value_nth(
+    __envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr key,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength key_length,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr, size_t nth) {
+  hoststub_headers_get((hoststub_headers*)headers, (const char*)key, key_length, nth,
+                       (char**)result_buffer_ptr, (size_t*)result_buffer_length_ptr);
+}
+
+size_t __envoy_dynamic_module_v1_http_get_response_header_value(
+    __envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr key,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength key_length,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr) {
+  return hoststub_headers_get((hoststub_headers*)headers, (const char*)key, key_length, 0,
+                              (char**)result_buffer_ptr, (size_t*)result_buffer_length_ptr);
+}
+
+void __envoy_dynamic_module_v1_http_get_response_header_value_nth(
+    __envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr key,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength key_length,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr, size_t nth) {
+  hoststub_headers_get((hoststub_headers*)headers, (const char*)key, key_length, nth,
+                       (char**)result_buffer_ptr, (size_t*)result_buffer_length_ptr);
+}
+
+void __envoy_dynamic_module_v1_http_set_request_header(
+    __envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr headers,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr key,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength key_length,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr value,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength value_length) {
+  hoststub_headers_set((hoststub_headers*)headers, (const char*)key, key_length,
+                       (const char*)value, value_length);
+}
+
+void __envoy_dynamic_module_v1_http_set_response_header(
+    __envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr key,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength key_length,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr value,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength value_length) {
+  hoststub_headers_set((hoststub_headers*)headers, (const char*)key, key_length,
+                       (const char*)value, value_length);
+}
+
+// ---------------- Batch Header API ----------------
+
+void __envoy_dynamic_module_v1_http_mutate_request_headers(
+    __envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr headers,
+    __envoy_dynamic_module_v1_type_InModuleBuffersPtr remove,
+    __envoy_dynamic_module_v1_type_InModuleBuffersSize remove_size,
+    __envoy_dynamic_module_v1_type_InModuleHeadersPtr set,
+    __envoy_dynamic_module_v1_type_InModuleHeadersSize set_size,
+    __envoy_dynamic_module_v1_type_InModuleHeadersPtr add,
+    __envoy_dynamic_module_v1_type_InModuleHeadersSize add_size) {
+  hoststub_headers_mutate((hoststub_headers*)headers,
+                          (const __envoy_dynamic_module_v1_type_InModuleBuffer*)remove, remove_size,
+                          (const __envoy_dynamic_module_v1_type_InModuleHeader*)set, set_size,
+                          (const __envoy_dynamic_module_v1_type_InModuleHeader*)add, add_size);
+}
+
+void __envoy_dynamic_module_v1_http_mutate_response_headers(
+    __envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers,
+    __envoy_dynamic_module_v1_type_InModuleBuffersPtr remove,
+    __envoy_dynamic_module_v1_type_InModuleBuffersSize remove_size,
+    __envoy_dynamic_module_v1_type_InModuleHeadersPtr set,
+    __envoy_dynamic_module_v1_type_InModuleHeadersSize set_size,
+    __envoy_dynamic_module_v1_type_InModuleHeadersPtr add,
+    __envoy_dynamic_module_v1_type_InModuleHeadersSize add_size) {
+  hoststub_headers_mutate((hoststub_headers*)headers,
+                          (const __envoy_dynamic_module_v1_type_InModuleBuffer*)remove, remove_size,
+                          (const __envoy_dynamic_module_v1_type_InModuleHeader*)set, set_size,
+                          (const __envoy_dynamic_module_v1_type_InModuleHeader*)add, add_size);
+}
+
+size_t __envoy_dynamic_module_v1_http_get_request_header_values(
+    __envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr headers,
+    __envoy_dynamic_module_v1_type_InModuleBuffersPtr keys,
+    __envoy_dynamic_module_v1_type_InModuleBuffersSize keys_size,
+    __envoy_dynamic_module_v1_type_DataSlicesResult results) {
+  return hoststub_headers_get_values((hoststub_headers*)headers,
+                                     (const __envoy_dynamic_module_v1_type_InModuleBuffer*)keys,
+                                     keys_size, (__envoy_dynamic_module_v1_type_DataSlice*)results);
+}
+
+size_t __envoy_dynamic_module_v1_http_get_response_header_values(
+    __envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers,
+    __envoy_dynamic_module_v1_type_InModuleBuffersPtr keys,
+    __envoy_dynamic_module_v1_type_InModuleBuffersSize keys_size,
+    __envoy_dynamic_module_v1_type_DataSlicesResult results) {
+  return hoststub_headers_get_values((hoststub_headers*)headers,
+                                     (const __envoy_dynamic_module_v1_type_InModuleBuffer*)keys,
+                                     keys_size, (__envoy_dynamic_module_v1_type_DataSlice*)results);
+}
+
+// ---------------- Body buffer ----------------
+
+hoststub_buffer* hoststub_buffer_new() { return calloc(1, sizeof(hoststub_buffer)); }
+
+static void hoststub_buffer_clear(hoststub_buffer* buffer) {
+  for (size_t i = 0; i < buffer->size; i++) {
+    free(buffer->slices[i].base);
+  }
+  buffer->size = 0;
+}
+
+void hoststub_buffer_free(hoststub_buffer* buffer) {
+  hoststub_buffer_clear(buffer);
+  free(buffer->slices);
+  free(buffer);
+}
+
+// hoststub_buffer_insert inserts a copy of the data as a new slice at the index.
+static void hoststub_buffer_insert(hoststub_buffer* buffer, size_t index, const char* data,
+                                   size_t length) {
+  if (length == 0) {
+    return;
+  }
+  hoststub_check(data != NULL, "null data inserted to the buffer");
+  if (buffer->size == buffer->capacity) {
+    buffer->capacity = buffer->capacity == 0 ? 4 : buffer->capacity * 2;
+    buffer->slices = realloc(buffer->slices, buffer->capacity * sizeof(hoststub_slice));
+  }
+  memmove(&buffer->slices[index + 1], &buffer->slices[index],
+          (buffer->size - index) * sizeof(hoststub_slice));
+  hoststub_slice* slice = &buffer->slices[index];
+  slice->base = malloc(length);
+  memcpy(slice->base, data, length);
+  slice->data = slice->base;
+  slice->length = length;
+  buffer->size++;
+}
+
+void hoststub_buffer_append(hoststub_buffer* buffer, const char* data, size_t length) {
+  hoststub_buffer_insert(buffer, buffer->size, data, length);
+}
+
+size_t hoststub_buffer_length(hoststub_buffer* buffer) {
+  size_t length = 0;
+  for (size_t i = 0; i < buffer->size; i++) {
+    length += buffer->slices[i].length;
+  }
+  return length;
+}
+
+void hoststub_buffer_copy_out(hoststub_buffer* buffer, size_t offset, size_t length, char* dst) {
+  hoststub_check(offset <= hoststub_buffer_length(buffer) &&
+                     length <= hoststub_buffer_length(buffer) - offset,
+                 "copy out of the range of the buffer");
+  hoststub_check(length == 0 || dst != NULL, "copy out to null");
+  for (size_t i = 0; i < buffer->size && length > 0; i++) {
+    hoststub_slice* slice = &buffer->slices[i];
+    if (offset >= slice->length) {
+      offset -= slice->length;
+      continue;
+    }
+    size_t n = slice->length - offset < length ? slice->length - offset : length;
+    memcpy(dst, slice->data + offset, n);
+    dst += n;
+    length -= n;
+    offset = 0;
+  }
+}
+
+static void hoststub_buffer_drain(hoststub_buffer* buffer, size_t length) {
+  hoststub_check(length <= hoststub_buffer_length(buffer), "drain more than the buffer");
+  size_t drained = 0;
+  while (drained < buffer->size && length >= buffer->slices[drained].length) {
+    length -= buffer->slices[drained].length;
+    free(buffer->slices[drained].base);
+    drained++;
+  }
+  memmove(&buffer->slices[0], &buffer->slices[drained],
+          (buffer->size - drained) * sizeof(hoststub_slice));
+  buffer->size -= drained;
+  if (buffer->size > 0 && length > 0) {
+    buffer->slices[0].data += length;
+    buffer->slices[0].length -= length;
+  }
+}
+
+static size_t hoststub_buffer_slices_count(hoststub_buffer* buffer) { return buffer->size; }
+
+static void hoststub_buffer_slice(hoststub_buffer* buffer, size_t nth, char** data, size_t* length) {
+  if (nth >= buffer->size) {
+    *data = NULL;
+    *length = 0;
+    return;
+  }
+  *data = buffer->slices[nth].data;
+  *length = buffer->slices[nth].length;
+}
+
+// ---------------- Buffer API ----------------
+
+static hoststub_buffer* hoststub_request_buffer(
+    __envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr ptr) {
+  hoststub_buffer* buffer = (hoststub_buffer*)ptr;
+  hoststub_check(buffer->kind != HOSTSTUB_BUFFER_RESPONSE, "response body accessed as request body");
+  return buffer;
+}
+
+static hoststub_buffer* hoststub_response_buffer(
+    __envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr ptr) {
+  hoststub_buffer* buffer = (hoststub_buffer*)ptr;
+  hoststub_check(buffer->kind != HOSTSTUB_BUFFER_REQUEST, "request body accessed as response body");
+  return buffer;
+}
+
+__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr
+__envoy_dynamic_module_v1_http_get_request_body_buffer(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr) {
+  hoststub_stream* stream = (hoststub_stream*)envoy_filter_instance_ptr;
+  return stream == NULL ? 0 : (__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr)stream->request_body;
+}
+
+__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr
+__envoy_dynamic_module_v1_http_get_response_body_buffer(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr) {
+  hoststub_stream* stream = (hoststub_stream*)envoy_filter_instance_ptr;
+  return stream == NULL ? 0 : (__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr)stream->response_body;
+}
+
+size_t __envoy_dynamic_module_v1_http_get_request_body_buffer_length(
+    __envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer) {
+  return hoststub_buffer_length(hoststub_request_buffer(buffer));
+}
+
+size_t __envoy_dynamic_module_v1_http_get_request_body_buffer_slices_count(
+    __envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer) {
+  return hoststub_buffer_slices_count(hoststub_request_buffer(buffer));
+}
+
+void __envoy_dynamic_module_v1_http_get_request_body_buffer_slice(
+    __envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer, size_t nth,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr) {
+  hoststub_buffer_slice(hoststub_request_buffer(buffer), nth, (char**)result_buffer_ptr,
+                        (size_t*)result_buffer_length_ptr);
+}
+
+void __envoy_dynamic_module_v1_http_copy_out_request_body_buffer(
+    __envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer, size_t offset, size_t length,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr result_buffer_ptr) {
+  hoststub_buffer_copy_out(hoststub_request_buffer(buffer), offset, length, (char*)result_buffer_ptr);
+}
+
+void __envoy_dynamic_module_v1_http_append_request_body_buffer(
+    __envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length) {
+  hoststub_buffer_append(hoststub_request_buffer(buffer), (const char*)data, data_length);
+}
+
+void __envoy_dynamic_module_v1_http_prepend_request_body_buffer(
+    __envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length) {
+  hoststub_buffer_insert(hoststub_request_buffer(buffer), 0, (const char*)data, data_length);
+}
+
+void __envoy_dynamic_module_v1_http_drain_request_body_buffer(
+    __envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer, size_t length) {
+  hoststub_buffer_drain(hoststub_request_buffer(buffer), length);
+}
+
+size_t __envoy_dynamic_module_v1_http_get_response_body_buffer_length(
+    __envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer) {
+  return hoststub_buffer_length(hoststub_response_buffer(buffer));
+}
+
+size_t __envoy_dynamic_module_v1_http_get_response_body_buffer_slices_count(
+    __envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer) {
+  return hoststub_buffer_slices_count(hoststub_response_buffer(buffer));
+}
+
+void __envoy_dynamic_module_v1_http_get_response_body_buffer_slice(
+    __envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer, size_t nth,
+    __envoy_dynamic_module_v1_type_DataSlicePtrResult result_buffer_ptr,
+    __envoy_dynamic_module_v1_type_DataSliceLengthResult result_buffer_length_ptr) {
+  hoststub_buffer_slice(hoststub_response_buffer(buffer), nth, (char**)result_buffer_ptr,
+                        (size_t*)result_buffer_length_ptr);
+}
+
+void __envoy_dynamic_module_v1_http_copy_out_response_body_buffer(
+    __envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer, size_t offset, size_t length,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr result_buffer_ptr) {
+  hoststub_buffer_copy_out(hoststub_response_buffer(buffer), offset, length, (char*)result_buffer_ptr);
+}
+
+void __envoy_dynamic_module_v1_http_append_response_body_buffer(
+    __envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length) {
+  hoststub_buffer_append(hoststub_response_buffer(buffer), (const char*)data, data_length);
+}
+
+void __envoy_dynamic_module_v1_http_prepend_response_body_buffer(
+    __envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length) {
+  hoststub_buffer_insert(hoststub_response_buffer(buffer), 0, (const char*)data, data_length);
+}
+
+void __envoy_dynamic_module_v1_http_drain_response_body_buffer(
+    __envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer, size_t length) {
+  hoststub_buffer_drain(hoststub_response_buffer(buffer), length);
+}
+
+// ---------------- Local reply ----------------
+
+void hoststub_stream_free(hoststub_stream* stream) {
+  if (stream == NULL) {
+    return;
+  }
+  if (stream->local_reply_headers != NULL) {
+    hoststub_headers_free(stream->local_reply_headers);
+  }
+  hoststub_buffer* buffers[] = {stream->local_reply_body, stream->request_injected,
+                                stream->response_injected};
+  for (size_t i = 0; i < sizeof(buffers) / sizeof(buffers[0]); i++) {
+    if (buffers[i] != NULL) {
+      hoststub_buffer_free(buffers[i]);
+    }
+  }
+  free(stream);
+}
+
+void __envoy_dynamic_module_v1_http_send_response(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr,
+    uint32_t status_code, __envoy_dynamic_module_v1_type_InModuleHeadersPtr headers_vector,
+    __envoy_dynamic_module_v1_type_InModuleHeadersSize headers_vector_size,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr body,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength body_length) {
+  hoststub_stream* stream = (hoststub_stream*)envoy_filter_instance_ptr;
+  hoststub_check(stream != NULL, "local reply on null stream");
+  hoststub_check(stream->local_reply_status == 0, "local reply sent twice");
+  hoststub_check(headers_vector_size == 0 || headers_vector != 0, "null headers of local reply");
+  hoststub_check(body_length == 0 || body != 0, "null body of local reply");
+  stream->local_reply_status = status_code;
+  stream->local_reply_headers = hoststub_headers_new();
+  const __envoy_dynamic_module_v1_type_InModuleHeader* headers =
+      (const __envoy_dynamic_module_v1_type_InModuleHeader*)headers_vector;
+  for (size_t i = 0; i < headers_vector_size; i++) {
+    hoststub_headers_add(stream->local_reply_headers, (const char*)headers[i].header_key,
+                         headers[i].header_key_length, (const char*)headers[i].header_value,
+                         headers[i].header_value_length);
+  }
+  stream->local_reply_body = hoststub_buffer_new();
+  hoststub_buffer_append(stream->local_reply_body, (const char*)body, body_length);
+}
+
+// ---------------- Data injection ----------------
+
+static void hoststub_inject(hoststub_buffer** injected,
+                            __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+                            __envoy_dynamic_module_v1_type_InModuleBufferLength data_length) {
+  hoststub_check(data_length == 0 || data != 0, "null injected data");
+  if (*injected == NULL) {
+    *injected = hoststub_buffer_new();
+  }
+  hoststub_buffer_append(*injected, (const char*)data, data_length);
+}
+
+void __envoy_dynamic_module_v1_http_inject_request_data(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length,
+    __envoy_dynamic_module_v1_type_EndOfStream end_of_stream) {
+  hoststub_stream* stream = (hoststub_stream*)envoy_filter_instance_ptr;
+  hoststub_check(stream != NULL, "injection on null stream");
+  hoststub_inject(&stream->request_injected, data, data_length);
+}
+
+void __envoy_dynamic_module_v1_http_inject_response_data(
+    __envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoy_filter_instance_ptr,
+    __envoy_dynamic_module_v1_type_InModuleBufferPtr data,
+    __envoy_dynamic_module_v1_type_InModuleBufferLength data_length,
+    __envoy_dynamic_module_v1_type_EndOfStream end_of_stream) {
+  hoststub_stream* stream = (hoststub_stream*)envoy_filter_instance_ptr;
+  hoststub_check(stream != NULL, "injection on null stream");
+  hoststub_inject(&stream->response_injected, data, data_length);
+}
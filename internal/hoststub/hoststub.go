@@ -0,0 +1,224 @@
+// Package hoststub is a stub of Envoy for the benchmarks. This implements the Envoy API in abi.h in C,
+// and drives the event hooks of the module linked into the same binary, i.e. the envoy package, through cgo
+// in the same way as Envoy does.
+//
+// The Envoy API functions not implemented in hoststub.c are the no-ops in hoststub_gen.c.
+package hoststub
+
+//go:generate go run ../abigen -header ../../envoy/abi.h -stub hoststub_gen.c
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../../envoy
+#include "abi.h"
+#include <stdlib.h>
+#include "hoststub.h"
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	// The event hooks called by this package are exported by the envoy package.
+	_ "github.com/mathetake/envoy-dynamic-modules-go-sdk/envoy"
+)
+
+// HttpFilterInit calls __envoy_dynamic_module_v1_event_http_filter_init with the config,
+// and returns the pointer to the created http filter.
+func HttpFilterInit(config string) uintptr {
+	return uintptr(C.__envoy_dynamic_module_v1_event_http_filter_init(
+		C.__envoy_dynamic_module_v1_type_HttpFilterConfigPtr(uintptr(unsafe.Pointer(unsafe.StringData(config)))),
+		C.__envoy_dynamic_module_v1_type_HttpFilterConfigSize(len(config)),
+	))
+}
+
+// HttpFilterDestroy calls __envoy_dynamic_module_v1_event_http_filter_destroy.
+func HttpFilterDestroy(filter uintptr) {
+	C.__envoy_dynamic_module_v1_event_http_filter_destroy(C.__envoy_dynamic_module_v1_type_HttpFilterPtr(filter))
+}
+
+// HttpFilterInstanceInit calls __envoy_dynamic_module_v1_event_http_filter_instance_init for the filter and
+// the stream, and returns the pointer to the created http filter instance. The zero Stream can be used if
+// the instance doesn't access the buffered bodies.
+func HttpFilterInstanceInit(filter uintptr, stream Stream) uintptr {
+	return uintptr(C.__envoy_dynamic_module_v1_event_http_filter_instance_init(
+		C.__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr(uintptr(unsafe.Pointer(stream.ptr))),
+		C.__envoy_dynamic_module_v1_type_HttpFilterPtr(filter)))
+}
+
+// HttpFilterInstanceDestroy calls __envoy_dynamic_module_v1_event_http_filter_instance_destroy.
+func HttpFilterInstanceDestroy(instance uintptr) {
+	C.__envoy_dynamic_module_v1_event_http_filter_instance_destroy(
+		C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr(instance))
+}
+
+// HttpFilterInstanceRequestHeaders calls __envoy_dynamic_module_v1_event_http_filter_instance_request_headers,
+// and returns the status.
+func HttpFilterInstanceRequestHeaders(instance uintptr, headers Headers, endOfStream bool) int {
+	return int(C.__envoy_dynamic_module_v1_event_http_filter_instance_request_headers(
+		C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr(instance),
+		C.__envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr(uintptr(unsafe.Pointer(headers.ptr))),
+		C.__envoy_dynamic_module_v1_type_EndOfStream(boolToInt(endOfStream)),
+	))
+}
+
+// HttpFilterInstanceResponseHeaders calls __envoy_dynamic_module_v1_event_http_filter_instance_response_headers,
+// and returns the status.
+func HttpFilterInstanceResponseHeaders(instance uintptr, headers Headers, endOfStream bool) int {
+	return int(C.__envoy_dynamic_module_v1_event_http_filter_instance_response_headers(
+		C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr(instance),
+		C.__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr(uintptr(unsafe.Pointer(headers.ptr))),
+		C.__envoy_dynamic_module_v1_type_EndOfStream(boolToInt(endOfStream)),
+	))
+}
+
+// HttpFilterInstanceRequestBody calls __envoy_dynamic_module_v1_event_http_filter_instance_request_body,
+// and returns the status.
+func HttpFilterInstanceRequestBody(instance uintptr, buffer Buffer, endOfStream bool) int {
+	buffer.setKind(C.HOSTSTUB_BUFFER_REQUEST)
+	return int(C.__envoy_dynamic_module_v1_event_http_filter_instance_request_body(
+		C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr(instance),
+		C.__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr(uintptr(unsafe.Pointer(buffer.ptr))),
+		C.__envoy_dynamic_module_v1_type_EndOfStream(boolToInt(endOfStream)),
+	))
+}
+
+// HttpFilterInstanceResponseBody calls __envoy_dynamic_module_v1_event_http_filter_instance_response_body,
+// and returns the status.
+func HttpFilterInstanceResponseBody(instance uintptr, buffer Buffer, endOfStream bool) int {
+	buffer.setKind(C.HOSTSTUB_BUFFER_RESPONSE)
+	return int(C.__envoy_dynamic_module_v1_event_http_filter_instance_response_body(
+		C.__envoy_dynamic_module_v1_type_HttpFilterInstancePtr(instance),
+		C.__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr(uintptr(unsafe.Pointer(buffer.ptr))),
+		C.__envoy_dynamic_module_v1_type_EndOfStream(boolToInt(endOfStream)),
+	))
+}
+
+// Stream is the Envoy filter instance of the host stub, which represents a single HTTP stream.
+type Stream struct {
+	ptr *C.hoststub_stream
+}
+
+// NewStream creates a new stream whose buffered request and response bodies are the given buffers.
+// The buffers are not owned by the stream. Free must be called after use.
+func NewStream(requestBody, responseBody Buffer) Stream {
+	stream := (*C.hoststub_stream)(C.calloc(1, C.sizeof_hoststub_stream))
+	stream.request_body = requestBody.ptr
+	stream.response_body = responseBody.ptr
+	requestBody.setKind(C.HOSTSTUB_BUFFER_REQUEST)
+	responseBody.setKind(C.HOSTSTUB_BUFFER_RESPONSE)
+	return Stream{ptr: stream}
+}
+
+// Free frees the stream.
+func (s Stream) Free() {
+	C.hoststub_stream_free(s.ptr)
+}
+
+// LocalReply returns the copy of the local reply sent by __envoy_dynamic_module_v1_http_send_response,
+// or false if not sent. The host stub aborts if the local reply is sent more than once.
+func (s Stream) LocalReply() (statusCode int, headers [][2]string, body []byte, ok bool) {
+	if s.ptr.local_reply_status == 0 {
+		return 0, nil, nil, false
+	}
+	statusCode = int(s.ptr.local_reply_status)
+	headers = Headers{ptr: s.ptr.local_reply_headers}.All()
+	body = Buffer{ptr: s.ptr.local_reply_body}.Bytes()
+	return statusCode, headers, body, true
+}
+
+// Injected returns the copies of the request and response data injected by
+// __envoy_dynamic_module_v1_http_inject_request_data and the response one so far.
+func (s Stream) Injected() (request, response []byte) {
+	if s.ptr.request_injected != nil {
+		request = Buffer{ptr: s.ptr.request_injected}.Bytes()
+	}
+	if s.ptr.response_injected != nil {
+		response = Buffer{ptr: s.ptr.response_injected}.Bytes()
+	}
+	return request, response
+}
+
+// Buffer is the body buffer of the host stub, which is passed as the request or response body.
+type Buffer struct {
+	ptr *C.hoststub_buffer
+}
+
+// NewBuffer creates a new buffer that consists of the copies of the slices. Free must be called after use.
+func NewBuffer(slices ...[]byte) Buffer {
+	buffer := Buffer{ptr: C.hoststub_buffer_new()}
+	for _, slice := range slices {
+		buffer.Append(slice)
+	}
+	return buffer
+}
+
+// Append appends the copy of the data to the buffer as a new slice.
+func (b Buffer) Append(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	C.hoststub_buffer_append(b.ptr, (*C.char)(unsafe.Pointer(&data[0])), C.size_t(len(data)))
+}
+
+// setKind sets the direction of the body the buffer is passed as, which is checked by the Buffer API.
+func (b Buffer) setKind(kind C.int) {
+	if b.ptr != nil {
+		b.ptr.kind = kind
+	}
+}
+
+// Bytes returns the copy of the bytes in the buffer.
+func (b Buffer) Bytes() []byte {
+	ret := make([]byte, C.hoststub_buffer_length(b.ptr))
+	if len(ret) > 0 {
+		C.hoststub_buffer_copy_out(b.ptr, 0, C.size_t(len(ret)), (*C.char)(unsafe.Pointer(&ret[0])))
+	}
+	return ret
+}
+
+// Free frees the buffer.
+func (b Buffer) Free() {
+	C.hoststub_buffer_free(b.ptr)
+}
+
+// Headers is the header map of the host stub, which is passed as the request or response headers.
+type Headers struct {
+	ptr *C.hoststub_headers
+}
+
+// NewHeaders creates a new header map with the key-value pairs. Free must be called after use.
+func NewHeaders(kvs [][2]string) Headers {
+	headers := C.hoststub_headers_new()
+	for _, kv := range kvs {
+		key, value := C.CString(kv[0]), C.CString(kv[1])
+		C.hoststub_headers_add(headers, key, C.size_t(len(kv[0])), value, C.size_t(len(kv[1])))
+		C.free(unsafe.Pointer(key))
+		C.free(unsafe.Pointer(value))
+	}
+	return Headers{ptr: headers}
+}
+
+// Free frees the header map.
+func (h Headers) Free() {
+	C.hoststub_headers_free(h.ptr)
+}
+
+// All returns the copy of the key-value pairs in the header map in the insertion order.
+func (h Headers) All() [][2]string {
+	entries := unsafe.Slice(h.ptr.entries, h.ptr.size)
+	ret := make([][2]string, len(entries))
+	for i, entry := range entries {
+		ret[i] = [2]string{
+			C.GoStringN(entry.key, C.int(entry.key_length)),
+			C.GoStringN(entry.value, C.int(entry.value_length)),
+		}
+	}
+	return ret
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+// The data structures of the host stub shared between hoststub.c and hoststub.go.
+
+#pragma once
+
+#include <stddef.h>
+
+typedef struct {
+  char* key;
+  size_t key_length;
+  char* value;
+  size_t value_length;
+} hoststub_header;
+
+// hoststub_headers is the header map passed as the request or response headers.
+typedef struct {
+  hoststub_header* entries;
+  size_t size;
+  size_t capacity;
+} hoststub_headers;
+
+hoststub_headers* hoststub_headers_new();
+void hoststub_headers_free(hoststub_headers* headers);
+void hoststub_headers_add(hoststub_headers* headers, const char* key, size_t key_length,
+                          const char* value, size_t value_length);
+
+typedef struct {
+  // base is the allocated memory, and data is the start of the remaining bytes after draining.
+  char* base;
+  char* data;
+  size_t length;
+} hoststub_slice;
+
+// hoststub_buffer is the body buffer passed as the request or response body. This consists of the
+// slices in the same way as Envoy's Buffer::Instance.
+typedef struct {
+  hoststub_slice* slices;
+  size_t size;
+  size_t capacity;
+  // kind is the direction of the body the buffer is last passed as, which is checked by the Buffer API to
+  // catch the request body accessed via the response body API and vice versa.
+  int kind;
+} hoststub_buffer;
+
+enum {
+  HOSTSTUB_BUFFER_ANY,
+  HOSTSTUB_BUFFER_REQUEST,
+  HOSTSTUB_BUFFER_RESPONSE,
+};
+
+hoststub_buffer* hoststub_buffer_new();
+void hoststub_buffer_free(hoststub_buffer* buffer);
+void hoststub_buffer_append(hoststub_buffer* buffer, const char* data, size_t length);
+size_t hoststub_buffer_length(hoststub_buffer* buffer);
+void hoststub_buffer_copy_out(hoststub_buffer* buffer, size_t offset, size_t length, char* dst);
+
+// hoststub_stream is the Envoy filter instance of a stream, which holds the buffered bodies
+// returned by __envoy_dynamic_module_v1_http_get_request_body_buffer and the response one.
+typedef struct {
+  hoststub_buffer* request_body;
+  hoststub_buffer* response_body;
+  // local_reply_status is the status code passed to __envoy_dynamic_module_v1_http_send_response,
+  // or zero if not called. The headers and the body are allocated on the call, and freed with the stream.
+  size_t local_reply_status;
+  hoststub_headers* local_reply_headers;
+  hoststub_buffer* local_reply_body;
+  // request_injected and response_injected are the data passed to
+  // __envoy_dynamic_module_v1_http_inject_request_data and the response one, which are allocated on the
+  // first call, and freed with the stream.
+  hoststub_buffer* request_injected;
+  hoststub_buffer* response_injected;
+} hoststub_stream;
+
+void hoststub_stream_free(hoststub_stream* stream);
@@ -0,0 +1,168 @@
+// Code generated by internal/abigen from abi.h. DO NOT EDIT.
+
+// The default no-op definitions of the Envoy API. Each function can be overridden by a strong definition.
+
+#define ENVOY_DYNAMIC_MODULE
+#include "abi.h"
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_get_abi_version() { return 0; }
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_http_get_request_header_value(__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr) { return 0; }
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_get_request_header_value_nth(__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr, size_t nth) {}
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_http_get_response_header_value(__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr) { return 0; }
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_get_response_header_value_nth(__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr, size_t nth) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_set_request_header(__envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr headers, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength, __envoy_dynamic_module_v1_type_InModuleBufferPtr value, __envoy_dynamic_module_v1_type_InModuleBufferLength valueLength) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_set_response_header(__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength, __envoy_dynamic_module_v1_type_InModuleBufferPtr value, __envoy_dynamic_module_v1_type_InModuleBufferLength valueLength) {}
+
+__attribute__((weak)) __envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr __envoy_dynamic_module_v1_http_get_request_body_buffer(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr) { return 0; }
+
+__attribute__((weak)) __envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr __envoy_dynamic_module_v1_http_get_response_body_buffer(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr) { return 0; }
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_http_get_request_body_buffer_length(__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer) { return 0; }
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_http_get_request_body_buffer_slices_count(__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer) { return 0; }
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_get_request_body_buffer_slice(__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer, size_t nth, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_copy_out_request_body_buffer(__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer, size_t offset, size_t length, __envoy_dynamic_module_v1_type_InModuleBufferPtr resultBufferPtr) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_append_request_body_buffer(__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer, __envoy_dynamic_module_v1_type_InModuleBufferPtr data, __envoy_dynamic_module_v1_type_InModuleBufferLength dataLength) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_prepend_request_body_buffer(__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer, __envoy_dynamic_module_v1_type_InModuleBufferPtr data, __envoy_dynamic_module_v1_type_InModuleBufferLength dataLength) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_drain_request_body_buffer(__envoy_dynamic_module_v1_type_HttpRequestBodyBufferPtr buffer, size_t length) {}
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_http_get_response_body_buffer_length(__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer) { return 0; }
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_http_get_response_body_buffer_slices_count(__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer) { return 0; }
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_get_response_body_buffer_slice(__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer, size_t nth, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_copy_out_response_body_buffer(__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer, size_t offset, size_t length, __envoy_dynamic_module_v1_type_InModuleBufferPtr resultBufferPtr) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_append_response_body_buffer(__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer, __envoy_dynamic_module_v1_type_InModuleBufferPtr data, __envoy_dynamic_module_v1_type_InModuleBufferLength dataLength) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_prepend_response_body_buffer(__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer, __envoy_dynamic_module_v1_type_InModuleBufferPtr data, __envoy_dynamic_module_v1_type_InModuleBufferLength dataLength) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_drain_response_body_buffer(__envoy_dynamic_module_v1_type_HttpResponseBodyBufferPtr buffer, size_t length) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_continue_request(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_continue_response(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_read_disable_request(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr, size_t disable) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_read_disable_response(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr, size_t disable) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_inject_request_data(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr, __envoy_dynamic_module_v1_type_InModuleBufferPtr data, __envoy_dynamic_module_v1_type_InModuleBufferLength dataLength, __envoy_dynamic_module_v1_type_EndOfStream endOfStream) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_inject_response_data(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr, __envoy_dynamic_module_v1_type_InModuleBufferPtr data, __envoy_dynamic_module_v1_type_InModuleBufferLength dataLength, __envoy_dynamic_module_v1_type_EndOfStream endOfStream) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_add_request_body(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr, __envoy_dynamic_module_v1_type_InModuleBufferPtr data, __envoy_dynamic_module_v1_type_InModuleBufferLength dataLength) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_add_response_body(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr, __envoy_dynamic_module_v1_type_InModuleBufferPtr data, __envoy_dynamic_module_v1_type_InModuleBufferLength dataLength) {}
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_http_get_upstream_attempt(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr) { return 0; }
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_http_get_upstream_cluster_name(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr) { return 0; }
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_http_get_upstream_host_address(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr) { return 0; }
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_bypass_events(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr, __envoy_dynamic_module_v1_type_HttpFilterEventMask events) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_send_response(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr, uint32_t statusCode, __envoy_dynamic_module_v1_type_InModuleHeadersPtr headersVector, __envoy_dynamic_module_v1_type_InModuleHeadersSize headersVectorSize, __envoy_dynamic_module_v1_type_InModuleBufferPtr body, __envoy_dynamic_module_v1_type_InModuleBufferLength bodyLength) {}
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_network_get_read_buffer_length(__envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer) { return 0; }
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_network_get_read_buffer_slices_count(__envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer) { return 0; }
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_network_get_read_buffer_slice(__envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer, size_t nth, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_network_copy_out_read_buffer(__envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer, size_t offset, size_t length, __envoy_dynamic_module_v1_type_InModuleBufferPtr resultBufferPtr) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_network_append_read_buffer(__envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer, __envoy_dynamic_module_v1_type_InModuleBufferPtr data, __envoy_dynamic_module_v1_type_InModuleBufferLength dataLength) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_network_prepend_read_buffer(__envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer, __envoy_dynamic_module_v1_type_InModuleBufferPtr data, __envoy_dynamic_module_v1_type_InModuleBufferLength dataLength) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_network_drain_read_buffer(__envoy_dynamic_module_v1_type_NetworkReadBufferPtr buffer, size_t length) {}
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_network_get_write_buffer_length(__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer) { return 0; }
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_network_get_write_buffer_slices_count(__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer) { return 0; }
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_network_get_write_buffer_slice(__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer, size_t nth, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_network_copy_out_write_buffer(__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer, size_t offset, size_t length, __envoy_dynamic_module_v1_type_InModuleBufferPtr resultBufferPtr) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_network_append_write_buffer(__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer, __envoy_dynamic_module_v1_type_InModuleBufferPtr data, __envoy_dynamic_module_v1_type_InModuleBufferLength dataLength) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_network_prepend_write_buffer(__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer, __envoy_dynamic_module_v1_type_InModuleBufferPtr data, __envoy_dynamic_module_v1_type_InModuleBufferLength dataLength) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_network_drain_write_buffer(__envoy_dynamic_module_v1_type_NetworkWriteBufferPtr buffer, size_t length) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_network_continue_reading(__envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr envoyFilterInstancePtr) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_network_write(__envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr envoyFilterInstancePtr, __envoy_dynamic_module_v1_type_InModuleBufferPtr data, __envoy_dynamic_module_v1_type_InModuleBufferLength dataLength, __envoy_dynamic_module_v1_type_EndOfStream endOfStream) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_network_close(__envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr envoyFilterInstancePtr, size_t flushWrite) {}
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_network_get_remote_address(__envoy_dynamic_module_v1_type_EnvoyNetworkFilterInstancePtr envoyFilterInstancePtr, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr) { return 0; }
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_listener_get_peek_buffer_length(__envoy_dynamic_module_v1_type_ListenerPeekBufferPtr buffer) { return 0; }
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_listener_get_peek_buffer_slices_count(__envoy_dynamic_module_v1_type_ListenerPeekBufferPtr buffer) { return 0; }
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_listener_get_peek_buffer_slice(__envoy_dynamic_module_v1_type_ListenerPeekBufferPtr buffer, size_t nth, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_listener_copy_out_peek_buffer(__envoy_dynamic_module_v1_type_ListenerPeekBufferPtr buffer, size_t offset, size_t length, __envoy_dynamic_module_v1_type_InModuleBufferPtr resultBufferPtr) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_listener_set_detected_transport_protocol(__envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr envoyFilterInstancePtr, __envoy_dynamic_module_v1_type_InModuleBufferPtr protocol, __envoy_dynamic_module_v1_type_InModuleBufferLength protocolLength) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_listener_set_requested_server_name(__envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr envoyFilterInstancePtr, __envoy_dynamic_module_v1_type_InModuleBufferPtr serverName, __envoy_dynamic_module_v1_type_InModuleBufferLength serverNameLength) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_listener_continue_filter_chain(__envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr envoyFilterInstancePtr, size_t success) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_listener_close_socket(__envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr envoyFilterInstancePtr) {}
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_listener_get_remote_address(__envoy_dynamic_module_v1_type_EnvoyListenerFilterInstancePtr envoyFilterInstancePtr, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr) { return 0; }
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_access_log_get_header_value(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr, __envoy_dynamic_module_v1_type_AccessLogHeadersType headersType, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr) { return 0; }
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_access_log_get_header_value_nth(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr, __envoy_dynamic_module_v1_type_AccessLogHeadersType headersType, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr, size_t nth) {}
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_access_log_get_headers_count(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr, __envoy_dynamic_module_v1_type_AccessLogHeadersType headersType) { return 0; }
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_access_log_get_header_nth(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr, __envoy_dynamic_module_v1_type_AccessLogHeadersType headersType, size_t nth, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultKeyPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultKeyLengthPtr, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultValuePtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultValueLengthPtr) {}
+
+__attribute__((weak)) int64_t __envoy_dynamic_module_v1_access_log_get_start_time(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr) { return 0; }
+
+__attribute__((weak)) int64_t __envoy_dynamic_module_v1_access_log_get_timing(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr, __envoy_dynamic_module_v1_type_AccessLogTiming timing) { return 0; }
+
+__attribute__((weak)) uint64_t __envoy_dynamic_module_v1_access_log_get_bytes_received(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr) { return 0; }
+
+__attribute__((weak)) uint64_t __envoy_dynamic_module_v1_access_log_get_bytes_sent(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr) { return 0; }
+
+__attribute__((weak)) uint32_t __envoy_dynamic_module_v1_access_log_get_response_code(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr) { return 0; }
+
+__attribute__((weak)) uint64_t __envoy_dynamic_module_v1_access_log_get_response_flags(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr) { return 0; }
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_access_log_get_dynamic_metadata(__envoy_dynamic_module_v1_type_AccessLogEntryPtr logEntryPtr, __envoy_dynamic_module_v1_type_InModuleBufferPtr metadataNamespace, __envoy_dynamic_module_v1_type_InModuleBufferLength metadataNamespaceLength, __envoy_dynamic_module_v1_type_InModuleBufferPtr key, __envoy_dynamic_module_v1_type_InModuleBufferLength keyLength, __envoy_dynamic_module_v1_type_DataSlicePtrResult resultBufferPtr, __envoy_dynamic_module_v1_type_DataSliceLengthResult resultBufferLengthPtr) { return 0; }
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_stats_set_gauge(__envoy_dynamic_module_v1_type_InModuleBufferPtr name, __envoy_dynamic_module_v1_type_InModuleBufferLength nameLength, uint64_t value) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_stats_increment_counter(__envoy_dynamic_module_v1_type_InModuleBufferPtr name, __envoy_dynamic_module_v1_type_InModuleBufferLength nameLength, uint64_t value) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_mutate_request_headers(__envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr headers, __envoy_dynamic_module_v1_type_InModuleBuffersPtr remove, __envoy_dynamic_module_v1_type_InModuleBuffersSize removeSize, __envoy_dynamic_module_v1_type_InModuleHeadersPtr set, __envoy_dynamic_module_v1_type_InModuleHeadersSize setSize, __envoy_dynamic_module_v1_type_InModuleHeadersPtr add, __envoy_dynamic_module_v1_type_InModuleHeadersSize addSize) {}
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_mutate_response_headers(__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers, __envoy_dynamic_module_v1_type_InModuleBuffersPtr remove, __envoy_dynamic_module_v1_type_InModuleBuffersSize removeSize, __envoy_dynamic_module_v1_type_InModuleHeadersPtr set, __envoy_dynamic_module_v1_type_InModuleHeadersSize setSize, __envoy_dynamic_module_v1_type_InModuleHeadersPtr add, __envoy_dynamic_module_v1_type_InModuleHeadersSize addSize) {}
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_http_get_request_header_values(__envoy_dynamic_module_v1_type_HttpRequestHeadersMapPtr headers, __envoy_dynamic_module_v1_type_InModuleBuffersPtr keys, __envoy_dynamic_module_v1_type_InModuleBuffersSize keysSize, __envoy_dynamic_module_v1_type_DataSlicesResult results) { return 0; }
+
+__attribute__((weak)) size_t __envoy_dynamic_module_v1_http_get_response_header_values(__envoy_dynamic_module_v1_type_HttpResponseHeaderMapPtr headers, __envoy_dynamic_module_v1_type_InModuleBuffersPtr keys, __envoy_dynamic_module_v1_type_InModuleBuffersSize keysSize, __envoy_dynamic_module_v1_type_DataSlicesResult results) { return 0; }
+
+__attribute__((weak)) void __envoy_dynamic_module_v1_http_reset_stream(__envoy_dynamic_module_v1_type_EnvoyFilterInstancePtr envoyFilterInstancePtr) {}